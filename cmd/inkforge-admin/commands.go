@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/infrastructures/archive"
+	"github.com/InkForge/Blog_Website/infrastructures/db/migrations"
+)
+
+type userResult struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role"`
+	Verified bool   `json:"verified"`
+	Created  bool   `json:"created"`
+}
+
+type actionResult struct {
+	Email  string `json:"email"`
+	Action string `json:"action"`
+}
+
+type contentResult struct {
+	Action   string `json:"action"`
+	Tags     int    `json:"tags"`
+	Blogs    int    `json:"blogs"`
+	Comments int    `json:"comments"`
+}
+
+type discrepancyResult struct {
+	Collection string `json:"collection"`
+	DocumentID string `json:"document_id"`
+	Field      string `json:"field"`
+	Stored     int    `json:"stored"`
+	Actual     int    `json:"actual"`
+}
+
+type recountResult struct {
+	DryRun          bool                `json:"dry_run"`
+	StartedAt       time.Time           `json:"started_at"`
+	FinishedAt      time.Time           `json:"finished_at"`
+	BlogsChecked    int                 `json:"blogs_checked"`
+	CommentsChecked int                 `json:"comments_checked"`
+	Repaired        int                 `json:"repaired"`
+	Skipped         int                 `json:"skipped"`
+	Discrepancies   []discrepancyResult `json:"discrepancies"`
+}
+
+func createAdmin(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin")
+	username := flags.String("username", "", "username, when creating the admin")
+	password := flags.String("password", "", "password, when creating the admin; read from stdin if empty")
+	if err := parseFlags(flags, args); err != nil || *email == "" {
+		return errUsage
+	}
+
+	// promoting an existing user takes no password, so it is only asked for
+	// once CreateAdmin turns out to create the user
+	user, created, err := a.operations.CreateAdmin(ctx, *email, *username, *password)
+	if errors.Is(err, domain.ErrWeakPassword) && *password == "" {
+		pw, readErr := a.readPassword("")
+		if readErr != nil {
+			return readErr
+		}
+		user, created, err = a.operations.CreateAdmin(ctx, *email, *username, pw)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := userResult{
+		UserID:   user.UserID,
+		Email:    user.Email,
+		Role:     string(user.Role),
+		Verified: user.IsVerified,
+		Created:  created,
+	}
+	if user.Username != nil {
+		result.Username = *user.Username
+	}
+	return a.out.print(result, []string{"USER ID", "EMAIL", "USERNAME", "ROLE", "VERIFIED", "CREATED"}, [][]string{{
+		result.UserID, result.Email, result.Username, result.Role, strconv.FormatBool(result.Verified), strconv.FormatBool(result.Created),
+	}})
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "new password; read from stdin if empty")
+	if err := parseFlags(flags, args); err != nil || *email == "" {
+		return errUsage
+	}
+	pw, err := a.readPassword(*password)
+	if err != nil {
+		return err
+	}
+	if err := a.operations.ResetPassword(ctx, *email, pw); err != nil {
+		return err
+	}
+	return a.printAction(*email, "password reset")
+}
+
+func verifyEmail(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("verify-email", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	if err := parseFlags(flags, args); err != nil || *email == "" {
+		return errUsage
+	}
+	if err := a.operations.VerifyEmail(ctx, *email); err != nil {
+		return err
+	}
+	return a.printAction(*email, "email verified")
+}
+
+func (a *app) printAction(email, action string) error {
+	return a.out.print(actionResult{Email: email, Action: action}, []string{"EMAIL", "ACTION"}, [][]string{{email, action}})
+}
+
+func recount(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("recount", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report the counters that drifted")
+	if err := parseFlags(flags, args); err != nil {
+		return errUsage
+	}
+
+	report, err := a.counters.Reconcile(ctx, !*dryRun)
+	if err != nil {
+		return err
+	}
+
+	result := recountResult{
+		DryRun:          *dryRun,
+		StartedAt:       report.Started_at,
+		FinishedAt:      report.Finished_at,
+		BlogsChecked:    report.Blogs_checked,
+		CommentsChecked: report.Comments_checked,
+		Repaired:        report.Repaired,
+		Skipped:         report.Skipped,
+		Discrepancies:   make([]discrepancyResult, len(report.Discrepancies)),
+	}
+	rows := make([][]string, len(report.Discrepancies))
+	for i, d := range report.Discrepancies {
+		result.Discrepancies[i] = discrepancyResult{
+			Collection: d.Collection,
+			DocumentID: d.Document_id,
+			Field:      d.Field,
+			Stored:     d.Stored,
+			Actual:     d.Actual,
+		}
+		rows[i] = []string{d.Collection, d.Document_id, d.Field, strconv.Itoa(d.Stored), strconv.Itoa(d.Actual)}
+	}
+	if err := a.out.print(result, []string{"COLLECTION", "ID", "FIELD", "STORED", "ACTUAL"}, rows); err != nil {
+		return err
+	}
+	if !a.out.json {
+		fmt.Fprintf(a.stdout, "\nchecked %d blogs and %d comments: %d drifted, %d repaired, %d skipped\n",
+			result.BlogsChecked, result.CommentsChecked, len(result.Discrepancies), result.Repaired, result.Skipped)
+	}
+	return nil
+}
+
+func exportContent(ctx context.Context, a *app, args []string) (err error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "", "file to write; stdout if empty")
+	if err := parseFlags(flags, args); err != nil {
+		return errUsage
+	}
+
+	out, summary := a.stdout, a.out
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(*file)
+			}
+		}()
+		out = f
+	} else {
+		// the archive takes stdout
+		summary.w = a.stderr
+	}
+
+	w, err := archive.NewWriter(out, time.Now())
+	if err != nil {
+		return err
+	}
+	counts, err := a.operations.ExportContent(ctx, w)
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return printCounts(summary, "exported", counts)
+}
+
+func importContent(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "file to read; stdin if empty")
+	if err := parseFlags(flags, args); err != nil {
+		return errUsage
+	}
+
+	in := a.stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	counts, err := a.operations.ImportContent(ctx, func(sink domain.IContentSink) error {
+		return archive.NewReader(in).Read(sink)
+	})
+	if err != nil {
+		// what was upserted before the error stays, and importing again is safe
+		printCounts(printer{w: a.stderr}, "imported", counts)
+		return err
+	}
+	return printCounts(a.out, "imported", counts)
+}
+
+func printCounts(p printer, action string, counts domain.ContentCounts) error {
+	result := contentResult{Action: action, Tags: counts.Tags, Blogs: counts.Blogs, Comments: counts.Comments}
+	return p.print(result, []string{"ACTION", "TAGS", "BLOGS", "COMMENTS"}, [][]string{{
+		action, strconv.Itoa(counts.Tags), strconv.Itoa(counts.Blogs), strconv.Itoa(counts.Comments),
+	}})
+}
+
+func migrate(ctx context.Context, a *app, args []string) error {
+	if !a.out.json || len(args) != 1 || args[0] != "status" {
+		return migrations.RunCommand(ctx, a.migrator, args, a.stdout)
+	}
+	statuses, err := a.migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	type statusResult struct {
+		Version   int        `json:"version"`
+		Name      string     `json:"name"`
+		AppliedAt *time.Time `json:"applied_at"`
+	}
+	results := make([]statusResult, len(statuses))
+	for i, s := range statuses {
+		results[i] = statusResult{Version: s.Version, Name: s.Name}
+		if s.Applied {
+			appliedAt := s.Applied_at
+			results[i].AppliedAt = &appliedAt
+		}
+	}
+	return a.out.print(results, nil, nil)
+}
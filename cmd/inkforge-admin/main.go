@@ -0,0 +1,183 @@
+// Command inkforge-admin runs operational tasks against the database the
+// server is configured for, through the same repositories and usecases.
+//
+//	inkforge-admin [-o table|json] <command> [flags]
+//
+// Run it without a command for the list of commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/InkForge/Blog_Website/domain"
+	infrastructures2 "github.com/InkForge/Blog_Website/infrastructures"
+	infrastructures "github.com/InkForge/Blog_Website/infrastructures/auth"
+	"github.com/InkForge/Blog_Website/infrastructures/db/migrations"
+	mongo "github.com/InkForge/Blog_Website/infrastructures/db/mongo"
+	"github.com/InkForge/Blog_Website/infrastructures/logging"
+	"github.com/InkForge/Blog_Website/repositories"
+	"github.com/InkForge/Blog_Website/usecases"
+	"golang.org/x/term"
+)
+
+// errUsage makes the command print its usage and exit with status 2.
+var errUsage = errors.New("usage")
+
+// app is what commands run with.
+type app struct {
+	operations domain.IOperationsUseCase
+	counters   domain.ICounterUseCase
+	migrator   *migrations.Migrator
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	out    printer
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{"create-admin", "create-admin -email EMAIL [-username NAME] [-password PASSWORD]\n\tcreate a verified admin, or promote the user with the email", createAdmin},
+	{"reset-password", "reset-password -email EMAIL [-password PASSWORD]\n\tset a user's password and sign them out", resetPassword},
+	{"verify-email", "verify-email -email EMAIL\n\tmark a user's email as verified", verifyEmail},
+	{"recount", "recount [-dry-run]\n\trecount the reaction, view and comment counters of blogs and comments", recount},
+	{"export", "export [-file FILE]\n\twrite tags, blogs and comments as JSON, to stdout by default", exportContent},
+	{"import", "import [-file FILE]\n\tupsert the tags, blogs and comments of an export, from stdin by default", importContent},
+	{"migrate", "migrate up | down [steps] | status\n\tapply, roll back or list database migrations", migrate},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("inkforge-admin", flag.ContinueOnError)
+	output := flags.String("o", "table", "output format: table or json")
+	flags.Usage = func() { usage(os.Stderr) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output != "table" && *output != "json" {
+		usage(os.Stderr)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		usage(os.Stderr)
+		return 2
+	}
+	cmd, ok := findCommand(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flags.Arg(0))
+		usage(os.Stderr)
+		return 2
+	}
+
+	configs, err := infrastructures2.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading config failed:", err)
+		return 1
+	}
+	// stdout may carry an export, so logs go to stderr
+	logger, err := logging.New(os.Stderr, configs.LogLevel, configs.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "configuring the logger failed:", err)
+		return 1
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := mongo.NewMongoClient()
+	defer client.Disconnect(context.Background())
+	db := client.Database(configs.DBName)
+
+	migrator, err := migrations.New(db, migrations.All())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	userRepo := repositories.NewUserRepository(db)
+	a := &app{
+		operations: usecases.NewOperationsUseCase(userRepo, repositories.NewContentRepository(db), infrastructures.NewPasswordService()),
+		counters:   usecases.NewCounterUseCase(repositories.NewCounterRepository(db)),
+		migrator:   migrator,
+		stdin:      os.Stdin,
+		stdout:     os.Stdout,
+		stderr:     os.Stderr,
+		out:        printer{w: os.Stdout, json: *output == "json"},
+	}
+
+	err = cmd.run(ctx, a, flags.Args()[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, migrations.ErrUsage):
+		fmt.Fprintf(os.Stderr, "usage: inkforge-admin [-o table|json] %s\n", cmd.usage)
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		return 1
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: inkforge-admin [-o table|json] <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n", cmd.usage)
+	}
+}
+
+// parseFlags parses a command's flags, turning a parse error into errUsage.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+	return nil
+}
+
+// readPassword returns flagValue, or else the first line of stdin. On a
+// terminal it prompts and reads without echoing. Passing the password on
+// stdin keeps it out of the process list and shell history.
+func (a *app) readPassword(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if f, ok := a.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(a.stderr, "Password: ")
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(a.stderr)
+		return string(password), err
+	}
+	line, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPassword(t *testing.T) {
+	var stderr bytes.Buffer
+	a := &app{stdin: strings.NewReader("s3cret password\r\nnext line\n"), stderr: &stderr}
+
+	password, err := a.readPassword("")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret password", password)
+	assert.Empty(t, stderr.String(), "no prompt when stdin is not a terminal")
+
+	password, err = a.readPassword("from-flag")
+	require.NoError(t, err)
+	assert.Equal(t, "from-flag", password)
+}
+
+func TestReadPassword_FromPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = w.WriteString("piped1234")
+	require.NoError(t, err)
+	w.Close()
+
+	var stderr bytes.Buffer
+	a := &app{stdin: r, stderr: &stderr}
+	password, err := a.readPassword("")
+	require.NoError(t, err)
+	assert.Equal(t, "piped1234", password)
+	assert.Empty(t, stderr.String())
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
@@ -32,9 +34,10 @@ func (ac *AIController) SuggestTags(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
 	defer cancel()
-	tags, err := ac.aiUsecase.SuggestTags(ctx, req.Title, req.Content, req.MaxTags)
+	tags, err := ac.aiUsecase.SuggestTags(ctx, aiRequester(c), req.Title, req.Content, req.MaxTags)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "SuggestTagsFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "SuggestTagsFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.SuggestTagsResponse{Tags: tags})
@@ -53,9 +56,10 @@ func (ac *AIController) Summarize(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
 	defer cancel()
-	summary, err := ac.aiUsecase.Summarize(ctx, req.Content, req.MaxWords)
+	summary, err := ac.aiUsecase.Summarize(ctx, aiRequester(c), req.Content, req.MaxWords)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "SummarizeFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "SummarizeFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.SummarizeResponse{Summary: summary})
@@ -74,9 +78,10 @@ func (ac *AIController) GenerateTitle(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
 	defer cancel()
-	title, err := ac.aiUsecase.GenerateTitle(ctx, req.Content, req.Style)
+	title, err := ac.aiUsecase.GenerateTitle(ctx, aiRequester(c), req.Content, req.Style)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "GenerateTitleFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "GenerateTitleFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.GenerateTitleResponse{Title: title})
@@ -98,9 +103,10 @@ func (ac *AIController) SuggestContent(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 25*time.Second)
 	defer cancel()
-	content, err := ac.aiUsecase.SuggestContent(ctx, req.Keywords, req.Style, req.WordCount)
+	content, err := ac.aiUsecase.SuggestContent(ctx, aiRequester(c), req.Keywords, req.Style, req.WordCount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "SuggestContentFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "SuggestContentFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.SuggestContentResponse{Content: content})
@@ -119,9 +125,10 @@ func (ac *AIController) ImproveContent(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 25*time.Second)
 	defer cancel()
-	res, err := ac.aiUsecase.ImproveContent(ctx, req.Content, req.Focus)
+	res, err := ac.aiUsecase.ImproveContent(ctx, aiRequester(c), req.Content, req.Focus)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "ImproveContentFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "ImproveContentFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.ImproveContentResponse{ImprovedContent: res.ImprovedContent, Suggestions: res.Suggestions})
@@ -145,10 +152,162 @@ func (ac *AIController) Chat(c *gin.Context) {
 	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
-	out, err := ac.aiUsecase.Chat(ctx, msgs)
+	extendWriteDeadline(c, 30*time.Second)
+	out, err := ac.aiUsecase.Chat(ctx, aiRequester(c), msgs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "ChatFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		status := aiErrorStatus(err)
+		c.JSON(status, dto.ErrorResponse{Error: "ChatFailed", Message: err.Error(), Code: status})
 		return
 	}
 	c.JSON(http.StatusOK, dto.ChatResponse{Message: dto.ChatMessage{Role: out.Role, Content: out.Content}})
 }
+
+// SuggestContentStream drafts content like SuggestContent but streams it as server-sent events.
+func (ac *AIController) SuggestContentStream(c *gin.Context) {
+	if ac.aiUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI service not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+	var req dto.SuggestContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+	if req.WordCount <= 0 {
+		req.WordCount = 250
+	}
+	streamAIResponse(c, "SuggestContentFailed", func(ctx context.Context, onDelta domain.AIStreamHandler) (dto.StreamDoneEvent, error) {
+		content, err := ac.aiUsecase.SuggestContentStream(ctx, aiRequester(c), req.Keywords, req.Style, req.WordCount, onDelta)
+		return dto.StreamDoneEvent{Content: content}, err
+	})
+}
+
+// ImproveContentStream streams an improved version of draft content as server-sent events.
+func (ac *AIController) ImproveContentStream(c *gin.Context) {
+	if ac.aiUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI service not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+	var req dto.ImproveContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+	streamAIResponse(c, "ImproveContentFailed", func(ctx context.Context, onDelta domain.AIStreamHandler) (dto.StreamDoneEvent, error) {
+		content, err := ac.aiUsecase.ImproveContentStream(ctx, aiRequester(c), req.Content, req.Focus, onDelta)
+		return dto.StreamDoneEvent{Content: content}, err
+	})
+}
+
+// ChatStream performs a stateless chat turn and streams the reply as server-sent events.
+func (ac *AIController) ChatStream(c *gin.Context) {
+	if ac.aiUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI service not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+	var req dto.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+	msgs := make([]domain.AIMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		msgs = append(msgs, domain.AIMessage{Role: m.Role, Content: m.Content})
+	}
+	streamAIResponse(c, "ChatFailed", func(ctx context.Context, onDelta domain.AIStreamHandler) (dto.StreamDoneEvent, error) {
+		out, err := ac.aiUsecase.ChatStream(ctx, aiRequester(c), msgs, onDelta)
+		return dto.StreamDoneEvent{Content: out.Content, Role: out.Role}, err
+	})
+}
+
+// streamAIResponse runs a streaming AI call and writes its output as server-sent
+// events: a "delta" event per fragment, then either "done" with the full text or
+// "error". Failures that happen before the first fragment are answered with a
+// regular JSON error instead, since the status code can still be set.
+func streamAIResponse(c *gin.Context, errorName string, run func(ctx context.Context, onDelta domain.AIStreamHandler) (dto.StreamDoneEvent, error)) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+	extendWriteDeadline(c, 2*time.Minute)
+
+	started := false
+	startStream := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+	}
+
+	done, err := run(ctx, func(delta string) error {
+		// stop generating once the client has gone away
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		startStream()
+		c.SSEvent("delta", dto.StreamDeltaEvent{Content: delta})
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err != nil {
+		if !started {
+			status := aiErrorStatus(err)
+			c.JSON(status, dto.ErrorResponse{Error: errorName, Message: err.Error(), Code: status})
+			return
+		}
+		c.SSEvent("error", dto.ErrorResponse{Error: errorName, Message: err.Error(), Code: http.StatusBadGateway})
+		c.Writer.Flush()
+		return
+	}
+
+	startStream()
+	c.SSEvent("done", done)
+	c.Writer.Flush()
+}
+
+// aiRequester identifies the authenticated caller for AI metering and quotas.
+// The fresh=true query parameter bypasses cached AI results.
+func aiRequester(c *gin.Context) domain.AIRequestInfo {
+	fresh, _ := strconv.ParseBool(c.Query("fresh"))
+	return domain.AIRequestInfo{
+		UserID: c.GetString("userID"),
+		Role:   domain.Role(c.GetString("userRole")),
+		Fresh:  fresh,
+	}
+}
+
+// aiErrorStatus maps AI usecase errors to HTTP status codes.
+func aiErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContentMissing):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrAIQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrAIMalformedResponse):
+		return http.StatusBadGateway
+	case errors.Is(err, domain.ErrAIProvidersUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ProviderHealth reports the circuit breaker state of every AI provider in the fallback chain.
+func (ac *AIController) ProviderHealth(c *gin.Context) {
+	if ac.aiUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI service not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+	health := ac.aiUsecase.ProviderHealth(c.Request.Context())
+	c.JSON(http.StatusOK, dto.FromDomainProviderHealth(health))
+}
+
+// extendWriteDeadline lets a long-running response outlive the server's write
+// timeout. It is a no-op if the writer does not support deadlines.
+func extendWriteDeadline(c *gin.Context, timeout time.Duration) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(timeout))
+}
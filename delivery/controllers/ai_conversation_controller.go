@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AIConversationController struct {
+	conversationUsecase domain.IAIConversationUseCase
+}
+
+// NewAIConversationController creates a controller for persistent AI chat conversations.
+func NewAIConversationController(conversationUsecase domain.IAIConversationUseCase) *AIConversationController {
+	return &AIConversationController{conversationUsecase: conversationUsecase}
+}
+
+// CreateConversation starts a conversation, optionally anchored to one of the user's blogs.
+func (cc *AIConversationController) CreateConversation(c *gin.Context) {
+	var req dto.CreateAIConversationRequest
+	// the body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	conversation, err := cc.conversationUsecase.CreateConversation(ctx, c.GetString("userID"), req.BlogID)
+	if err != nil {
+		writeConversationError(c, "CreateConversationFailed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromDomainAIConversation(conversation))
+}
+
+// ListConversations lists the user's conversations, most recently active first.
+func (cc *AIConversationController) ListConversations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	conversations, pagination, err := cc.conversationUsecase.ListConversations(ctx, c.GetString("userID"), page, limit)
+	if err != nil {
+		writeConversationError(c, "ListConversationsFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainAIConversationList(conversations, pagination))
+}
+
+// GetConversation returns a conversation with all of its messages.
+func (cc *AIConversationController) GetConversation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	conversation, err := cc.conversationUsecase.GetConversation(ctx, c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		writeConversationError(c, "GetConversationFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainAIConversation(conversation))
+}
+
+// RenameConversation changes the conversation title.
+func (cc *AIConversationController) RenameConversation(c *gin.Context) {
+	var req dto.RenameAIConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := cc.conversationUsecase.RenameConversation(ctx, c.Param("id"), c.GetString("userID"), req.Title); err != nil {
+		writeConversationError(c, "RenameConversationFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation renamed successfully"})
+}
+
+// DeleteConversation removes a conversation and its messages.
+func (cc *AIConversationController) DeleteConversation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := cc.conversationUsecase.DeleteConversation(ctx, c.Param("id"), c.GetString("userID")); err != nil {
+		writeConversationError(c, "DeleteConversationFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted successfully"})
+}
+
+// SendMessage posts a user message and returns the assistant's reply.
+func (cc *AIConversationController) SendMessage(c *gin.Context) {
+	var req dto.SendAIMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 45*time.Second)
+	defer cancel()
+	extendWriteDeadline(c, 45*time.Second)
+
+	reply, err := cc.conversationUsecase.SendMessage(ctx, c.Param("id"), aiRequester(c), req.Content)
+	if err != nil {
+		writeConversationError(c, "SendMessageFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": dto.FromDomainAIMessage(reply)})
+}
+
+func writeConversationError(c *gin.Context, errorName string, err error) {
+	status := aiErrorStatus(err)
+	switch {
+	case errors.Is(err, domain.ErrAIConversationNotFound), errors.Is(err, domain.ErrBlogNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidConversationID), errors.Is(err, domain.ErrInvalidBlogID),
+		errors.Is(err, domain.ErrEmptyConversationTitle), errors.Is(err, domain.ErrInvalidUserID):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotBlogAuthor):
+		status = http.StatusForbidden
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	c.JSON(status, dto.ErrorResponse{Error: errorName, Message: err.Error(), Code: status})
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AIPromptController struct {
+	promptUsecase domain.IPromptTemplateUseCase
+}
+
+// NewAIPromptController creates a controller for managing AI prompt template versions.
+func NewAIPromptController(promptUsecase domain.IPromptTemplateUseCase) *AIPromptController {
+	return &AIPromptController{promptUsecase: promptUsecase}
+}
+
+// ListPrompts lists every prompt with its latest version and rollout.
+func (pc *AIPromptController) ListPrompts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	infos, err := pc.promptUsecase.ListTemplates(ctx)
+	if err != nil {
+		writePromptError(c, "ListPromptsFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainPromptTemplateInfos(infos))
+}
+
+// ListVersions returns every version of one prompt, including the built-in default.
+func (pc *AIPromptController) ListVersions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	name := c.Param("name")
+	versions, rollout, err := pc.promptUsecase.ListVersions(ctx, name)
+	if err != nil {
+		writePromptError(c, "ListVersionsFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainPromptVersions(name, versions, rollout))
+}
+
+// CreateVersion stores a new version of a prompt. It is not used until rolled out.
+func (pc *AIPromptController) CreateVersion(c *gin.Context) {
+	var req dto.PromptVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	version, err := pc.promptUsecase.CreateVersion(ctx, c.Param("name"), req.Body, c.GetString("userID"))
+	if err != nil {
+		writePromptError(c, "CreateVersionFailed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromDomainPromptTemplate(version))
+}
+
+// SetRollout activates versions of a prompt with the given traffic weights.
+func (pc *AIPromptController) SetRollout(c *gin.Context) {
+	var req dto.PromptRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := pc.promptUsecase.SetRollout(ctx, c.Param("name"), req.ToDomain()); err != nil {
+		writePromptError(c, "SetRolloutFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "prompt rollout updated successfully"})
+}
+
+// VersionStats compares requests, failures, latency and tokens of each version
+// of a prompt over the same period as the usage report.
+func (pc *AIPromptController) VersionStats(c *gin.Context) {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	name := c.Param("name")
+	stats, err := pc.promptUsecase.VersionStats(ctx, name, from, to)
+	if err != nil {
+		writePromptError(c, "PromptStatsFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainPromptVersionStats(name, from, to, stats))
+}
+
+func writePromptError(c *gin.Context, code string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrUnknownPromptTemplate), errors.Is(err, domain.ErrPromptVersionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPromptTemplate), errors.Is(err, domain.ErrInvalidPromptRollout):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{Error: code, Message: err.Error(), Code: status})
+}
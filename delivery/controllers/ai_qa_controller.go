@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AIQAController struct {
+	qaUsecase domain.IBlogQAUseCase
+}
+
+// NewAIQAController creates a controller for answering questions from blog posts.
+// A nil usecase means no embedding provider is configured.
+func NewAIQAController(qaUsecase domain.IBlogQAUseCase) *AIQAController {
+	return &AIQAController{qaUsecase: qaUsecase}
+}
+
+// Ask answers a question from the blog's own posts, with citations.
+func (qc *AIQAController) Ask(c *gin.Context) {
+	if qc.qaUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI question answering not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+	var req dto.AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	extendWriteDeadline(c, 30*time.Second)
+
+	answer, err := qc.qaUsecase.Ask(ctx, aiRequester(c), req.Question)
+	if err != nil {
+		status := aiErrorStatus(err)
+		switch {
+		case errors.Is(err, domain.ErrEmptyQuestion):
+			status = http.StatusBadRequest
+		case errors.Is(err, domain.ErrAIEmbeddingFailed):
+			status = http.StatusBadGateway
+		case errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusGatewayTimeout
+		}
+		c.JSON(status, dto.ErrorResponse{Error: "AskFailed", Message: err.Error(), Code: status})
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainAIAnswer(answer))
+}
+
+// Reindex queues every blog for re-embedding.
+func (qc *AIQAController) Reindex(c *gin.Context) {
+	if qc.qaUsecase == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "ServiceUnavailable", Message: "AI question answering not configured", Code: http.StatusServiceUnavailable})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	extendWriteDeadline(c, 30*time.Second)
+
+	queued, err := qc.qaUsecase.ReindexAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "ReindexFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		return
+	}
+	c.JSON(http.StatusAccepted, dto.ReindexResponse{Queued: queued})
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AIUsageController struct {
+	usageUsecase domain.IAIUsageUseCase
+}
+
+// NewAIUsageController creates a controller exposing AI usage and quota endpoints.
+func NewAIUsageController(usageUsecase domain.IAIUsageUseCase) *AIUsageController {
+	return &AIUsageController{usageUsecase: usageUsecase}
+}
+
+// MyUsage returns the caller's AI usage and remaining daily and monthly quota.
+func (uc *AIUsageController) MyUsage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := uc.usageUsecase.QuotaStatus(ctx, aiRequester(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "UsageLookupFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainAIQuotaStatus(status))
+}
+
+// UsageReport aggregates AI usage and estimated cost by provider and model.
+// The period defaults to the last 30 days; from and to accept RFC 3339 timestamps
+// or YYYY-MM-DD dates.
+func (uc *AIUsageController) UsageReport(c *gin.Context) {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	report, err := uc.usageUsecase.Report(ctx, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "UsageReportFailed", Message: err.Error(), Code: http.StatusInternalServerError})
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainAIUsageReport(report))
+}
+
+// SetUserQuota overrides the role default AI quota of one user. Zero limits are unlimited.
+func (uc *AIUsageController) SetUserQuota(c *gin.Context) {
+	var req dto.AIQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := uc.usageUsecase.SetUserQuota(ctx, c.Param("id"), req.ToDomain()); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidAIQuota) || errors.Is(err, domain.ErrInvalidUserID) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, dto.ErrorResponse{Error: "SetQuotaFailed", Message: err.Error(), Code: status})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "AI quota updated successfully"})
+}
+
+// reportPeriod reads the from and to query parameters, defaulting to the last
+// 30 days. It writes the error response and returns false if they are invalid.
+func reportPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = parseReportTime(v); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidFrom", Message: err.Error(), Code: http.StatusBadRequest})
+			return from, to, false
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = parseReportTime(v); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidTo", Message: err.Error(), Code: http.StatusBadRequest})
+			return from, to, false
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPeriod", Message: "from must be before to", Code: http.StatusBadRequest})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/InkForge/Blog_Website/domain"
@@ -30,6 +31,11 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
+	// emails go out in the browser's language unless one is given
+	if input.Language == "" {
+		input.Language = preferredLanguage(c.GetHeader("Accept-Language"))
+	}
+
 	// context timeout handling
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -172,6 +178,44 @@ func (au *AuthController) ChangePassword(c *gin.Context) {
 
 }
 
+// SetPassword adds a password to an account created through an OAuth provider,
+// so the user can also log in with their email and password.
+// Returns 400 for a weak password and 409 if the account already has one.
+func (au *AuthController) SetPassword(c *gin.Context) {
+	type payload struct {
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	var body payload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "failed to fetch userID"})
+		return
+	}
+
+	err := au.AuthUsecase.SetPassword(c, userID, body.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		case errors.Is(err, domain.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPasswordAlreadySet):
+			c.JSON(http.StatusConflict, gin.H{"error": "password is already set, change it instead"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set password", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password set successfully"})
+}
+
 // ResetPassword handles password reset using a reset token sent via email.
 // It expects a valid token and a new password, and updates the user’s password if valid.
 // Returns 400 for invalid token or password input, or 500 on internal failure.
@@ -347,3 +391,15 @@ func (au *AuthController) Logout(c *gin.Context) {
 	})
 	c.Status(http.StatusOK)
 }
+
+// preferredLanguage returns the first language of an Accept-Language header,
+// e.g. "fr-CA" for "fr-CA,fr;q=0.9,en;q=0.8".
+func preferredLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
@@ -3,7 +3,7 @@ package controllers
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -75,7 +75,7 @@ func (bc *BlogController) CreateBlog(c *gin.Context) {
 		case errors.Is(err, domain.ErrDocumentDecoding):
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode document", "details": err.Error()})
 		default:
-			log.Printf("Error creating blog: %v", err)
+			slog.ErrorContext(c.Request.Context(), "creating blog failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog", "details": err.Error()})
 		}
 		return
@@ -116,7 +116,7 @@ func (bc *BlogController) GetAllBlogs(c *gin.Context) {
 		case errors.Is(err, domain.ErrBlogNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "No blogs found"})
 		default:
-			log.Printf("Error fetching all blogs: %v", err)
+			slog.ErrorContext(c.Request.Context(), "fetching all blogs failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blogs", "details": err.Error()})
 		}
 		return
@@ -156,7 +156,7 @@ func (bc *BlogController) GetBlogByID(c *gin.Context) {
 		case errors.Is(err, domain.ErrBlogNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
 		case errors.Is(err, domain.ErrViewRecordAlreadyExists):
-			log.Printf("View record already exists for blog %s by user %s. Returning blog anyway.", blogID, userID)
+			slog.DebugContext(c.Request.Context(), "view already recorded, returning blog anyway", "blog_id", blogID, "user_id", userID)
 			c.JSON(http.StatusOK, gin.H{"blog": blog})
 		case errors.Is(err, domain.ErrCreateViewRecordFailed):
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create view record", "details": err.Error()})
@@ -169,7 +169,7 @@ func (bc *BlogController) GetBlogByID(c *gin.Context) {
 		case errors.Is(err, domain.ErrQueryFailed):
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute query", "details": err.Error()})
 		default:
-			log.Printf("Error fetching blog by ID %s: %v", blogID, err)
+			slog.ErrorContext(c.Request.Context(), "fetching blog failed", "blog_id", blogID, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blog", "details": err.Error()})
 		}
 		return
@@ -240,7 +240,7 @@ func (bc *BlogController) UpdateBlog(c *gin.Context) {
 		case errors.Is(err, domain.ErrCursorFailed):
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cursor failed", "details": err.Error()})
 		default:
-			log.Printf("Error updating blog %s: %v", blogID, err)
+			slog.ErrorContext(c.Request.Context(), "updating blog failed", "blog_id", blogID, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update blog", "details": err.Error()})
 		}
 		return
@@ -267,7 +267,7 @@ func (bc *BlogController) DeleteBlog(c *gin.Context) {
 		case errors.Is(err, domain.ErrBlogNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
-			log.Printf("Error deleting blog %s: %v", blogID, err)
+			slog.ErrorContext(c.Request.Context(), "deleting blog failed", "blog_id", blogID, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog", "details": err.Error()})
 		}
 		return
@@ -301,7 +301,7 @@ func (bc *BlogController) Search(c *gin.Context) {
 		case errors.Is(err, domain.ErrUserNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "No users found for author search"})
 		default:
-			log.Printf("Error searching blogs: %v", err)
+			slog.ErrorContext(c.Request.Context(), "searching blogs failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search blogs", "details": err.Error()})
 		}
 		return
@@ -341,7 +341,7 @@ func (bc *BlogController) FilterBlogs(c *gin.Context) {
 		case errors.Is(err, domain.ErrBlogNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "No blogs found"})
 		default:
-			log.Printf("Error filtering blogs: %v", err)
+			slog.ErrorContext(c.Request.Context(), "filtering blogs failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to filter blogs", "details": err.Error()})
 		}
 		return
@@ -349,3 +349,73 @@ func (bc *BlogController) FilterBlogs(c *gin.Context) {
 	jsonResponse := dto.FromDomainPaginatedBlogs(*paginatedBlogs)
 	c.JSON(http.StatusOK, gin.H{"blogs": jsonResponse})
 }
+
+func (bc *BlogController) SetAutoEnrich(c *gin.Context) {
+	blogID := c.Param("id")
+
+	var input dto.AutoEnrichRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Payload", "details": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	err := bc.BlogUsecase.SetAutoEnrich(ctx, blogID, c.GetString("userID"), *input.Enabled)
+	if err != nil {
+		writeSuggestionError(c, blogID, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Auto enrichment updated", "auto_enrich": *input.Enabled})
+}
+
+func (bc *BlogController) AcceptSuggestedTags(c *gin.Context) {
+	bc.reviewSuggestedTags(c, bc.BlogUsecase.AcceptSuggestedTags, "Suggested tags accepted")
+}
+
+func (bc *BlogController) RejectSuggestedTags(c *gin.Context) {
+	bc.reviewSuggestedTags(c, bc.BlogUsecase.RejectSuggestedTags, "Suggested tags rejected")
+}
+
+func (bc *BlogController) reviewSuggestedTags(c *gin.Context, review func(ctx context.Context, blogID, userID string, tags []string) error, message string) {
+	blogID := c.Param("id")
+
+	var input dto.SuggestedTagsRequest
+	// an empty body reviews every suggestion
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Payload", "details": err.Error()})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := review(ctx, blogID, c.GetString("userID"), input.Tags); err != nil {
+		writeSuggestionError(c, blogID, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+func writeSuggestionError(c *gin.Context, blogID string, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out"})
+	case errors.Is(err, domain.ErrBlogIDRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Blog ID is required"})
+	case errors.Is(err, domain.ErrInvalidBlogID), errors.Is(err, domain.ErrInvalidBlogIdFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blog ID"})
+	case errors.Is(err, domain.ErrBlogNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+	case errors.Is(err, domain.ErrNotBlogAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can change this blog"})
+	case errors.Is(err, domain.ErrNoSuggestedTags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "None of the given tags is currently suggested"})
+	default:
+		slog.ErrorContext(c.Request.Context(), "updating blog failed", "blog_id", blogID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update blog", "details": err.Error()})
+	}
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// counterReconcileTimeout bounds a recount of every blog and comment.
+const counterReconcileTimeout = 2 * time.Minute
+
+type CounterController struct {
+	counterUsecase domain.ICounterUseCase
+}
+
+// NewCounterController creates a controller for reconciling blog and comment counters.
+func NewCounterController(counterUsecase domain.ICounterUseCase) *CounterController {
+	return &CounterController{counterUsecase: counterUsecase}
+}
+
+// ListDiscrepancies recounts every counter and reports the ones that drifted,
+// without changing them.
+func (cc *CounterController) ListDiscrepancies(c *gin.Context) {
+	cc.reconcile(c, false)
+}
+
+// Reconcile recounts every counter and sets the ones that drifted right.
+func (cc *CounterController) Reconcile(c *gin.Context) {
+	cc.reconcile(c, true)
+}
+
+func (cc *CounterController) reconcile(c *gin.Context, repair bool) {
+	extendWriteDeadline(c, counterReconcileTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), counterReconcileTimeout)
+	defer cancel()
+
+	report, err := cc.counterUsecase.Reconcile(ctx, repair)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrCounterReconcileRunning) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ErrorResponse{Error: "ReconcileCountersFailed", Message: err.Error(), Code: status})
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainCounterReport(report))
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type DigestController struct {
+	digestUsecase domain.IDigestUseCase
+}
+
+// NewDigestController creates a controller for digest preferences and unsubscribe links.
+func NewDigestController(digestUsecase domain.IDigestUseCase) *DigestController {
+	return &DigestController{digestUsecase: digestUsecase}
+}
+
+// GetPreferences returns the caller's digest frequency and followed authors and tags.
+func (dc *DigestController) GetPreferences(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := dc.digestUsecase.GetPreferences(ctx, c.GetString("userID"))
+	if err != nil {
+		writeDigestError(c, "GetDigestPreferencesFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainDigestSubscription(sub))
+}
+
+// UpdatePreferences replaces the caller's digest preferences.
+func (dc *DigestController) UpdatePreferences(c *gin.Context) {
+	var req dto.DigestPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := dc.digestUsecase.UpdatePreferences(ctx, c.GetString("userID"), domain.DigestFrequency(req.Frequency), req.AuthorIDs, req.TagIDs)
+	if err != nil {
+		writeDigestError(c, "UpdateDigestPreferencesFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainDigestSubscription(sub))
+}
+
+// Unsubscribe turns digests off for the user of the link's token. It needs no
+// login, and accepts POST for mail clients' one-click unsubscribe.
+func (dc *DigestController) Unsubscribe(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := dc.digestUsecase.Unsubscribe(ctx, c.Query("token")); err != nil {
+		writeDigestError(c, "UnsubscribeFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "you will no longer receive digest emails"})
+}
+
+func writeDigestError(c *gin.Context, code string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrInvalidDigestFrequency), errors.Is(err, domain.ErrInvalidDigestPreferences),
+		errors.Is(err, domain.ErrInvalidUnsubscribeToken):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{Error: code, Message: err.Error(), Code: status})
+}
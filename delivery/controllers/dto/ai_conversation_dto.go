@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type CreateAIConversationRequest struct {
+	BlogID string `json:"blog_id"`
+}
+
+type RenameAIConversationRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type SendAIMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type AIMessageResponse struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AIConversationResponse struct {
+	ConversationID string              `json:"conversation_id"`
+	BlogID         string              `json:"blog_id,omitempty"`
+	Title          string              `json:"title"`
+	Messages       []AIMessageResponse `json:"messages,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+type AIConversationListResponse struct {
+	Conversations []AIConversationResponse `json:"conversations"`
+	Pagination    PaginationJson           `json:"pagination"`
+}
+
+func FromDomainAIMessage(m domain.AIMessage) AIMessageResponse {
+	return AIMessageResponse{Role: m.Role, Content: m.Content, CreatedAt: m.Created_at}
+}
+
+func FromDomainAIConversation(c *domain.AIConversation) AIConversationResponse {
+	messages := make([]AIMessageResponse, 0, len(c.Messages))
+	for _, m := range c.Messages {
+		messages = append(messages, FromDomainAIMessage(m))
+	}
+	return AIConversationResponse{
+		ConversationID: c.Conversation_id,
+		BlogID:         c.Blog_id,
+		Title:          c.Title,
+		Messages:       messages,
+		CreatedAt:      c.Created_at,
+		UpdatedAt:      c.Updated_at,
+	}
+}
+
+func FromDomainAIConversationList(conversations []domain.AIConversation, p domain.Pagination) AIConversationListResponse {
+	items := make([]AIConversationResponse, 0, len(conversations))
+	for i := range conversations {
+		items = append(items, FromDomainAIConversation(&conversations[i]))
+	}
+	return AIConversationListResponse{
+		Conversations: items,
+		Pagination: PaginationJson{
+			Page:  p.Page,
+			Limit: p.Limit,
+			Total: p.Total,
+		},
+	}
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
 type SuggestTagsRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
@@ -63,6 +69,21 @@ type ChatResponse struct {
 	Message ChatMessage `json:"message"`
 }
 
+type AIProviderHealthResponse struct {
+	Name                string     `json:"name"`
+	Model               string     `json:"model"`
+	Priority            int        `json:"priority"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+type AIProvidersHealthResponse struct {
+	Providers []AIProviderHealthResponse `json:"providers"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
@@ -72,6 +93,28 @@ type ErrorResponse struct {
 type ChatCompletionRequest struct {
 	Model    string        `json:"model"`
 	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// StreamOptions with IncludeUsage asks for token usage in the last stream chunk.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Usage is the token accounting returned by OpenAI-compatible providers.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ResponseFormat asks OpenAI-compatible providers for a specific output format,
+// e.g. {"type": "json_object"}.
+type ResponseFormat struct {
+	Type string `json:"type"`
 }
 
 type ChatCompletionChoice struct {
@@ -84,4 +127,58 @@ type ChatCompletionResponse struct {
 	Created int64                   `json:"created"`
 	Model   string                  `json:"model"`
 	Choices []ChatCompletionChoice  `json:"choices"`
-}
\ No newline at end of file
+	Usage   *Usage                  `json:"usage,omitempty"`
+}
+
+// ChatCompletionChunk is one server-sent event of a streamed chat completion.
+type ChatCompletionChunk struct {
+	ID      string                     `json:"id"`
+	Model   string                     `json:"model"`
+	Choices []ChatCompletionChunkChoice `json:"choices"`
+	Usage   *Usage                     `json:"usage,omitempty"`
+	// Groq reports stream usage under x_groq instead of usage.
+	XGroq *struct {
+		Usage *Usage `json:"usage,omitempty"`
+	} `json:"x_groq,omitempty"`
+}
+
+type ChatCompletionChunkChoice struct {
+	Delta        ChatMessage `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+// StreamDeltaEvent is sent to the browser for every generated fragment.
+type StreamDeltaEvent struct {
+	Content string `json:"content"`
+}
+
+// StreamDoneEvent is sent once the full response has been generated.
+type StreamDoneEvent struct {
+	Content string `json:"content"`
+	Role    string `json:"role,omitempty"`
+}
+
+func FromDomainProviderHealth(health []domain.AIProviderHealth) AIProvidersHealthResponse {
+	providers := make([]AIProviderHealthResponse, len(health))
+	for i, h := range health {
+		providers[i] = AIProviderHealthResponse{
+			Name:                h.Name,
+			Model:               h.Model,
+			Priority:            h.Priority,
+			State:               h.State,
+			ConsecutiveFailures: h.ConsecutiveFailures,
+			LastError:           h.LastError,
+			LastFailureAt:       optionalTime(h.LastFailureAt),
+			RetryAt:             optionalTime(h.RetryAt),
+		}
+	}
+	return AIProvidersHealthResponse{Providers: providers}
+}
+
+// optionalTime returns nil for the zero time so it is omitted from JSON.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type PromptVersionRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type PromptRolloutEntry struct {
+	Version int `json:"version"`
+	Weight  int `json:"weight"`
+}
+
+// PromptRolloutRequest replaces the traffic split of a prompt. An empty list
+// goes back to the built-in default.
+type PromptRolloutRequest struct {
+	Versions []PromptRolloutEntry `json:"versions"`
+}
+
+func (r PromptRolloutRequest) ToDomain() []domain.PromptRollout {
+	rollout := make([]domain.PromptRollout, len(r.Versions))
+	for i, v := range r.Versions {
+		rollout[i] = domain.PromptRollout{Version: v.Version, Weight: v.Weight}
+	}
+	return rollout
+}
+
+type PromptTemplateJson struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	Body      string    `json:"body"`
+	Default   bool      `json:"default"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type PromptTemplateSummaryJson struct {
+	Name          string               `json:"name"`
+	LatestVersion int                  `json:"latest_version"`
+	Rollout       []PromptRolloutEntry `json:"rollout"`
+}
+
+type PromptVersionsResponse struct {
+	Name     string               `json:"name"`
+	Rollout  []PromptRolloutEntry `json:"rollout"`
+	Versions []PromptTemplateJson `json:"versions"`
+}
+
+type PromptVersionStatsJson struct {
+	Version        int     `json:"version"`
+	Requests       int     `json:"requests"`
+	Failures       int     `json:"failures"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms"`
+	AvgTotalTokens float64 `json:"avg_total_tokens"`
+}
+
+type PromptStatsResponse struct {
+	Name     string                   `json:"name"`
+	From     time.Time                `json:"from"`
+	To       time.Time                `json:"to"`
+	Versions []PromptVersionStatsJson `json:"versions"`
+}
+
+func FromDomainPromptTemplate(t domain.PromptTemplate) PromptTemplateJson {
+	return PromptTemplateJson{
+		Name:      t.Name,
+		Version:   t.Version,
+		Body:      t.Body,
+		Default:   t.Version == 0,
+		CreatedBy: t.Created_by,
+		CreatedAt: t.Created_at,
+	}
+}
+
+func fromDomainPromptRollout(rollout []domain.PromptRollout) []PromptRolloutEntry {
+	entries := make([]PromptRolloutEntry, len(rollout))
+	for i, r := range rollout {
+		entries[i] = PromptRolloutEntry{Version: r.Version, Weight: r.Weight}
+	}
+	return entries
+}
+
+func FromDomainPromptTemplateInfos(infos []domain.PromptTemplateInfo) []PromptTemplateSummaryJson {
+	out := make([]PromptTemplateSummaryJson, len(infos))
+	for i, info := range infos {
+		out[i] = PromptTemplateSummaryJson{
+			Name:          info.Name,
+			LatestVersion: info.Latest_version,
+			Rollout:       fromDomainPromptRollout(info.Rollout),
+		}
+	}
+	return out
+}
+
+func FromDomainPromptVersions(name string, versions []domain.PromptTemplate, rollout []domain.PromptRollout) PromptVersionsResponse {
+	out := PromptVersionsResponse{
+		Name:     name,
+		Rollout:  fromDomainPromptRollout(rollout),
+		Versions: make([]PromptTemplateJson, len(versions)),
+	}
+	for i, v := range versions {
+		out.Versions[i] = FromDomainPromptTemplate(v)
+	}
+	return out
+}
+
+func FromDomainPromptVersionStats(name string, from, to time.Time, stats []domain.PromptVersionStats) PromptStatsResponse {
+	out := PromptStatsResponse{
+		Name:     name,
+		From:     from,
+		To:       to,
+		Versions: make([]PromptVersionStatsJson, len(stats)),
+	}
+	for i, s := range stats {
+		out.Versions[i] = PromptVersionStatsJson{
+			Version:        s.Version,
+			Requests:       s.Requests,
+			Failures:       s.Failures,
+			AvgLatencyMs:   s.Avg_latency_ms,
+			AvgTotalTokens: s.Avg_total_tokens,
+		}
+	}
+	return out
+}
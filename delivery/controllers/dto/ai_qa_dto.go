@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/InkForge/Blog_Website/domain"
+
+type AskRequest struct {
+	Question string `json:"question" binding:"required"`
+}
+
+type AICitationResponse struct {
+	BlogID  string  `json:"blog_id"`
+	Title   string  `json:"title"`
+	Excerpt string  `json:"excerpt"`
+	URL     string  `json:"url"`
+	Score   float64 `json:"score"`
+}
+
+type AskResponse struct {
+	Answer    string               `json:"answer"`
+	Citations []AICitationResponse `json:"citations"`
+}
+
+type ReindexResponse struct {
+	Queued int `json:"queued"`
+}
+
+func FromDomainAIAnswer(answer domain.AIAnswer) AskResponse {
+	citations := make([]AICitationResponse, 0, len(answer.Citations))
+	for _, c := range answer.Citations {
+		citations = append(citations, AICitationResponse{
+			BlogID:  c.Blog_id,
+			Title:   c.Title,
+			Excerpt: c.Excerpt,
+			URL:     "/blogs/" + c.Blog_id,
+			Score:   c.Score,
+		})
+	}
+	return AskResponse{Answer: answer.Answer, Citations: citations}
+}
@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type AIQuotaRequest struct {
+	DailyRequests   int `json:"daily_requests"`
+	MonthlyRequests int `json:"monthly_requests"`
+	DailyTokens     int `json:"daily_tokens"`
+	MonthlyTokens   int `json:"monthly_tokens"`
+}
+
+func (r AIQuotaRequest) ToDomain() domain.AIQuota {
+	return domain.AIQuota{
+		Daily_requests:   r.DailyRequests,
+		Monthly_requests: r.MonthlyRequests,
+		Daily_tokens:     r.DailyTokens,
+		Monthly_tokens:   r.MonthlyTokens,
+	}
+}
+
+// AIQuotaWindowResponse reports usage in one period. Limits and remaining
+// counts are omitted when unlimited.
+type AIQuotaWindowResponse struct {
+	RequestsUsed      int       `json:"requests_used"`
+	RequestsLimit     *int      `json:"requests_limit,omitempty"`
+	RequestsRemaining *int      `json:"requests_remaining,omitempty"`
+	TokensUsed        int       `json:"tokens_used"`
+	TokensLimit       *int      `json:"tokens_limit,omitempty"`
+	TokensRemaining   *int      `json:"tokens_remaining,omitempty"`
+	ResetsAt          time.Time `json:"resets_at"`
+}
+
+type AIQuotaStatusResponse struct {
+	Unlimited bool                   `json:"unlimited"`
+	Daily     *AIQuotaWindowResponse `json:"daily,omitempty"`
+	Monthly   *AIQuotaWindowResponse `json:"monthly,omitempty"`
+}
+
+type AIUsageReportRowResponse struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	Failures         int     `json:"failures"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+}
+
+type AIUsageReportResponse struct {
+	From      time.Time                  `json:"from"`
+	To        time.Time                  `json:"to"`
+	Providers []AIUsageReportRowResponse `json:"providers"`
+	Total     AIUsageReportRowResponse   `json:"total"`
+}
+
+func FromDomainAIQuotaStatus(s domain.AIQuotaStatus) AIQuotaStatusResponse {
+	if s.Unlimited {
+		return AIQuotaStatusResponse{Unlimited: true}
+	}
+	daily := fromDomainAIQuotaWindow(s.Daily)
+	monthly := fromDomainAIQuotaWindow(s.Monthly)
+	return AIQuotaStatusResponse{Daily: &daily, Monthly: &monthly}
+}
+
+func fromDomainAIQuotaWindow(w domain.AIQuotaWindow) AIQuotaWindowResponse {
+	res := AIQuotaWindowResponse{
+		RequestsUsed: w.Requests_used,
+		TokensUsed:   w.Tokens_used,
+		ResetsAt:     w.Resets_at,
+	}
+	if w.Requests_limit > 0 {
+		res.RequestsLimit = intPtr(w.Requests_limit)
+		res.RequestsRemaining = intPtr(max(w.Requests_limit-w.Requests_used, 0))
+	}
+	if w.Tokens_limit > 0 {
+		res.TokensLimit = intPtr(w.Tokens_limit)
+		res.TokensRemaining = intPtr(max(w.Tokens_limit-w.Tokens_used, 0))
+	}
+	return res
+}
+
+func FromDomainAIUsageReport(r domain.AIUsageReport) AIUsageReportResponse {
+	rows := make([]AIUsageReportRowResponse, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		rows = append(rows, fromDomainAIUsageReportRow(row))
+	}
+	return AIUsageReportResponse{
+		From:      r.From,
+		To:        r.To,
+		Providers: rows,
+		Total:     fromDomainAIUsageReportRow(r.Total),
+	}
+}
+
+func fromDomainAIUsageReportRow(row domain.AIUsageReportRow) AIUsageReportRowResponse {
+	return AIUsageReportRowResponse{
+		Provider:         row.Provider,
+		Model:            row.Model,
+		Requests:         row.Requests,
+		Failures:         row.Failures,
+		PromptTokens:     row.Prompt_tokens,
+		CompletionTokens: row.Completion_tokens,
+		EstimatedCost:    row.Estimated_cost,
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
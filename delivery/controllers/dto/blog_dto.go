@@ -30,6 +30,22 @@ type BlogJson struct {
 	ViewCount    int       `json:"view_count"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// AutoEnrich is only read on create; use PUT /blogs/:id/auto-enrich to change it.
+	AutoEnrich       bool     `json:"auto_enrich"`
+	Summary          string   `json:"summary,omitempty"`
+	SuggestedTags    []string `json:"suggested_tags,omitempty"`
+	EnrichmentStatus string   `json:"enrichment_status,omitempty"`
+}
+
+type AutoEnrichRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SuggestedTagsRequest names the suggested tags to accept or reject.
+// An empty list means all of them.
+type SuggestedTagsRequest struct {
+	Tags []string `json:"tags"`
 }
 
 func FromDomainBlog(blog *domain.Blog) *BlogJson {
@@ -46,6 +62,11 @@ func FromDomainBlog(blog *domain.Blog) *BlogJson {
 		ViewCount:    blog.View_count,
 		CreatedAt:    blog.Created_at,
 		UpdatedAt:    blog.Updated_at,
+
+		AutoEnrich:       blog.Auto_enrich,
+		Summary:          blog.Summary,
+		SuggestedTags:    blog.Suggested_tags,
+		EnrichmentStatus: blog.EnrichmentState(),
 	}
 }
 
@@ -63,6 +84,7 @@ func (bj *BlogJson) ToDomainBlog() *domain.Blog {
 		View_count:    bj.ViewCount,
 		Created_at:    bj.CreatedAt,
 		Updated_at:    bj.UpdatedAt,
+		Auto_enrich:   bj.AutoEnrich,
 	}
 }
 
@@ -80,3 +102,19 @@ func FromDomainPaginatedBlogs(pb domain.PaginatedBlogs) PaginatedBlogsJson {
 		},
 	}
 }
+
+type RelatedBlogJson struct {
+	BlogJson
+	Score float64 `json:"score"`
+}
+
+func FromDomainRelatedBlogPosts(posts []domain.RelatedBlogPost) []RelatedBlogJson {
+	related := make([]RelatedBlogJson, 0, len(posts))
+	for i := range posts {
+		related = append(related, RelatedBlogJson{
+			BlogJson: *FromDomainBlog(&posts[i].Blog),
+			Score:    posts[i].Score,
+		})
+	}
+	return related
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type CounterDiscrepancyResponse struct {
+	Collection string `json:"collection"`
+	DocumentID string `json:"document_id"`
+	Field      string `json:"field"`
+	Stored     int    `json:"stored"`
+	Actual     int    `json:"actual"`
+}
+
+// CounterReportResponse is the result of a reconciliation. Repaired and
+// skipped stay 0 when nothing was repaired.
+type CounterReportResponse struct {
+	StartedAt       time.Time                    `json:"started_at"`
+	FinishedAt      time.Time                    `json:"finished_at"`
+	BlogsChecked    int                          `json:"blogs_checked"`
+	CommentsChecked int                          `json:"comments_checked"`
+	Repaired        int                          `json:"repaired"`
+	Skipped         int                          `json:"skipped"`
+	Discrepancies   []CounterDiscrepancyResponse `json:"discrepancies"`
+}
+
+func FromDomainCounterReport(r domain.CounterReport) CounterReportResponse {
+	discrepancies := make([]CounterDiscrepancyResponse, len(r.Discrepancies))
+	for i, d := range r.Discrepancies {
+		discrepancies[i] = CounterDiscrepancyResponse{
+			Collection: d.Collection,
+			DocumentID: d.Document_id,
+			Field:      d.Field,
+			Stored:     d.Stored,
+			Actual:     d.Actual,
+		}
+	}
+	return CounterReportResponse{
+		StartedAt:       r.Started_at,
+		FinishedAt:      r.Finished_at,
+		BlogsChecked:    r.Blogs_checked,
+		CommentsChecked: r.Comments_checked,
+		Repaired:        r.Repaired,
+		Skipped:         r.Skipped,
+		Discrepancies:   discrepancies,
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type DigestPreferencesRequest struct {
+	Frequency string   `json:"frequency" binding:"required,oneof=off daily weekly"`
+	AuthorIDs []string `json:"author_ids"`
+	TagIDs    []string `json:"tag_ids"`
+}
+
+type DigestPreferencesResponse struct {
+	Frequency  string     `json:"frequency"`
+	AuthorIDs  []string   `json:"author_ids"`
+	TagIDs     []string   `json:"tag_ids"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	NextSendAt *time.Time `json:"next_send_at,omitempty"`
+}
+
+func FromDomainDigestSubscription(sub domain.DigestSubscription) DigestPreferencesResponse {
+	res := DigestPreferencesResponse{
+		Frequency: string(sub.Frequency),
+		AuthorIDs: sub.Author_ids,
+		TagIDs:    sub.Tag_ids,
+	}
+	if res.AuthorIDs == nil {
+		res.AuthorIDs = []string{}
+	}
+	if res.TagIDs == nil {
+		res.TagIDs = []string{}
+	}
+	if sub.Frequency != domain.DigestOff {
+		res.LastSentAt = optionalTime(sub.Last_sent_at)
+		res.NextSendAt = optionalTime(sub.Next_send_at)
+	}
+	return res
+}
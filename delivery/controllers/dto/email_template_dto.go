@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type EmailTemplateRequest struct {
+	Subject string `json:"subject" binding:"required"`
+	Html    string `json:"html" binding:"required"`
+	Text    string `json:"text" binding:"required"`
+}
+
+func (r EmailTemplateRequest) ToDomain(name, locale, userID string) domain.EmailTemplate {
+	return domain.EmailTemplate{
+		Name:       name,
+		Locale:     locale,
+		Subject:    r.Subject,
+		Html:       r.Html,
+		Text:       r.Text,
+		Updated_by: userID,
+	}
+}
+
+type EmailTemplateSummaryJson struct {
+	Name       string     `json:"name"`
+	Locale     string     `json:"locale"`
+	Overridden bool       `json:"overridden"`
+	UpdatedBy  string     `json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type EmailTemplateJson struct {
+	Name       string     `json:"name"`
+	Locale     string     `json:"locale"`
+	Subject    string     `json:"subject"`
+	Html       string     `json:"html"`
+	Text       string     `json:"text"`
+	Overridden bool       `json:"overridden"`
+	UpdatedBy  string     `json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type EmailPreviewResponse struct {
+	Subject string `json:"subject"`
+	Html    string `json:"html"`
+	Text    string `json:"text"`
+	Locale  string `json:"locale"`
+}
+
+func FromDomainEmailTemplateInfos(infos []domain.EmailTemplateInfo) []EmailTemplateSummaryJson {
+	out := make([]EmailTemplateSummaryJson, len(infos))
+	for i, info := range infos {
+		out[i] = EmailTemplateSummaryJson{
+			Name:       info.Name,
+			Locale:     info.Locale,
+			Overridden: info.Overridden,
+			UpdatedBy:  info.Updated_by,
+			UpdatedAt:  optionalTime(info.Updated_at),
+		}
+	}
+	return out
+}
+
+func FromDomainEmailTemplate(t domain.EmailTemplate, overridden bool) EmailTemplateJson {
+	return EmailTemplateJson{
+		Name:       t.Name,
+		Locale:     t.Locale,
+		Subject:    t.Subject,
+		Html:       t.Html,
+		Text:       t.Text,
+		Overridden: overridden,
+		UpdatedBy:  t.Updated_by,
+		UpdatedAt:  optionalTime(t.Updated_at),
+	}
+}
+
+func FromDomainRenderedEmail(e domain.RenderedEmail) EmailPreviewResponse {
+	return EmailPreviewResponse{Subject: e.Subject, Html: e.HTML, Text: e.Text, Locale: e.Locale}
+}
+
+type OutboxEmailJson struct {
+	ID            string     `json:"id"`
+	To            string     `json:"to"`
+	Subject       string     `json:"subject"`
+	Template      string     `json:"template,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	Delivered     []string   `json:"delivered_channels,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+type OutboxListResponse struct {
+	Counts     map[string]int    `json:"counts"`
+	Emails     []OutboxEmailJson `json:"emails"`
+	Pagination PaginationJson    `json:"pagination"`
+}
+
+func FromDomainOutboxList(emails []domain.OutboxEmail, counts map[domain.OutboxStatus]int, p domain.Pagination) OutboxListResponse {
+	out := OutboxListResponse{
+		Counts:     make(map[string]int, len(counts)),
+		Emails:     make([]OutboxEmailJson, len(emails)),
+		Pagination: PaginationJson{Page: p.Page, Limit: p.Limit, Total: p.Total},
+	}
+	for status, count := range counts {
+		out.Counts[string(status)] = count
+	}
+	for i, e := range emails {
+		item := OutboxEmailJson{
+			ID:        e.Email_id,
+			To:        e.To,
+			Subject:   e.Subject,
+			Template:  e.Template,
+			Status:    string(e.Status),
+			Attempts:  e.Attempts,
+			LastError: e.Last_error,
+			Delivered: e.Delivered_channels,
+			CreatedAt: e.Created_at,
+			SentAt:    optionalTime(e.Sent_at),
+		}
+		// only meaningful while the email is still to be sent
+		if e.Status == domain.OutboxPending || e.Status == domain.OutboxSending {
+			item.NextAttemptAt = optionalTime(e.Next_attempt_at)
+		}
+		out.Emails[i] = item
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/InkForge/Blog_Website/domain"
+
+type DependencyHealthJson struct {
+	Name      string `json:"name"`
+	Critical  bool   `json:"critical"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks []DependencyHealthJson `json:"checks,omitempty"`
+}
+
+func FromDomainHealthReport(report domain.HealthReport) HealthResponse {
+	res := HealthResponse{Status: string(report.Status), Checks: make([]DependencyHealthJson, len(report.Checks))}
+	for i, check := range report.Checks {
+		res.Checks[i] = DependencyHealthJson{
+			Name:      check.Name,
+			Critical:  check.Critical,
+			Status:    string(check.Status),
+			LatencyMs: check.Latency.Milliseconds(),
+			Error:     check.Error,
+		}
+	}
+	return res
+}
@@ -0,0 +1,150 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type NewsletterSubscribeRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	AuthorID string `json:"author_id"`
+	Language string `json:"language"`
+}
+
+type NewsletterBounceRequest struct {
+	Email   string `json:"email" binding:"required"`
+	Type    string `json:"type" binding:"required,oneof=hard soft complaint"`
+	IssueID string `json:"issue_id"`
+}
+
+type SendNewsletterIssueRequest struct {
+	BlogID string `json:"blog_id" binding:"required"`
+}
+
+type NewsletterSubscriberJson struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	Language    string     `json:"language,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	LeftAt      *time.Time `json:"left_at,omitempty"`
+}
+
+type NewsletterSubscriberListResponse struct {
+	Subscribers []NewsletterSubscriberJson `json:"subscribers"`
+	Pagination  PaginationJson             `json:"pagination"`
+}
+
+func FromDomainNewsletterSubscriber(sub domain.NewsletterSubscriber) NewsletterSubscriberJson {
+	return NewsletterSubscriberJson{
+		ID:          sub.Subscriber_id,
+		Email:       sub.Email,
+		Language:    sub.Language,
+		Status:      string(sub.Status),
+		CreatedAt:   sub.Created_at,
+		ConfirmedAt: optionalTime(sub.Confirmed_at),
+		LeftAt:      optionalTime(sub.Left_at),
+	}
+}
+
+func FromDomainNewsletterSubscriberList(subs []domain.NewsletterSubscriber, p domain.Pagination) NewsletterSubscriberListResponse {
+	out := NewsletterSubscriberListResponse{
+		Subscribers: make([]NewsletterSubscriberJson, len(subs)),
+		Pagination:  PaginationJson{Page: p.Page, Limit: p.Limit, Total: p.Total},
+	}
+	for i, sub := range subs {
+		out.Subscribers[i] = FromDomainNewsletterSubscriber(sub)
+	}
+	return out
+}
+
+// NewsletterSubscriberCSVHeader is the first row of a subscriber export.
+var NewsletterSubscriberCSVHeader = []string{"email", "status", "language", "created_at", "confirmed_at", "left_at"}
+
+// NewsletterSubscriberCSVRow formats sub as a row of a subscriber export.
+func NewsletterSubscriberCSVRow(sub domain.NewsletterSubscriber) []string {
+	return []string{csvText(sub.Email), string(sub.Status), csvText(sub.Language), csvTime(sub.Created_at), csvTime(sub.Confirmed_at), csvTime(sub.Left_at)}
+}
+
+// csvText keeps a value anyone could have subscribed with from being read as
+// a formula when the export is opened in a spreadsheet.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type NewsletterIssueJson struct {
+	ID        string     `json:"id"`
+	BlogID    string     `json:"blog_id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+type NewsletterIssueListResponse struct {
+	Issues     []NewsletterIssueJson `json:"issues"`
+	Pagination PaginationJson        `json:"pagination"`
+}
+
+type NewsletterIssueStatsJson struct {
+	Recipients   int `json:"recipients"`
+	Pending      int `json:"pending"`
+	Delivered    int `json:"delivered"`
+	Failed       int `json:"failed"`
+	Bounced      int `json:"bounced"`
+	Unsubscribed int `json:"unsubscribed"`
+}
+
+type NewsletterIssueStatsResponse struct {
+	Issue NewsletterIssueJson      `json:"issue"`
+	Stats NewsletterIssueStatsJson `json:"stats"`
+}
+
+func FromDomainNewsletterIssue(issue domain.NewsletterIssue) NewsletterIssueJson {
+	return NewsletterIssueJson{
+		ID:        issue.Issue_id,
+		BlogID:    issue.Blog_id,
+		Title:     issue.Title,
+		Status:    string(issue.Status),
+		CreatedAt: issue.Created_at,
+		SentAt:    optionalTime(issue.Sent_at),
+	}
+}
+
+func FromDomainNewsletterIssueList(issues []domain.NewsletterIssue, p domain.Pagination) NewsletterIssueListResponse {
+	out := NewsletterIssueListResponse{
+		Issues:     make([]NewsletterIssueJson, len(issues)),
+		Pagination: PaginationJson{Page: p.Page, Limit: p.Limit, Total: p.Total},
+	}
+	for i, issue := range issues {
+		out.Issues[i] = FromDomainNewsletterIssue(issue)
+	}
+	return out
+}
+
+func FromDomainNewsletterIssueStats(issue domain.NewsletterIssue, stats domain.NewsletterIssueStats) NewsletterIssueStatsResponse {
+	return NewsletterIssueStatsResponse{
+		Issue: FromDomainNewsletterIssue(issue),
+		Stats: NewsletterIssueStatsJson{
+			Recipients:   stats.Recipients,
+			Pending:      stats.Pending,
+			Delivered:    stats.Delivered,
+			Failed:       stats.Failed,
+			Bounced:      stats.Bounced,
+			Unsubscribed: stats.Unsubscribed,
+		},
+	}
+}
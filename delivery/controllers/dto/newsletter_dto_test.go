@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewsletterSubscriberCSVRow_EscapesFormulas(t *testing.T) {
+	for _, value := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\tx", "\rx"} {
+		row := NewsletterSubscriberCSVRow(domain.NewsletterSubscriber{Email: value, Language: value})
+		assert.Equal(t, "'"+value, row[0])
+		assert.Equal(t, "'"+value, row[2])
+	}
+
+	row := NewsletterSubscriberCSVRow(domain.NewsletterSubscriber{Email: "reader@example.com", Language: "fr"})
+	assert.Equal(t, "reader@example.com", row[0])
+	assert.Equal(t, "fr", row[2])
+}
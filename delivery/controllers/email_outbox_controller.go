@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailOutboxController struct {
+	emailOutboxUsecase domain.IEmailOutboxUseCase
+}
+
+// NewEmailOutboxController creates a controller for inspecting queued emails.
+func NewEmailOutboxController(emailOutboxUsecase domain.IEmailOutboxUseCase) *EmailOutboxController {
+	return &EmailOutboxController{emailOutboxUsecase: emailOutboxUsecase}
+}
+
+// ListEmails lists outbox emails, newest first, optionally filtered by
+// status, along with the number of emails in each status.
+func (oc *EmailOutboxController) ListEmails(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	status := domain.OutboxStatus(c.Query("status"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	emails, pagination, err := oc.emailOutboxUsecase.ListEmails(ctx, status, page, limit)
+	if err != nil {
+		writeOutboxError(c, "ListOutboxEmailsFailed", err)
+		return
+	}
+	counts, err := oc.emailOutboxUsecase.CountByStatus(ctx)
+	if err != nil {
+		writeOutboxError(c, "ListOutboxEmailsFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainOutboxList(emails, counts, pagination))
+}
+
+// RetryEmail sends a dead email again with a fresh set of attempts.
+func (oc *EmailOutboxController) RetryEmail(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := oc.emailOutboxUsecase.Retry(ctx, c.Param("id")); err != nil {
+		writeOutboxError(c, "RetryOutboxEmailFailed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "email queued for delivery"})
+}
+
+func writeOutboxError(c *gin.Context, code string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrOutboxEmailNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrOutboxEmailNotDead), errors.Is(err, domain.ErrInvalidOutboxStatus):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{Error: code, Message: err.Error(), Code: status})
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailTemplateController struct {
+	emailTemplateUsecase domain.IEmailTemplateUseCase
+}
+
+// NewEmailTemplateController creates a controller for managing transactional email templates.
+func NewEmailTemplateController(emailTemplateUsecase domain.IEmailTemplateUseCase) *EmailTemplateController {
+	return &EmailTemplateController{emailTemplateUsecase: emailTemplateUsecase}
+}
+
+// ListTemplates lists every email in every locale and whether an admin override replaces it.
+func (ec *EmailTemplateController) ListTemplates(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	infos, err := ec.emailTemplateUsecase.ListTemplates(ctx)
+	if err != nil {
+		writeEmailTemplateError(c, "ListEmailTemplatesFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainEmailTemplateInfos(infos))
+}
+
+// GetTemplate returns the template in use for a name and locale.
+func (ec *EmailTemplateController) GetTemplate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	template, overridden, err := ec.emailTemplateUsecase.GetTemplate(ctx, c.Param("name"), c.Param("locale"))
+	if err != nil {
+		writeEmailTemplateError(c, "GetEmailTemplateFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainEmailTemplate(template, overridden))
+}
+
+// OverrideTemplate replaces the built-in template for a name and locale.
+func (ec *EmailTemplateController) OverrideTemplate(c *gin.Context) {
+	var req dto.EmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	template, err := ec.emailTemplateUsecase.OverrideTemplate(ctx, req.ToDomain(c.Param("name"), c.Param("locale"), c.GetString("userID")))
+	if err != nil {
+		writeEmailTemplateError(c, "OverrideEmailTemplateFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainEmailTemplate(template, true))
+}
+
+// ResetTemplate removes the override so the built-in template is used again.
+func (ec *EmailTemplateController) ResetTemplate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := ec.emailTemplateUsecase.ResetTemplate(ctx, c.Param("name"), c.Param("locale")); err != nil {
+		writeEmailTemplateError(c, "ResetEmailTemplateFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "email template reset to the default"})
+}
+
+// Preview renders a template with sample data. Without a body it renders the
+// template in use; with one it renders that draft without saving it.
+func (ec *EmailTemplateController) Preview(c *gin.Context) {
+	var draft *domain.EmailTemplate
+	var req dto.EmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err == nil {
+		template := req.ToDomain(c.Param("name"), c.Param("locale"), "")
+		draft = &template
+	} else if !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	email, err := ec.emailTemplateUsecase.Preview(ctx, c.Param("name"), c.Param("locale"), draft)
+	if err != nil {
+		writeEmailTemplateError(c, "PreviewEmailTemplateFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainRenderedEmail(email))
+}
+
+func writeEmailTemplateError(c *gin.Context, code string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrUnknownEmailTemplate), errors.Is(err, domain.ErrUnsupportedLocale),
+		errors.Is(err, domain.ErrEmailTemplateNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidEmailTemplate):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{Error: code, Message: err.Error(), Code: status})
+}
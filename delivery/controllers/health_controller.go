@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	checker domain.IHealthChecker
+}
+
+// NewHealthController creates a controller for the liveness and readiness probes.
+func NewHealthController(checker domain.IHealthChecker) *HealthController {
+	return &HealthController{checker: checker}
+}
+
+// Liveness answers as long as the process can serve requests at all.
+func (hc *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponse{Status: string(domain.HealthUp)})
+}
+
+// Readiness checks the dependencies. It fails only when a critical one, the
+// database, is down; degraded dependencies are reported with a 200.
+func (hc *HealthController) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	report := hc.checker.Check(ctx)
+	status := http.StatusOK
+	if report.Status == domain.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, dto.FromDomainHealthReport(report))
+}
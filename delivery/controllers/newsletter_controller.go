@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// BounceSecretHeader carries the shared secret of the bounce webhook.
+const BounceSecretHeader = "X-InkForge-Bounce-Secret"
+
+type NewsletterController struct {
+	newsletterUsecase domain.INewsletterUseCase
+	bounceSecret      string
+}
+
+// NewNewsletterController creates a controller for newsletter subscriptions
+// and issues. Bounce reports are refused unless bounceSecret is set.
+func NewNewsletterController(newsletterUsecase domain.INewsletterUseCase, bounceSecret string) *NewsletterController {
+	return &NewsletterController{newsletterUsecase: newsletterUsecase, bounceSecret: bounceSecret}
+}
+
+// Subscribe sends a confirmation link to the email. The response is the same
+// whether or not the email was already subscribed.
+func (nc *NewsletterController) Subscribe(c *gin.Context) {
+	var req dto.NewsletterSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := nc.newsletterUsecase.Subscribe(ctx, req.Email, req.AuthorID, req.Language); err != nil {
+		writeNewsletterError(c, "SubscribeFailed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "check your inbox to confirm the subscription"})
+}
+
+// Confirm activates the subscription of the link's token.
+func (nc *NewsletterController) Confirm(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := nc.newsletterUsecase.Confirm(ctx, c.Query("token")); err != nil {
+		writeNewsletterError(c, "ConfirmSubscriptionFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "subscription confirmed"})
+}
+
+// Unsubscribe ends the subscription of the link's token. It accepts POST for
+// mail clients' one-click unsubscribe.
+func (nc *NewsletterController) Unsubscribe(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := nc.newsletterUsecase.Unsubscribe(ctx, c.Query("token")); err != nil {
+		writeNewsletterError(c, "UnsubscribeFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "you will no longer receive this newsletter"})
+}
+
+// HandleBounce takes bounce and complaint reports from the mail provider.
+func (nc *NewsletterController) HandleBounce(c *gin.Context) {
+	secret := c.GetHeader(BounceSecretHeader)
+	if nc.bounceSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(nc.bounceSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized", Message: "invalid bounce secret", Code: http.StatusUnauthorized})
+		return
+	}
+
+	var req dto.NewsletterBounceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := nc.newsletterUsecase.HandleBounce(ctx, req.Email, domain.BounceKind(req.Type), req.IssueID); err != nil {
+		writeNewsletterError(c, "HandleBounceFailed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListSubscribers lists the caller's subscribers, optionally filtered by status.
+func (nc *NewsletterController) ListSubscribers(c *gin.Context) {
+	nc.listSubscribers(c, c.GetString("userID"))
+}
+
+// ExportSubscribers streams the caller's subscribers as CSV.
+func (nc *NewsletterController) ExportSubscribers(c *gin.Context) {
+	nc.exportSubscribers(c, c.GetString("userID"))
+}
+
+// RemoveSubscriber deletes one of the caller's subscribers.
+func (nc *NewsletterController) RemoveSubscriber(c *gin.Context) {
+	nc.removeSubscriber(c, c.GetString("userID"))
+}
+
+// ListSiteSubscribers lists the subscribers of the site-wide newsletter.
+func (nc *NewsletterController) ListSiteSubscribers(c *gin.Context) {
+	nc.listSubscribers(c, "")
+}
+
+// ExportSiteSubscribers streams the subscribers of the site-wide newsletter as CSV.
+func (nc *NewsletterController) ExportSiteSubscribers(c *gin.Context) {
+	nc.exportSubscribers(c, "")
+}
+
+// RemoveSiteSubscriber deletes a subscriber of the site-wide newsletter.
+func (nc *NewsletterController) RemoveSiteSubscriber(c *gin.Context) {
+	nc.removeSubscriber(c, "")
+}
+
+func (nc *NewsletterController) listSubscribers(c *gin.Context, authorID string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	status := domain.SubscriberStatus(c.Query("status"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	subs, pagination, err := nc.newsletterUsecase.ListSubscribers(ctx, authorID, status, page, limit)
+	if err != nil {
+		writeNewsletterError(c, "ListSubscribersFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainNewsletterSubscriberList(subs, pagination))
+}
+
+func (nc *NewsletterController) exportSubscribers(c *gin.Context, authorID string) {
+	// exports of large lists outlast the usual timeout
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+	extendWriteDeadline(c, 2*time.Minute)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="subscribers.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	err := w.Write(dto.NewsletterSubscriberCSVHeader)
+	if err == nil {
+		err = nc.newsletterUsecase.ExportSubscribers(ctx, authorID, func(sub domain.NewsletterSubscriber) error {
+			return w.Write(dto.NewsletterSubscriberCSVRow(sub))
+		})
+	}
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		// the status is sent already, so a failure can only cut the file short
+		slog.ErrorContext(ctx, "newsletter: exporting subscribers failed", "author_id", authorID, "error", err)
+	}
+}
+
+func (nc *NewsletterController) removeSubscriber(c *gin.Context, authorID string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := nc.newsletterUsecase.RemoveSubscriber(ctx, authorID, c.Param("id")); err != nil {
+		writeNewsletterError(c, "RemoveSubscriberFailed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SendIssue sends one of the caller's blogs to their subscribers.
+func (nc *NewsletterController) SendIssue(c *gin.Context) {
+	authorID := c.GetString("userID")
+	nc.sendIssue(c, func(ctx context.Context, blogID string) (domain.NewsletterIssue, error) {
+		return nc.newsletterUsecase.SendIssue(ctx, authorID, blogID)
+	})
+}
+
+// ListIssues lists the caller's issues, newest first.
+func (nc *NewsletterController) ListIssues(c *gin.Context) {
+	nc.listIssues(c, c.GetString("userID"))
+}
+
+// GetIssue returns one of the caller's issues with its delivery stats.
+func (nc *NewsletterController) GetIssue(c *gin.Context) {
+	nc.getIssue(c, c.GetString("userID"))
+}
+
+// SendSiteIssue sends any blog to the subscribers of the site-wide newsletter.
+func (nc *NewsletterController) SendSiteIssue(c *gin.Context) {
+	nc.sendIssue(c, nc.newsletterUsecase.SendSiteIssue)
+}
+
+// ListSiteIssues lists the issues of the site-wide newsletter, newest first.
+func (nc *NewsletterController) ListSiteIssues(c *gin.Context) {
+	nc.listIssues(c, "")
+}
+
+// GetSiteIssue returns an issue of the site-wide newsletter with its delivery stats.
+func (nc *NewsletterController) GetSiteIssue(c *gin.Context) {
+	nc.getIssue(c, "")
+}
+
+func (nc *NewsletterController) sendIssue(c *gin.Context, send func(ctx context.Context, blogID string) (domain.NewsletterIssue, error)) {
+	var req dto.SendNewsletterIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "InvalidPayload", Message: err.Error(), Code: http.StatusBadRequest})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	issue, err := send(ctx, req.BlogID)
+	if err != nil {
+		writeNewsletterError(c, "SendIssueFailed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, dto.FromDomainNewsletterIssue(issue))
+}
+
+func (nc *NewsletterController) listIssues(c *gin.Context, authorID string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	issues, pagination, err := nc.newsletterUsecase.ListIssues(ctx, authorID, page, limit)
+	if err != nil {
+		writeNewsletterError(c, "ListIssuesFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainNewsletterIssueList(issues, pagination))
+}
+
+func (nc *NewsletterController) getIssue(c *gin.Context, authorID string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	issue, stats, err := nc.newsletterUsecase.IssueStats(ctx, authorID, c.Param("id"))
+	if err != nil {
+		writeNewsletterError(c, "GetIssueFailed", err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDomainNewsletterIssueStats(issue, stats))
+}
+
+func writeNewsletterError(c *gin.Context, code string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrSubscriberNotFound), errors.Is(err, domain.ErrIssueNotFound),
+		errors.Is(err, domain.ErrBlogNotFound), errors.Is(err, domain.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrIssueAlreadySent):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidSubscriberEmail), errors.Is(err, domain.ErrInvalidSubscriberStatus),
+		errors.Is(err, domain.ErrInvalidConfirmationToken), errors.Is(err, domain.ErrInvalidUnsubscribeToken),
+		errors.Is(err, domain.ErrInvalidBounce), errors.Is(err, domain.ErrUnsupportedLocale):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{Error: code, Message: err.Error(), Code: status})
+}
@@ -1,33 +1,83 @@
 package controllers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/InkForge/Blog_Website/domain"
 	"github.com/gin-gonic/gin"
 )
 
+// oauthStateCookie holds the signed state of a login in progress. It is only
+// sent to the OAuth routes.
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/oauth"
+)
+
 type OAuth2Controller struct {
 	OAuthService domain.IOAuth2Service
 	AuthUsecase  domain.IAuthUsecase
+	StateStore   domain.IOAuthStateStore
 }
 
-func NewOAuth2Controller(service domain.IOAuth2Service, authUsecase domain.IAuthUsecase) *OAuth2Controller {
+func NewOAuth2Controller(service domain.IOAuth2Service, authUsecase domain.IAuthUsecase, stateStore domain.IOAuthStateStore) *OAuth2Controller {
 	return &OAuth2Controller{
 		OAuthService: service,
 		AuthUsecase:  authUsecase,
+		StateStore:   stateStore,
 	}
 }
 
+// RedirectToProvider starts a login with a fresh state and PKCE verifier kept
+// in a signed cookie. An optional return_to query sends the browser back there
+// once logged in.
 func (ctrl *OAuth2Controller) RedirectToProvider(c *gin.Context) {
+	ctrl.startOAuth(c, "")
+}
+
+// LinkProvider starts a login at the provider whose account is then linked to
+// the logged in user instead of logging in.
+func (ctrl *OAuth2Controller) LinkProvider(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "failed to fetch userID"})
+		return
+	}
+	ctrl.startOAuth(c, userID)
+}
+
+func (ctrl *OAuth2Controller) startOAuth(c *gin.Context, linkUserID string) {
 	provider := c.Param("provider")
-	state := "random-state" 
-	url, err := ctrl.OAuthService.GetAuthorizationURL(provider, state)
+
+	state, cookie, err := ctrl.StateStore.Issue(provider, c.Query("return_to"), linkUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	url, err := ctrl.OAuthService.GetAuthorizationURL(c.Request.Context(), provider, state.State, state.Code_verifier)
+	if errors.Is(err, domain.ErrOAuthProviderUnavailable) {
+		slog.WarnContext(c.Request.Context(), "oauth: provider unavailable", "provider", provider, "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": domain.ErrOAuthProviderUnavailable.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    cookie,
+		Path:     oauthStateCookiePath,
+		HttpOnly: true,
+		Secure:   true,
+		// Lax still sends the cookie on the provider's top-level redirect back
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(ctrl.StateStore.TTL().Seconds()),
+	})
 	c.Redirect(http.StatusFound, url)
 }
 
@@ -36,18 +86,45 @@ func (ctrl *OAuth2Controller) HandleCallback(c *gin.Context) {
 	provider := c.Param("provider")
 	code := c.Query("code")
 
+	// the state is single use, whatever the outcome
+	cookie, _ := c.Cookie(oauthStateCookie)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    "",
+		Path:     oauthStateCookiePath,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+
+	state, err := ctrl.StateStore.Verify(cookie, provider, c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "OAuth login was not completed: " + providerErr})
+		return
+	}
 	if code == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing code in query"})
 		return
 	}
 
 	// authenticate with the provider
-	oauthUser, err := ctrl.OAuthService.Authenticate(ctx, provider, code)
+	oauthUser, err := ctrl.OAuthService.Authenticate(ctx, provider, code, state.Code_verifier)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
+	if state.Link_user_id != "" {
+		ctrl.finishLink(c, state, oauthUser)
+		return
+	}
+
 	// register/login via usecase
 	result, err := ctrl.AuthUsecase.OAuthLogin(ctx, oauthUser)
 	if err != nil {
@@ -66,6 +143,11 @@ func (ctrl *OAuth2Controller) HandleCallback(c *gin.Context) {
 		MaxAge:   int(result.ExpiresIn.Seconds()),
 	})
 
+	if state.Return_to != "" {
+		c.Redirect(http.StatusFound, state.Return_to)
+		return
+	}
+
 	// return safe user response
 	safeUser := gin.H{
 		"user_id":   result.User.UserID,
@@ -82,3 +164,75 @@ func (ctrl *OAuth2Controller) HandleCallback(c *gin.Context) {
 		"user":    safeUser,
 	})
 }
+
+func (ctrl *OAuth2Controller) finishLink(c *gin.Context, state domain.OAuthState, oauthUser *domain.User) {
+	identity, err := ctrl.AuthUsecase.LinkIdentity(c.Request.Context(), state.Link_user_id, oauthUser)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrIdentityAlreadyLinked):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrInvalidUserID):
+			status = http.StatusUnauthorized
+		case errors.Is(err, domain.ErrInvalidOAuthUserData):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if state.Return_to != "" {
+		c.Redirect(http.StatusFound, state.Return_to)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Account linked successfully",
+		"identity": identityResponse(identity),
+	})
+}
+
+// ListIdentities returns the OAuth accounts linked to the logged in user.
+func (ctrl *OAuth2Controller) ListIdentities(c *gin.Context) {
+	identities, err := ctrl.AuthUsecase.ListIdentities(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrInvalidUserID) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(identities))
+	for i, identity := range identities {
+		response[i] = identityResponse(identity)
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": response})
+}
+
+// UnlinkIdentity removes a linked provider. The last way to log in cannot be removed.
+func (ctrl *OAuth2Controller) UnlinkIdentity(c *gin.Context) {
+	err := ctrl.AuthUsecase.UnlinkIdentity(c.Request.Context(), c.GetString("userID"), c.Param("provider"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrIdentityNotLinked):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrLastLoginMethod):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrInvalidUserID):
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlinked successfully"})
+}
+
+func identityResponse(identity domain.LinkedIdentity) gin.H {
+	return gin.H{
+		"provider":  identity.Provider,
+		"email":     identity.Email,
+		"linked_at": identity.Linked_at.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type RelatedBlogController struct {
+	RelatedBlogUsecase domain.IRelatedBlogUseCase
+}
+
+func NewRelatedBlogController(usecase domain.IRelatedBlogUseCase) *RelatedBlogController {
+	return &RelatedBlogController{
+		RelatedBlogUsecase: usecase,
+	}
+}
+
+func (rc *RelatedBlogController) GetRelatedBlogs(c *gin.Context) {
+	blogID := c.Param("id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	posts, err := rc.RelatedBlogUsecase.GetRelated(ctx, blogID, c.GetString("userID"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out while fetching related blogs"})
+		case errors.Is(err, domain.ErrBlogIDRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Blog ID is required"})
+		case errors.Is(err, domain.ErrInvalidBlogID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blog ID"})
+		case errors.Is(err, domain.ErrBlogNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+		default:
+			slog.ErrorContext(c.Request.Context(), "fetching related blogs failed", "blog_id", blogID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related blogs", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"related": dto.FromDomainRelatedBlogPosts(posts)})
+}
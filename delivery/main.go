@@ -1,16 +1,35 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/InkForge/Blog_Website/delivery/controllers"
 	"github.com/InkForge/Blog_Website/delivery/routes"
+	"github.com/InkForge/Blog_Website/domain"
 	infrastructures2 "github.com/InkForge/Blog_Website/infrastructures"
 	infrastructures "github.com/InkForge/Blog_Website/infrastructures/auth"
 	infrastructures3 "github.com/InkForge/Blog_Website/infrastructures/ai"
+	aicache "github.com/InkForge/Blog_Website/infrastructures/ai/cache"
 	aiclient "github.com/InkForge/Blog_Website/infrastructures/ai/client"
+	"github.com/InkForge/Blog_Website/infrastructures/ai/embedding"
+	"github.com/InkForge/Blog_Website/infrastructures/ai/prompts"
+	"github.com/InkForge/Blog_Website/infrastructures/email"
+	"github.com/InkForge/Blog_Website/infrastructures/health"
+	"github.com/InkForge/Blog_Website/infrastructures/lifecycle"
+	"github.com/InkForge/Blog_Website/infrastructures/logging"
+	"github.com/InkForge/Blog_Website/infrastructures/metrics"
+	"github.com/InkForge/Blog_Website/infrastructures/notification"
+	"github.com/InkForge/Blog_Website/infrastructures/tracing"
+	"github.com/InkForge/Blog_Website/infrastructures/db/migrations"
 	mongo "github.com/InkForge/Blog_Website/infrastructures/db/mongo"
+	"github.com/InkForge/Blog_Website/infrastructures/worker"
 	"github.com/InkForge/Blog_Website/repositories"
 	mongo2 "github.com/InkForge/Blog_Website/repositories/mongo"
 	"github.com/InkForge/Blog_Website/usecases"
@@ -19,11 +38,64 @@ import (
 func main() {
 	configs, err := infrastructures2.LoadConfig()
 	if err != nil {
-		log.Fatal("error: ", err)
+		fatal("loading config failed", err)
+	}
+	logger, err := logging.New(os.Stdout, configs.LogLevel, configs.LogFormat)
+	if err != nil {
+		fatal("configuring the logger failed", err)
+	}
+	// the standard log package, and libraries using it, log through it too
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrate(configs, os.Args[2:])
+		if errors.Is(err, migrations.ErrUsage) {
+			fmt.Fprintln(os.Stderr, migrations.Usage)
+			os.Exit(2)
+		}
+		if err != nil {
+			fatal("migrate failed", err)
+		}
+		return
 	}
 
+	// components register their stop right after they start; shutdown runs
+	// them in reverse, so workers stop before the connections they use
+	app := lifecycle.New(lifecycle.Options{
+		Addr:              ":" + configs.AppPort,
+		ReadTimeout:       time.Duration(configs.HTTPReadTimeoutSec) * time.Second,
+		ReadHeaderTimeout: time.Duration(configs.HTTPReadHeaderTimeoutSec) * time.Second,
+		WriteTimeout:      time.Duration(configs.HTTPWriteTimeoutSec) * time.Second,
+		IdleTimeout:       time.Duration(configs.HTTPIdleTimeoutSec) * time.Second,
+		ShutdownTimeout:   time.Duration(configs.ShutdownTimeoutSec) * time.Second,
+		DrainDelay:        time.Duration(configs.ShutdownDrainDelaySec) * time.Second,
+		Logger:            logger,
+	})
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    configs.TracingExporter,
+		Endpoint:    configs.TracingEndpoint,
+		Insecure:    configs.TracingInsecure,
+		SampleRatio: configs.TracingSampleRatio,
+		ServiceName: configs.TracingServiceName,
+	})
+	if err != nil {
+		fatal("configuring tracing failed", err)
+	}
+	app.OnStop("tracing", lifecycle.StopFunc(shutdownTracing))
+
 	client := mongo.NewMongoClient()
+	app.OnStop("mongo", lifecycle.StopFunc(client.Disconnect))
 	db := client.Database(configs.DBName)
+	if configs.DBMigrateOnStart {
+		migrator, err := migrations.New(db, migrations.All())
+		if err != nil {
+			fatal("loading migrations failed", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			fatal("migrating the database failed", err)
+		}
+	}
 
 	userRepo := repositories.NewUserRepository(db)
 	commentRepo := repositories.NewCommentMongoRepository(db)
@@ -35,19 +107,57 @@ func main() {
 
 	passwordService := infrastructures.NewPasswordService()
 	jwtService := infrastructures.NewJWTService(configs.AccessTokenSecret, configs.RefreshTokenSecret, userRepo)
-	notificationService := infrastructures2.NewSMTPService(configs.SMTPHost, configs.SMTPPort, configs.SMTPUsername, configs.SMTPPassword, configs.EmailFrom)
+	notificationService := infrastructures2.NewSMTPService(configs.SMTPHost, configs.SMTPPort, configs.SMTPUsername, configs.SMTPPassword, configs.EmailFrom, configs.EmailFromName)
 	txManager := mongo2.NewMongoTransactionManager(client)
 
+	notificationConfigs, err := infrastructures2.BuildNotificationChannelConfigs(configs)
+	if err != nil {
+		fatal("configuring notification channels failed", err)
+	}
+	notifier, err := notification.NewRouter(notificationConfigs, notificationService, nil)
+	if err != nil {
+		fatal("configuring notification channels failed", err)
+	}
+	// closes file channels once the outbox workers are done with them
+	app.OnStop("notification channels", notifier)
+
+	emailTemplateRepo := repositories.NewEmailTemplateRepository(db)
+	emailRenderer := email.NewRenderer(emailTemplateRepo, configs.EmailFromName, time.Duration(configs.EmailTemplateCacheSec)*time.Second)
+	emailTemplateUsecase := usecases.NewEmailTemplateUseCase(emailTemplateRepo, emailRenderer)
+	emailTemplateController := controllers.NewEmailTemplateController(emailTemplateUsecase)
+
+	// retries are tracked on the outbox rows and picked up by the sweep, so the
+	// queue itself never retries
+	var emailOutboxUsecase domain.IEmailOutboxUseCase
+	emailOutboxQueue := worker.NewQueue(func(ctx context.Context, emailID string) error {
+		return emailOutboxUsecase.Deliver(ctx, emailID)
+	}, worker.Options{
+		Name:        "email outbox",
+		Workers:     configs.EmailOutboxWorkers,
+		MaxAttempts: 1,
+	})
+	emailOutboxUsecase = usecases.NewEmailOutboxUseCase(repositories.NewEmailOutboxRepository(db), notifier, emailOutboxQueue, usecases.EmailOutboxOptions{
+		MaxAttempts: configs.EmailOutboxAttempts,
+		BaseBackoff: time.Duration(configs.EmailOutboxBackoffSec) * time.Second,
+	})
+	emailOutboxSweep := worker.NewPeriodic(time.Duration(configs.EmailOutboxSweepSec)*time.Second, emailOutboxUsecase.RequeueDue)
+	emailOutboxQueue.Start()
+	emailOutboxSweep.Start()
+	app.OnStop("email outbox queue", emailOutboxQueue)
+	app.OnStop("email outbox sweep", emailOutboxSweep)
+	emailOutboxController := controllers.NewEmailOutboxController(emailOutboxUsecase)
+
 	providersConfigs, err := infrastructures2.BuildProviderConfigs()
 	if err != nil {
-		log.Fatal("error: ", err)
+		fatal("configuring OAuth providers failed", err)
 	}
 
 	authService := infrastructures.NewAuthService(jwtService, configs.JWTSecretKey)
 	oauth2Service, err := infrastructures.NewOAuth2Service(providersConfigs)
+	if err != nil {
+		fatal("configuring OAuth providers failed", err)
+	}
 
-	
-	blogUsecase := usecases.NewBlogUsecase(blogRepo, blogViewRepo, tagRepo, userRepo, txManager)
 	blogReactionUsecase := usecases.NewBlogReactionUseCase(blogRepo, blogReactionRepo, txManager)
 	
 	userUsecase:=usecases.NewUserUseCase(userRepo, 10 * time.Second)
@@ -58,32 +168,189 @@ func main() {
 		userRepo,
 		passwordService,
 		jwtService,
-		notificationService,
+		emailOutboxUsecase,
+		emailRenderer,
+		txManager,
 		configs.BaseURL,
 		time.Second*10,
 	)
-	blogController := controllers.NewBlogController(blogUsecase)
 	blogReactionController := controllers.NewBlogReactionController(blogReactionUsecase)
 	commentController := controllers.NewCommentController(commentUsecase)
 	commentReactionController := controllers.NewCommentReactionController(commentReactionUsecase)
 	authController := controllers.NewAuthController(authUsecase)
-	oauthController := controllers.NewOAuth2Controller(oauth2Service, authUsecase)
+	oauthStateStore := infrastructures.NewOAuthStateStore(configs.OAuthStateSecret, time.Duration(configs.OAuthStateTTLMinutes)*time.Minute, configs.AllowedOrigins)
+	oauthController := controllers.NewOAuth2Controller(oauth2Service, authUsecase, oauthStateStore)
 	userControler:=controllers.NewUserController(userUsecase)
 
-	apikey := configs.AIApiKey
-	aimodelname := configs.AIModelName
-
-	aiclient := aiclient.NewGroqClient(apikey, aimodelname)
-	
+	aiProviders := infrastructures2.BuildAIProviderConfigs(configs)
+	aiClient, err := aiclient.NewProviderChain(aiProviders, aiclient.FallbackOptions{
+		AttemptTimeout:   time.Duration(configs.AIRequestTimeoutSec) * time.Second,
+		BreakerThreshold: configs.AIBreakerThreshold,
+		BreakerCooldown:  time.Duration(configs.AIBreakerCooldownSec) * time.Second,
+	})
 	if err != nil {
-		log.Fatal("error: ", err)
+		fatal("configuring AI providers failed", err)
 	}
 
-	aiService := infrastructures3.NewAIContentService(aiclient)
-	aiUsecase := usecases.NewAIUsecase(aiService)
+	promptTemplateRepo := repositories.NewPromptTemplateRepository(db)
+	promptStore := prompts.NewStore(promptTemplateRepo, time.Duration(configs.AIPromptCacheSec)*time.Second)
+	aiService := infrastructures3.NewAIContentService(aiClient, promptStore)
+	aiUsageRepo := repositories.NewAIUsageRepository(db)
+	aiQuotaRepo := repositories.NewAIQuotaRepository(db)
+	aiUsageUsecase := usecases.NewAIUsageUseCase(aiUsageRepo, aiQuotaRepo, infrastructures2.BuildAIRoleQuotas(configs), infrastructures2.BuildAIPrices(configs))
+	aiUsageController := controllers.NewAIUsageController(aiUsageUsecase)
+	promptTemplateUsecase := usecases.NewPromptTemplateUseCase(promptTemplateRepo, aiUsageRepo, promptStore)
+	aiPromptController := controllers.NewAIPromptController(promptTemplateUsecase)
+
+	aiCacheRepo := repositories.NewAIResponseCacheRepository(db)
+	aiCache := aicache.NewTieredCache(configs.AICacheSize, time.Duration(configs.AICacheTTLMinutes)*time.Minute, aiCacheRepo)
+
+	aiUsecase := usecases.NewAIUsecase(aiService, aiClient, aiUsageUsecase, aiCache, promptStore)
 	aiController := controllers.NewAIController(aiUsecase)
 
-	r := routes.SetupRouter(commentController, commentReactionController, blogController, blogReactionController, authService, authController, oauthController,userControler, aiController)
+	// the queue calls back into the usecase, which in turn queues blogs on it
+	var blogEnrichmentUsecase domain.IBlogEnrichmentUseCase
+	enrichmentQueue := worker.NewQueue(func(ctx context.Context, blogID string) error {
+		return blogEnrichmentUsecase.Enrich(ctx, blogID)
+	}, worker.Options{
+		Name:        "blog enrichment",
+		Workers:     configs.AIEnrichWorkers,
+		MaxAttempts: configs.AIEnrichMaxAttempts,
+		BaseBackoff: time.Duration(configs.AIEnrichBackoffSec) * time.Second,
+		OnGiveUp: func(blogID string, err error) {
+			blogEnrichmentUsecase.MarkFailed(context.Background(), blogID, err)
+		},
+	})
+	blogEnrichmentUsecase = usecases.NewBlogEnrichmentUseCase(blogRepo, tagRepo, aiUsecase, enrichmentQueue)
+	enrichmentSweep := worker.NewPeriodic(time.Duration(configs.AIEnrichSweepMinutes)*time.Minute, blogEnrichmentUsecase.RequeueStale)
+	enrichmentQueue.Start()
+	enrichmentSweep.Start()
+	app.OnStop("blog enrichment queue", enrichmentQueue)
+	app.OnStop("blog enrichment sweep", enrichmentSweep)
+
+	blogListeners := []domain.IBlogChangeListener{blogEnrichmentUsecase}
+
+	// question answering stays off until an embedding provider is configured
+	openAISettings := configs.AIProviderSettings["openai"]
+	embedder, err := embedding.NewClient(configs.AIEmbeddingProvider, openAISettings.APIKey, openAISettings.APIBaseURL, configs.AIEmbeddingModel)
+	if err != nil {
+		fatal("configuring the embedding provider failed", err)
+	}
+	var blogQAUsecase domain.IBlogQAUseCase
+	var blogChunkRepo domain.IBlogChunkRepository
+	embeddingModel := ""
+	if embedder != nil {
+		blogChunkRepo = repositories.NewBlogChunkRepository(db)
+		embeddingModel = embedder.Model()
+		indexQueue := worker.NewQueue(func(ctx context.Context, blogID string) error {
+			return blogQAUsecase.IndexBlog(ctx, blogID)
+		}, worker.Options{Name: "blog Q&A indexing", BaseBackoff: 10 * time.Second})
+		blogQAUsecase = usecases.NewBlogQAUseCase(blogRepo, blogChunkRepo, embedding.NewIndex(blogChunkRepo), embedder, aiService, aiUsageUsecase, txManager, indexQueue, configs.AIQATopK, configs.AIQAMinScore)
+		indexQueue.Start()
+		app.OnStop("blog Q&A indexing queue", indexQueue)
+		blogListeners = append(blogListeners, blogQAUsecase)
+	}
+	aiQAController := controllers.NewAIQAController(blogQAUsecase)
+
+	relatedBlogRepo := repositories.NewRelatedBlogRepository(db)
+	relatedBlogUsecase := usecases.NewRelatedBlogUseCase(blogRepo, blogViewRepo, blogReactionRepo, relatedBlogRepo, blogChunkRepo, embeddingModel)
+	relatedBlogController := controllers.NewRelatedBlogController(relatedBlogUsecase)
+	recomputeRelated := func(ctx context.Context) {
+		if err := relatedBlogUsecase.Recompute(ctx); err != nil {
+			slog.ErrorContext(ctx, "related blogs: recompute failed", "error", err)
+		}
+	}
+	relatedBlogsJob := worker.NewPeriodic(time.Duration(configs.RelatedBlogsRefreshMinutes)*time.Minute, recomputeRelated)
+	// don't wait a whole interval for the first recommendations
+	relatedBlogsJob.StartNow()
+	app.OnStop("related blogs job", relatedBlogsJob)
+
+	digestUsecase := usecases.NewDigestUseCase(repositories.NewDigestSubscriptionRepository(db), blogRepo, userRepo, emailRenderer, emailOutboxUsecase, jwtService, txManager, configs.BaseURL)
+	digestController := controllers.NewDigestController(digestUsecase)
+	digestJob := worker.NewPeriodic(time.Duration(configs.DigestSweepMinutes)*time.Minute, digestUsecase.SendDue)
+	digestJob.Start()
+	app.OnStop("digest job", digestJob)
+
+	var newsletterUsecase domain.INewsletterUseCase
+	// issues can reach thousands of subscribers; an interrupted send is
+	// resumed from its cursor by the sweep
+	newsletterQueue := worker.NewQueue(func(ctx context.Context, issueID string) error {
+		return newsletterUsecase.DeliverIssue(ctx, issueID)
+	}, worker.Options{Name: "newsletter", MaxAttempts: 1, JobTimeout: 10 * time.Minute})
+	newsletterUsecase = usecases.NewNewsletterUseCase(
+		repositories.NewNewsletterSubscriberRepository(db),
+		repositories.NewNewsletterIssueRepository(db),
+		blogRepo, userRepo, emailRenderer, emailOutboxUsecase, jwtService, txManager, newsletterQueue, configs.BaseURL,
+	)
+	newsletterController := controllers.NewNewsletterController(newsletterUsecase, configs.NewsletterBounceSecret)
+	newsletterSweep := worker.NewPeriodic(time.Duration(configs.NewsletterSweepMinutes)*time.Minute, newsletterUsecase.ResumeIssues)
+	newsletterQueue.Start()
+	newsletterSweep.Start()
+	app.OnStop("newsletter queue", newsletterQueue)
+	app.OnStop("newsletter sweep", newsletterSweep)
+
+	counterUsecase := usecases.NewCounterUseCase(repositories.NewCounterRepository(db))
+	counterController := controllers.NewCounterController(counterUsecase)
+	if configs.CounterReconcileMinutes > 0 {
+		counterJob := worker.NewPeriodic(time.Duration(configs.CounterReconcileMinutes)*time.Minute, func(ctx context.Context) {
+			report, err := counterUsecase.Reconcile(ctx, configs.CounterReconcileRepair)
+			switch {
+			case errors.Is(err, domain.ErrCounterReconcileRunning):
+				slog.InfoContext(ctx, "counters: skipping the scheduled reconciliation, one is running")
+			case err != nil:
+				slog.ErrorContext(ctx, "counters: reconciliation failed", "error", err)
+			default:
+				metrics.ObserveCounterReconciliation(report)
+			}
+		})
+		counterJob.Start()
+		app.OnStop("counter reconciliation job", counterJob)
+	}
+
+	blogUsecase := usecases.NewBlogUsecase(blogRepo, blogViewRepo, tagRepo, userRepo, txManager, blogListeners...)
+	blogController := controllers.NewBlogController(blogUsecase)
+
+	aiConversationRepo := repositories.NewAIConversationRepository(db)
+	aiConversationUsecase := usecases.NewAIConversationUseCase(aiConversationRepo, blogRepo, aiService, aiUsageUsecase, configs.AIChatTokenBudget)
+	aiConversationController := controllers.NewAIConversationController(aiConversationUsecase)
+
+	healthChecks := []health.Check{
+		// fails as soon as shutdown begins, so traffic moves elsewhere while requests drain
+		{Name: "server", Critical: true, Probe: app.Ready},
+		{Name: "mongo", Critical: true, Probe: health.MongoProbe(client)},
+		{Name: "ai", Probe: health.AIProbe(aiClient)},
+		// also retries the discovery of OIDC issuers that were down
+		{Name: "oauth", Probe: oauth2Service.Ready},
+	}
+	for _, channel := range notificationConfigs {
+		if channel.Type == domain.NotificationChannelSMTP && configs.SMTPHost != "" {
+			healthChecks = append(healthChecks, health.Check{Name: "smtp", Probe: health.TCPProbe(net.JoinHostPort(configs.SMTPHost, strconv.Itoa(configs.SMTPPort)))})
+			break
+		}
+	}
+	healthController := controllers.NewHealthController(health.NewChecker(2*time.Second, healthChecks...))
+
+	r := routes.SetupRouter(commentController, commentReactionController, blogController, blogReactionController, authService, authController, oauthController,userControler, aiController, aiConversationController, aiUsageController, aiQAController, relatedBlogController, aiPromptController, emailTemplateController, emailOutboxController, digestController, newsletterController, healthController, counterController)
+
+	if err := app.Run(context.Background(), r); err != nil {
+		fatal("server stopped with an error", err)
+	}
+}
+
+// migrate runs the migrate subcommand, e.g. `migrate up`.
+func migrate(configs *infrastructures2.Config, args []string) error {
+	client := mongo.NewMongoClient()
+	defer client.Disconnect(context.Background())
+
+	migrator, err := migrations.New(client.Database(configs.DBName), migrations.All())
+	if err != nil {
+		return err
+	}
+	return migrations.RunCommand(context.Background(), migrator, args, os.Stdout)
+}
 
-	r.Run(":" + configs.AppPort)
+// fatal logs an error the server cannot start without and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -1,9 +1,14 @@
 package routes
 
 import (
+	"log/slog"
+
 	"github.com/InkForge/Blog_Website/delivery/controllers"
 	auth "github.com/InkForge/Blog_Website/infrastructures/auth"
 	infrastructures "github.com/InkForge/Blog_Website/infrastructures/auth"
+	"github.com/InkForge/Blog_Website/infrastructures/logging"
+	"github.com/InkForge/Blog_Website/infrastructures/metrics"
+	"github.com/InkForge/Blog_Website/infrastructures/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +24,9 @@ func RegisterBlogRoutes(router *gin.Engine, blogController *controllers.BlogCont
 		authGroup.POST("/blogs", blogController.CreateBlog)
 		authGroup.PUT("/blogs/:id", blogController.UpdateBlog)
 		authGroup.DELETE("/blogs/:id", blogController.DeleteBlog)
+		authGroup.PUT("/blogs/:id/auto-enrich", blogController.SetAutoEnrich)
+		authGroup.POST("/blogs/:id/suggested-tags/accept", blogController.AcceptSuggestedTags)
+		authGroup.POST("/blogs/:id/suggested-tags/reject", blogController.RejectSuggestedTags)
 	}
 
 	// Search and filter endpoints (public)
@@ -26,6 +34,11 @@ func RegisterBlogRoutes(router *gin.Engine, blogController *controllers.BlogCont
 	router.GET("/blogs/filter", blogController.FilterBlogs)
 }
 
+// RegisterRelatedBlogRoutes registers the related posts route.
+func RegisterRelatedBlogRoutes(router *gin.Engine, relatedBlogController *controllers.RelatedBlogController, authService *infrastructures.AuthService) {
+	router.GET("/blogs/:id/related", authService.AuthWithRole("USER", "ADMIN"), relatedBlogController.GetRelatedBlogs)
+}
+
 // RegisterBlogReactionRoutes registers blog reaction routes.
 func RegisterBlogReactionRoutes(router *gin.Engine, blogReactionController *controllers.BlogReactionController, authService *infrastructures.AuthService) {
 	authGroup := router.Group("/")
@@ -56,6 +69,7 @@ func NewAuthRouter(authController controllers.AuthController, authService auth.A
 	group.POST("/forget", authController.RequestPasswordReset)
 	group.POST("/reset", authController.ResetPassword)
 	group.POST("/logout", authService.AuthWithRole("USER", "ADMIN"), authController.Logout)
+	group.POST("/password", authService.AuthWithRole("USER", "ADMIN"), authController.SetPassword)
 	group.POST("/refresh/", authController.RefreshToken)
 }
 
@@ -86,12 +100,21 @@ func RegisterCommentAndReactionRoutes(
 func RegisterOAuthRoutes(
 	router *gin.Engine,
 	oauthController *controllers.OAuth2Controller,
+	authService *infrastructures.AuthService,
 ) {
 	oauth := router.Group("/oauth")
 	{
 		oauth.GET("/:provider/login", oauthController.RedirectToProvider)
 		oauth.GET("/:provider/callback", oauthController.HandleCallback)
 	}
+
+	authGroup := oauth.Group("/")
+	authGroup.Use(authService.AuthWithRole("USER", "ADMIN"))
+	{
+		authGroup.GET("/identities", oauthController.ListIdentities)
+		authGroup.GET("/:provider/link", oauthController.LinkProvider)
+		authGroup.DELETE("/:provider", oauthController.UnlinkIdentity)
+	}
 }
 
 func NewAIRouter(aiController *controllers.AIController, authService *infrastructures.AuthService, group gin.RouterGroup) {
@@ -106,9 +129,125 @@ func NewAIRouter(aiController *controllers.AIController, authService *infrastruc
 		groupAuth.POST("/suggest-content", aiController.SuggestContent)
 		groupAuth.POST("/improve-content", aiController.ImproveContent)
 		groupAuth.POST("/chat", aiController.Chat)
+		groupAuth.POST("/suggest-content/stream", aiController.SuggestContentStream)
+		groupAuth.POST("/improve-content/stream", aiController.ImproveContentStream)
+		groupAuth.POST("/chat/stream", aiController.ChatStream)
+	}
+}
+
+// NewAIConversationRouter registers the persistent AI chat conversation routes.
+func NewAIConversationRouter(conversationController *controllers.AIConversationController, authService *infrastructures.AuthService, group gin.RouterGroup) {
+	groupAuth := group.Group("/conversations")
+	groupAuth.Use(authService.AuthWithRole("USER", "ADMIN"))
+	{
+		groupAuth.POST("", conversationController.CreateConversation)
+		groupAuth.GET("", conversationController.ListConversations)
+		groupAuth.GET("/:id", conversationController.GetConversation)
+		groupAuth.PATCH("/:id", conversationController.RenameConversation)
+		groupAuth.DELETE("/:id", conversationController.DeleteConversation)
+		groupAuth.POST("/:id/messages", conversationController.SendMessage)
+	}
+}
+
+// NewAIUsageRouter registers the caller's AI usage route.
+func NewAIUsageRouter(aiUsageController *controllers.AIUsageController, authService *infrastructures.AuthService, group gin.RouterGroup) {
+	groupAuth := group.Group("/usage")
+	groupAuth.Use(authService.AuthWithRole("USER", "ADMIN"))
+	groupAuth.GET("/me", aiUsageController.MyUsage)
+}
+
+// NewAIQARouter registers the question answering route.
+func NewAIQARouter(aiQAController *controllers.AIQAController, authService *infrastructures.AuthService, group gin.RouterGroup) {
+	groupAuth := group.Group("/")
+	groupAuth.Use(authService.AuthWithRole("USER", "ADMIN"))
+	groupAuth.POST("/ask", aiQAController.Ask)
+}
+
+// NewAdminAIRouter registers admin-only AI operations routes.
+func NewAdminAIRouter(aiController *controllers.AIController, aiUsageController *controllers.AIUsageController, aiQAController *controllers.AIQAController, group gin.RouterGroup) {
+	group.GET("/ai/providers", aiController.ProviderHealth)
+	group.GET("/ai/usage", aiUsageController.UsageReport)
+	group.PUT("/ai/quotas/users/:id", aiUsageController.SetUserQuota)
+	group.POST("/ai/reindex", aiQAController.Reindex)
+}
+
+// NewAdminPromptRouter registers admin-only AI prompt template routes.
+func NewAdminPromptRouter(aiPromptController *controllers.AIPromptController, group gin.RouterGroup) {
+	group.GET("/ai/prompts", aiPromptController.ListPrompts)
+	group.GET("/ai/prompts/:name", aiPromptController.ListVersions)
+	group.POST("/ai/prompts/:name/versions", aiPromptController.CreateVersion)
+	group.PUT("/ai/prompts/:name/rollout", aiPromptController.SetRollout)
+	group.GET("/ai/prompts/:name/stats", aiPromptController.VersionStats)
+}
+
+// NewAdminEmailRouter registers admin-only email template routes.
+func NewAdminEmailRouter(emailTemplateController *controllers.EmailTemplateController, group gin.RouterGroup) {
+	group.GET("/emails/templates", emailTemplateController.ListTemplates)
+	group.GET("/emails/templates/:name/:locale", emailTemplateController.GetTemplate)
+	group.PUT("/emails/templates/:name/:locale", emailTemplateController.OverrideTemplate)
+	group.DELETE("/emails/templates/:name/:locale", emailTemplateController.ResetTemplate)
+	group.POST("/emails/templates/:name/:locale/preview", emailTemplateController.Preview)
+}
+
+// NewAdminOutboxRouter registers admin-only routes for the email outbox.
+func NewAdminOutboxRouter(emailOutboxController *controllers.EmailOutboxController, group gin.RouterGroup) {
+	group.GET("/emails/outbox", emailOutboxController.ListEmails)
+	group.POST("/emails/outbox/:id/retry", emailOutboxController.RetryEmail)
+}
+
+// NewAdminCounterRouter registers admin-only routes for reconciling blog and comment counters.
+func NewAdminCounterRouter(counterController *controllers.CounterController, group gin.RouterGroup) {
+	group.GET("/counters/discrepancies", counterController.ListDiscrepancies)
+	group.POST("/counters/reconcile", counterController.Reconcile)
+}
+
+// NewDigestRouter registers digest preference and unsubscribe routes.
+func NewDigestRouter(digestController *controllers.DigestController, authService *infrastructures.AuthService, group gin.RouterGroup) {
+	// unsubscribe links are opened from emails, without a login
+	group.GET("/unsubscribe", digestController.Unsubscribe)
+	group.POST("/unsubscribe", digestController.Unsubscribe)
+
+	groupAuth := group.Group("/")
+	groupAuth.Use(authService.AuthWithRole("USER", "ADMIN"))
+	{
+		groupAuth.GET("/preferences", digestController.GetPreferences)
+		groupAuth.PUT("/preferences", digestController.UpdatePreferences)
 	}
 }
 
+// NewNewsletterRouter registers the public subscription routes and the
+// author's subscriber and issue routes.
+func NewNewsletterRouter(newsletterController *controllers.NewsletterController, authService *infrastructures.AuthService, group gin.RouterGroup) {
+	// readers subscribe without an account, and links are opened from emails
+	group.POST("/subscribe", newsletterController.Subscribe)
+	group.GET("/confirm", newsletterController.Confirm)
+	group.GET("/unsubscribe", newsletterController.Unsubscribe)
+	group.POST("/unsubscribe", newsletterController.Unsubscribe)
+	// called by the mail provider, authenticated by a shared secret
+	group.POST("/bounces", newsletterController.HandleBounce)
+
+	groupAuth := group.Group("/")
+	groupAuth.Use(authService.AuthWithRole("USER", "ADMIN"))
+	{
+		groupAuth.GET("/subscribers", newsletterController.ListSubscribers)
+		groupAuth.GET("/subscribers/export", newsletterController.ExportSubscribers)
+		groupAuth.DELETE("/subscribers/:id", newsletterController.RemoveSubscriber)
+		groupAuth.POST("/issues", newsletterController.SendIssue)
+		groupAuth.GET("/issues", newsletterController.ListIssues)
+		groupAuth.GET("/issues/:id", newsletterController.GetIssue)
+	}
+}
+
+// NewAdminNewsletterRouter registers admin-only routes for the site-wide newsletter.
+func NewAdminNewsletterRouter(newsletterController *controllers.NewsletterController, group gin.RouterGroup) {
+	group.GET("/newsletter/subscribers", newsletterController.ListSiteSubscribers)
+	group.GET("/newsletter/subscribers/export", newsletterController.ExportSiteSubscribers)
+	group.DELETE("/newsletter/subscribers/:id", newsletterController.RemoveSiteSubscriber)
+	group.POST("/newsletter/issues", newsletterController.SendSiteIssue)
+	group.GET("/newsletter/issues", newsletterController.ListSiteIssues)
+	group.GET("/newsletter/issues/:id", newsletterController.GetSiteIssue)
+}
+
 func SetupRouter(
 	commentController *controllers.CommentController,
 	commentReactionController *controllers.CommentReactionController,
@@ -119,14 +258,33 @@ func SetupRouter(
 	oauthController *controllers.OAuth2Controller,
 	userController *controllers.UserController,
 	aiController *controllers.AIController,
+	aiConversationController *controllers.AIConversationController,
+	aiUsageController *controllers.AIUsageController,
+	aiQAController *controllers.AIQAController,
+	relatedBlogController *controllers.RelatedBlogController,
+	aiPromptController *controllers.AIPromptController,
+	emailTemplateController *controllers.EmailTemplateController,
+	emailOutboxController *controllers.EmailOutboxController,
+	digestController *controllers.DigestController,
+	newsletterController *controllers.NewsletterController,
+	healthController *controllers.HealthController,
+	counterController *controllers.CounterController,
 ) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	// request IDs and spans go first so the access log and handlers see them
+	router.Use(logging.RequestID(), tracing.HTTP(), logging.AccessLog(slog.Default()), metrics.HTTP(), logging.Recovery(slog.Default()))
+
+	// probes and scraping, for the orchestrator and Prometheus
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Register comment & reaction routes
 	RegisterCommentAndReactionRoutes(router, commentController, commentReactionController, authService)
 
 	// Register blog routes
 	RegisterBlogRoutes(router, blogController, authService)
+	RegisterRelatedBlogRoutes(router, relatedBlogController, authService)
 
 	// Register blog reaction routes
 	RegisterBlogReactionRoutes(router, blogReactionController, authService)
@@ -135,7 +293,7 @@ func SetupRouter(
 	authGroup := router.Group("/auth")
 	NewAuthRouter(*authController, *authService, *authGroup)
 
-	RegisterOAuthRoutes(router, oauthController)
+	RegisterOAuthRoutes(router, oauthController, authService)
 
 	//user routes
 
@@ -146,6 +304,25 @@ func SetupRouter(
 	// ai integration routes
 	aiGroup := router.Group("/ai")
 	NewAIRouter(aiController, authService, *aiGroup)
+	NewAIConversationRouter(aiConversationController, authService, *aiGroup)
+	NewAIUsageRouter(aiUsageController, authService, *aiGroup)
+	NewAIQARouter(aiQAController, authService, *aiGroup)
+
+	digestGroup := router.Group("/digests")
+	NewDigestRouter(digestController, authService, *digestGroup)
+
+	newsletterGroup := router.Group("/newsletter")
+	NewNewsletterRouter(newsletterController, authService, *newsletterGroup)
+
+	// admin routes
+	adminGroup := router.Group("/admin")
+	adminGroup.Use(authService.AuthWithRole("ADMIN"))
+	NewAdminAIRouter(aiController, aiUsageController, aiQAController, *adminGroup)
+	NewAdminPromptRouter(aiPromptController, *adminGroup)
+	NewAdminEmailRouter(emailTemplateController, *adminGroup)
+	NewAdminOutboxRouter(emailOutboxController, *adminGroup)
+	NewAdminNewsletterRouter(newsletterController, *adminGroup)
+	NewAdminCounterRouter(counterController, *adminGroup)
 
 	return router
 }
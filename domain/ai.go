@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+    "context"
+    "time"
+)
 
 // AIContentService defines AI-powered content assistance capabilities.
 type IAIContentService interface {
@@ -10,22 +13,33 @@ type IAIContentService interface {
     SuggestContent(ctx context.Context, keywords, style string, wordCount int) (string, error)
     ImproveContent(ctx context.Context, content, focus string) (ImprovementResult, error)
     Chat(ctx context.Context, messages []AIMessage) (AIMessage, error)
+
+    // Streaming variants relay the generated text to onDelta as it arrives
+    // and return the complete text once the model is done.
+    SuggestContentStream(ctx context.Context, keywords, style string, wordCount int, onDelta AIStreamHandler) (string, error)
+    ImproveContentStream(ctx context.Context, content, focus string, onDelta AIStreamHandler) (string, error)
+    ChatStream(ctx context.Context, messages []AIMessage, onDelta AIStreamHandler) (AIMessage, error)
 }
 
 // IAIUseCase exposes AI helpers to the delivery layer.
 type IAIUseCase interface {
-    SuggestTags(ctx context.Context, title, content string, maxTags int) ([]string, error)
-    Summarize(ctx context.Context, content string, maxWords int) (string, error)
-    GenerateTitle(ctx context.Context, content, style string) (string, error)
-    SuggestContent(ctx context.Context, keywords, style string, wordCount int) (string, error)
-    ImproveContent(ctx context.Context, content, focus string) (ImprovementResult, error)
-    Chat(ctx context.Context, messages []AIMessage) (AIMessage, error)
+    SuggestTags(ctx context.Context, requester AIRequestInfo, title, content string, maxTags int) ([]string, error)
+    Summarize(ctx context.Context, requester AIRequestInfo, content string, maxWords int) (string, error)
+    GenerateTitle(ctx context.Context, requester AIRequestInfo, content, style string) (string, error)
+    SuggestContent(ctx context.Context, requester AIRequestInfo, keywords, style string, wordCount int) (string, error)
+    ImproveContent(ctx context.Context, requester AIRequestInfo, content, focus string) (ImprovementResult, error)
+    Chat(ctx context.Context, requester AIRequestInfo, messages []AIMessage) (AIMessage, error)
+    SuggestContentStream(ctx context.Context, requester AIRequestInfo, keywords, style string, wordCount int, onDelta AIStreamHandler) (string, error)
+    ImproveContentStream(ctx context.Context, requester AIRequestInfo, content, focus string, onDelta AIStreamHandler) (string, error)
+    ChatStream(ctx context.Context, requester AIRequestInfo, messages []AIMessage, onDelta AIStreamHandler) (AIMessage, error)
+    ProviderHealth(ctx context.Context) []AIProviderHealth
 }
 
 // AIMessage models a chat message for a simple AI chat feature.
 type AIMessage struct {
-    Role    string // "system" | "user" | "assistant"
-    Content string
+    Role       string // "system" | "user" | "assistant"
+    Content    string
+    Created_at time.Time
 }
 
 // ImprovementResult represents improved content plus suggestions.
@@ -35,8 +49,59 @@ type ImprovementResult struct {
 }
 
 
+// AIStreamHandler receives each text fragment of a streamed AI response.
+// Returning an error aborts the stream.
+type AIStreamHandler func(delta string) error
+
 // AIModelClient defines the low-level AI API communication methods.
 // Generate sends a prompt string to the AI provider and returns the raw text response.
+// GenerateStream does the same but calls onDelta with each fragment as it is produced,
+// returning the complete text at the end.
 type IAIModelClient interface {
     Generate(ctx context.Context, prompt string) (string, error)
+    GenerateStream(ctx context.Context, prompt string, onDelta AIStreamHandler) (string, error)
+}
+
+// IAIResponseCache stores AI results under a content-addressed key.
+type IAIResponseCache interface {
+    Get(ctx context.Context, key string) (string, bool)
+    Set(ctx context.Context, key, value string)
+}
+
+// IAIResponseCacheRepository is the persistent tier of the AI response cache.
+// Get returns ErrAICacheMiss for missing or expired entries.
+type IAIResponseCacheRepository interface {
+    Get(ctx context.Context, key string) (string, error)
+    Set(ctx context.Context, key, value string, expiresAt time.Time) error
+}
+
+// IAIJSONModelClient is implemented by model clients whose provider can be asked
+// to answer with a JSON object only (JSON mode / response_format).
+type IAIJSONModelClient interface {
+    GenerateJSON(ctx context.Context, prompt string) (string, error)
+}
+
+// AIProviderConfig holds the settings needed to build a client for one provider.
+type AIProviderConfig struct {
+    Name    string // "groq" | "deepseek" | "openai"
+    APIKey  string
+    BaseURL string
+    Model   string
+}
+
+// AIProviderHealth reports the circuit breaker state of a provider in the fallback chain.
+type AIProviderHealth struct {
+    Name                string
+    Model               string
+    Priority            int
+    State               string // "closed" | "open" | "half-open"
+    ConsecutiveFailures int
+    LastError           string
+    LastFailureAt       time.Time
+    RetryAt             time.Time
+}
+
+// IAIProviderMonitor exposes the health of the configured AI providers.
+type IAIProviderMonitor interface {
+    ProviderHealth() []AIProviderHealth
 }
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AIConversation is a persisted chat between a user and the AI assistant.
+// Blog_id optionally anchors the conversation to a blog the user is drafting,
+// whose current content is shown to the assistant on every turn.
+type AIConversation struct {
+	Conversation_id string
+	User_id         string
+	Blog_id         string
+	Title           string
+	Messages        []AIMessage
+
+	Created_at time.Time
+	Updated_at time.Time
+}
+
+type IAIConversationRepository interface {
+	Create(ctx context.Context, conversation AIConversation) (string, error)
+	GetByID(ctx context.Context, conversationID string) (AIConversation, error)
+	// ListByUser returns the user's conversations, most recently updated first, without messages.
+	ListByUser(ctx context.Context, userID string, page, limit int) ([]AIConversation, int, error)
+	AppendMessages(ctx context.Context, conversationID string, messages []AIMessage) error
+	UpdateTitle(ctx context.Context, conversationID, title string) error
+	Delete(ctx context.Context, conversationID string) error
+}
+
+type IAIConversationUseCase interface {
+	CreateConversation(ctx context.Context, userID, blogID string) (*AIConversation, error)
+	ListConversations(ctx context.Context, userID string, page, limit int) ([]AIConversation, Pagination, error)
+	GetConversation(ctx context.Context, conversationID, userID string) (*AIConversation, error)
+	RenameConversation(ctx context.Context, conversationID, userID, title string) error
+	DeleteConversation(ctx context.Context, conversationID, userID string) error
+	// SendMessage stores the user's message, asks the assistant for a reply using
+	// the conversation history and returns the stored reply.
+	SendMessage(ctx context.Context, conversationID string, requester AIRequestInfo, content string) (AIMessage, error)
+}
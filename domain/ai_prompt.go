@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PromptTemplate is one stored version of an AI prompt, written with Go's
+// text/template. Version 0 is the built-in default shipped with the binary.
+type PromptTemplate struct {
+	Template_id string
+	Name        string
+	Version     int
+	Body        string
+	Created_by  string
+	Created_at  time.Time
+}
+
+// PromptRollout gives a template version a share of the traffic. Several
+// versions with weights split requests between them for A/B comparison.
+type PromptRollout struct {
+	Version int
+	Weight  int
+}
+
+// RenderedPrompt is a prompt ready to send, with the version it came from.
+type RenderedPrompt struct {
+	Text    string
+	Name    string
+	Version int
+}
+
+// PromptTemplateInfo describes one prompt and how it is currently rolled out.
+// An empty Rollout means the built-in default is used.
+type PromptTemplateInfo struct {
+	Name           string
+	Latest_version int
+	Rollout        []PromptRollout
+}
+
+// PromptVersionStats compares the AI calls made with one template version.
+type PromptVersionStats struct {
+	Version          int
+	Requests         int
+	Failures         int
+	Avg_latency_ms   float64
+	Avg_total_tokens float64
+}
+
+type IPromptTemplateRepository interface {
+	// CreateVersion stores body as the next version of name.
+	CreateVersion(ctx context.Context, template PromptTemplate) (PromptTemplate, error)
+	// GetVersion returns ErrPromptVersionNotFound if it does not exist.
+	GetVersion(ctx context.Context, name string, version int) (PromptTemplate, error)
+	ListVersions(ctx context.Context, name string) ([]PromptTemplate, error)
+	GetRollout(ctx context.Context, name string) ([]PromptRollout, error)
+	SetRollout(ctx context.Context, name string, rollout []PromptRollout) error
+}
+
+// IPromptRenderer renders named prompts from their rolled out versions.
+type IPromptRenderer interface {
+	// Render picks a version according to the rollout, unless one is pinned in
+	// ctx, and executes it with data. It records the version on the usage meter in ctx.
+	Render(ctx context.Context, name string, data interface{}) (RenderedPrompt, error)
+	// PickVersion picks the version Render would, without rendering it.
+	PickVersion(ctx context.Context, name string) (int, error)
+	// Validate parses body and executes it against sample data for name.
+	Validate(name, body string) error
+	// DefaultBody returns the built-in template of name.
+	DefaultBody(name string) (string, bool)
+	Names() []string
+	// Invalidate drops the cached rollout of name, e.g. after it was changed.
+	Invalidate(name string)
+}
+
+type pinnedPromptKey struct{ name string }
+
+// WithPromptVersion returns a context in which prompt name renders as version,
+// so a version picked before the call, e.g. to key a cache, is the one used.
+func WithPromptVersion(ctx context.Context, name string, version int) context.Context {
+	return context.WithValue(ctx, pinnedPromptKey{name: name}, version)
+}
+
+// PinnedPromptVersion returns the version of name pinned in ctx, if any.
+func PinnedPromptVersion(ctx context.Context, name string) (int, bool) {
+	version, ok := ctx.Value(pinnedPromptKey{name: name}).(int)
+	return version, ok
+}
+
+type IPromptTemplateUseCase interface {
+	ListTemplates(ctx context.Context) ([]PromptTemplateInfo, error)
+	// ListVersions returns the stored versions, newest first, followed by the
+	// built-in default as version 0, and the current rollout.
+	ListVersions(ctx context.Context, name string) ([]PromptTemplate, []PromptRollout, error)
+	CreateVersion(ctx context.Context, name, body, userID string) (PromptTemplate, error)
+	SetRollout(ctx context.Context, name string, rollout []PromptRollout) error
+	VersionStats(ctx context.Context, name string, from, to time.Time) ([]PromptVersionStats, error)
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// IEmbeddingClient turns text into vectors for similarity search. Model names
+// the embedding model, so vectors from different models are never compared.
+type IEmbeddingClient interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+}
+
+// BlogChunk is one passage of a blog with its embedding.
+type BlogChunk struct {
+	Chunk_id   string
+	Blog_id    string
+	Blog_title string
+	Position   int
+	Content    string
+	Embedding  []float32
+	Model      string
+	Created_at time.Time
+}
+
+// ScoredBlogChunk is a search hit with its cosine similarity to the query.
+type ScoredBlogChunk struct {
+	Chunk BlogChunk
+	Score float64
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 if
+// either is a zero vector or their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+type IBlogChunkRepository interface {
+	// ReplaceForBlog swaps all chunks of a blog for the given ones.
+	ReplaceForBlog(ctx context.Context, blogID string, chunks []BlogChunk) error
+	DeleteByBlog(ctx context.Context, blogID string) error
+	// ForEachByModel calls fn with every chunk embedded by model.
+	ForEachByModel(ctx context.Context, model string, fn func(chunk BlogChunk) error) error
+}
+
+// IBlogChunkIndex finds the k chunks most similar to a query vector.
+type IBlogChunkIndex interface {
+	Search(ctx context.Context, model string, query []float32, k int) ([]ScoredBlogChunk, error)
+}
+
+// AIAnswer is an answer grounded in blog posts, with the posts it drew on.
+type AIAnswer struct {
+	Answer    string
+	Citations []AICitation
+}
+
+type AICitation struct {
+	Blog_id string
+	Title   string
+	Excerpt string
+	Score   float64
+}
+
+// IBlogQAUseCase answers questions from the blog's own posts. It listens to
+// blog writes to keep the chunk index current.
+type IBlogQAUseCase interface {
+	IBlogChangeListener
+
+	// IndexBlog re-chunks and embeds a blog, or drops its chunks if it is gone.
+	IndexBlog(ctx context.Context, blogID string) error
+	// ReindexAll queues every blog for indexing and returns how many were queued.
+	ReindexAll(ctx context.Context) (int, error)
+	Ask(ctx context.Context, requester AIRequestInfo, question string) (AIAnswer, error)
+}
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCosineSimilarity_HandlesZeroAndMismatchedVectors(t *testing.T) {
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{0, 0}, []float32{1, 0}))
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{1}, []float32{1, 0}))
+	assert.InDelta(t, -1.0, CosineSimilarity([]float32{1, 0}, []float32{-2, 0}), 1e-9)
+}
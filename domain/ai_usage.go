@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AIRequestInfo identifies who an AI call is made for, so it can be metered
+// and checked against quotas. Fresh bypasses cached results.
+type AIRequestInfo struct {
+	UserID string
+	Role   Role
+	Fresh  bool
+}
+
+// AIUsageMeter collects the provider, model and token counts reported by the
+// model clients while serving one AI operation. A nil meter ignores reports.
+type AIUsageMeter struct {
+	mu sync.Mutex
+
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// Estimated is set when a provider did not report token counts and they
+	// were approximated from the text length instead.
+	Estimated bool
+
+	// the prompt template version the operation was rendered from, if any
+	PromptTemplate string
+	PromptVersion  int
+}
+
+// Add records one model call. Repeated calls, e.g. a repair retry, accumulate.
+func (m *AIUsageMeter) Add(provider, model string, promptTokens, completionTokens int, estimated bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Provider = provider
+	m.Model = model
+	m.PromptTokens += promptTokens
+	m.CompletionTokens += completionTokens
+	m.Estimated = m.Estimated || estimated
+}
+
+// SetPrompt records the prompt template version used. The last one wins.
+func (m *AIUsageMeter) SetPrompt(name string, version int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.PromptTemplate = name
+	m.PromptVersion = version
+}
+
+type aiUsageMeterKey struct{}
+
+// WithAIUsageMeter returns a context whose model calls report to meter.
+func WithAIUsageMeter(ctx context.Context, meter *AIUsageMeter) context.Context {
+	return context.WithValue(ctx, aiUsageMeterKey{}, meter)
+}
+
+// AIUsageMeterFrom returns the meter attached to ctx, or nil.
+func AIUsageMeterFrom(ctx context.Context) *AIUsageMeter {
+	meter, _ := ctx.Value(aiUsageMeterKey{}).(*AIUsageMeter)
+	return meter
+}
+
+// AIUsageRecord is one metered AI operation.
+type AIUsageRecord struct {
+	Usage_id          string
+	User_id           string
+	Role              Role
+	Operation         string
+	Provider          string
+	Model             string
+	Prompt_tokens     int
+	Completion_tokens int
+	Total_tokens      int
+	Tokens_estimated  bool
+	Estimated_cost    float64
+	Latency_ms        int64
+	Success           bool
+	Error             string
+	Prompt_template   string
+	Prompt_version    int
+	Created_at        time.Time
+}
+
+// AIQuota limits AI usage per day and per calendar month (UTC). Zero means unlimited.
+type AIQuota struct {
+	Daily_requests   int
+	Monthly_requests int
+	Daily_tokens     int
+	Monthly_tokens   int
+}
+
+// AIPrice is a provider's price per 1K prompt and completion tokens.
+type AIPrice struct {
+	Prompt_per_1k     float64
+	Completion_per_1k float64
+}
+
+// AIUsageTotals is the usage of one user over a period.
+type AIUsageTotals struct {
+	Requests int
+	Tokens   int
+}
+
+// AIQuotaWindow is usage against the limits of one period. A Limit of zero is unlimited.
+type AIQuotaWindow struct {
+	Requests_used  int
+	Requests_limit int
+	Tokens_used    int
+	Tokens_limit   int
+	Resets_at      time.Time
+}
+
+// AIQuotaPeriod is one day or calendar month (UTC) of a user's quota.
+type AIQuotaPeriod struct {
+	User_id string
+	Name    string // "day" or "month"
+	Start   time.Time
+	End     time.Time
+}
+
+// AIQuotaReservation holds the request counted in each limited period before
+// an AI call. It is nil when the requester is unlimited.
+type AIQuotaReservation struct {
+	Periods []AIQuotaPeriod
+}
+
+// AIQuotaStatus is a user's current usage against their daily and monthly quota.
+type AIQuotaStatus struct {
+	Unlimited bool
+	Daily     AIQuotaWindow
+	Monthly   AIQuotaWindow
+}
+
+// AIUsageReportRow aggregates usage of one provider/model pair.
+type AIUsageReportRow struct {
+	Provider          string
+	Model             string
+	Requests          int
+	Failures          int
+	Prompt_tokens     int
+	Completion_tokens int
+	Estimated_cost    float64
+}
+
+type AIUsageReport struct {
+	From  time.Time
+	To    time.Time
+	Rows  []AIUsageReportRow
+	Total AIUsageReportRow
+}
+
+type IAIUsageRepository interface {
+	Create(ctx context.Context, record AIUsageRecord) error
+	Report(ctx context.Context, from, to time.Time) ([]AIUsageReportRow, error)
+	PromptVersionStats(ctx context.Context, name string, from, to time.Time) ([]PromptVersionStats, error)
+}
+
+type IAIQuotaRepository interface {
+	// GetUserQuota returns ErrAIQuotaNotFound when the user has no override.
+	GetUserQuota(ctx context.Context, userID string) (AIQuota, error)
+	UpsertUserQuota(ctx context.Context, userID string, quota AIQuota) error
+
+	// ReserveRequest counts one request in the period, unless it already
+	// reached requestLimit requests or tokenLimit tokens (0 is unlimited), in
+	// which case it returns ErrAIQuotaExceeded. The check and the count are
+	// one atomic update.
+	ReserveRequest(ctx context.Context, period AIQuotaPeriod, requestLimit, tokenLimit int) error
+	// ReleaseRequest takes back a request reserved for a call that failed.
+	ReleaseRequest(ctx context.Context, period AIQuotaPeriod) error
+	AddTokens(ctx context.Context, period AIQuotaPeriod, tokens int) error
+	// PeriodUsage returns what was charged in the period.
+	PeriodUsage(ctx context.Context, period AIQuotaPeriod) (AIUsageTotals, error)
+}
+
+type IAIUsageUseCase interface {
+	// ReserveQuota counts one request against the requester's quota before
+	// an AI call, or returns ErrAIQuotaExceeded when a limit is used up.
+	ReserveQuota(ctx context.Context, requester AIRequestInfo) (*AIQuotaReservation, error)
+	// Track stores one AI operation using what the meter collected, and
+	// settles the reservation: a failed call gives its request back, a
+	// successful one is charged its tokens.
+	Track(ctx context.Context, requester AIRequestInfo, reservation *AIQuotaReservation, operation string, meter *AIUsageMeter, latency time.Duration, callErr error)
+	QuotaStatus(ctx context.Context, requester AIRequestInfo) (AIQuotaStatus, error)
+	Report(ctx context.Context, from, to time.Time) (AIUsageReport, error)
+	SetUserQuota(ctx context.Context, userID string, quota AIQuota) error
+}
@@ -49,8 +49,22 @@ type IAuthUsecase interface {
 	ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error
 
 	// OAuthLogin handles login/registration via an external OAuth2 provider.
+	// An existing account can only be logged into with an identity linked to it.
 	OAuthLogin(ctx context.Context, oauthUser *User) (*LoginResult, error)
 
+	// ListIdentities returns the OAuth identities linked to the user.
+	ListIdentities(ctx context.Context, userID string) ([]LinkedIdentity, error)
+
+	// LinkIdentity links the provider account to the logged in user.
+	LinkIdentity(ctx context.Context, userID string, oauthUser *User) (LinkedIdentity, error)
+
+	// UnlinkIdentity removes a linked identity. It returns ErrLastLoginMethod
+	// if the user would be left without any way to log in.
+	UnlinkIdentity(ctx context.Context, userID, provider string) error
+
+	// SetPassword adds a password to an account that only has OAuth identities.
+	SetPassword(ctx context.Context, userID, newPassword string) error
+
 }
 
 //PasswordService Interface
@@ -70,6 +84,10 @@ type IJWTService interface {
 	GeneratePasswordResetToken(userID string) (string, error)
 	ValidatePasswordResetToken(token string) (userID string, err error)
 	GetAccessTokenRemaining(token string) (time.Duration, error)
+	// GenerateScopedToken signs a token for subject that is only accepted for
+	// purpose, e.g. an unsubscribe link.
+	GenerateScopedToken(subject, purpose string, ttl time.Duration) (string, error)
+	ValidateScopedToken(token, purpose string) (subject string, err error)
 }
 
 
@@ -79,19 +97,53 @@ type OAuth2ProviderConfig struct {
 	RedirectURL  string
 	Scopes       []string
 	Endpoint     oauth2.Endpoint
+
+	// Issuer makes the provider a generic OpenID Connect one; its endpoints
+	// are discovered from the issuer instead of Endpoint
+	Issuer string
 }
 
-// OAuth2 providers interface
+// OAuth2 providers interface. codeVerifier is the PKCE verifier; the provider
+// sends its S256 challenge with the authorization request.
 type IOAuth2Provider interface {
 	Name() string // provider name
-	Authenticate(ctx context.Context, code string) (*User, error)
-	GetAuthorizationURL(state string) string
+	Authenticate(ctx context.Context, code string, codeVerifier string) (*User, error)
+	GetAuthorizationURL(state string, codeVerifier string) string
 }
 
 type IOAuth2Service interface {
 	SupportedProviders() []string
-	GetAuthorizationURL(provider string, state string) (string, error)
-	Authenticate(ctx context.Context, provider string, code string) (*User, error)
+	GetAuthorizationURL(ctx context.Context, provider string, state string, codeVerifier string) (string, error)
+	Authenticate(ctx context.Context, provider string, code string, codeVerifier string) (*User, error)
+	// Ready fails while a provider cannot be used yet, e.g. an OIDC issuer
+	// whose discovery document could not be fetched.
+	Ready(ctx context.Context) error
+}
+
+// OAuthState is what the browser must bring back to the callback of a login it started.
+// A Link_user_id marks a login started to link the provider to that user's account.
+type OAuthState struct {
+	State         string
+	Provider      string
+	Code_verifier string
+	Return_to     string
+	Link_user_id  string
+	Expires_at    time.Time
+}
+
+// IOAuthStateStore issues and checks the state of OAuth logins. The state is
+// kept in a signed browser cookie, so a callback is only accepted from the
+// browser that started the login.
+type IOAuthStateStore interface {
+	// Issue starts a login, or a link to linkUserID's account if set, and
+	// returns its state with the cookie value to set.
+	// It returns ErrInvalidReturnTo if returnTo is not an allowed redirect.
+	Issue(provider, returnTo, linkUserID string) (OAuthState, string, error)
+	// Verify checks the cookie against the provider and state of the callback.
+	// It returns ErrInvalidOAuthState if they do not match or the login expired.
+	Verify(cookie, provider, state string) (OAuthState, error)
+	// TTL is how long a login may take, used as the cookie lifetime.
+	TTL() time.Duration
 }
 
 type LoginResult struct {
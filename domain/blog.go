@@ -19,8 +19,38 @@ type Blog struct {
 	Dislike_count int
 	View_count    int
 
+	// AI enrichment, filled in by a background job when Auto_enrich is on.
+	// Enriched_version is the Content_updated_at of the content the job last
+	// ran for, so it trails Content_updated_at while enrichment is pending.
+	Auto_enrich       bool
+	Summary           string
+	Suggested_tags    []string
+	Enrichment_status string
+	Enriched_version  time.Time
+
 	Created_at time.Time
 	Updated_at time.Time
+	// Content_updated_at is when the title or content last changed. Editing
+	// only the tags or images leaves it alone.
+	Content_updated_at time.Time
+}
+
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// EnrichmentState reports whether the stored summary and suggested tags match
+// the current content. It is empty when auto enrichment is off.
+func (b Blog) EnrichmentState() string {
+	if !b.Auto_enrich {
+		return ""
+	}
+	if b.Enriched_version.Before(b.Content_updated_at) {
+		return EnrichmentPending
+	}
+	return b.Enrichment_status
 }
 
 type Pagination struct {
@@ -64,6 +94,22 @@ type IBlogRepository interface {
 	// Comments
 	AddCommentID(ctx context.Context, blogID, commentID string) error
 	RemoveCommentID(ctx context.Context, blogID, commentID string) error
+
+	// AI enrichment. version is the Content_updated_at of the content that was enriched;
+	// a result for content that has since changed is discarded.
+	SaveEnrichment(ctx context.Context, blogID string, version time.Time, summary string, suggestedTags []string) error
+	MarkEnrichmentFailed(ctx context.Context, blogID string, version time.Time) error
+	SetAutoEnrich(ctx context.Context, blogID string, enabled bool) error
+	UpdateTagSuggestions(ctx context.Context, blogID string, tagIDs, suggestedTags []string) error
+	FindStaleEnrichments(ctx context.Context, updatedBefore time.Time, limit int) ([]string, error)
+
+	// Recommendations
+	FindByIDs(ctx context.Context, blogIDs []string) ([]Blog, error)
+	TagIDsByBlog(ctx context.Context) (map[string][]string, error)
+
+	// Digests. FindPublished returns blogs created in (since, until] by one of
+	// authorIDs or with one of tagIDs, newest first.
+	FindPublished(ctx context.Context, since, until time.Time, authorIDs, tagIDs []string, limit int) ([]Blog, error)
 }
 
 type IBlogUseCase interface {
@@ -77,4 +123,17 @@ type IBlogUseCase interface {
 
 	SearchBlogs(ctx context.Context, title, author string, page, limit int) (*PaginatedBlogs, error)
 	FilterBlogs(ctx context.Context, params FilterParams) (*PaginatedBlogs, error)
+
+	SetAutoEnrich(ctx context.Context, blogID, userID string, enabled bool) error
+	// AcceptSuggestedTags attaches the given suggested tags to the blog, or all
+	// of them when tags is empty. RejectSuggestedTags drops them instead.
+	AcceptSuggestedTags(ctx context.Context, blogID, userID string, tags []string) error
+	RejectSuggestedTags(ctx context.Context, blogID, userID string, tags []string) error
+}
+
+// IBlogChangeListener is told about blog writes after they are committed.
+// Implementations must return quickly; slow work belongs in a background job.
+type IBlogChangeListener interface {
+	OnBlogSaved(ctx context.Context, blog Blog)
+	OnBlogDeleted(ctx context.Context, blogID string)
 }
@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+// IJobQueue schedules background work by key without blocking the caller.
+// It returns false if the job could not be queued.
+type IJobQueue interface {
+	Enqueue(key string) bool
+}
+
+// IBlogEnrichmentUseCase generates summaries and suggested tags for blogs that
+// opted in, in the background. It listens to blog writes to queue the work.
+type IBlogEnrichmentUseCase interface {
+	IBlogChangeListener
+
+	// Enrich runs one enrichment job. An error means the job should be retried.
+	Enrich(ctx context.Context, blogID string) error
+	// MarkFailed records that the job for blogID gave up.
+	MarkFailed(ctx context.Context, blogID string, cause error)
+	// RequeueStale queues blogs whose enrichment is pending but no longer
+	// queued, e.g. after a restart or a full queue.
+	RequeueStale(ctx context.Context)
+}
@@ -20,6 +20,9 @@ type IBlogReactionRepository interface {
 	UpdateReaction(ctx context.Context, blogReaction BlogReaction) error
 
 	DeleteReaction(ctx context.Context, blog_id, user_id string) error
+
+	// LikedBlogsByUser maps each user to the blogs they liked.
+	LikedBlogsByUser(ctx context.Context) (map[string][]string, error)
 }
 
 type IBlogReactionUsecase interface {
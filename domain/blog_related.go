@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RelatedBlog is a recommendation for another post with its combined score.
+type RelatedBlog struct {
+	Blog_id string
+	Score   float64
+}
+
+// BlogRelations holds the precomputed recommendations for one blog, best first.
+type BlogRelations struct {
+	Blog_id     string
+	Related     []RelatedBlog
+	Computed_at time.Time
+}
+
+// RelatedBlogPost is a recommended post as served to a reader.
+type RelatedBlogPost struct {
+	Blog  Blog
+	Score float64
+}
+
+type IRelatedBlogRepository interface {
+	SaveAll(ctx context.Context, relations []BlogRelations) error
+	// Get returns ErrNoRelatedBlogs if the blog has no relations stored.
+	Get(ctx context.Context, blogID string) (BlogRelations, error)
+	// DeleteComputedBefore drops relations left over from blogs that no longer exist.
+	DeleteComputedBefore(ctx context.Context, before time.Time) error
+}
+
+type IRelatedBlogUseCase interface {
+	// Recompute rebuilds the recommendations of every blog.
+	Recompute(ctx context.Context) error
+	// GetRelated returns up to limit posts related to blogID, leaving out the
+	// ones userID has already viewed.
+	GetRelated(ctx context.Context, blogID, userID string, limit int) ([]RelatedBlogPost, error)
+}
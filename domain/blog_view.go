@@ -14,4 +14,8 @@ type BlogView struct {
 
 type IBlogViewRepository interface {
 	CreateViewRecord(ctx context.Context, blog_id, user_id string) error
+	// ViewedBlogsByUser maps each user to the blogs they viewed.
+	ViewedBlogsByUser(ctx context.Context) (map[string][]string, error)
+	// FilterViewed returns the blogs among blogIDs that the user has viewed.
+	FilterViewed(ctx context.Context, userID string, blogIDs []string) ([]string, error)
 }
@@ -0,0 +1,31 @@
+package domain
+
+import "context"
+
+// IContentSink receives exported content: every tag, then every blog, then
+// every comment.
+type IContentSink interface {
+	Tag(tag Tag) error
+	Blog(blog Blog) error
+	Comment(comment Comment) error
+}
+
+// ContentCounts counts the content exported or imported.
+type ContentCounts struct {
+	Tags     int
+	Blogs    int
+	Comments int
+}
+
+// IContentRepository reads and writes content in bulk, keeping IDs, for
+// backups and moving content between instances.
+type IContentRepository interface {
+	EachTag(ctx context.Context, fn func(Tag) error) error
+	EachBlog(ctx context.Context, fn func(Blog) error) error
+	EachComment(ctx context.Context, fn func(Comment) error) error
+
+	// Upserts replace the document with the same ID, or insert it.
+	UpsertTag(ctx context.Context, tag Tag) error
+	UpsertBlog(ctx context.Context, blog Blog) error
+	UpsertComment(ctx context.Context, comment Comment) error
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CounterDiscrepancy is a denormalized counter that differs from a count of
+// the documents it summarizes, e.g. a blog's like_count and its likes in
+// blog_reactions.
+type CounterDiscrepancy struct {
+	Collection  string // "blogs" or "comments"
+	Document_id string
+	Field       string
+	Stored      int
+	Actual      int
+}
+
+// CounterReport is the result of reconciling the counters. Repaired counters
+// were set to their actual count; skipped ones changed while being checked
+// and are left for the next run.
+type CounterReport struct {
+	Started_at       time.Time
+	Finished_at      time.Time
+	Blogs_checked    int
+	Comments_checked int
+	Discrepancies    []CounterDiscrepancy
+	Repaired         int
+	Skipped          int
+}
+
+type ICounterRepository interface {
+	// FindBlogDiscrepancies recounts the reactions, views and comments of
+	// every blog, calls fn for each counter that differs and returns the
+	// number of blogs checked.
+	FindBlogDiscrepancies(ctx context.Context, fn func(CounterDiscrepancy) error) (int, error)
+	// FindCommentDiscrepancies does the same for the reactions of comments.
+	FindCommentDiscrepancies(ctx context.Context, fn func(CounterDiscrepancy) error) (int, error)
+	// Repair counts the documents d.Field summarizes again, and sets the
+	// counter to that count if it still holds d.Stored. It returns
+	// ErrCounterChanged when the counter changed or no longer drifts.
+	Repair(ctx context.Context, d CounterDiscrepancy) error
+}
+
+type ICounterUseCase interface {
+	// Reconcile reports every counter that drifted, and sets them right when
+	// repair is true. Only one reconciliation runs at a time; another one
+	// fails with ErrCounterReconcileRunning.
+	Reconcile(ctx context.Context, repair bool) (CounterReport, error)
+}
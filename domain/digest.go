@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const EmailTemplateDigest = "digest"
+
+// DigestFrequency is how often a reader gets the digest of new posts.
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// Period returns the time between two digests, or zero when digests are off.
+func (f DigestFrequency) Period() time.Duration {
+	switch f {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestSubscription holds a reader's digest preferences. A post goes in the
+// digest when it is by one of Author_ids or has one of Tag_ids, and was
+// published after Last_sent_at, the watermark of the previous digest.
+type DigestSubscription struct {
+	User_id      string
+	Frequency    DigestFrequency
+	Author_ids   []string
+	Tag_ids      []string
+	Last_sent_at time.Time
+	Next_send_at time.Time
+	Created_at   time.Time
+	Updated_at   time.Time
+}
+
+type IDigestSubscriptionRepository interface {
+	// Get returns ErrDigestSubscriptionNotFound if the user never subscribed.
+	Get(ctx context.Context, userID string) (DigestSubscription, error)
+	Upsert(ctx context.Context, sub DigestSubscription) error
+	// FindDue returns the subscriptions whose digest is due at now.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]DigestSubscription, error)
+	// Advance moves the watermark to sentAt and schedules the next digest, if
+	// the digest is still due at now. Otherwise it returns ErrDigestNotDue.
+	Advance(ctx context.Context, userID string, now, sentAt, nextSendAt time.Time) (DigestSubscription, error)
+	SetFrequency(ctx context.Context, userID string, frequency DigestFrequency) error
+}
+
+type IDigestUseCase interface {
+	GetPreferences(ctx context.Context, userID string) (DigestSubscription, error)
+	UpdatePreferences(ctx context.Context, userID string, frequency DigestFrequency, authorIDs, tagIDs []string) (DigestSubscription, error)
+	// SendDue queues the digest of every user whose digest is due.
+	SendDue(ctx context.Context)
+	// Unsubscribe turns digests off for the user an unsubscribe token was issued to.
+	Unsubscribe(ctx context.Context, token string) error
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	// OutboxSending is held by one worker until Next_attempt_at, after which
+	// the email is due again in case that worker died mid-send
+	OutboxSending OutboxStatus = "sending"
+	OutboxSent    OutboxStatus = "sent"
+	// OutboxDead emails gave up after too many attempts and wait for an admin
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxEmail is an email waiting for, or done with, background delivery.
+type OutboxEmail struct {
+	Email_id string
+	To       string
+	Subject  string
+	Html     string
+	Text     string
+	Template string
+	// Batch_id groups the emails of one mailing, e.g. a newsletter issue
+	Batch_id        string
+	Status          OutboxStatus
+	Attempts        int
+	Last_error      string
+	Next_attempt_at time.Time
+	Created_at      time.Time
+	Sent_at         time.Time
+	// Delivered_channels accepted the email on an earlier attempt and are
+	// skipped when it is retried
+	Delivered_channels []string
+}
+
+type IEmailOutboxRepository interface {
+	// Insert stores a pending email. It joins the transaction in ctx, if any.
+	Insert(ctx context.Context, email OutboxEmail) (OutboxEmail, error)
+	// InsertMany stores pending emails and returns their IDs, in order.
+	InsertMany(ctx context.Context, emails []OutboxEmail) ([]string, error)
+	// Claim marks a due email as sending until leaseUntil. It returns
+	// ErrOutboxEmailNotDue if the email is not pending and due, e.g. because
+	// another worker claimed it.
+	Claim(ctx context.Context, emailID string, now, leaseUntil time.Time) (OutboxEmail, error)
+	MarkSent(ctx context.Context, emailID string, sentAt time.Time) error
+	// MarkFailed records a failed attempt, setting the email to status.
+	// delivered names the channels that have accepted the email so far.
+	MarkFailed(ctx context.Context, emailID string, status OutboxStatus, attempts int, lastError string, delivered []string, nextAttemptAt time.Time) error
+	// FindDue returns the IDs of emails whose next attempt is due.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// List returns emails with status, newest first. An empty status lists all.
+	List(ctx context.Context, status OutboxStatus, page, limit int) ([]OutboxEmail, int, error)
+	CountByStatus(ctx context.Context) (map[OutboxStatus]int, error)
+	CountByBatch(ctx context.Context, batchID string) (map[OutboxStatus]int, error)
+	// Requeue makes a dead email pending again with its attempts reset.
+	// It returns ErrOutboxEmailNotDead if the email is not dead.
+	Requeue(ctx context.Context, emailID string, now time.Time) error
+}
+
+// IEmailOutbox queues emails for background delivery.
+type IEmailOutbox interface {
+	// Queue stores msg for delivery and returns its ID. It joins the
+	// transaction in ctx, if any; call Dispatch once that has committed.
+	Queue(ctx context.Context, msg EmailMessage, template string) (string, error)
+	// QueueBatch stores the emails of one mailing under batchID, like Queue.
+	QueueBatch(ctx context.Context, batchID string, msgs []EmailMessage, template string) ([]string, error)
+	// Dispatch hands queued emails to the workers now rather than at the next sweep.
+	Dispatch(emailIDs ...string)
+}
+
+type IEmailOutboxUseCase interface {
+	IEmailOutbox
+
+	// Deliver makes one delivery attempt. Failures are recorded on the email
+	// and retried with backoff; an error means recording itself failed.
+	Deliver(ctx context.Context, emailID string) error
+	// RequeueDue dispatches emails that are due but no longer queued, e.g.
+	// retries, or emails queued before a restart.
+	RequeueDue(ctx context.Context)
+	ListEmails(ctx context.Context, status OutboxStatus, page, limit int) ([]OutboxEmail, Pagination, error)
+	CountByStatus(ctx context.Context) (map[OutboxStatus]int, error)
+	// BatchStatus counts the emails of a batch by status. Sent emails expire
+	// from the outbox, so read it before they do.
+	BatchStatus(ctx context.Context, batchID string) (map[OutboxStatus]int, error)
+	// Retry sends a dead email again.
+	Retry(ctx context.Context, emailID string) error
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// names of the transactional emails
+const (
+	EmailTemplateVerifyEmail   = "verify_email"
+	EmailTemplatePasswordReset = "password_reset"
+)
+
+// EmailTemplate is the content of one transactional email in one locale,
+// written with Go's html/template (HTML) and text/template (Subject, Text).
+// The HTML is rendered inside the shared layout and may use its partials.
+type EmailTemplate struct {
+	Name       string
+	Locale     string
+	Subject    string
+	Html       string
+	Text       string
+	Updated_by string
+	Updated_at time.Time
+}
+
+// RenderedEmail is an email ready to send, with the locale it was rendered in.
+type RenderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
+	Locale  string
+}
+
+// EmailTemplateInfo tells whether an admin override replaces the built-in
+// template of a name and locale.
+type EmailTemplateInfo struct {
+	Name       string
+	Locale     string
+	Overridden bool
+	Updated_by string
+	Updated_at time.Time
+}
+
+type IEmailTemplateRepository interface {
+	// Get returns ErrEmailTemplateNotFound if there is no override.
+	Get(ctx context.Context, name, locale string) (EmailTemplate, error)
+	List(ctx context.Context) ([]EmailTemplate, error)
+	Upsert(ctx context.Context, template EmailTemplate) error
+	// Delete returns ErrEmailTemplateNotFound if there is no override.
+	Delete(ctx context.Context, name, locale string) error
+}
+
+// IEmailRenderer renders transactional emails, preferring admin overrides to
+// the built-in templates.
+type IEmailRenderer interface {
+	// Render executes name in the locale closest to the requested one with data.
+	Render(ctx context.Context, name, locale string, data map[string]interface{}) (RenderedEmail, error)
+	// Preview executes template with the sample data of its name.
+	Preview(template EmailTemplate) (RenderedEmail, error)
+	// Validate parses template and executes it against sample data.
+	Validate(template EmailTemplate) error
+	// Default returns the built-in template of name in locale.
+	Default(name, locale string) (EmailTemplate, bool)
+	Names() []string
+	Locales() []string
+	// Invalidate drops the cached override of name in locale, e.g. after it was changed.
+	Invalidate(name, locale string)
+}
+
+type IEmailTemplateUseCase interface {
+	ListTemplates(ctx context.Context) ([]EmailTemplateInfo, error)
+	// GetTemplate returns the template in use and whether it is an override.
+	GetTemplate(ctx context.Context, name, locale string) (EmailTemplate, bool, error)
+	OverrideTemplate(ctx context.Context, template EmailTemplate) (EmailTemplate, error)
+	// ResetTemplate removes the override so the built-in template is used again.
+	ResetTemplate(ctx context.Context, name, locale string) error
+	// Preview renders draft, or the template in use if draft is nil, with sample data.
+	Preview(ctx context.Context, name, locale string, draft *EmailTemplate) (RenderedEmail, error)
+}
@@ -15,6 +15,8 @@ var (
 	ErrBlogIDRequired      = errors.New("blog ID is required")
 	ErrIncrementViewFailed = errors.New("failed to increment blog view count")
 	ErrNotBlogAuthor       = errors.New("user is not the author of the blog")
+	ErrNoSuggestedTags     = errors.New("no matching suggested tags")
+	ErrNoRelatedBlogs      = errors.New("related blogs not computed yet")
 
 	// ─── Blog Reaction Errors ──────────────────────────────────────────────
 	ErrBlogReactionNotFound     = errors.New("blog reaction not found")
@@ -81,10 +83,70 @@ var (
 	ErrInvalidRole                      = errors.New("invalid role specified")
 	ErrInvalidOAuthUserData             = errors.New("invalid OAuth user data")
 	ErrOAuthProviderMismatch             = errors.New("OAuth provider mismatch for this account")
+	ErrInvalidOAuthState                = errors.New("invalid or expired OAuth state")
+	ErrOAuthProviderUnavailable         = errors.New("OAuth provider is unavailable, try again later")
+	ErrInvalidReturnTo                  = errors.New("return_to is not an allowed redirect")
+	ErrIdentityAlreadyLinked            = errors.New("this provider account is already linked")
+	ErrIdentityNotLinked                = errors.New("no identity from this provider is linked")
+	ErrLastLoginMethod                  = errors.New("cannot remove the last way to log in")
+	ErrPasswordAlreadySet               = errors.New("password is already set")
+	ErrPasswordNotSet                   = errors.New("account has no password")
 
 
 	// ─── AI Errors ───────────────────────────────────────────────────────
-	ErrContentMissing = errors.New("content is missing")
+	ErrContentMissing         = errors.New("content is missing")
+	ErrAIProviderNotSupported = errors.New("AI provider not supported")
+	ErrAIProvidersUnavailable = errors.New("all AI providers are unavailable")
+	ErrAIMalformedResponse    = errors.New("AI response could not be parsed")
+	ErrAIConversationNotFound = errors.New("AI conversation not found")
+	ErrInvalidConversationID  = errors.New("invalid AI conversation ID")
+	ErrEmptyConversationTitle = errors.New("conversation title cannot be empty")
+	ErrAIQuotaExceeded        = errors.New("AI usage quota exceeded")
+	ErrAIQuotaNotFound        = errors.New("AI quota not found")
+	ErrInvalidAIQuota         = errors.New("AI quota limits cannot be negative")
+	ErrAICacheMiss            = errors.New("AI response not cached")
+	ErrEmptyQuestion          = errors.New("question cannot be empty")
+	ErrAIEmbeddingFailed      = errors.New("failed to embed text")
+	ErrUnknownPromptTemplate  = errors.New("unknown prompt template")
+	ErrInvalidPromptTemplate  = errors.New("invalid prompt template")
+	ErrPromptVersionNotFound  = errors.New("prompt template version not found")
+	ErrInvalidPromptRollout   = errors.New("invalid prompt rollout")
+
+	// ─── Email Errors ──────────────────────────────────────────────────────
+	ErrUnknownEmailTemplate  = errors.New("unknown email template")
+	ErrInvalidEmailTemplate  = errors.New("invalid email template")
+	ErrEmailTemplateNotFound = errors.New("email template override not found")
+	ErrUnsupportedLocale     = errors.New("unsupported locale")
+	ErrOutboxEmailNotFound   = errors.New("outbox email not found")
+	ErrOutboxEmailNotDue     = errors.New("outbox email is not due for delivery")
+	ErrOutboxEmailNotDead    = errors.New("only failed emails can be retried")
+	ErrInvalidOutboxStatus   = errors.New("invalid outbox status")
+
+	// ─── Notification Errors ───────────────────────────────────────────────
+	ErrNoNotificationChannel = errors.New("no notification channel for event")
+	ErrNotificationRejected  = errors.New("notification rejected by channel")
+
+	// ─── Digest Errors ─────────────────────────────────────────────────────
+	ErrDigestSubscriptionNotFound = errors.New("digest subscription not found")
+	ErrDigestNotDue               = errors.New("digest is not due")
+	ErrInvalidDigestFrequency     = errors.New("invalid digest frequency")
+	ErrInvalidDigestPreferences   = errors.New("invalid digest preferences")
+	ErrInvalidUnsubscribeToken    = errors.New("invalid or expired unsubscribe link")
+
+	// ─── Newsletter Errors ─────────────────────────────────────────────────
+	ErrSubscriberNotFound       = errors.New("newsletter subscriber not found")
+	ErrInvalidSubscriberEmail   = errors.New("invalid subscriber email")
+	ErrInvalidSubscriberStatus  = errors.New("invalid subscriber status")
+	ErrInvalidConfirmationToken = errors.New("invalid or expired confirmation link")
+	ErrIssueNotFound            = errors.New("newsletter issue not found")
+	ErrIssueNotClaimable        = errors.New("newsletter issue is not waiting to be sent")
+	ErrIssueAlreadySent         = errors.New("blog was already sent to this newsletter")
+	ErrInvalidBounce            = errors.New("invalid bounce report")
+
+	// ─── Counter Errors ────────────────────────────────────────────────────
+	ErrCounterChanged          = errors.New("counter changed while being reconciled")
+	ErrCounterReconcileRunning = errors.New("counter reconciliation already running")
+
 	// ─── Generic Errors ────────────────────────────────────────────────────
 	ErrInternalServerError = errors.New("internal server error")
 )
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthUp HealthStatus = "up"
+	// HealthDegraded means a dependency the service can run without is down,
+	// e.g. SMTP or the AI providers.
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+// DependencyHealth is the result of checking one dependency.
+type DependencyHealth struct {
+	Name string
+	// Critical dependencies take the whole service down with them
+	Critical bool
+	Status   HealthStatus
+	Latency  time.Duration
+	Error    string
+}
+
+type HealthReport struct {
+	Status HealthStatus
+	Checks []DependencyHealth
+}
+
+// IHealthChecker checks the dependencies the service needs to serve requests.
+type IHealthChecker interface {
+	Check(ctx context.Context) HealthReport
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EmailTemplateNewsletterConfirm = "newsletter_confirm"
+	EmailTemplateNewsletterIssue   = "newsletter_issue"
+)
+
+// SubscriberStatus is where a newsletter subscription is in its lifecycle.
+type SubscriberStatus string
+
+const (
+	// SubscriberPending has not confirmed the email yet and gets no issues.
+	SubscriberPending      SubscriberStatus = "pending"
+	SubscriberActive       SubscriberStatus = "active"
+	SubscriberUnsubscribed SubscriberStatus = "unsubscribed"
+	// SubscriberBounced had mail rejected or reported as spam and gets no issues.
+	SubscriberBounced SubscriberStatus = "bounced"
+)
+
+// NewsletterSubscriber is an email, not necessarily a user's, subscribed to
+// one author's newsletter, or to every issue when Author_id is empty.
+type NewsletterSubscriber struct {
+	Subscriber_id        string
+	Email                string
+	Author_id            string
+	Language             string
+	Status               SubscriberStatus
+	Confirmation_sent_at time.Time
+	Created_at           time.Time
+	Confirmed_at         time.Time
+	Left_at              time.Time
+}
+
+// IssueStatus is the progress of sending a newsletter issue.
+type IssueStatus string
+
+const (
+	IssueSending IssueStatus = "sending"
+	IssueSent    IssueStatus = "sent"
+)
+
+// NewsletterIssue is a published blog sent to the author's subscribers, or
+// to the site-wide list when Author_id is empty. A blog is sent to a list once.
+// Recipients are queued in order of email; Cursor is the last email queued,
+// so an interrupted send resumes after it.
+type NewsletterIssue struct {
+	Issue_id    string
+	Author_id   string
+	Blog_id     string
+	Title       string
+	Status      IssueStatus
+	Cursor      string
+	Lease_until time.Time
+	Created_at  time.Time
+	Sent_at     time.Time
+
+	Recipients   int
+	Bounced      int
+	Unsubscribed int
+	// Delivered and Failed are copied from the outbox once every email of the
+	// issue has been sent or given up on, since sent emails expire there.
+	Delivered int
+	Failed    int
+	Finalized bool
+}
+
+// NewsletterIssueStats counts what happened to the emails of an issue.
+type NewsletterIssueStats struct {
+	Recipients   int
+	Pending      int
+	Delivered    int
+	Failed       int
+	Bounced      int
+	Unsubscribed int
+}
+
+// BounceKind is how a mail provider reports an undeliverable email.
+type BounceKind string
+
+const (
+	BounceHard      BounceKind = "hard"
+	BounceSoft      BounceKind = "soft"
+	BounceComplaint BounceKind = "complaint"
+)
+
+type INewsletterSubscriberRepository interface {
+	Create(ctx context.Context, sub NewsletterSubscriber) (NewsletterSubscriber, error)
+	Get(ctx context.Context, subscriberID string) (NewsletterSubscriber, error)
+	// FindByEmail returns ErrSubscriberNotFound if the email is not on the author's list.
+	FindByEmail(ctx context.Context, email, authorID string) (NewsletterSubscriber, error)
+	Update(ctx context.Context, sub NewsletterSubscriber) error
+	// SetStatusByEmail changes every subscription of the email and returns how many changed.
+	SetStatusByEmail(ctx context.Context, email string, status SubscriberStatus, at time.Time) (int, error)
+	List(ctx context.Context, authorID string, status SubscriberStatus, page, limit int) ([]NewsletterSubscriber, int, error)
+	// Each calls fn for every subscriber on the author's list, ordered by email.
+	Each(ctx context.Context, authorID string, fn func(NewsletterSubscriber) error) error
+	// Recipients returns the active subscribers of the author's list, or the
+	// site-wide list when authorID is empty, with an email after afterEmail, ordered by email.
+	Recipients(ctx context.Context, authorID, afterEmail string, limit int) ([]NewsletterSubscriber, error)
+	Delete(ctx context.Context, subscriberID, authorID string) error
+}
+
+type INewsletterIssueRepository interface {
+	// Create returns ErrIssueAlreadySent if the blog was already sent to the list.
+	Create(ctx context.Context, issue NewsletterIssue) (NewsletterIssue, error)
+	Get(ctx context.Context, issueID string) (NewsletterIssue, error)
+	List(ctx context.Context, authorID string, page, limit int) ([]NewsletterIssue, int, error)
+	// Claim takes an issue that is sending and not leased by another worker.
+	// It returns ErrIssueNotClaimable otherwise.
+	Claim(ctx context.Context, issueID string, now, leaseUntil time.Time) (NewsletterIssue, error)
+	// Advance records a queued batch of recipients and renews the lease.
+	Advance(ctx context.Context, issueID, cursor string, queued int, leaseUntil time.Time) error
+	MarkSent(ctx context.Context, issueID string, sentAt time.Time) error
+	Increment(ctx context.Context, issueID string, bounced, unsubscribed int) error
+	Finalize(ctx context.Context, issueID string, delivered, failed int) error
+	// FindUnsent returns issues left sending whose lease ran out.
+	FindUnsent(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// FindUnfinalized returns sent issues whose delivery counts are not final yet.
+	FindUnfinalized(ctx context.Context, limit int) ([]string, error)
+}
+
+type INewsletterUseCase interface {
+	// Subscribe adds the email to an author's list, or the site-wide list when
+	// authorID is empty, and emails a confirmation link. It succeeds without
+	// sending anything if the email is already subscribed.
+	Subscribe(ctx context.Context, email, authorID, language string) error
+	Confirm(ctx context.Context, token string) error
+	Unsubscribe(ctx context.Context, token string) error
+	HandleBounce(ctx context.Context, email string, kind BounceKind, issueID string) error
+
+	ListSubscribers(ctx context.Context, authorID string, status SubscriberStatus, page, limit int) ([]NewsletterSubscriber, Pagination, error)
+	ExportSubscribers(ctx context.Context, authorID string, fn func(NewsletterSubscriber) error) error
+	RemoveSubscriber(ctx context.Context, authorID, subscriberID string) error
+
+	// SendIssue creates an issue of the author's blog and queues it in the background.
+	SendIssue(ctx context.Context, authorID, blogID string) (NewsletterIssue, error)
+	// SendSiteIssue sends any published blog to the site-wide list.
+	SendSiteIssue(ctx context.Context, blogID string) (NewsletterIssue, error)
+	// DeliverIssue queues the emails of an issue; called by the background queue.
+	DeliverIssue(ctx context.Context, issueID string) error
+	// ResumeIssues requeues interrupted issues and finalizes delivery counts.
+	ResumeIssues(ctx context.Context)
+	ListIssues(ctx context.Context, authorID string, page, limit int) ([]NewsletterIssue, Pagination, error)
+	IssueStats(ctx context.Context, authorID, issueID string) (NewsletterIssue, NewsletterIssueStats, error)
+}
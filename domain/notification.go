@@ -1,5 +1,61 @@
 package domain
 
+import "context"
+
+// EmailMessage is an email with an HTML body and a plain-text alternative.
+type EmailMessage struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
 type INotificationService interface {
 	SendEmail(to string, subject string, body string) error
+	// Send sends msg as multipart/alternative, so clients without HTML show Text.
+	Send(msg EmailMessage) error
+}
+
+// Notification channel types
+const (
+	NotificationChannelSMTP    = "smtp"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelSlack   = "slack"
+	NotificationChannelDiscord = "discord"
+	NotificationChannelFile    = "file"
+)
+
+// Notification is an event to deliver, such as a verification email. To is
+// only used by channels that address a person; the others deliver to their
+// configured destination.
+type Notification struct {
+	Event   string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// NotificationChannelConfig describes one configured channel and the events
+// routed to it. "*" in Events matches every event; no Events routes none.
+type NotificationChannelConfig struct {
+	Name   string
+	Type   string
+	URL    string
+	Path   string
+	Secret string
+	Events []string
+}
+
+type INotificationChannel interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// INotifier delivers a notification to every channel routed for its event.
+type INotifier interface {
+	// Notify skips the channels named in delivered, e.g. those that accepted n
+	// on an earlier attempt, and returns delivered with the channels that
+	// accepted it now added. A failing channel does not stop the others.
+	Notify(ctx context.Context, n Notification, delivered []string) ([]string, error)
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// IOperationsUseCase holds the tasks operators run from the admin CLI,
+// outside of any HTTP session.
+type IOperationsUseCase interface {
+	// CreateAdmin creates a verified admin, or promotes the user who already
+	// has the email. It reports whether a user was created. Unlike
+	// PromoteToAdmin it needs no existing admin, so it sets up the first one.
+	CreateAdmin(ctx context.Context, email, username, password string) (*User, bool, error)
+	// ResetPassword sets the user's password and signs them out everywhere.
+	ResetPassword(ctx context.Context, email, password string) error
+	VerifyEmail(ctx context.Context, email string) error
+
+	ExportContent(ctx context.Context, sink IContentSink) (ContentCounts, error)
+	// ImportContent calls read with a sink upserting everything it is given.
+	ImportContent(ctx context.Context, read func(IContentSink) error) (ContentCounts, error)
+}
@@ -10,6 +10,8 @@ type Role string
 const (
 	RoleUser  Role = "USER"
 	RoleAdmin Role = "ADMIN"
+	// RoleSystem identifies work the server does on its own behalf, e.g. background jobs.
+	RoleSystem Role = "SYSTEM"
 )
 
 type User struct {
@@ -31,9 +33,43 @@ type User struct {
 	Provider string // for oauth2 user
 	RawData  map[string]any
 
+	// Identities are the OAuth accounts the user can log in with
+	Identities []LinkedIdentity
+
+	// Language is the preferred locale of emails sent to the user, e.g. "fr"
+	Language string
+
 	Role Role
 }
 
+// LinkedIdentity is an account at an OAuth provider linked to a user.
+// Subject_id is the provider's own ID for the account.
+type LinkedIdentity struct {
+	Provider   string
+	Subject_id string
+	Email      string
+	Linked_at  time.Time
+}
+
+// Identity returns the user's identity at provider.
+func (u User) Identity(provider string) (LinkedIdentity, bool) {
+	for _, identity := range u.Identities {
+		if identity.Provider == provider {
+			return identity, true
+		}
+	}
+	return LinkedIdentity{}, false
+}
+
+// LoginMethods counts the ways the user can log in: a password and each linked identity.
+func (u User) LoginMethods() int {
+	n := len(u.Identities)
+	if u.Password != nil && *u.Password != "" {
+		n++
+	}
+	return n
+}
+
 // UserRepository Interface
 type IUserRepository interface {
 	CreateUser(c context.Context, user *User) error
@@ -55,7 +91,16 @@ type IUserRepository interface {
 	SearchUsers(c context.Context, q string) ([]User, error)
 
 	UpdateTokens(c context.Context, userID string, accesToken string, refreshToken string) error	
-	UpdateRole(c context.Context, userID string, role string) error
+	UpdateRole(c context.Context, userID string, role Role) error
+
+	// FindByIdentity returns ErrUserNotFound if no user linked the provider account.
+	FindByIdentity(c context.Context, provider, subjectID string) (*User, error)
+	// AddIdentity returns ErrIdentityAlreadyLinked if the user already has an
+	// identity at the provider or another user linked the same account.
+	AddIdentity(c context.Context, userID string, identity LinkedIdentity) error
+	// RemoveIdentity returns ErrIdentityNotLinked if there is nothing to remove,
+	// and ErrLastLoginMethod if it is the user's only way to log in.
+	RemoveIdentity(c context.Context, userID, provider string) error
 }
 
 // User UseCase Interface
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2, time.Hour)
+
+	c.Set(ctx, "a", "1")
+	c.Set(ctx, "b", "2")
+	_, _ = c.Get(ctx, "a") // a is now more recent than b
+	c.Set(ctx, "c", "3")
+
+	_, ok := c.Get(ctx, "b")
+	assert.False(t, ok)
+	v, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "k", "v")
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get(ctx, "k")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get(ctx, "k")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_SetReplacesValue(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2, time.Hour)
+
+	c.Set(ctx, "k", "old")
+	c.Set(ctx, "k", "new")
+
+	v, _ := c.Get(ctx, "k")
+	assert.Equal(t, "new", v)
+	assert.Equal(t, 1, c.Len())
+}
+
+type mapStore struct {
+	entries map[string]string
+}
+
+func (s *mapStore) Get(ctx context.Context, key string) (string, error) {
+	v, ok := s.entries[key]
+	if !ok {
+		return "", domain.ErrAICacheMiss
+	}
+	return v, nil
+}
+
+func (s *mapStore) Set(ctx context.Context, key, value string, expiresAt time.Time) error {
+	s.entries[key] = value
+	return nil
+}
+
+func TestTieredCache_FillsMemoryFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := &mapStore{entries: map[string]string{"k": "from store"}}
+	c := NewTieredCache(10, time.Hour, store)
+
+	v, ok := c.Get(ctx, "k")
+	assert.True(t, ok)
+	assert.Equal(t, "from store", v)
+
+	delete(store.entries, "k")
+	v, ok = c.Get(ctx, "k")
+	assert.True(t, ok)
+	assert.Equal(t, "from store", v)
+
+	c.Set(ctx, "n", "new")
+	assert.Equal(t, "new", store.entries["n"])
+}
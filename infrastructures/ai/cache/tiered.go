@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// TieredCache implements domain.IAIResponseCache with an in-memory LRU in front
+// of a persistent store shared by all instances. Store failures are logged and
+// treated as misses so the cache never breaks the AI call it fronts.
+type TieredCache struct {
+	memory *LRU
+	store  domain.IAIResponseCacheRepository
+	ttl    time.Duration
+}
+
+// NewTieredCache returns a cache keeping up to size entries in memory and every
+// entry in store, each for ttl. store may be nil for a memory-only cache.
+func NewTieredCache(size int, ttl time.Duration, store domain.IAIResponseCacheRepository) *TieredCache {
+	return &TieredCache{
+		memory: NewLRU(size, ttl),
+		store:  store,
+		ttl:    ttl,
+	}
+}
+
+func (c *TieredCache) Get(ctx context.Context, key string) (string, bool) {
+	if value, ok := c.memory.Get(ctx, key); ok {
+		return value, true
+	}
+	if c.store == nil {
+		return "", false
+	}
+
+	value, err := c.store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, domain.ErrAICacheMiss) {
+			slog.WarnContext(ctx, "AI cache lookup failed", "error", err)
+		}
+		return "", false
+	}
+	c.memory.Set(ctx, key, value)
+	return value, true
+}
+
+func (c *TieredCache) Set(ctx context.Context, key, value string) {
+	c.memory.Set(ctx, key, value)
+	if c.store == nil {
+		return
+	}
+	if err := c.store.Set(ctx, key, value, time.Now().Add(c.ttl)); err != nil {
+		slog.WarnContext(ctx, "AI cache write failed", "error", err)
+	}
+}
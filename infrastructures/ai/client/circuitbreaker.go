@@ -0,0 +1,138 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker stops calls to a provider after threshold consecutive failures.
+// Once the cooldown elapses a single trial call is let through (half-open); its
+// outcome decides whether the breaker closes again or stays open.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state            breakerState
+	failures         int
+	openedAt         time.Time
+	lastErr          string
+	lastFailureAt    time.Time
+	halfOpenInFlight bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may be sent to the provider.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.halfOpenInFlight = true
+		return true
+	case breakerHalfOpen:
+		if b.halfOpenInFlight {
+			return false
+		}
+		b.halfOpenInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// onSuccess closes the breaker and resets the failure count.
+func (b *circuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+	b.halfOpenInFlight = false
+}
+
+// onFailure records a failed call and opens the breaker when the threshold is
+// reached or when the half-open trial call failed.
+func (b *circuitBreaker) onFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastErr = err.Error()
+	b.lastFailureAt = b.now()
+	b.halfOpenInFlight = false
+
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release frees the half-open slot without recording an outcome, e.g. when the
+// caller cancelled the request before the provider answered.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+	b.halfOpenInFlight = false
+}
+
+type breakerSnapshot struct {
+	state         breakerState
+	failures      int
+	lastErr       string
+	lastFailureAt time.Time
+	retryAt       time.Time
+}
+
+func (b *circuitBreaker) snapshot() breakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := breakerSnapshot{
+		state:         b.state,
+		failures:      b.failures,
+		lastErr:       b.lastErr,
+		lastFailureAt: b.lastFailureAt,
+	}
+	if b.state == breakerOpen {
+		s.retryAt = b.openedAt.Add(b.cooldown)
+	}
+	return s
+}
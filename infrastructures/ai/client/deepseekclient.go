@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
 )
 
 type DeepSeekClient struct {
@@ -35,14 +36,24 @@ func NewDeepSeekClient(apiKey, baseURL, model string) *DeepSeekClient {
 }
 
 func (d *DeepSeekClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return d.complete(ctx, prompt, nil)
+}
+
+// GenerateJSON uses DeepSeek's JSON output mode so the reply is a single JSON object.
+func (d *DeepSeekClient) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return d.complete(ctx, prompt, &dto.ResponseFormat{Type: "json_object"})
+}
+
+func (d *DeepSeekClient) complete(ctx context.Context, prompt string, format *dto.ResponseFormat) (string, error) {
 	// wrap prompt into the chat messages expected by DeepSeek (user role)
 	messages := []dto.ChatMessage{
 		{Role: "user", Content: prompt},
 	}
 
 	reqBody := dto.ChatCompletionRequest{
-		Model:    d.model,
-		Messages: messages,
+		Model:          d.model,
+		Messages:       messages,
+		ResponseFormat: format,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -66,7 +77,7 @@ func (d *DeepSeekClient) Generate(ctx context.Context, prompt string) (string, e
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", errors.New(fmt.Sprintf("deepseek API error: status %d, body: %s", resp.StatusCode, string(bodyBytes)))
+		return "", &APIError{Provider: "deepseek", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	var res dto.ChatCompletionResponse
@@ -78,5 +89,17 @@ func (d *DeepSeekClient) Generate(ctx context.Context, prompt string) (string, e
 		return "", errors.New("deepseek API error: no choices returned")
 	}
 
+	reportUsage(ctx, "deepseek", d.model, res.Usage, prompt, res.Choices[0].Message.Content)
 	return res.Choices[0].Message.Content, nil
 }
+
+func (d *DeepSeekClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	reqBody := dto.ChatCompletionRequest{
+		Model:    d.model,
+		Messages: []dto.ChatMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+
+		StreamOptions: &dto.StreamOptions{IncludeUsage: true},
+	}
+	return streamChatCompletion(ctx, "deepseek", d.model, d.baseURL+"/chat/completions", d.apiKey, prompt, reqBody, onDelta)
+}
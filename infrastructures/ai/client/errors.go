@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// APIError is returned when a provider answers with a non-200 status code.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error: status %d, body: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// isRetryable reports whether err means the provider is unhealthy, so the call
+// should fall through to the next provider in the chain. Server errors, rate
+// limits, timeouts, transport failures and truncated streams qualify; other
+// client errors do not.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == 429
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errStreamTruncated) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
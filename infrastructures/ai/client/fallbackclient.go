@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/infrastructures/metrics"
+	"github.com/InkForge/Blog_Website/infrastructures/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultAttemptTimeout   = 30 * time.Second
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// NamedClient pairs a model client with the provider and model it talks to.
+type NamedClient struct {
+	Name   string
+	Model  string
+	Client domain.IAIModelClient
+}
+
+// FallbackOptions tunes the per-attempt timeout and the circuit breakers.
+// Zero values fall back to sensible defaults.
+type FallbackOptions struct {
+	AttemptTimeout   time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+type providerEntry struct {
+	NamedClient
+	breaker *circuitBreaker
+}
+
+// FallbackClient implements domain.IAIModelClient over an ordered chain of
+// providers. A 5xx, rate limit, timeout or transport failure from one provider
+// falls through to the next one; each provider has its own circuit breaker.
+type FallbackClient struct {
+	providers      []*providerEntry
+	attemptTimeout time.Duration
+}
+
+// NewFallbackClient returns a FallbackClient trying providers in the given order.
+func NewFallbackClient(providers []NamedClient, opts FallbackOptions) *FallbackClient {
+	if opts.AttemptTimeout <= 0 {
+		opts.AttemptTimeout = defaultAttemptTimeout
+	}
+	if opts.BreakerThreshold <= 0 {
+		opts.BreakerThreshold = defaultBreakerThreshold
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = defaultBreakerCooldown
+	}
+
+	entries := make([]*providerEntry, 0, len(providers))
+	for _, p := range providers {
+		entries = append(entries, &providerEntry{
+			NamedClient: p,
+			breaker:     newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		})
+	}
+
+	return &FallbackClient{
+		providers:      entries,
+		attemptTimeout: opts.AttemptTimeout,
+	}
+}
+
+// Generate sends the prompt to the first healthy provider in the chain.
+func (f *FallbackClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return f.do(ctx, func(ctx context.Context, c domain.IAIModelClient) (string, error) {
+		attemptCtx, cancel := context.WithTimeout(ctx, f.attemptTimeout)
+		defer cancel()
+		return c.Generate(attemptCtx, prompt)
+	})
+}
+
+// GenerateJSON asks the first healthy provider for a JSON-only reply, using
+// its JSON mode when the provider client supports one.
+func (f *FallbackClient) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return f.do(ctx, func(ctx context.Context, c domain.IAIModelClient) (string, error) {
+		attemptCtx, cancel := context.WithTimeout(ctx, f.attemptTimeout)
+		defer cancel()
+		if jc, ok := c.(domain.IAIJSONModelClient); ok {
+			return jc.GenerateJSON(attemptCtx, prompt)
+		}
+		return c.Generate(attemptCtx, prompt)
+	})
+}
+
+// errNoFirstDelta marks a streaming attempt that produced nothing within the
+// attempt timeout.
+var errNoFirstDelta = errors.New("no response before attempt timeout")
+
+// streamInterruptedError wraps a failure that happened after some deltas were
+// already relayed to the caller. Such a stream cannot be retried elsewhere
+// without duplicating output, so it ends the chain.
+type streamInterruptedError struct {
+	err error
+}
+
+func (e *streamInterruptedError) Error() string { return e.err.Error() }
+func (e *streamInterruptedError) Unwrap() error { return e.err }
+
+// GenerateStream streams the response of the first healthy provider. The
+// attempt timeout only bounds the wait for the first delta, and the chain
+// falls through to the next provider only while nothing has been relayed yet.
+func (f *FallbackClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	return f.do(ctx, func(ctx context.Context, c domain.IAIModelClient) (string, error) {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		timer := time.AfterFunc(f.attemptTimeout, func() { cancel(errNoFirstDelta) })
+		defer timer.Stop()
+
+		started := false
+		out, err := c.GenerateStream(attemptCtx, prompt, func(delta string) error {
+			if !started {
+				started = true
+				timer.Stop()
+			}
+			return onDelta(delta)
+		})
+		if err == nil {
+			return out, nil
+		}
+		if errors.Is(context.Cause(attemptCtx), errNoFirstDelta) {
+			err = fmt.Errorf("%w: %w", errNoFirstDelta, context.DeadlineExceeded)
+		}
+		if started {
+			return out, &streamInterruptedError{err: err}
+		}
+		return out, err
+	})
+}
+
+func (f *FallbackClient) do(ctx context.Context, call func(context.Context, domain.IAIModelClient) (string, error)) (string, error) {
+	var lastErr error
+
+	for _, p := range f.providers {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if !p.breaker.allow() {
+			lastErr = fmt.Errorf("%s: circuit open", p.Name)
+			continue
+		}
+
+		started := time.Now()
+		attemptCtx, span := tracing.Tracer().Start(ctx, "AI "+p.Name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("ai.provider", p.Name), attribute.String("ai.model", p.Model)))
+		out, err := call(attemptCtx, p.Client)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		metrics.ObserveAICall(p.Name, time.Since(started), err)
+
+		var interrupted *streamInterruptedError
+		switch {
+		case err == nil:
+			p.breaker.onSuccess()
+			return out, nil
+		case ctx.Err() != nil:
+			// the caller gave up; that says nothing about the provider
+			p.breaker.release()
+			return "", err
+		case errors.As(err, &interrupted):
+			if isRetryable(interrupted.err) {
+				p.breaker.onFailure(interrupted.err)
+			} else {
+				p.breaker.onSuccess()
+			}
+			return out, interrupted.err
+		case !isRetryable(err):
+			// the provider answered, so it is healthy even though the call failed
+			p.breaker.onSuccess()
+			return "", err
+		}
+
+		p.breaker.onFailure(err)
+		slog.WarnContext(ctx, "AI provider failed, trying next provider", "provider", p.Name, "error", err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no providers configured")
+	}
+	return "", fmt.Errorf("%w: %v", domain.ErrAIProvidersUnavailable, lastErr)
+}
+
+// ProviderHealth reports the breaker state of every provider, in chain order.
+func (f *FallbackClient) ProviderHealth() []domain.AIProviderHealth {
+	health := make([]domain.AIProviderHealth, 0, len(f.providers))
+	for i, p := range f.providers {
+		s := p.breaker.snapshot()
+		health = append(health, domain.AIProviderHealth{
+			Name:                p.Name,
+			Model:               p.Model,
+			Priority:            i + 1,
+			State:               s.state.String(),
+			ConsecutiveFailures: s.failures,
+			LastError:           s.lastErr,
+			LastFailureAt:       s.lastFailureAt,
+			RetryAt:             s.retryAt,
+		})
+	}
+	return health
+}
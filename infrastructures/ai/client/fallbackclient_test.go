@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubClient returns the queued errors in order, then succeeds with reply.
+type stubClient struct {
+	reply string
+	errs  []error
+	calls int
+
+	// midStreamErr, when set, is returned by GenerateStream after the first word.
+	midStreamErr error
+}
+
+func (s *stubClient) Generate(ctx context.Context, prompt string) (string, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return "", err
+	}
+	return s.reply, nil
+}
+
+func (s *stubClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return "", err
+	}
+	var sent string
+	for _, word := range strings.SplitAfter(s.reply, " ") {
+		if err := onDelta(word); err != nil {
+			return sent, err
+		}
+		sent += word
+		if s.midStreamErr != nil {
+			return sent, s.midStreamErr
+		}
+	}
+	return sent, nil
+}
+
+func TestFallbackClient_FallsThroughOnServerError(t *testing.T) {
+	primary := &stubClient{errs: []error{&APIError{Provider: "groq", StatusCode: 503}}}
+	secondary := &stubClient{reply: "from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{})
+
+	out, err := fc.Generate(context.Background(), "hi")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "from deepseek", out)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+func TestFallbackClient_DoesNotFallThroughOnClientError(t *testing.T) {
+	primary := &stubClient{errs: []error{&APIError{Provider: "groq", StatusCode: 401}}}
+	secondary := &stubClient{reply: "from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{})
+
+	_, err := fc.Generate(context.Background(), "hi")
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFallbackClient_AllProvidersFail(t *testing.T) {
+	primary := &stubClient{errs: []error{context.DeadlineExceeded}}
+
+	fc := NewFallbackClient([]NamedClient{{Name: "groq", Client: primary}}, FallbackOptions{})
+
+	_, err := fc.Generate(context.Background(), "hi")
+
+	assert.ErrorIs(t, err, domain.ErrAIProvidersUnavailable)
+}
+
+func TestFallbackClient_BreakerOpensAndRecovers(t *testing.T) {
+	serverErr := &APIError{Provider: "groq", StatusCode: 500}
+	primary := &stubClient{reply: "from groq", errs: []error{serverErr, serverErr}}
+	secondary := &stubClient{reply: "from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+
+	now := time.Now()
+	fc.providers[0].breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		out, err := fc.Generate(context.Background(), "hi")
+		assert.NoError(t, err)
+		assert.Equal(t, "from deepseek", out)
+	}
+
+	// the third call skipped groq because its breaker was open
+	assert.Equal(t, 2, primary.calls)
+	health := fc.ProviderHealth()
+	assert.Equal(t, "open", health[0].State)
+	assert.Equal(t, 2, health[0].ConsecutiveFailures)
+
+	// after the cooldown a trial call goes through and closes the breaker
+	now = now.Add(2 * time.Minute)
+	out, err := fc.Generate(context.Background(), "hi")
+	assert.NoError(t, err)
+	assert.Equal(t, "from groq", out)
+	assert.Equal(t, "closed", fc.ProviderHealth()[0].State)
+}
+
+func TestFallbackClient_StreamFallsThroughBeforeFirstDelta(t *testing.T) {
+	primary := &stubClient{errs: []error{&APIError{Provider: "groq", StatusCode: 502}}}
+	secondary := &stubClient{reply: "hello from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{})
+
+	var deltas []string
+	out, err := fc.GenerateStream(context.Background(), "hi", func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello from deepseek", out)
+	assert.Equal(t, []string{"hello ", "from ", "deepseek"}, deltas)
+}
+
+func TestFallbackClient_StreamDoesNotFallThroughAfterFirstDelta(t *testing.T) {
+	primary := &stubClient{reply: "partial answer", midStreamErr: &APIError{Provider: "groq", StatusCode: 503}}
+	secondary := &stubClient{reply: "from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{})
+
+	out, err := fc.GenerateStream(context.Background(), "hi", func(string) error { return nil })
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "partial ", out)
+	assert.Equal(t, 0, secondary.calls)
+	assert.Equal(t, 1, fc.ProviderHealth()[0].ConsecutiveFailures)
+}
+
+func TestReadChatCompletionStream(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+		``,
+		`data: {"choices":[],"x_groq":{"usage":{"prompt_tokens":12,"completion_tokens":2,"total_tokens":14}}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var deltas []string
+	out, usage, err := readChatCompletionStream(strings.NewReader(body), func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", out)
+	assert.Equal(t, []string{"Hel", "lo"}, deltas)
+	if assert.NotNil(t, usage) {
+		assert.Equal(t, 12, usage.PromptTokens)
+		assert.Equal(t, 2, usage.CompletionTokens)
+	}
+}
+
+func TestReadChatCompletionStream_TruncatedWithoutDone(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+		``,
+	}, "\n")
+
+	out, _, err := readChatCompletionStream(strings.NewReader(body), func(string) error { return nil })
+	assert.ErrorIs(t, err, errStreamTruncated)
+	assert.Equal(t, "Hel", out)
+
+	_, _, err = readChatCompletionStream(strings.NewReader(""), func(string) error { return nil })
+	assert.ErrorIs(t, err, errStreamTruncated)
+}
+
+func TestFallbackClient_StreamFallsThroughOnTruncatedStream(t *testing.T) {
+	primary := &stubClient{errs: []error{errStreamTruncated}}
+	secondary := &stubClient{reply: "from deepseek"}
+
+	fc := NewFallbackClient([]NamedClient{
+		{Name: "groq", Client: primary},
+		{Name: "deepseek", Client: secondary},
+	}, FallbackOptions{})
+
+	out, err := fc.GenerateStream(context.Background(), "hi", func(string) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "from deepseek", out)
+	assert.Equal(t, 1, fc.ProviderHealth()[0].ConsecutiveFailures)
+
+	// after the first delta the truncation ends the chain
+	primary.midStreamErr = errStreamTruncated
+	primary.reply = "partial answer"
+	out, err = fc.GenerateStream(context.Background(), "hi", func(string) error { return nil })
+	assert.ErrorIs(t, err, errStreamTruncated)
+	assert.Equal(t, "partial ", out)
+	assert.Equal(t, 2, fc.ProviderHealth()[0].ConsecutiveFailures)
+}
@@ -7,24 +7,35 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
 )
 
 type GroqClient struct {
-	apiKey string
-	model  string
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
 }
 
-func NewGroqClient(apiKey, model string) *GroqClient {
+func NewGroqClient(apiKey, baseURL, model string) *GroqClient {
+	if baseURL == "" {
+		baseURL = "https://api.groq.com/openai/v1"
+	}
 	return &GroqClient{
-		apiKey: apiKey,
-		model:  model,
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
 	}
 }
 
-func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error) {
-	url := "https://api.groq.com/openai/v1/chat/completions"
-
-	reqBody := map[string]interface{}{
+func (c *GroqClient) requestBody(prompt string) map[string]interface{} {
+	return map[string]interface{}{
 		"model": c.model,
 		"messages": []map[string]string{
 			{"role": "system", "content": "You are an AI assistant."},
@@ -32,6 +43,21 @@ func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 		},
 		"temperature": 0.7,
 	}
+}
+
+func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, prompt, c.requestBody(prompt))
+}
+
+// GenerateJSON uses Groq's JSON mode so the reply is a single JSON object.
+func (c *GroqClient) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	reqBody := c.requestBody(prompt)
+	reqBody["response_format"] = map[string]string{"type": "json_object"}
+	return c.complete(ctx, prompt, reqBody)
+}
+
+func (c *GroqClient) complete(ctx context.Context, prompt string, reqBody map[string]interface{}) (string, error) {
+	url := c.baseURL + "/chat/completions"
 
 	data, _ := json.Marshal(reqBody)
 
@@ -43,7 +69,7 @@ func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -51,7 +77,7 @@ func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("groq API error: status %d, body: %s", resp.StatusCode, string(body))
+		return "", &APIError{Provider: "groq", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var result struct {
@@ -61,6 +87,7 @@ func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage *dto.Usage `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -71,5 +98,13 @@ func (c *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 		return "", fmt.Errorf("no completion returned from groq")
 	}
 
+	reportUsage(ctx, "groq", c.model, result.Usage, prompt, result.Choices[0].Message.Content)
 	return result.Choices[0].Message.Content, nil
 }
+
+func (c *GroqClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	reqBody := c.requestBody(prompt)
+	reqBody["stream"] = true
+	reqBody["stream_options"] = dto.StreamOptions{IncludeUsage: true}
+	return streamChatCompletion(ctx, "groq", c.model, c.baseURL+"/chat/completions", c.apiKey, prompt, reqBody, onDelta)
+}
@@ -2,45 +2,130 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
 	openai "github.com/sashabaranov/go-openai"
 )
 
 type OpenAIClient struct {
-    client *openai.Client
+	client  *openai.Client
 	aimodel string
 }
 
-func NewOpenAIClient(apiKey, aimodel string) (*OpenAIClient, error) {
-    return &OpenAIClient{
-        client: openai.NewClient(apiKey),
+func NewOpenAIClient(apiKey, baseURL, aimodel string) (*OpenAIClient, error) {
+	config := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	return &OpenAIClient{
+		client:  openai.NewClientWithConfig(config),
 		aimodel: aimodel,
-    }, nil
+	}, nil
+}
+
+func (c *OpenAIClient) chatRequest(prompt string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: c.aimodel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    "system",
+				Content: "You are a helpful AI assistant focused on blog content creation.",
+			},
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		Temperature: 0.7,
+	}
 }
 
 func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
-    resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-        Model: c.aimodel, 
-        Messages: []openai.ChatCompletionMessage{
-            {
-                Role:    "system",
-                Content: "You are a helpful AI assistant focused on blog content creation.",
-            },
-            {
-                Role:    "user",
-                Content: prompt,
-            },
-        },
-        Temperature: 0.7,
-    })
-    if err != nil {
-        return "", fmt.Errorf("OpenAI API error: %w", err)
-    }
-
-    if len(resp.Choices) == 0 {
-        return "", fmt.Errorf("OpenAI API returned no choices")
-    }
-
-    return resp.Choices[0].Message.Content, nil
-}
\ No newline at end of file
+	return c.complete(ctx, c.chatRequest(prompt))
+}
+
+// GenerateJSON uses OpenAI's JSON mode so the reply is a single JSON object.
+func (c *OpenAIClient) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	req := c.chatRequest(prompt)
+	req.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	return c.complete(ctx, req)
+}
+
+func (c *OpenAIClient) complete(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+	resp, err := c.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return "", wrapOpenAIError(err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI API returned no choices")
+	}
+
+	prompt := req.Messages[len(req.Messages)-1].Content
+	reportUsage(ctx, "openai", c.aimodel, &dto.Usage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}, prompt, resp.Choices[0].Message.Content)
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (c *OpenAIClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	req := c.chatRequest(prompt)
+	req.Stream = true
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", wrapOpenAIError(err)
+	}
+	defer stream.Close()
+
+	var full strings.Builder
+	var usage *dto.Usage
+	defer func() { reportUsage(ctx, "openai", c.aimodel, usage, prompt, full.String()) }()
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return full.String(), nil
+		}
+		if err != nil {
+			return full.String(), wrapOpenAIError(err)
+		}
+		if resp.Usage != nil {
+			usage = &dto.Usage{
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+				TotalTokens:      resp.Usage.TotalTokens,
+			}
+		}
+		for _, choice := range resp.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			full.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return full.String(), err
+			}
+		}
+	}
+}
+
+// wrapOpenAIError converts go-openai status errors into APIError so the
+// fallback chain can classify them like the other providers.
+func wrapOpenAIError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return &APIError{Provider: "openai", StatusCode: apiErr.HTTPStatusCode, Body: apiErr.Message}
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0 {
+		return &APIError{Provider: "openai", StatusCode: reqErr.HTTPStatusCode, Body: reqErr.Error()}
+	}
+	return fmt.Errorf("OpenAI API error: %w", err)
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// NewModelClient builds the client for a single provider configuration.
+func NewModelClient(cfg domain.AIProviderConfig) (domain.IAIModelClient, error) {
+	switch cfg.Name {
+	case "groq":
+		return NewGroqClient(cfg.APIKey, cfg.BaseURL, cfg.Model), nil
+	case "deepseek":
+		return NewDeepSeekClient(cfg.APIKey, cfg.BaseURL, cfg.Model), nil
+	case "openai":
+		return NewOpenAIClient(cfg.APIKey, cfg.BaseURL, cfg.Model)
+	default:
+		return nil, fmt.Errorf("%w: %s", domain.ErrAIProviderNotSupported, cfg.Name)
+	}
+}
+
+// NewProviderChain builds a FallbackClient over the configured providers, in order.
+func NewProviderChain(configs []domain.AIProviderConfig, opts FallbackOptions) (*FallbackClient, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("%w: no AI provider configured", domain.ErrAIProviderNotSupported)
+	}
+
+	providers := make([]NamedClient, 0, len(configs))
+	for _, cfg := range configs {
+		c, err := NewModelClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, NamedClient{Name: cfg.Name, Model: cfg.Model, Client: c})
+	}
+
+	return NewFallbackClient(providers, opts), nil
+}
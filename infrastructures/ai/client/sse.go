@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// streamHTTPClient has no overall timeout: a streamed completion can run for
+// longer than a buffered one, so its lifetime is bounded by the request context.
+var streamHTTPClient = &http.Client{}
+
+// streamChatCompletion posts an OpenAI-compatible chat/completions request with
+// stream enabled and relays every content fragment to onDelta. The usage in the
+// final chunk, if any, is reported to the usage meter in ctx.
+func streamChatCompletion(ctx context.Context, provider, model, url, apiKey, prompt string, body interface{}, onDelta domain.AIStreamHandler) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := streamHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", &APIError{Provider: provider, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	out, usage, err := readChatCompletionStream(resp.Body, onDelta)
+	reportUsage(ctx, provider, model, usage, prompt, out)
+	return out, err
+}
+
+// errStreamTruncated is returned when a stream ends without the [DONE]
+// sentinel, e.g. because the provider dropped the connection.
+var errStreamTruncated = errors.New("stream ended before [DONE]")
+
+// readChatCompletionStream parses "data:" lines of a chat completion event
+// stream until the [DONE] sentinel, returning the text and the usage reported
+// by the provider. A stream that ends earlier returns errStreamTruncated with
+// the text received so far.
+func readChatCompletionStream(r io.Reader, onDelta domain.AIStreamHandler) (string, *dto.Usage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var full strings.Builder
+	var usage *dto.Usage
+	done := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk dto.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return full.String(), usage, fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		} else if chunk.XGroq != nil && chunk.XGroq.Usage != nil {
+			usage = chunk.XGroq.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			full.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return full.String(), usage, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return full.String(), usage, err
+	}
+	if !done {
+		return full.String(), usage, errStreamTruncated
+	}
+	return full.String(), usage, nil
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/InkForge/Blog_Website/delivery/controllers/dto"
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// reportUsage adds the token usage of one completion to the meter in ctx.
+// When the provider did not report usage, it is estimated from the text.
+func reportUsage(ctx context.Context, provider, model string, usage *dto.Usage, prompt, completion string) {
+	meter := domain.AIUsageMeterFrom(ctx)
+	if meter == nil {
+		return
+	}
+	if usage != nil && usage.TotalTokens > 0 {
+		meter.Add(provider, model, usage.PromptTokens, usage.CompletionTokens, false)
+		return
+	}
+	meter.Add(provider, model, estimateTokens(prompt), estimateTokens(completion), true)
+}
+
+// estimateTokens approximates a token count at four characters per token.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
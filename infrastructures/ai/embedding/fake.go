@@ -0,0 +1,68 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const defaultFakeDimensions = 256
+
+// FakeClient is a deterministic embedding client that needs no network. It
+// hashes the words of a text into a fixed size vector, so texts sharing words
+// score as similar. It is meant for tests and offline development.
+type FakeClient struct {
+	dimensions int
+}
+
+func NewFakeClient(dimensions int) *FakeClient {
+	if dimensions <= 0 {
+		dimensions = defaultFakeDimensions
+	}
+	return &FakeClient{dimensions: dimensions}
+}
+
+func (c *FakeClient) Model() string {
+	return fmt.Sprintf("fake-hash-%d", c.dimensions)
+}
+
+func (c *FakeClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = c.embed(text)
+	}
+	return vectors, nil
+}
+
+func (c *FakeClient) embed(text string) []float32 {
+	vector := make([]float32, c.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		sum := h.Sum32()
+		// the top bit picks the sign so unrelated words tend to cancel out
+		sign := float32(1)
+		if sum&(1<<31) != 0 {
+			sign = -1
+		}
+		vector[int(sum%uint32(c.dimensions))] += sign
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+	return vector
+}
@@ -0,0 +1,63 @@
+package embedding
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// Index is a brute force vector search over the stored blog chunks. Every
+// search scans the chunks of the query's model and keeps the k best in a heap,
+// which is plenty for a blog sized corpus.
+type Index struct {
+	repo domain.IBlogChunkRepository
+}
+
+func NewIndex(repo domain.IBlogChunkRepository) domain.IBlogChunkIndex {
+	return &Index{repo: repo}
+}
+
+// Search returns up to k chunks ordered by descending cosine similarity.
+func (i *Index) Search(ctx context.Context, model string, query []float32, k int) ([]domain.ScoredBlogChunk, error) {
+	if k <= 0 || len(query) == 0 {
+		return nil, nil
+	}
+
+	best := &scoredHeap{}
+	err := i.repo.ForEachByModel(ctx, model, func(chunk domain.BlogChunk) error {
+		if len(chunk.Embedding) != len(query) {
+			return nil
+		}
+		hit := domain.ScoredBlogChunk{Chunk: chunk, Score: domain.CosineSimilarity(query, chunk.Embedding)}
+		if best.Len() < k {
+			heap.Push(best, hit)
+		} else if hit.Score > (*best)[0].Score {
+			(*best)[0] = hit
+			heap.Fix(best, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []domain.ScoredBlogChunk(*best)
+	sort.Slice(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+	return hits, nil
+}
+
+// scoredHeap is a min-heap on score, so the weakest of the kept hits is on top.
+type scoredHeap []domain.ScoredBlogChunk
+
+func (h scoredHeap) Len() int            { return len(h) }
+func (h scoredHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h scoredHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoredHeap) Push(x interface{}) { *h = append(*h, x.(domain.ScoredBlogChunk)) }
+func (h *scoredHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
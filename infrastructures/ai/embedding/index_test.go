@@ -0,0 +1,95 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryChunkRepository keeps chunks in a slice.
+type memoryChunkRepository struct {
+	chunks []domain.BlogChunk
+}
+
+func (r *memoryChunkRepository) ReplaceForBlog(ctx context.Context, blogID string, chunks []domain.BlogChunk) error {
+	_ = r.DeleteByBlog(ctx, blogID)
+	r.chunks = append(r.chunks, chunks...)
+	return nil
+}
+
+func (r *memoryChunkRepository) DeleteByBlog(ctx context.Context, blogID string) error {
+	kept := r.chunks[:0]
+	for _, c := range r.chunks {
+		if c.Blog_id != blogID {
+			kept = append(kept, c)
+		}
+	}
+	r.chunks = kept
+	return nil
+}
+
+func (r *memoryChunkRepository) ForEachByModel(ctx context.Context, model string, fn func(chunk domain.BlogChunk) error) error {
+	for _, c := range r.chunks {
+		if c.Model != model {
+			continue
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexPassages(t *testing.T, client *FakeClient, repo *memoryChunkRepository, passages map[string]string) {
+	t.Helper()
+	for blogID, text := range passages {
+		vectors, err := client.Embed(context.Background(), []string{text})
+		assert.NoError(t, err)
+		_ = repo.ReplaceForBlog(context.Background(), blogID, []domain.BlogChunk{{
+			Blog_id: blogID, Content: text, Embedding: vectors[0], Model: client.Model(),
+		}})
+	}
+}
+
+func TestFakeClient_IsDeterministic(t *testing.T) {
+	client := NewFakeClient(64)
+
+	first, err := client.Embed(context.Background(), []string{"Goroutines and channels"})
+	assert.NoError(t, err)
+	second, _ := NewFakeClient(64).Embed(context.Background(), []string{"goroutines, and CHANNELS!"})
+
+	assert.Equal(t, first, second)
+	assert.InDelta(t, 1.0, domain.CosineSimilarity(first[0], first[0]), 1e-6)
+}
+
+func TestIndex_SearchRanksByCosineSimilarity(t *testing.T) {
+	client := NewFakeClient(256)
+	repo := &memoryChunkRepository{}
+	indexPassages(t, client, repo, map[string]string{
+		"go":      "goroutines and channels make concurrency in go simple",
+		"cooking": "slow roasted tomatoes with garlic and olive oil",
+		"mongo":   "mongodb replica sets are needed for multi document transactions",
+	})
+
+	query, _ := client.Embed(context.Background(), []string{"how do channels work with goroutines"})
+	hits, err := NewIndex(repo).Search(context.Background(), client.Model(), query[0], 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, "go", hits[0].Chunk.Blog_id)
+	assert.GreaterOrEqual(t, hits[0].Score, hits[1].Score)
+}
+
+func TestIndex_SearchIgnoresOtherModels(t *testing.T) {
+	client := NewFakeClient(32)
+	repo := &memoryChunkRepository{}
+	indexPassages(t, client, repo, map[string]string{"go": "goroutines"})
+
+	query, _ := client.Embed(context.Background(), []string{"goroutines"})
+	hits, err := NewIndex(repo).Search(context.Background(), "another-model", query[0], 3)
+
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+}
@@ -0,0 +1,65 @@
+// Package embedding provides the embedding clients and the vector search used
+// to answer questions from blog posts.
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/InkForge/Blog_Website/domain"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+
+// OpenAIClient embeds text with an OpenAI compatible embeddings endpoint.
+type OpenAIClient struct {
+	client *openai.Client
+	model  string
+}
+
+func NewOpenAIClient(apiKey, baseURL, model string) *OpenAIClient {
+	config := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	return &OpenAIClient{
+		client: openai.NewClientWithConfig(config),
+		model:  model,
+	}
+}
+
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	resp, err := c.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(c.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrAIEmbeddingFailed, err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("%w: got %d embeddings for %d texts", domain.ErrAIEmbeddingFailed, len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("%w: embedding index %d out of range", domain.ErrAIEmbeddingFailed, item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+
+	domain.AIUsageMeterFrom(ctx).Add("openai", c.model, resp.Usage.PromptTokens, 0, false)
+	return vectors, nil
+}
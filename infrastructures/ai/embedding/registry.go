@@ -0,0 +1,23 @@
+package embedding
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// NewClient builds the embedding client for provider. An empty provider
+// returns nil, which turns question answering off.
+func NewClient(provider, apiKey, baseURL, model string) (domain.IEmbeddingClient, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "":
+		return nil, nil
+	case "fake":
+		return NewFakeClient(defaultFakeDimensions), nil
+	case "openai":
+		return NewOpenAIClient(apiKey, baseURL, model), nil
+	default:
+		return nil, fmt.Errorf("%w: %s embeddings", domain.ErrAIProviderNotSupported, provider)
+	}
+}
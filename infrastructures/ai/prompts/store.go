@@ -0,0 +1,285 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/infrastructures/ttlcache"
+)
+
+// names of the prompts used by the AI content service
+const (
+	SuggestTags          = "suggest_tags"
+	Summarize            = "summarize"
+	GenerateTitle        = "generate_title"
+	SuggestContent       = "suggest_content"
+	ImproveContent       = "improve_content"
+	ImproveContentStream = "improve_content_stream"
+	Chat                 = "chat"
+	ChatStream           = "chat_stream"
+)
+
+// DefaultRolloutTTL is how long a rollout is cached before it is read again,
+// so changes made on another instance are picked up.
+const DefaultRolloutTTL = 30 * time.Second
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// sampleData is executed against a new version to catch fields it references
+// that the service does not provide.
+var sampleData = map[string]map[string]interface{}{
+	SuggestTags:          {"MaxTags": 5, "Title": "Sample title", "Content": "Sample content"},
+	Summarize:            {"MaxWords": 200, "Content": "Sample content"},
+	GenerateTitle:        {"Style": "informative", "Content": "Sample content"},
+	SuggestContent:       {"Keywords": "sample, keywords", "Style": "informative", "WordCount": 250},
+	ImproveContent:       {"Focus": "clarity", "Content": "Sample content"},
+	ImproveContentStream: {"Focus": "clarity", "Content": "Sample content"},
+	Chat:                 {"Messages": `[{"role":"user","content":"Hello"}]`},
+	ChatStream:           {"Messages": `[{"role":"user","content":"Hello"}]`},
+}
+
+type templateKey struct {
+	name    string
+	version int
+}
+
+// Store renders prompts from the versions rolled out in the repository,
+// falling back to the embedded defaults.
+type Store struct {
+	repo       domain.IPromptTemplateRepository
+	rolloutTTL time.Duration
+	defaults   map[string]string
+	now        func() time.Time
+	// pick returns a number in [0, n) to choose a version by weight
+	pick func(n int) int
+
+	rollouts *ttlcache.Cache[string, []domain.PromptRollout]
+
+	mu     sync.Mutex
+	parsed map[templateKey]*template.Template
+}
+
+// NewStore returns a renderer backed by repo. A nil repo always renders the
+// embedded defaults.
+func NewStore(repo domain.IPromptTemplateRepository, rolloutTTL time.Duration) domain.IPromptRenderer {
+	return newStore(repo, rolloutTTL)
+}
+
+func newStore(repo domain.IPromptTemplateRepository, rolloutTTL time.Duration) *Store {
+	if rolloutTTL <= 0 {
+		rolloutTTL = DefaultRolloutTTL
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var rngMu sync.Mutex
+
+	s := &Store{
+		repo:       repo,
+		rolloutTTL: rolloutTTL,
+		defaults:   loadDefaults(),
+		now:        time.Now,
+		pick: func(n int) int {
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			return rng.Intn(n)
+		},
+		parsed: make(map[templateKey]*template.Template),
+	}
+	s.rollouts = ttlcache.New[string, []domain.PromptRollout](rolloutTTL, func() time.Time { return s.now() })
+	return s
+}
+
+func loadDefaults() map[string]string {
+	files, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	defaults := make(map[string]string, len(files))
+	for _, file := range files {
+		body, err := defaultTemplates.ReadFile(path.Join("templates", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		defaults[strings.TrimSuffix(file.Name(), ".tmpl")] = string(body)
+	}
+	return defaults
+}
+
+// Render executes the version of name pinned in ctx, or else the one picked
+// from its rollout. Any problem with a stored version is logged and the
+// embedded default is used instead, so a bad edit cannot take the AI features down.
+func (s *Store) Render(ctx context.Context, name string, data interface{}) (domain.RenderedPrompt, error) {
+	if _, ok := s.defaults[name]; !ok {
+		return domain.RenderedPrompt{}, domain.ErrUnknownPromptTemplate
+	}
+
+	version, pinned := domain.PinnedPromptVersion(ctx, name)
+	if !pinned {
+		version = s.pickVersion(s.rollout(ctx, name))
+	}
+
+	if version != 0 {
+		text, err := s.execute(ctx, name, version, data)
+		if err == nil {
+			domain.AIUsageMeterFrom(ctx).SetPrompt(name, version)
+			return domain.RenderedPrompt{Text: text, Name: name, Version: version}, nil
+		}
+		slog.WarnContext(ctx, "prompt version failed, using the default", "prompt", name, "version", version, "error", err)
+	}
+
+	text, err := s.execute(ctx, name, 0, data)
+	if err != nil {
+		return domain.RenderedPrompt{}, err
+	}
+	domain.AIUsageMeterFrom(ctx).SetPrompt(name, 0)
+	return domain.RenderedPrompt{Text: text, Name: name, Version: 0}, nil
+}
+
+// PickVersion chooses a version of name from its rollout.
+func (s *Store) PickVersion(ctx context.Context, name string) (int, error) {
+	if _, ok := s.defaults[name]; !ok {
+		return 0, domain.ErrUnknownPromptTemplate
+	}
+	return s.pickVersion(s.rollout(ctx, name)), nil
+}
+
+// Validate parses body and executes it against sample data for name.
+func (s *Store) Validate(name, body string) error {
+	if _, ok := s.defaults[name]; !ok {
+		return domain.ErrUnknownPromptTemplate
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is empty", domain.ErrInvalidPromptTemplate)
+	}
+	tmpl, err := parse(name, body)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, sampleData[name]); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidPromptTemplate, err)
+	}
+	return nil
+}
+
+func (s *Store) DefaultBody(name string) (string, bool) {
+	body, ok := s.defaults[name]
+	return body, ok
+}
+
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.defaults))
+	for name := range s.defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) Invalidate(name string) {
+	s.rollouts.Invalidate(name)
+}
+
+// rollout returns the cached rollout of name, reading it again once expired.
+// A failed read is cached too, so an unavailable database is not hit on every call.
+func (s *Store) rollout(ctx context.Context, name string) []domain.PromptRollout {
+	if s.repo == nil {
+		return nil
+	}
+	return s.rollouts.Get(name, func() []domain.PromptRollout {
+		rollout, err := s.repo.GetRollout(ctx, name)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load prompt rollout, using the default", "prompt", name, "error", err)
+			return nil
+		}
+		return rollout
+	})
+}
+
+// pickVersion chooses a version at random in proportion to its weight.
+// An empty rollout means the default.
+func (s *Store) pickVersion(rollout []domain.PromptRollout) int {
+	total := 0
+	for _, r := range rollout {
+		if r.Weight > 0 {
+			total += r.Weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	n := s.pick(total)
+	for _, r := range rollout {
+		if r.Weight <= 0 {
+			continue
+		}
+		if n < r.Weight {
+			return r.Version
+		}
+		n -= r.Weight
+	}
+	return 0
+}
+
+func (s *Store) execute(ctx context.Context, name string, version int, data interface{}) (string, error) {
+	tmpl, err := s.template(ctx, name, version)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidPromptTemplate, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// template returns the parsed version. Versions never change once stored, so
+// they are cached for the life of the process.
+func (s *Store) template(ctx context.Context, name string, version int) (*template.Template, error) {
+	key := templateKey{name: name, version: version}
+	s.mu.Lock()
+	tmpl, ok := s.parsed[key]
+	s.mu.Unlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	body := s.defaults[name]
+	if version != 0 {
+		if s.repo == nil {
+			return nil, domain.ErrPromptVersionNotFound
+		}
+		stored, err := s.repo.GetVersion(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		body = stored.Body
+	}
+
+	tmpl, err := parse(name, body)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.parsed[key] = tmpl
+	s.mu.Unlock()
+	return tmpl, nil
+}
+
+func parse(name, body string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPromptTemplate, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,190 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTemplateRepository struct {
+	versions     map[templateKey]domain.PromptTemplate
+	rollouts     map[string][]domain.PromptRollout
+	rolloutErr   error
+	rolloutReads int
+}
+
+func newMemoryTemplateRepository() *memoryTemplateRepository {
+	return &memoryTemplateRepository{
+		versions: make(map[templateKey]domain.PromptTemplate),
+		rollouts: make(map[string][]domain.PromptRollout),
+	}
+}
+
+func (r *memoryTemplateRepository) CreateVersion(ctx context.Context, t domain.PromptTemplate) (domain.PromptTemplate, error) {
+	for key := range r.versions {
+		if key.name == t.Name && key.version >= t.Version {
+			t.Version = key.version
+		}
+	}
+	t.Version++
+	r.versions[templateKey{name: t.Name, version: t.Version}] = t
+	return t, nil
+}
+
+func (r *memoryTemplateRepository) GetVersion(ctx context.Context, name string, version int) (domain.PromptTemplate, error) {
+	t, ok := r.versions[templateKey{name: name, version: version}]
+	if !ok {
+		return domain.PromptTemplate{}, domain.ErrPromptVersionNotFound
+	}
+	return t, nil
+}
+
+func (r *memoryTemplateRepository) ListVersions(ctx context.Context, name string) ([]domain.PromptTemplate, error) {
+	return nil, nil
+}
+
+func (r *memoryTemplateRepository) GetRollout(ctx context.Context, name string) ([]domain.PromptRollout, error) {
+	r.rolloutReads++
+	return r.rollouts[name], r.rolloutErr
+}
+
+func (r *memoryTemplateRepository) SetRollout(ctx context.Context, name string, rollout []domain.PromptRollout) error {
+	r.rollouts[name] = rollout
+	return nil
+}
+
+func TestStore_RendersEmbeddedDefaultWithoutRepository(t *testing.T) {
+	s := newStore(nil, 0)
+	meter := &domain.AIUsageMeter{}
+	ctx := domain.WithAIUsageMeter(context.Background(), meter)
+
+	prompt, err := s.Render(ctx, SuggestTags, map[string]interface{}{"MaxTags": 3, "Title": "Go", "Content": `say "hi"`})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, prompt.Version)
+	assert.Contains(t, prompt.Text, "Maximum 3 tags")
+	assert.Contains(t, prompt.Text, `Content: "say \"hi\""`)
+	assert.Equal(t, SuggestTags, meter.PromptTemplate)
+	assert.Equal(t, 0, meter.PromptVersion)
+}
+
+func TestStore_EveryDefaultRendersItsSampleData(t *testing.T) {
+	s := newStore(nil, 0)
+	for _, name := range s.Names() {
+		body, ok := s.DefaultBody(name)
+		require.True(t, ok)
+		assert.NoError(t, s.Validate(name, body), name)
+	}
+	assert.Len(t, s.Names(), len(sampleData))
+}
+
+func TestStore_UnknownTemplate(t *testing.T) {
+	s := newStore(nil, 0)
+
+	_, err := s.Render(context.Background(), "nope", nil)
+	assert.ErrorIs(t, err, domain.ErrUnknownPromptTemplate)
+	assert.ErrorIs(t, s.Validate("nope", "hi"), domain.ErrUnknownPromptTemplate)
+}
+
+func TestStore_ValidateRejectsBadTemplates(t *testing.T) {
+	s := newStore(nil, 0)
+
+	assert.ErrorIs(t, s.Validate(Summarize, "{{.MaxWords"), domain.ErrInvalidPromptTemplate)
+	assert.ErrorIs(t, s.Validate(Summarize, "{{.Title}}"), domain.ErrInvalidPromptTemplate)
+	assert.ErrorIs(t, s.Validate(Summarize, "  "), domain.ErrInvalidPromptTemplate)
+	assert.NoError(t, s.Validate(Summarize, "Summarize in {{.MaxWords}} words: {{.Content}}"))
+}
+
+func TestStore_PicksVersionsByWeight(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	_, _ = repo.CreateVersion(context.Background(), domain.PromptTemplate{Name: Summarize, Body: "v1 {{.Content}}"})
+	_, _ = repo.CreateVersion(context.Background(), domain.PromptTemplate{Name: Summarize, Body: "v2 {{.Content}}"})
+	repo.rollouts[Summarize] = []domain.PromptRollout{{Version: 1, Weight: 1}, {Version: 2, Weight: 3}}
+
+	s := newStore(repo, 0)
+	next := 0
+	s.pick = func(n int) int {
+		assert.Equal(t, 4, n)
+		defer func() { next++ }()
+		return next % n
+	}
+
+	var versions []int
+	for i := 0; i < 4; i++ {
+		prompt, err := s.Render(context.Background(), Summarize, map[string]interface{}{"Content": "text"})
+		require.NoError(t, err)
+		versions = append(versions, prompt.Version)
+	}
+	assert.Equal(t, []int{1, 2, 2, 2}, versions)
+}
+
+func TestStore_FallsBackToDefaultWhenVersionIsBroken(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	_, _ = repo.CreateVersion(context.Background(), domain.PromptTemplate{Name: Summarize, Body: "{{.Missing}}"})
+	repo.rollouts[Summarize] = []domain.PromptRollout{{Version: 1, Weight: 1}, {Version: 7, Weight: 1}}
+
+	s := newStore(repo, 0)
+	for _, pick := range []int{0, 1} {
+		s.pick = func(n int) int { return pick }
+		prompt, err := s.Render(context.Background(), Summarize, map[string]interface{}{"MaxWords": 10, "Content": "text"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, prompt.Version)
+		assert.Contains(t, prompt.Text, "at most 10 words")
+	}
+}
+
+func TestStore_CachesRolloutUntilInvalidated(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	s := newStore(repo, time.Minute)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	data := map[string]interface{}{"Focus": "clarity", "Content": "text"}
+
+	_, _ = s.Render(context.Background(), ImproveContent, data)
+	_, _ = s.Render(context.Background(), ImproveContent, data)
+	assert.Equal(t, 1, repo.rolloutReads)
+
+	s.Invalidate(ImproveContent)
+	_, _ = s.Render(context.Background(), ImproveContent, data)
+	assert.Equal(t, 2, repo.rolloutReads)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = s.Render(context.Background(), ImproveContent, data)
+	assert.Equal(t, 3, repo.rolloutReads)
+}
+
+func TestStore_RolloutErrorUsesDefault(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	repo.rolloutErr = errors.New("connection refused")
+	s := newStore(repo, 0)
+
+	prompt, err := s.Render(context.Background(), Chat, map[string]interface{}{"Messages": "[]"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, prompt.Version)
+}
+
+func TestStore_RendersPinnedVersion(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	_, _ = repo.CreateVersion(context.Background(), domain.PromptTemplate{Name: Summarize, Body: "v1 {{.Content}}"})
+	_, _ = repo.CreateVersion(context.Background(), domain.PromptTemplate{Name: Summarize, Body: "v2 {{.Content}}"})
+	repo.rollouts[Summarize] = []domain.PromptRollout{{Version: 2, Weight: 1}}
+	s := newStore(repo, 0)
+
+	version, err := s.PickVersion(context.Background(), Summarize)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	ctx := domain.WithPromptVersion(context.Background(), Summarize, 1)
+	prompt, err := s.Render(ctx, Summarize, map[string]interface{}{"Content": "text"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, prompt.Version)
+	assert.Equal(t, "v1 text", prompt.Text)
+
+	_, err = s.PickVersion(context.Background(), "nope")
+	assert.ErrorIs(t, err, domain.ErrUnknownPromptTemplate)
+}
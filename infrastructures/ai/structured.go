@@ -0,0 +1,247 @@
+package infrastructures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// maxRepairAttempts is how many times a malformed reply is sent back to the
+// model with a repair prompt before giving up.
+const maxRepairAttempts = 1
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindStringList
+	kindObject
+)
+
+func (k fieldKind) String() string {
+	switch k {
+	case kindStringList:
+		return "array of strings"
+	case kindObject:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// schemaField describes one field of a structured AI response. Nested fields
+// use a dotted path, e.g. "message.content".
+type schemaField struct {
+	path     string
+	kind     fieldKind
+	nonEmpty bool
+}
+
+// responseSchema is the shape an operation expects back from the model.
+// example is shown to the model when it has to repair its reply.
+type responseSchema struct {
+	name    string
+	example string
+	fields  []schemaField
+}
+
+var (
+	suggestTagsSchema = responseSchema{
+		name:    "suggest_tags",
+		example: `{"tags": ["tag1", "tag2", "tag3"]}`,
+		fields:  []schemaField{{path: "tags", kind: kindStringList}},
+	}
+	summarizeSchema = responseSchema{
+		name:    "summarize",
+		example: `{"summary": "your summary here"}`,
+		fields:  []schemaField{{path: "summary", kind: kindString, nonEmpty: true}},
+	}
+	generateTitleSchema = responseSchema{
+		name:    "generate_title",
+		example: `{"title": "..."}`,
+		fields:  []schemaField{{path: "title", kind: kindString, nonEmpty: true}},
+	}
+	improveContentSchema = responseSchema{
+		name:    "improve_content",
+		example: `{"improved_content": "...", "suggestions": ["...", "..."]}`,
+		fields: []schemaField{
+			{path: "improved_content", kind: kindString, nonEmpty: true},
+			{path: "suggestions", kind: kindStringList},
+		},
+	}
+	chatSchema = responseSchema{
+		name:    "chat",
+		example: `{"message": {"role": "assistant", "content": "..."}}`,
+		fields: []schemaField{
+			{path: "message", kind: kindObject},
+			{path: "message.content", kind: kindString, nonEmpty: true},
+		},
+	}
+)
+
+// generateStructured sends the prompt, extracts and validates the JSON object in
+// the reply and decodes it into out. A reply that does not match the schema is
+// retried with a repair prompt; if it still does not match,
+// domain.ErrAIMalformedResponse is returned.
+func (s *AIContentService) generateStructured(ctx context.Context, prompt string, schema responseSchema, out interface{}) error {
+	raw, err := s.generateJSON(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	object, verr := parseStructured(raw, schema)
+	for attempt := 0; verr != nil && attempt < maxRepairAttempts; attempt++ {
+		raw, err = s.generateJSON(ctx, repairPrompt(prompt, raw, schema, verr))
+		if err != nil {
+			return err
+		}
+		object, verr = parseStructured(raw, schema)
+	}
+	if verr != nil {
+		return fmt.Errorf("%w: %s: %v", domain.ErrAIMalformedResponse, schema.name, verr)
+	}
+
+	if err := json.Unmarshal([]byte(object), out); err != nil {
+		return fmt.Errorf("%w: %s: %v", domain.ErrAIMalformedResponse, schema.name, err)
+	}
+	return nil
+}
+
+// generateJSON uses the provider's JSON mode when the client supports it.
+func (s *AIContentService) generateJSON(ctx context.Context, prompt string) (string, error) {
+	if jc, ok := s.client.(domain.IAIJSONModelClient); ok {
+		return jc.GenerateJSON(ctx, prompt)
+	}
+	return s.client.Generate(ctx, prompt)
+}
+
+func repairPrompt(prompt, previous string, schema responseSchema, cause error) string {
+	const maxEcho = 2000
+	if len(previous) > maxEcho {
+		previous = previous[:maxEcho]
+	}
+	return fmt.Sprintf(`%s
+
+		Your previous reply could not be used: %v.
+		Previous reply: %q
+
+		Respond again with ONLY a valid JSON object in this exact format:
+		%s
+		Do not include backticks, code fences, or any text outside the JSON.
+	`, prompt, cause, previous, schema.example)
+}
+
+// parseStructured extracts the first JSON object from raw and checks it against
+// the schema, returning the object text.
+func parseStructured(raw string, schema responseSchema) (string, error) {
+	object, err := extractJSONObject(raw)
+	if err != nil {
+		return "", err
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(object), &decoded); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	if err := validateSchema(decoded, schema); err != nil {
+		return "", err
+	}
+	return object, nil
+}
+
+// extractJSONObject returns the first balanced {...} in s, skipping any
+// preamble, code fences or trailing commentary around it.
+func extractJSONObject(s string) (string, error) {
+	start := strings.IndexByte(s, '{')
+	if start < 0 {
+		return "", errors.New("no JSON object found")
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], nil
+			}
+		}
+	}
+	return "", errors.New("unterminated JSON object")
+}
+
+func validateSchema(decoded map[string]interface{}, schema responseSchema) error {
+	for _, field := range schema.fields {
+		value, ok := lookupPath(decoded, field.path)
+		if !ok {
+			return fmt.Errorf("missing field %q", field.path)
+		}
+
+		switch field.kind {
+		case kindString:
+			str, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("field %q must be a %s", field.path, field.kind)
+			}
+			if field.nonEmpty && strings.TrimSpace(str) == "" {
+				return fmt.Errorf("field %q must not be empty", field.path)
+			}
+		case kindStringList:
+			list, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("field %q must be an %s", field.path, field.kind)
+			}
+			for _, item := range list {
+				if _, ok := item.(string); !ok {
+					return fmt.Errorf("field %q must be an %s", field.path, field.kind)
+				}
+			}
+			if field.nonEmpty && len(list) == 0 {
+				return fmt.Errorf("field %q must not be empty", field.path)
+			}
+		case kindObject:
+			if _, ok := value.(map[string]interface{}); !ok {
+				return fmt.Errorf("field %q must be an %s", field.path, field.kind)
+			}
+		}
+	}
+	return nil
+}
+
+func lookupPath(decoded map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = decoded
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[key]
+		if !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,95 @@
+package infrastructures
+
+import (
+	"context"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedClient replies with the queued responses in order and records prompts.
+type scriptedClient struct {
+	replies []string
+	prompts []string
+}
+
+func (c *scriptedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return reply, nil
+}
+
+func (c *scriptedClient) GenerateStream(ctx context.Context, prompt string, onDelta domain.AIStreamHandler) (string, error) {
+	reply, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return reply, onDelta(reply)
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	cases := map[string]string{
+		`{"title": "Plain"}`:                                     `{"title": "Plain"}`,
+		"```json\n{\"title\": \"Fenced\"}\n```":                  `{"title": "Fenced"}`,
+		`Sure! Here you go: {"title": "A {braced} title"} Enjoy`: `{"title": "A {braced} title"}`,
+		`{"title": "Escaped \" quote }"} {"title": "second"}`:    `{"title": "Escaped \" quote }"}`,
+	}
+	for input, want := range cases {
+		got, err := extractJSONObject(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := extractJSONObject("no json here")
+	assert.Error(t, err)
+	_, err = extractJSONObject(`{"title": "cut off`)
+	assert.Error(t, err)
+}
+
+func TestParseStructured_ValidatesSchema(t *testing.T) {
+	_, err := parseStructured(`{"tags": ["go", "web"]}`, suggestTagsSchema)
+	assert.NoError(t, err)
+
+	_, err = parseStructured(`{"tags": "go, web"}`, suggestTagsSchema)
+	assert.ErrorContains(t, err, `field "tags"`)
+
+	_, err = parseStructured(`{"summary": "   "}`, summarizeSchema)
+	assert.ErrorContains(t, err, "must not be empty")
+
+	_, err = parseStructured(`{"message": {"role": "assistant"}}`, chatSchema)
+	assert.ErrorContains(t, err, `missing field "message.content"`)
+}
+
+func TestSuggestTags_StripsFencesAndPreamble(t *testing.T) {
+	client := &scriptedClient{replies: []string{"Here are your tags:\n```json\n{\"tags\": [\"go\", \"testing\"]}\n```"}}
+	svc := NewAIContentService(client, nil)
+
+	tags, err := svc.SuggestTags(context.Background(), "Title", "Content", 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "testing"}, tags)
+	assert.Len(t, client.prompts, 1)
+}
+
+func TestGenerateTitle_RepairsMalformedReply(t *testing.T) {
+	client := &scriptedClient{replies: []string{`{"heading": "Wrong key"}`, `{"title": "Fixed"}`}}
+	svc := NewAIContentService(client, nil)
+
+	title, err := svc.GenerateTitle(context.Background(), "Content", "formal")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Fixed", title)
+	assert.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[1], `missing field "title"`)
+}
+
+func TestSummarize_ReturnsMalformedResponseError(t *testing.T) {
+	client := &scriptedClient{replies: []string{"not json", "still not json"}}
+	svc := NewAIContentService(client, nil)
+
+	_, err := svc.Summarize(context.Background(), "Content", 50)
+
+	assert.ErrorIs(t, err, domain.ErrAIMalformedResponse)
+}
@@ -0,0 +1,86 @@
+package infrastructures
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// BuildAIProviderConfigs returns the ordered AI provider chain: AI_PROVIDER first,
+// followed by AI_FALLBACK_PROVIDERS. The primary provider falls back to the generic
+// AI_API_KEY, AI_MODEL_NAME and AI_API_BASE_URL settings; fallback providers without
+// an API key are skipped.
+func BuildAIProviderConfigs(cfg *Config) []domain.AIProviderConfig {
+	primary := strings.ToLower(strings.TrimSpace(cfg.AIProvider))
+	if primary == "" {
+		primary = "groq"
+	}
+
+	seen := make(map[string]bool)
+	var chain []domain.AIProviderConfig
+
+	for i, name := range append([]string{primary}, cfg.AIFallbackProviders...) {
+		name = strings.ToLower(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		settings := cfg.AIProviderSettings[name]
+		providerCfg := domain.AIProviderConfig{
+			Name:    name,
+			APIKey:  settings.APIKey,
+			BaseURL: settings.APIBaseURL,
+			Model:   settings.ModelName,
+		}
+
+		if i == 0 {
+			providerCfg.APIKey = firstNonEmpty(providerCfg.APIKey, cfg.AIApiKey)
+			providerCfg.BaseURL = firstNonEmpty(providerCfg.BaseURL, cfg.AIApiBaseUrl)
+			providerCfg.Model = firstNonEmpty(providerCfg.Model, cfg.AIModelName)
+		} else if providerCfg.APIKey == "" {
+			slog.Warn("skipping AI fallback provider: no API key configured", "provider", name)
+			continue
+		}
+
+		chain = append(chain, providerCfg)
+	}
+
+	return chain
+}
+
+// BuildAIRoleQuotas returns the default AI quota of each role.
+func BuildAIRoleQuotas(cfg *Config) map[domain.Role]domain.AIQuota {
+	quotas := make(map[domain.Role]domain.AIQuota, len(cfg.AIRoleQuotas))
+	for role, q := range cfg.AIRoleQuotas {
+		quotas[domain.Role(role)] = domain.AIQuota{
+			Daily_requests:   q.DailyRequests,
+			Monthly_requests: q.MonthlyRequests,
+			Daily_tokens:     q.DailyTokens,
+			Monthly_tokens:   q.MonthlyTokens,
+		}
+	}
+	return quotas
+}
+
+// BuildAIPrices returns the configured token prices keyed by provider name.
+func BuildAIPrices(cfg *Config) map[string]domain.AIPrice {
+	prices := make(map[string]domain.AIPrice, len(cfg.AIProviderSettings))
+	for name, settings := range cfg.AIProviderSettings {
+		prices[name] = domain.AIPrice{
+			Prompt_per_1k:     settings.PromptPricePer1K,
+			Completion_per_1k: settings.CompletionPricePer1K,
+		}
+	}
+	return prices
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
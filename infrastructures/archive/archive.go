@@ -0,0 +1,310 @@
+// Package archive reads and writes exported content as one JSON document:
+//
+//	{"format": "inkforge-content", "version": 1, "exported_at": "...",
+//	 "tags": [...], "blogs": [...], "comments": [...]}
+//
+// Both directions stream, so an export never has to fit in memory.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	Format  = "inkforge-content"
+	Version = 1
+)
+
+var ErrInvalidArchive = errors.New("invalid content archive")
+
+// sections in the order they are written
+var sections = []string{"tags", "blogs", "comments"}
+
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Blog struct {
+	ID      string   `json:"id"`
+	UserID  string   `json:"user_id"`
+	Title   string   `json:"title"`
+	Images  []string `json:"images,omitempty"`
+	Content string   `json:"content"`
+	TagIDs  []string `json:"tag_ids,omitempty"`
+
+	CommentCount int `json:"comment_count"`
+	LikeCount    int `json:"like_count"`
+	DislikeCount int `json:"dislike_count"`
+	ViewCount    int `json:"view_count"`
+
+	AutoEnrich       bool      `json:"auto_enrich,omitempty"`
+	Summary          string    `json:"summary,omitempty"`
+	SuggestedTags    []string  `json:"suggested_tags,omitempty"`
+	EnrichmentStatus string    `json:"enrichment_status,omitempty"`
+	EnrichedVersion  time.Time `json:"enriched_version,omitzero"`
+
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	ContentUpdatedAt time.Time `json:"content_updated_at,omitzero"`
+}
+
+type Comment struct {
+	ID        string    `json:"id"`
+	BlogID    string    `json:"blog_id"`
+	UserID    string    `json:"user_id"`
+	Content   string    `json:"content"`
+	Like      int       `json:"like"`
+	Dislike   int       `json:"dislike"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Writer writes an archive. It implements domain.IContentSink; content must
+// come in section order, tags first and comments last. Close finishes the
+// document.
+type Writer struct {
+	w       io.Writer
+	section int // index in sections, -1 before the first
+	items   int // written in the current section
+	err     error
+}
+
+// NewWriter starts an archive exported at exportedAt on w.
+func NewWriter(w io.Writer, exportedAt time.Time) (*Writer, error) {
+	header, err := json.Marshal(exportedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(w, "{\n\"format\": %q,\n\"version\": %d,\n\"exported_at\": %s", Format, Version, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, section: -1}, nil
+}
+
+func (w *Writer) Tag(tag domain.Tag) error {
+	return w.write(0, Tag{ID: tag.Tag_id, Name: tag.TagName})
+}
+
+func (w *Writer) Blog(blog domain.Blog) error {
+	return w.write(1, Blog{
+		ID:               blog.Blog_id,
+		UserID:           blog.User_id,
+		Title:            blog.Title,
+		Images:           blog.Images,
+		Content:          blog.Content,
+		TagIDs:           blog.Tag_ids,
+		CommentCount:     blog.Comment_count,
+		LikeCount:        blog.Like_count,
+		DislikeCount:     blog.Dislike_count,
+		ViewCount:        blog.View_count,
+		AutoEnrich:       blog.Auto_enrich,
+		Summary:          blog.Summary,
+		SuggestedTags:    blog.Suggested_tags,
+		EnrichmentStatus: blog.Enrichment_status,
+		EnrichedVersion:  blog.Enriched_version,
+		CreatedAt:        blog.Created_at,
+		UpdatedAt:        blog.Updated_at,
+		ContentUpdatedAt: blog.Content_updated_at,
+	})
+}
+
+func (w *Writer) Comment(comment domain.Comment) error {
+	return w.write(2, Comment{
+		ID:        comment.Comment_id,
+		BlogID:    comment.Blog_id,
+		UserID:    comment.User_id,
+		Content:   comment.Content,
+		Like:      comment.Like,
+		Dislike:   comment.Dislike,
+		CreatedAt: comment.Created_at,
+		UpdatedAt: comment.Updated_at,
+	})
+}
+
+// Close writes the sections not written yet, empty, and ends the document.
+func (w *Writer) Close() error {
+	if err := w.enter(len(sections) - 1); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n]\n}\n")
+	return err
+}
+
+func (w *Writer) write(section int, item interface{}) error {
+	if err := w.enter(section); err != nil {
+		return err
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if w.items == 0 {
+		sep = "\n"
+	}
+	if _, err := fmt.Fprintf(w.w, "%s%s", sep, data); err != nil {
+		w.err = err
+		return err
+	}
+	w.items++
+	return nil
+}
+
+// enter closes the current section and opens the ones up to section.
+func (w *Writer) enter(section int) error {
+	if w.err != nil {
+		return w.err
+	}
+	if section < w.section {
+		return fmt.Errorf("archive: %s written after %s", sections[section], sections[w.section])
+	}
+	for w.section < section {
+		end := ",\n"
+		if w.section >= 0 {
+			end = "\n],\n"
+		}
+		w.section++
+		w.items = 0
+		if _, err := fmt.Fprintf(w.w, "%s%q: [", end, sections[w.section]); err != nil {
+			w.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// Reader reads an archive.
+type Reader struct {
+	dec *json.Decoder
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{dec: json.NewDecoder(r)}
+}
+
+// Read hands everything in the archive to sink, in the order it is stored. It
+// stops at the first error, returning ErrInvalidArchive for malformed input.
+func (r *Reader) Read(sink domain.IContentSink) error {
+	if err := r.expectDelim('{'); err != nil {
+		return err
+	}
+	for r.dec.More() {
+		key, err := r.dec.Token()
+		if err != nil {
+			return invalid(err)
+		}
+		switch key {
+		case "format":
+			var format string
+			if err := r.dec.Decode(&format); err != nil || format != Format {
+				return fmt.Errorf("%w: format %q is not %q", ErrInvalidArchive, format, Format)
+			}
+		case "version":
+			var version int
+			if err := r.dec.Decode(&version); err != nil || version != Version {
+				return fmt.Errorf("%w: version %d is not supported", ErrInvalidArchive, version)
+			}
+		case "tags":
+			err = readArray(r, func(tag Tag) error {
+				return sink.Tag(domain.Tag{Tag_id: tag.ID, TagName: tag.Name})
+			})
+		case "blogs":
+			err = readArray(r, func(blog Blog) error {
+				return sink.Blog(blog.toDomain())
+			})
+		case "comments":
+			err = readArray(r, func(comment Comment) error {
+				return sink.Comment(comment.toDomain())
+			})
+		default:
+			// exported_at, and fields added by later versions
+			var skip json.RawMessage
+			err = r.dec.Decode(&skip)
+		}
+		if err != nil {
+			return invalid(err)
+		}
+	}
+	return r.expectDelim('}')
+}
+
+func readArray[T any](r *Reader, fn func(T) error) error {
+	if err := r.expectDelim('['); err != nil {
+		return err
+	}
+	for r.dec.More() {
+		var item T
+		if err := r.dec.Decode(&item); err != nil {
+			return invalid(err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return r.expectDelim(']')
+}
+
+func (r *Reader) expectDelim(want json.Delim) error {
+	token, err := r.dec.Token()
+	if err != nil {
+		return invalid(err)
+	}
+	if token != want {
+		return fmt.Errorf("%w: expected %q, found %v", ErrInvalidArchive, want, token)
+	}
+	return nil
+}
+
+// invalid wraps a decoding error, leaving the sink's own errors as they are.
+func invalid(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	return err
+}
+
+func (b Blog) toDomain() domain.Blog {
+	return domain.Blog{
+		Blog_id:            b.ID,
+		User_id:            b.UserID,
+		Title:              b.Title,
+		Images:             b.Images,
+		Content:            b.Content,
+		Tag_ids:            b.TagIDs,
+		Comment_count:      b.CommentCount,
+		Like_count:         b.LikeCount,
+		Dislike_count:      b.DislikeCount,
+		View_count:         b.ViewCount,
+		Auto_enrich:        b.AutoEnrich,
+		Summary:            b.Summary,
+		Suggested_tags:     b.SuggestedTags,
+		Enrichment_status:  b.EnrichmentStatus,
+		Enriched_version:   b.EnrichedVersion,
+		Created_at:         b.CreatedAt,
+		Updated_at:         b.UpdatedAt,
+		Content_updated_at: b.ContentUpdatedAt,
+	}
+}
+
+func (c Comment) toDomain() domain.Comment {
+	return domain.Comment{
+		Comment_id: c.ID,
+		Blog_id:    c.BlogID,
+		User_id:    c.UserID,
+		Content:    c.Content,
+		Like:       c.Like,
+		Dislike:    c.Dislike,
+		Created_at: c.CreatedAt,
+		Updated_at: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,121 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collector struct {
+	tags     []domain.Tag
+	blogs    []domain.Blog
+	comments []domain.Comment
+	err      error
+}
+
+func (c *collector) Tag(tag domain.Tag) error {
+	c.tags = append(c.tags, tag)
+	return c.err
+}
+
+func (c *collector) Blog(blog domain.Blog) error {
+	c.blogs = append(c.blogs, blog)
+	return c.err
+}
+
+func (c *collector) Comment(comment domain.Comment) error {
+	c.comments = append(c.comments, comment)
+	return c.err
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	blog := domain.Blog{
+		Blog_id:       "65f000000000000000000001",
+		User_id:       "65f0000000000000000000aa",
+		Title:         "Hello",
+		Content:       "World",
+		Tag_ids:       []string{"65f000000000000000000010"},
+		Comment_count: 1,
+		Like_count:    3,
+		Created_at:    created,
+		Updated_at:    created,
+	}
+	comment := domain.Comment{
+		Comment_id: "65f000000000000000000100",
+		Blog_id:    blog.Blog_id,
+		User_id:    "65f0000000000000000000bb",
+		Content:    "Nice",
+		Like:       1,
+		Created_at: created,
+		Updated_at: created,
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, created)
+	require.NoError(t, err)
+	require.NoError(t, w.Tag(domain.Tag{Tag_id: "65f000000000000000000010", TagName: "go"}))
+	require.NoError(t, w.Tag(domain.Tag{Tag_id: "65f000000000000000000011", TagName: "mongo"}))
+	require.NoError(t, w.Blog(blog))
+	require.NoError(t, w.Comment(comment))
+	require.NoError(t, w.Close())
+
+	assert.True(t, json.Valid(buf.Bytes()), buf.String())
+
+	got := &collector{}
+	require.NoError(t, NewReader(&buf).Read(got))
+	assert.Equal(t, []domain.Tag{{Tag_id: "65f000000000000000000010", TagName: "go"}, {Tag_id: "65f000000000000000000011", TagName: "mongo"}}, got.tags)
+	assert.Equal(t, []domain.Blog{blog}, got.blogs)
+	assert.Equal(t, []domain.Comment{comment}, got.comments)
+}
+
+func TestWriter_EmptyArchiveIsValid(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, Format, doc["format"])
+	assert.Equal(t, []interface{}{}, doc["comments"])
+}
+
+func TestWriter_RejectsSectionsOutOfOrder(t *testing.T) {
+	w, err := NewWriter(&bytes.Buffer{}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, w.Blog(domain.Blog{Blog_id: "1"}))
+	assert.Error(t, w.Tag(domain.Tag{Tag_id: "2"}))
+}
+
+func TestReader_RejectsOtherDocuments(t *testing.T) {
+	for name, input := range map[string]string{
+		"wrong format":  `{"format": "something-else", "version": 1}`,
+		"newer version": `{"format": "inkforge-content", "version": 2}`,
+		"truncated":     `{"format": "inkforge-content", "version": 1, "blogs": [{"id": "1"}`,
+		"not an object": `[1, 2]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := NewReader(strings.NewReader(input)).Read(&collector{})
+			assert.ErrorIs(t, err, ErrInvalidArchive)
+		})
+	}
+}
+
+func TestReader_StopsAtSinkError(t *testing.T) {
+	failure := errors.New("upsert failed")
+	input := `{"format": "inkforge-content", "version": 1, "tags": [{"id": "1", "name": "a"}, {"id": "2", "name": "b"}]}`
+
+	got := &collector{err: failure}
+	err := NewReader(strings.NewReader(input)).Read(got)
+	assert.ErrorIs(t, err, failure)
+	assert.NotErrorIs(t, err, ErrInvalidArchive)
+	assert.Len(t, got.tags, 1)
+}
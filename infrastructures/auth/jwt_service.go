@@ -125,6 +125,10 @@ func (j *JWTService) ValidateVerificationToken(tokenString string) (string, erro
 	if err != nil {
 		return "", err
 	}
+	// tokens issued for another purpose must not verify an email
+	if _, ok := claims["purpose"]; ok {
+		return "", errors.New("invalid token purpose")
+	}
 	sub, ok := claims["sub"].(string)
 	if !ok {
 		return "", errors.New("invalid subject in token")
@@ -159,6 +163,33 @@ func (j *JWTService) ValidatePasswordResetToken(tokenString string) (string, err
 }
 
 
+// GenerateScopedToken signs a token that ValidateScopedToken only accepts for
+// the same purpose. It carries no role, so it is never an access token.
+func (j *JWTService) GenerateScopedToken(subject, purpose string, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":     subject,
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+		"purpose": purpose,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(j.accessSecret)
+}
+
+func (j *JWTService) ValidateScopedToken(tokenString, purpose string) (string, error) {
+	claims, err := j.parseToken(tokenString, j.accessSecret)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := claims["purpose"].(string); !ok || p != purpose {
+		return "", errors.New("invalid token purpose")
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok || sub == "" {
+		return "", errors.New("invalid subject in token")
+	}
+	return sub, nil
+}
 
 // helper to extract exp claim as int64
 func extractExp(claims jwt.MapClaims) (int64, error) {
@@ -0,0 +1,50 @@
+package infrastructures
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedToken_OnlyValidForItsPurpose(t *testing.T) {
+	j := NewJWTService("access-secret", "refresh-secret", nil)
+
+	token, err := j.GenerateScopedToken("user-1", "digest_unsubscribe", time.Hour)
+	require.NoError(t, err)
+
+	subject, err := j.ValidateScopedToken(token, "digest_unsubscribe")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+
+	_, err = j.ValidateScopedToken(token, "newsletter_confirm")
+	assert.Error(t, err)
+	_, err = j.ValidatePasswordResetToken(token)
+	assert.Error(t, err)
+	_, err = j.ValidateVerificationToken(token)
+	assert.Error(t, err)
+	_, _, err = j.ValidateAccessToken(token)
+	assert.Error(t, err)
+}
+
+func TestScopedToken_Expires(t *testing.T) {
+	j := NewJWTService("access-secret", "refresh-secret", nil)
+
+	token, err := j.GenerateScopedToken("user-1", "digest_unsubscribe", -time.Minute)
+	require.NoError(t, err)
+
+	_, err = j.ValidateScopedToken(token, "digest_unsubscribe")
+	assert.Error(t, err)
+}
+
+func TestVerificationToken_StillAccepted(t *testing.T) {
+	j := NewJWTService("access-secret", "refresh-secret", nil)
+
+	token, err := j.GenerateVerificationToken("user-1")
+	require.NoError(t, err)
+
+	subject, err := j.ValidateVerificationToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+}
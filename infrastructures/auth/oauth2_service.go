@@ -3,7 +3,9 @@ package infrastructures
 // imports
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/InkForge/Blog_Website/domain"
 	"github.com/InkForge/Blog_Website/infrastructures/auth/providers"
@@ -13,6 +15,12 @@ type oauth2Service struct {
 	providers map[string]domain.IOAuth2Provider
 }
 
+// readyProvider is a provider that must reach its issuer before it can be
+// used, such as an OIDC provider discovered on first use
+type readyProvider interface {
+	Ready(ctx context.Context) error
+}
+
 // creates a new OAuth2 service with the given providers
 func NewOAuth2Service(providerConfigs map[string]domain.OAuth2ProviderConfig) (domain.IOAuth2Service, error) {
 	service := &oauth2Service{
@@ -31,6 +39,16 @@ func NewOAuth2Service(providerConfigs map[string]domain.OAuth2ProviderConfig) (d
 		case "facebook":
 			provider = providers.NewFacebookProvider(config)
 		default:
+			if config.Issuer != "" {
+				// discovery waits for the first login, so an issuer that is down
+				// only degrades the readiness probe
+				oidc, err := providers.NewOIDCProvider(name, config, nil)
+				if err != nil {
+					return nil, err
+				}
+				provider = oidc
+				break
+			}
 			return nil, fmt.Errorf("unsupported OAuth2 provider: %s", name)
 		}
 		
@@ -49,20 +67,40 @@ func (o2serv *oauth2Service) SupportedProviders() []string {
 	return providers
 }
 
-func (o2serv *oauth2Service) GetAuthorizationURL(provider string, state string) (string, error) {
+func (o2serv *oauth2Service) GetAuthorizationURL(ctx context.Context, provider string, state string, codeVerifier string) (string, error) {
 	
 	p, ok := o2serv.providers[provider]
 	if !ok {
 		return "", fmt.Errorf("provider %s is not supported", provider)
 	}
-	return p.GetAuthorizationURL(state), nil
+	if rp, ok := p.(readyProvider); ok {
+		if err := rp.Ready(ctx); err != nil {
+			return "", fmt.Errorf("%w: %v", domain.ErrOAuthProviderUnavailable, err)
+		}
+	}
+	return p.GetAuthorizationURL(state, codeVerifier), nil
 }
 
-func (o2serv *oauth2Service) Authenticate(ctx context.Context, provider string, code string) (*domain.User, error) {
+func (o2serv *oauth2Service) Authenticate(ctx context.Context, provider string, code string, codeVerifier string) (*domain.User, error) {
 	
 	p, ok := o2serv.providers[provider]
 	if !ok {
 		return nil, fmt.Errorf("provider %s is not supported", provider)
 	}
-	return p.Authenticate(ctx, code)
-}
\ No newline at end of file
+	return p.Authenticate(ctx, code, codeVerifier)
+}
+
+func (o2serv *oauth2Service) Ready(ctx context.Context) error {
+	names := o2serv.SupportedProviders()
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if rp, ok := o2serv.providers[name].(readyProvider); ok {
+			if err := rp.Ready(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
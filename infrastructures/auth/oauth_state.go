@@ -0,0 +1,172 @@
+package infrastructures
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const defaultOAuthStateTTL = 10 * time.Minute
+
+type oauthStateStore struct {
+	secret         []byte
+	ttl            time.Duration
+	allowedOrigins map[string]bool
+	now            func() time.Time
+}
+
+// cookie payload, signed as a whole
+type oauthStatePayload struct {
+	State     string `json:"s"`
+	Provider  string `json:"p"`
+	Verifier  string `json:"v"`
+	ReturnTo  string `json:"r,omitempty"`
+	LinkUser  string `json:"l,omitempty"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// NewOAuthStateStore returns a state store signing its cookies with secret.
+// return_to may be a path on this site or a URL on one of allowedOrigins.
+func NewOAuthStateStore(secret string, ttl time.Duration, allowedOrigins []string) domain.IOAuthStateStore {
+	if ttl <= 0 {
+		ttl = defaultOAuthStateTTL
+	}
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		// a wildcard CORS origin must not turn into an open redirect
+		if origin != "" && origin != "*" {
+			origins[strings.ToLower(origin)] = true
+		}
+	}
+	return &oauthStateStore{
+		secret:         []byte(secret),
+		ttl:            ttl,
+		allowedOrigins: origins,
+		now:            time.Now,
+	}
+}
+
+func (s *oauthStateStore) TTL() time.Duration {
+	return s.ttl
+}
+
+func (s *oauthStateStore) Issue(provider, returnTo, linkUserID string) (domain.OAuthState, string, error) {
+	if returnTo != "" && !s.allowedReturnTo(returnTo) {
+		return domain.OAuthState{}, "", domain.ErrInvalidReturnTo
+	}
+
+	state, err := randomToken(24)
+	if err != nil {
+		return domain.OAuthState{}, "", err
+	}
+	// RFC 7636 asks for 43 to 128 characters; 32 random bytes encode to 43
+	verifier, err := randomToken(32)
+	if err != nil {
+		return domain.OAuthState{}, "", err
+	}
+	expiresAt := s.now().Add(s.ttl)
+
+	payload, err := json.Marshal(oauthStatePayload{
+		State:     state,
+		Provider:  provider,
+		Verifier:  verifier,
+		ReturnTo:  returnTo,
+		LinkUser:  linkUserID,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return domain.OAuthState{}, "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	cookie := encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+
+	return domain.OAuthState{
+		State:         state,
+		Provider:      provider,
+		Code_verifier: verifier,
+		Return_to:     returnTo,
+		Link_user_id:  linkUserID,
+		Expires_at:    expiresAt,
+	}, cookie, nil
+}
+
+func (s *oauthStateStore) Verify(cookie, provider, state string) (domain.OAuthState, error) {
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok || state == "" {
+		return domain.OAuthState{}, domain.ErrInvalidOAuthState
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return domain.OAuthState{}, domain.ErrInvalidOAuthState
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return domain.OAuthState{}, domain.ErrInvalidOAuthState
+	}
+	var payload oauthStatePayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return domain.OAuthState{}, domain.ErrInvalidOAuthState
+	}
+
+	expiresAt := time.Unix(payload.ExpiresAt, 0)
+	if !s.now().Before(expiresAt) ||
+		payload.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(payload.State), []byte(state)) != 1 {
+		return domain.OAuthState{}, domain.ErrInvalidOAuthState
+	}
+
+	return domain.OAuthState{
+		State:         payload.State,
+		Provider:      payload.Provider,
+		Code_verifier: payload.Verifier,
+		Return_to:     payload.ReturnTo,
+		Link_user_id:  payload.LinkUser,
+		Expires_at:    expiresAt,
+	}, nil
+}
+
+// allowedReturnTo accepts a path on this site or an http(s) URL on an allowed origin.
+func (s *oauthStateStore) allowedReturnTo(returnTo string) bool {
+	// browsers drop tabs and newlines from a Location, so "/\t/host" becomes "//host"
+	if strings.IndexFunc(returnTo, func(r rune) bool { return unicode.IsControl(r) || unicode.IsSpace(r) }) >= 0 {
+		return false
+	}
+	u, err := url.Parse(returnTo)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(returnTo, "/") {
+		// browsers treat "//host" and "/\host" as another site, escaped or not
+		return u.Scheme == "" && u.Host == "" &&
+			!strings.HasPrefix(returnTo, "//") && !strings.HasPrefix(returnTo, "/\\") &&
+			!strings.HasPrefix(u.Path, "//") && !strings.HasPrefix(u.Path, "/\\")
+	}
+	if u.Host == "" || u.User != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return s.allowedOrigins[strings.ToLower(u.Scheme+"://"+u.Host)]
+}
+
+func (s *oauthStateStore) sign(value string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,123 @@
+package infrastructures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStateStore() *oauthStateStore {
+	return NewOAuthStateStore("secret", time.Minute, []string{"https://app.example.com/", "*"}).(*oauthStateStore)
+}
+
+func TestOAuthState_RoundTrip(t *testing.T) {
+	s := newTestStateStore()
+
+	issued, cookie, err := s.Issue("google", "/blogs/42", "")
+	require.NoError(t, err)
+	assert.Len(t, issued.Code_verifier, 43)
+
+	got, err := s.Verify(cookie, "google", issued.State)
+	require.NoError(t, err)
+	assert.Equal(t, issued.Code_verifier, got.Code_verifier)
+	assert.Equal(t, "/blogs/42", got.Return_to)
+}
+
+func TestOAuthState_CarriesLinkingUser(t *testing.T) {
+	s := newTestStateStore()
+
+	issued, cookie, err := s.Issue("github", "", "user-1")
+	require.NoError(t, err)
+
+	got, err := s.Verify(cookie, "github", issued.State)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", got.Link_user_id)
+}
+
+func TestOAuthState_RejectsMismatches(t *testing.T) {
+	s := newTestStateStore()
+	issued, cookie, err := s.Issue("google", "", "")
+	require.NoError(t, err)
+
+	_, err = s.Verify(cookie, "github", issued.State)
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+
+	_, err = s.Verify(cookie, "google", "another-state")
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+
+	_, err = s.Verify("", "google", issued.State)
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+
+	other := NewOAuthStateStore("other-secret", time.Minute, nil)
+	_, err = other.Verify(cookie, "google", issued.State)
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+}
+
+func TestOAuthState_RejectsTamperedCookie(t *testing.T) {
+	s := newTestStateStore()
+	issued, cookie, err := s.Issue("google", "", "")
+	require.NoError(t, err)
+
+	payload, signature, _ := strings.Cut(cookie, ".")
+	tampered := payload[:len(payload)-2] + "AA." + signature
+
+	_, err = s.Verify(tampered, "google", issued.State)
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+}
+
+func TestOAuthState_Expires(t *testing.T) {
+	s := newTestStateStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	issued, cookie, err := s.Issue("google", "", "")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = s.Verify(cookie, "google", issued.State)
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthState)
+}
+
+func TestOAuthState_ValidatesReturnTo(t *testing.T) {
+	s := newTestStateStore()
+
+	tests := []struct {
+		name     string
+		returnTo string
+		allowed  bool
+	}{
+		{"root", "/", true},
+		{"path with query", "/blogs?page=2", true},
+		{"allowed origin", "https://app.example.com/dashboard", true},
+		{"allowed origin in capitals", "https://APP.example.com", true},
+		{"protocol-relative", "//evil.example.com", false},
+		{"backslash after slash", "/\\evil.example.com", false},
+		{"tab after slash", "/\t/evil.example.com", false},
+		{"newline after slash", "/\n/evil.example.com", false},
+		{"space in path", "/blogs /42", false},
+		{"escaped slash", "/%2F/evil.example.com", false},
+		{"escaped backslash", "/%5C/evil.example.com", false},
+		{"backslash then slash", "\\/evil.example.com", false},
+		{"https without host", "https:evil.example.com", false},
+		{"https with one slash", "https:/evil.example.com", false},
+		{"other origin", "https://evil.example.com/", false},
+		{"origin as a prefix", "https://app.example.com.evil.com/", false},
+		{"userinfo", "https://user@app.example.com/", false},
+		{"plain http", "http://app.example.com/", false},
+		{"javascript", "javascript:alert(1)", false},
+		{"relative path", "blogs/42", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.Issue("google", tt.returnTo, "")
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrInvalidReturnTo)
+			}
+		})
+	}
+}
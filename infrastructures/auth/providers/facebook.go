@@ -38,13 +38,13 @@ func (fbprov *facebookProvider) Name() string {
 	return "facebook"
 }
 
-func (fbprov *facebookProvider) GetAuthorizationURL(state string) string {
-	return fbprov.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (fbprov *facebookProvider) GetAuthorizationURL(state string, codeVerifier string) string {
+	return fbprov.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(codeVerifier))
 }
 
-func (fbprov *facebookProvider) Authenticate(ctx context.Context, code string) (*domain.User, error) {
+func (fbprov *facebookProvider) Authenticate(ctx context.Context, code string, codeVerifier string) (*domain.User, error) {
 	
-	token, err := fbprov.config.Exchange(ctx, code)
+	token, err := fbprov.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		return nil, fmt.Errorf("facebook: code exchange failed: %w", err)
 	}
@@ -94,7 +94,7 @@ func (fbprov *facebookProvider) Authenticate(ctx context.Context, code string) (
 	return &domain.User{
 		UserID:               userInfo.ID,
 		Email:                userInfo.Email,
-		IsVerified:           true,        // facebook doesn't provide this info
+		IsVerified:           false,       // facebook doesn't say whether the email is verified
 		Name:                 userInfo.Name,
 		FirstName:            userInfo.FirstName,
 		LastName:      		  userInfo.LastName,
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/InkForge/Blog_Website/domain"
 	"golang.org/x/oauth2"
@@ -39,13 +40,13 @@ func (ghprov *githubProvider) Name() string {
 	return "github"
 }
 
-func (ghprov *githubProvider) GetAuthorizationURL(state string) string {
-	return ghprov.config.AuthCodeURL(state)
+func (ghprov *githubProvider) GetAuthorizationURL(state string, codeVerifier string) string {
+	return ghprov.config.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier))
 }
 
-func (ghprov *githubProvider) Authenticate(ctx context.Context, code string) (*domain.User, error) {
+func (ghprov *githubProvider) Authenticate(ctx context.Context, code string, codeVerifier string) (*domain.User, error) {
 	
-	token, err := ghprov.config.Exchange(ctx, code)
+	token, err := ghprov.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		return nil, fmt.Errorf("github: code exchange failed: %w", err)
 	}
@@ -78,34 +79,20 @@ func (ghprov *githubProvider) Authenticate(ctx context.Context, code string) (*d
 		return nil, fmt.Errorf("github: failed parsing profile: %w", err)
 	}
 
-	// get primary email if not returned in profile
-	if profile.Email == "" {
-		emailsResp, err := client.Get("https://api.github.com/user/emails")
-		if err != nil {
-			return nil, fmt.Errorf("github: failed getting user emails: %w", err)
-		}
-		defer emailsResp.Body.Close()
-
-		emailsData, err := io.ReadAll(emailsResp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("github: failed reading emails response: %w", err)
-		}
-
-		var emails []struct {
-			Email    string `json:"email"`
-			Primary  bool   `json:"primary"`
-			Verified bool   `json:"verified"`
-		}
-
-		if err := json.Unmarshal(emailsData, &emails); err != nil {
-			return nil, fmt.Errorf("github: failed parsing emails: %w", err)
+	// the profile only has the public email, and says nothing about whether it
+	// is verified; the emails endpoint does
+	emails, err := githubEmails(client)
+	if err != nil && profile.Email == "" {
+		return nil, err
+	}
+	verified := false
+	for _, email := range emails {
+		if profile.Email == "" && email.Primary {
+			profile.Email = email.Email
 		}
-
-		for _, email := range emails {
-			if email.Primary {
-				profile.Email = email.Email
-				break
-			}
+		if email.Email == profile.Email {
+			verified = email.Verified
+			break
 		}
 	}
 
@@ -118,7 +105,7 @@ func (ghprov *githubProvider) Authenticate(ctx context.Context, code string) (*d
 	return &domain.User{
 		UserID:               fmt.Sprintf("%d", profile.ID),
 		Email:                profile.Email,
-		IsVerified:           true,      // GitHub doesn't provide this info
+		IsVerified:           verified,
 		Name:                 profile.Name,
 		FirstName:            profile.FirstName,      // GitHub doesn't provide first name separately
 		LastName:      		  profile.LastName,		  // GitHub doesn't provide last name separately
@@ -126,4 +113,28 @@ func (ghprov *githubProvider) Authenticate(ctx context.Context, code string) (*d
 		Provider:             ghprov.Name(),
 		RawData:              rawData,
 	}, nil
-}
\ No newline at end of file
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// githubEmails lists the user's email addresses, which needs the user:email scope.
+func githubEmails(client *http.Client) ([]githubEmail, error) {
+	resp, err := client.Get("https://api.github.com/user/emails")
+	if err != nil {
+		return nil, fmt.Errorf("github: failed getting user emails: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: failed getting user emails: status %d", resp.StatusCode)
+	}
+
+	var emails []githubEmail
+	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
+		return nil, fmt.Errorf("github: failed parsing emails: %w", err)
+	}
+	return emails, nil
+}
@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// redirectTransport sends every request to target, keeping the path.
+type redirectTransport struct{ target *url.URL }
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestGitHubProvider_VerifiedOnlyWhenGitHubSaysSo(t *testing.T) {
+	tests := []struct {
+		name         string
+		publicEmail  string
+		emails       []githubEmail
+		emailsStatus int
+		wantEmail    string
+		wantVerified bool
+		wantErr      bool
+	}{
+		{
+			name:         "public email verified",
+			publicEmail:  "me@example.com",
+			emails:       []githubEmail{{Email: "work@example.com", Primary: true, Verified: true}, {Email: "me@example.com", Verified: true}},
+			emailsStatus: http.StatusOK,
+			wantEmail:    "me@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "primary email verified",
+			emails:       []githubEmail{{Email: "old@example.com"}, {Email: "me@example.com", Primary: true, Verified: true}},
+			emailsStatus: http.StatusOK,
+			wantEmail:    "me@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "primary email unverified",
+			emails:       []githubEmail{{Email: "me@example.com", Primary: true}},
+			emailsStatus: http.StatusOK,
+			wantEmail:    "me@example.com",
+		},
+		{
+			name:         "public email without the emails scope",
+			publicEmail:  "me@example.com",
+			emailsStatus: http.StatusForbidden,
+			wantEmail:    "me@example.com",
+		},
+		{
+			name:         "no email at all",
+			emailsStatus: http.StatusForbidden,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, map[string]string{"access_token": "token", "token_type": "bearer"})
+			})
+			mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, map[string]interface{}{"id": 42, "login": "octocat", "email": tt.publicEmail})
+			})
+			mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+				if tt.emailsStatus != http.StatusOK {
+					w.WriteHeader(tt.emailsStatus)
+					return
+				}
+				writeJSON(w, tt.emails)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+			target, _ := url.Parse(server.URL)
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: redirectTransport{target: target}})
+
+			provider := NewGitHubProvider(domain.OAuth2ProviderConfig{ClientID: "id", ClientSecret: "secret"})
+			user, err := provider.Authenticate(ctx, "code", testVerifier)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEmail, user.Email)
+			assert.Equal(t, tt.wantVerified, user.IsVerified)
+		})
+	}
+}
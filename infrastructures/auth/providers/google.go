@@ -40,13 +40,13 @@ func (ggprov *googleProvider) Name() string {
 	return "google"
 }
 
-func (ggprov *googleProvider) GetAuthorizationURL(state string) string {
-	return ggprov.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (ggprov *googleProvider) GetAuthorizationURL(state string, codeVerifier string) string {
+	return ggprov.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(codeVerifier))
 }
 
-func (ggprov *googleProvider) Authenticate(ctx context.Context, code string) (*domain.User, error) {
+func (ggprov *googleProvider) Authenticate(ctx context.Context, code string, codeVerifier string) (*domain.User, error) {
 	
-	token, err := ggprov.config.Exchange(ctx, code)
+	token, err := ggprov.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		return nil, fmt.Errorf("google: code exchange failed: %w", err)
 	}
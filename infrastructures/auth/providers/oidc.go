@@ -0,0 +1,441 @@
+package providers
+
+// imports
+import (
+	"cmp"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// tolerated clock difference with the issuer
+	oidcClockSkew = time.Minute
+	// an unknown key ID refetches the key set at most this often
+	oidcJWKSRefreshInterval = time.Minute
+	// a failed discovery is tried again after this, doubling up to the max
+	oidcDiscoveryBackoff    = 5 * time.Second
+	oidcDiscoveryMaxBackoff = 5 * time.Minute
+)
+
+// signing algorithms accepted for ID tokens; symmetric ones would let anyone
+// holding the client secret mint tokens
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	name       string
+	issuer     string
+	config     *oauth2.Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	// discovery and the config's endpoints are set once discovered is true
+	discoverMu   sync.Mutex
+	discovered   bool
+	discovery    oidcDiscovery
+	discoverErr  error
+	retryAt      time.Time
+	retryBackoff time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// creates a generic OpenID Connect provider for the issuer in confg. The
+// endpoints are read from the issuer's discovery document on first use, so
+// an issuer that is down does not keep the server from starting.
+func NewOIDCProvider(name string, confg domain.OAuth2ProviderConfig, httpClient *http.Client) (*oidcProvider, error) {
+	if confg.Issuer == "" {
+		return nil, fmt.Errorf("oidc %s: issuer is required", name)
+	}
+	if len(confg.Scopes) == 0 {
+		confg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &oidcProvider{
+		name:       name,
+		issuer:     strings.TrimRight(confg.Issuer, "/"),
+		httpClient: httpClient,
+		now:        time.Now,
+		config: &oauth2.Config{
+			ClientID:     confg.ClientID,
+			ClientSecret: confg.ClientSecret,
+			RedirectURL:  confg.RedirectURL,
+			Scopes:       confg.Scopes,
+		},
+	}, nil
+}
+
+// Ready discovers the issuer's endpoints and signing keys unless that was
+// done. After a failure the same error is returned until a backoff passes,
+// so an unreachable issuer is not asked again on every login and probe.
+func (oidcprov *oidcProvider) Ready(ctx context.Context) error {
+	oidcprov.discoverMu.Lock()
+	defer oidcprov.discoverMu.Unlock()
+	if oidcprov.discovered {
+		return nil
+	}
+	if oidcprov.discoverErr != nil && oidcprov.now().Before(oidcprov.retryAt) {
+		return oidcprov.discoverErr
+	}
+
+	if err := oidcprov.discover(ctx); err != nil {
+		oidcprov.retryBackoff = min(max(2*oidcprov.retryBackoff, oidcDiscoveryBackoff), oidcDiscoveryMaxBackoff)
+		oidcprov.retryAt = oidcprov.now().Add(oidcprov.retryBackoff)
+		oidcprov.discoverErr = err
+		return err
+	}
+	oidcprov.discovered = true
+	oidcprov.discoverErr = nil
+	return nil
+}
+
+// discover must be called with discoverMu held.
+func (oidcprov *oidcProvider) discover(ctx context.Context) error {
+	var discovery oidcDiscovery
+	if err := oidcprov.getJSON(ctx, oidcprov.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return fmt.Errorf("oidc %s: discovery failed: %w", oidcprov.name, err)
+	}
+	// the issuer must identify itself as the one configured
+	if strings.TrimRight(discovery.Issuer, "/") != oidcprov.issuer {
+		return fmt.Errorf("oidc %s: discovery issuer %q does not match %q", oidcprov.name, discovery.Issuer, oidcprov.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return fmt.Errorf("oidc %s: discovery document is missing endpoints", oidcprov.name)
+	}
+
+	oidcprov.discovery = discovery
+	if err := oidcprov.refreshKeys(ctx); err != nil {
+		return fmt.Errorf("oidc %s: %w", oidcprov.name, err)
+	}
+	oidcprov.config.Endpoint = oauth2.Endpoint{
+		AuthURL:  discovery.AuthorizationEndpoint,
+		TokenURL: discovery.TokenEndpoint,
+	}
+	return nil
+}
+
+func (oidcprov *oidcProvider) Name() string {
+	return oidcprov.name
+}
+
+// GetAuthorizationURL expects Ready to have succeeded.
+func (oidcprov *oidcProvider) GetAuthorizationURL(state string, codeVerifier string) string {
+	return oidcprov.config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", oidcNonce(codeVerifier)),
+	)
+}
+
+func (oidcprov *oidcProvider) Authenticate(ctx context.Context, code string, codeVerifier string) (*domain.User, error) {
+	if err := oidcprov.Ready(ctx); err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, oidcprov.httpClient)
+
+	token, err := oidcprov.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%s: code exchange failed: %w", oidcprov.name, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%s: token response has no id_token", oidcprov.name)
+	}
+
+	claims, rawData, err := oidcprov.verifyIDToken(ctx, rawIDToken, oidcNonce(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	// some issuers keep the profile out of the ID token
+	if claims.Email == "" && oidcprov.discovery.UserinfoEndpoint != "" {
+		if err := oidcprov.fetchUserinfo(ctx, token, claims, rawData); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.User{
+		UserID:         claims.Subject,
+		Email:          claims.Email,
+		IsVerified:     claims.EmailVerified,
+		Name:           nonEmpty(claims.Name),
+		FirstName:      nonEmpty(claims.GivenName),
+		LastName:       nonEmpty(claims.FamilyName),
+		ProfilePicture: nonEmpty(claims.Picture),
+		Provider:       oidcprov.Name(),
+		RawData:        rawData,
+	}, nil
+}
+
+// oidcClaims are the identity claims of an ID token or a userinfo response.
+type oidcClaims struct {
+	Subject       string `json:"sub"`
+	AuthorizedBy  string `json:"azp"`
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+}
+
+// verifyIDToken checks the signature against the issuer's keys, the issuer,
+// audience, expiry and issue time, then the authorized party and nonce.
+func (oidcprov *oidcProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidcClaims, map[string]interface{}, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(oidcprov.discovery.Issuer),
+		jwt.WithAudience(oidcprov.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+		jwt.WithTimeFunc(oidcprov.now),
+	)
+	mapClaims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return oidcprov.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid id_token: %w", oidcprov.name, err)
+	}
+
+	raw, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid id_token claims: %w", oidcprov.name, err)
+	}
+	var claims oidcClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid id_token claims: %w", oidcprov.name, err)
+	}
+	audience, err := mapClaims.GetAudience()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid id_token claims: %w", oidcprov.name, err)
+	}
+
+	switch {
+	case len(audience) > 1 && claims.AuthorizedBy != oidcprov.config.ClientID:
+		return nil, nil, fmt.Errorf("%s: id_token was issued to another party", oidcprov.name)
+	case claims.Nonce != nonce:
+		return nil, nil, fmt.Errorf("%s: id_token nonce does not match", oidcprov.name)
+	case claims.Subject == "":
+		return nil, nil, fmt.Errorf("%s: id_token has no subject", oidcprov.name)
+	}
+
+	return &claims, mapClaims, nil
+}
+
+func (oidcprov *oidcProvider) fetchUserinfo(ctx context.Context, token *oauth2.Token, claims *oidcClaims, rawData map[string]interface{}) error {
+	resp, err := oidcprov.config.Client(ctx, token).Get(oidcprov.discovery.UserinfoEndpoint)
+	if err != nil {
+		return fmt.Errorf("%s: failed getting user info: %w", oidcprov.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: user info returned %s", oidcprov.name, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: failed reading user info: %w", oidcprov.name, err)
+	}
+	var info oidcClaims
+	if err := json.Unmarshal(data, &info); err != nil {
+		return fmt.Errorf("%s: failed parsing user info: %w", oidcprov.name, err)
+	}
+	// user info for another subject must not be mixed in
+	if info.Subject != claims.Subject {
+		return fmt.Errorf("%s: user info subject does not match the id_token", oidcprov.name)
+	}
+
+	claims.Email = info.Email
+	claims.EmailVerified = info.EmailVerified
+	claims.Name = cmp.Or(claims.Name, info.Name)
+	claims.GivenName = cmp.Or(claims.GivenName, info.GivenName)
+	claims.FamilyName = cmp.Or(claims.FamilyName, info.FamilyName)
+	claims.Picture = cmp.Or(claims.Picture, info.Picture)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err == nil {
+		for k, v := range raw {
+			if _, ok := rawData[k]; !ok {
+				rawData[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// key returns the verification key with the given ID. An unknown ID refetches
+// the key set, as the issuer may have rotated its keys.
+func (oidcprov *oidcProvider) key(ctx context.Context, kid string) (interface{}, error) {
+	oidcprov.mu.Lock()
+	key, ok := oidcprov.lookupKey(kid)
+	canRefresh := oidcprov.now().Sub(oidcprov.keysFetched) >= oidcJWKSRefreshInterval
+	oidcprov.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !canRefresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if err := oidcprov.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	oidcprov.mu.Lock()
+	defer oidcprov.mu.Unlock()
+	if key, ok := oidcprov.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey must be called with mu held. A token without a key ID can only
+// be matched when the issuer publishes a single key.
+func (oidcprov *oidcProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(oidcprov.keys) == 1 {
+		for _, key := range oidcprov.keys {
+			return key, true
+		}
+	}
+	key, ok := oidcprov.keys[kid]
+	return key, ok
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (oidcprov *oidcProvider) refreshKeys(ctx context.Context) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := oidcprov.getJSON(ctx, oidcprov.discovery.JWKSURI, &set); err != nil {
+		return fmt.Errorf("fetching signing keys failed: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// keys of unsupported types are skipped rather than failing the whole set
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("issuer published no usable signing keys")
+	}
+
+	oidcprov.mu.Lock()
+	oidcprov.keys = keys
+	oidcprov.keysFetched = oidcprov.now()
+	oidcprov.mu.Unlock()
+	return nil
+}
+
+func (jwk jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func (oidcprov *oidcProvider) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := oidcprov.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// oidcNonce derives the nonce from the PKCE verifier, which only the browser
+// that started the login holds in its state cookie. A replayed ID token from
+// another login carries a different nonce.
+func oidcNonce(codeVerifier string) string {
+	sum := sha256.Sum256([]byte("oidc-nonce:" + codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
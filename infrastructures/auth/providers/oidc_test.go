@@ -0,0 +1,358 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mJ92K9IrFoxXqGx3L2Ga3TtWZYlYyw"
+
+// fakeIssuer is an in-process OpenID Connect issuer. Its token endpoint
+// answers with whatever claims the test sets.
+type fakeIssuer struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	issuer        string // overrides the issuer in the discovery document
+	down          bool   // makes discovery fail
+	discoveries   int
+	key           *rsa.PrivateKey
+	kid           string
+	published     map[string]*rsa.PublicKey
+	signingKey    *rsa.PrivateKey
+	claims        jwt.MapClaims
+	userinfo      map[string]interface{}
+	challenge     string
+	jwksRequests  int
+	tokenRequests int
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	f := &fakeIssuer{key: key, signingKey: key, kid: "key-1", published: map[string]*rsa.PublicKey{"key-1": &key.PublicKey}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		issuer, down := f.issuer, f.down
+		f.discoveries++
+		f.mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if issuer == "" {
+			issuer = f.server.URL
+		}
+		writeJSON(w, map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"userinfo_endpoint":      f.server.URL + "/userinfo",
+			"jwks_uri":               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksRequests++
+		var keys []map[string]string
+		for kid, pub := range f.published {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		}
+		writeJSON(w, map[string]interface{}{"keys": keys})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.tokenRequests++
+		// PKCE: the verifier must match the challenge sent with the authorization request
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, f.claims)
+		token.Header["kid"] = f.kid
+		signed, err := token.SignedString(f.signingKey)
+		require.NoError(t, err)
+		writeJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signed,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, f.userinfo)
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeIssuer) config() domain.OAuth2ProviderConfig {
+	return domain.OAuth2ProviderConfig{
+		ClientID:     "inkforge",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/oauth/company/callback",
+		Issuer:       f.server.URL,
+	}
+}
+
+// authorize follows the authorization URL the way the browser would, recording
+// the PKCE challenge and returning the nonce the issuer would embed.
+func (f *fakeIssuer) authorize(t *testing.T, p *oidcProvider) string {
+	u, err := url.Parse(p.GetAuthorizationURL("state", testVerifier))
+	require.NoError(t, err)
+	query := u.Query()
+	assert.Equal(t, f.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	require.NotEmpty(t, query.Get("nonce"))
+
+	f.mu.Lock()
+	f.challenge = query.Get("code_challenge")
+	f.mu.Unlock()
+	return query.Get("nonce")
+}
+
+func (f *fakeIssuer) setClaims(claims jwt.MapClaims) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.claims = claims
+}
+
+func (f *fakeIssuer) validClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            f.server.URL,
+		"sub":            "user-42",
+		"aud":            "inkforge",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"given_name":     "Jane",
+	}
+}
+
+func newTestOIDCProvider(t *testing.T, f *fakeIssuer) *oidcProvider {
+	p, err := NewOIDCProvider("company", f.config(), f.server.Client())
+	require.NoError(t, err)
+	require.NoError(t, p.Ready(context.Background()))
+	return p
+}
+
+func TestOIDC_Authenticates(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := newTestOIDCProvider(t, f)
+	f.setClaims(f.validClaims(f.authorize(t, p)))
+
+	user, err := p.Authenticate(context.Background(), "good-code", testVerifier)
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-42", user.UserID)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.True(t, user.IsVerified)
+	assert.Equal(t, "company", user.Provider)
+	require.NotNil(t, user.Name)
+	assert.Equal(t, "Jane Doe", *user.Name)
+	require.NotNil(t, user.FirstName)
+	assert.Equal(t, "Jane", *user.FirstName)
+	assert.Nil(t, user.LastName)
+	assert.Equal(t, "user-42", user.RawData["sub"])
+}
+
+func TestOIDC_RejectsWrongVerifier(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := newTestOIDCProvider(t, f)
+	f.setClaims(f.validClaims(f.authorize(t, p)))
+
+	_, err := p.Authenticate(context.Background(), "good-code", "another-verifier-another-verifier-another-v")
+	assert.Error(t, err)
+}
+
+func TestOIDC_RejectsInvalidIDTokens(t *testing.T) {
+	cases := map[string]func(f *fakeIssuer, claims jwt.MapClaims){
+		"wrong nonce":    func(f *fakeIssuer, claims jwt.MapClaims) { claims["nonce"] = "replayed" },
+		"missing nonce":  func(f *fakeIssuer, claims jwt.MapClaims) { delete(claims, "nonce") },
+		"wrong audience": func(f *fakeIssuer, claims jwt.MapClaims) { claims["aud"] = "another-client" },
+		"other party": func(f *fakeIssuer, claims jwt.MapClaims) {
+			claims["aud"] = []string{"inkforge", "other"}
+			claims["azp"] = "other"
+		},
+		"wrong issuer":    func(f *fakeIssuer, claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		"expired":         func(f *fakeIssuer, claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"missing expiry":  func(f *fakeIssuer, claims jwt.MapClaims) { delete(claims, "exp") },
+		"issued later":    func(f *fakeIssuer, claims jwt.MapClaims) { claims["iat"] = time.Now().Add(time.Hour).Unix() },
+		"missing subject": func(f *fakeIssuer, claims jwt.MapClaims) { delete(claims, "sub") },
+		"foreign key": func(f *fakeIssuer, claims jwt.MapClaims) {
+			other, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err == nil {
+				f.signingKey = other
+			}
+		},
+	}
+
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFakeIssuer(t)
+			p := newTestOIDCProvider(t, f)
+			claims := f.validClaims(f.authorize(t, p))
+			tamper(f, claims)
+			f.setClaims(claims)
+
+			_, err := p.Authenticate(context.Background(), "good-code", testVerifier)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDC_AcceptsAudienceListWithAuthorizedParty(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := newTestOIDCProvider(t, f)
+	claims := f.validClaims(f.authorize(t, p))
+	claims["aud"] = []string{"inkforge", "account"}
+	claims["azp"] = "inkforge"
+	f.setClaims(claims)
+
+	_, err := p.Authenticate(context.Background(), "good-code", testVerifier)
+	assert.NoError(t, err)
+}
+
+func TestOIDC_RefetchesKeysAfterRotation(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := newTestOIDCProvider(t, f)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	f.mu.Lock()
+	f.signingKey, f.kid = rotated, "key-2"
+	f.published["key-2"] = &rotated.PublicKey
+	f.mu.Unlock()
+
+	// a just-fetched key set isn't refetched for every unknown key ID
+	f.setClaims(f.validClaims(f.authorize(t, p)))
+	_, err = p.Authenticate(context.Background(), "good-code", testVerifier)
+	assert.Error(t, err)
+	assert.Equal(t, 1, f.jwksRequests)
+
+	now = now.Add(2 * oidcJWKSRefreshInterval)
+	_, err = p.Authenticate(context.Background(), "good-code", testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.jwksRequests)
+}
+
+func TestOIDC_FallsBackToUserinfoForEmail(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := newTestOIDCProvider(t, f)
+	claims := f.validClaims(f.authorize(t, p))
+	delete(claims, "email")
+	delete(claims, "email_verified")
+	f.setClaims(claims)
+	f.userinfo = map[string]interface{}{"sub": "user-42", "email": "jane@corp.example.com", "email_verified": true, "family_name": "Doe"}
+
+	user, err := p.Authenticate(context.Background(), "good-code", testVerifier)
+
+	require.NoError(t, err)
+	assert.Equal(t, "jane@corp.example.com", user.Email)
+	assert.True(t, user.IsVerified)
+	require.NotNil(t, user.LastName)
+	assert.Equal(t, "Doe", *user.LastName)
+
+	// user info about someone else is refused
+	f.userinfo["sub"] = "user-7"
+	_, err = p.Authenticate(context.Background(), "good-code", testVerifier)
+	assert.Error(t, err)
+}
+
+func TestOIDC_DiscoveryMustMatchIssuer(t *testing.T) {
+	f := newFakeIssuer(t)
+	f.issuer = "https://sso.example.com"
+
+	p, err := NewOIDCProvider("company", f.config(), f.server.Client())
+	require.NoError(t, err)
+	assert.ErrorContains(t, p.Ready(context.Background()), "does not match")
+
+	config := f.config()
+	config.Issuer = f.server.URL + "/missing"
+	p, err = NewOIDCProvider("company", config, f.server.Client())
+	require.NoError(t, err)
+	assert.Error(t, p.Ready(context.Background()))
+}
+
+func TestOIDC_RetriesDiscoveryWithBackoff(t *testing.T) {
+	f := newFakeIssuer(t)
+	f.down = true
+	p, err := NewOIDCProvider("company", f.config(), f.server.Client())
+	require.NoError(t, err, "the issuer is not asked until the provider is used")
+	assert.Zero(t, f.discoveries)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err = p.Authenticate(ctx, "good-code", testVerifier)
+	assert.ErrorContains(t, err, "discovery failed")
+	assert.Error(t, p.Ready(ctx))
+	assert.Equal(t, 1, f.discoveries, "not asked again before the backoff")
+
+	now = now.Add(oidcDiscoveryBackoff)
+	assert.Error(t, p.Ready(ctx))
+	assert.Equal(t, 2, f.discoveries)
+
+	// the backoff doubled
+	f.mu.Lock()
+	f.down = false
+	f.mu.Unlock()
+	now = now.Add(oidcDiscoveryBackoff)
+	assert.Error(t, p.Ready(ctx))
+	now = now.Add(oidcDiscoveryBackoff)
+	require.NoError(t, p.Ready(ctx))
+	assert.Equal(t, 3, f.discoveries)
+
+	f.setClaims(f.validClaims(f.authorize(t, p)))
+	user, err := p.Authenticate(ctx, "good-code", testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, "user-42", user.UserID)
+	assert.Equal(t, 3, f.discoveries, "discovered once")
+}
@@ -1,6 +1,7 @@
 package infrastructures
 
 import (
+	"errors"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -13,6 +14,13 @@ type Config struct {
 	AppPort string
 	BaseURL string
 
+	HTTPReadTimeoutSec       int
+	HTTPReadHeaderTimeoutSec int
+	HTTPWriteTimeoutSec      int
+	HTTPIdleTimeoutSec       int
+	ShutdownTimeoutSec       int
+	ShutdownDrainDelaySec    int
+
 	DBHost     string
 	DBPort     string
 	DBUser     string
@@ -21,6 +29,8 @@ type Config struct {
 	DBDriver   string
 	DBUri      string
 
+	DBMigrateOnStart bool
+
 	JWTSecretKey              string
 	JWTExpirationMinutes      int
 	RefreshTokenSecret        string
@@ -35,18 +45,66 @@ type Config struct {
 	EmailFrom     string
 	EmailFromName string
 
+	EmailTemplateCacheSec int
+	EmailOutboxWorkers    int
+	EmailOutboxAttempts   int
+	EmailOutboxBackoffSec int
+	EmailOutboxSweepSec   int
+
+	NotificationChannels []NotificationChannelSettings
+
+	DigestSweepMinutes int
+
+	NewsletterBounceSecret string
+	NewsletterSweepMinutes int
+
 	AIApiKey       string
 	AIModelName    string
 	AIApiBaseUrl   string
 	AIProvider     string
 
+	AIFallbackProviders  []string
+	AIProviderSettings   map[string]AIProviderSettings
+	AIRequestTimeoutSec  int
+	AIBreakerThreshold   int
+	AIBreakerCooldownSec int
+	AIChatTokenBudget    int
+	AIRoleQuotas         map[string]AIQuotaSettings
+	AICacheSize          int
+	AICacheTTLMinutes    int
+	AIEnrichWorkers      int
+	AIEnrichMaxAttempts  int
+	AIEnrichBackoffSec   int
+	AIEnrichSweepMinutes int
+	AIEmbeddingProvider  string
+	AIEmbeddingModel     string
+	AIQATopK             int
+	AIQAMinScore         float64
+	AIPromptCacheSec     int
+
+	RelatedBlogsRefreshMinutes int
+
+	// CounterReconcileMinutes is how often blog and comment counters are
+	// recounted; 0 disables the schedule
+	CounterReconcileMinutes int
+	// CounterReconcileRepair lets the scheduled run repair what it finds. The
+	// run's lock is per process, so it should be on for one instance only.
+	CounterReconcileRepair bool
+
 	DefaultPageSize int
 	MaxPageSize     int
 
 	AllowedOrigins []string
 	LogLevel       string
+	LogFormat      string
 	Timezone       string
 
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+	TracingServiceName string
+
 	GoogleClientID         string
 	GoogleClientSecret     string
 	GoogleRedirectURL      string
@@ -56,9 +114,64 @@ type Config struct {
 	FacebookClientID       string
 	FacebookClientSecret   string
 	FacebookRedirectURL    string
+	OAuthStateSecret       string
+	OAuthStateTTLMinutes   int
+	OIDCProviders          map[string]OIDCProviderSettings
 
 }
 
+// AIProviderSettings holds the per-provider overrides read from
+// AI_<PROVIDER>_API_KEY, AI_<PROVIDER>_MODEL_NAME and AI_<PROVIDER>_API_BASE_URL.
+type AIProviderSettings struct {
+	APIKey     string
+	ModelName  string
+	APIBaseURL string
+
+	// prices used to estimate cost, from AI_<PROVIDER>_PROMPT_PRICE_PER_1K
+	// and AI_<PROVIDER>_COMPLETION_PRICE_PER_1K
+	PromptPricePer1K     float64
+	CompletionPricePer1K float64
+}
+
+// AIQuotaSettings holds the default AI quota of a role, read from
+// AI_QUOTA_<ROLE>_DAILY_REQUESTS, _MONTHLY_REQUESTS, _DAILY_TOKENS and _MONTHLY_TOKENS.
+// Zero means unlimited.
+type AIQuotaSettings struct {
+	DailyRequests   int
+	MonthlyRequests int
+	DailyTokens     int
+	MonthlyTokens   int
+}
+
+// OIDCProviderSettings holds a generic OpenID Connect provider listed in
+// OIDC_PROVIDERS, read from OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _SCOPES and _REDIRECT_URL.
+type OIDCProviderSettings struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	RedirectURL  string
+}
+
+// NotificationChannelSettings holds a channel listed in NOTIFY_CHANNELS, read
+// from NOTIFY_<NAME>_TYPE (defaults to the name), _URL, _PATH, _SECRET and
+// _EVENTS (defaults to every event).
+type NotificationChannelSettings struct {
+	Name   string
+	Type   string
+	URL    string
+	Path   string
+	Secret string
+	Events []string
+}
+
+// SupportedAIProviders lists the providers that can appear in the AI fallback chain.
+var SupportedAIProviders = []string{"groq", "deepseek", "openai"}
+
+// AIQuotaRoles lists the roles whose default AI quota can be configured.
+var AIQuotaRoles = []string{"USER", "ADMIN"}
+
 // LoadConfig loads config.env file using absolute project path which looks for
 // root/config.env. It returns config reference and nil if loading is a success.
 // Else it returns nil and error message.
@@ -73,6 +186,47 @@ func LoadConfig() (*Config, error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	// regular users get a modest AI allowance unless configured otherwise
+	viper.SetDefault("AI_QUOTA_USER_DAILY_REQUESTS", 100)
+	viper.SetDefault("AI_QUOTA_USER_MONTHLY_REQUESTS", 2000)
+	viper.SetDefault("AI_QUOTA_USER_DAILY_TOKENS", 100000)
+	viper.SetDefault("AI_QUOTA_USER_MONTHLY_TOKENS", 2000000)
+	viper.SetDefault("AI_CACHE_SIZE", 1000)
+	viper.SetDefault("AI_CACHE_TTL_MINUTES", 1440)
+	viper.SetDefault("AI_ENRICH_WORKERS", 1)
+	viper.SetDefault("AI_ENRICH_MAX_ATTEMPTS", 5)
+	viper.SetDefault("AI_ENRICH_BACKOFF_SECONDS", 30)
+	viper.SetDefault("AI_ENRICH_SWEEP_MINUTES", 10)
+	viper.SetDefault("AI_QA_TOP_K", 5)
+	viper.SetDefault("AI_QA_MIN_SCORE", 0.2)
+	viper.SetDefault("AI_PROMPT_CACHE_SECONDS", 30)
+	viper.SetDefault("OAUTH_STATE_TTL_MINUTES", 10)
+	viper.SetDefault("RELATED_BLOGS_REFRESH_MINUTES", 60)
+	viper.SetDefault("COUNTER_RECONCILE_MINUTES", 1440)
+	viper.SetDefault("COUNTER_RECONCILE_REPAIR", false)
+	viper.SetDefault("EMAIL_FROM_NAME", "InkForge")
+	viper.SetDefault("EMAIL_TEMPLATE_CACHE_SECONDS", 30)
+	viper.SetDefault("EMAIL_OUTBOX_WORKERS", 2)
+	viper.SetDefault("EMAIL_OUTBOX_MAX_ATTEMPTS", 8)
+	viper.SetDefault("EMAIL_OUTBOX_BACKOFF_SECONDS", 30)
+	viper.SetDefault("EMAIL_OUTBOX_SWEEP_SECONDS", 15)
+	viper.SetDefault("NOTIFY_CHANNELS", "email")
+	viper.SetDefault("NOTIFY_EMAIL_TYPE", "smtp")
+	viper.SetDefault("DIGEST_SWEEP_MINUTES", 15)
+	viper.SetDefault("NEWSLETTER_SWEEP_MINUTES", 5)
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "text")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_ENDPOINT", "localhost:4318")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("TRACING_SERVICE_NAME", "inkforge")
+	viper.SetDefault("HTTP_READ_TIMEOUT_SECONDS", 15)
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT_SECONDS", 5)
+	viper.SetDefault("HTTP_WRITE_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("HTTP_IDLE_TIMEOUT_SECONDS", 120)
+	viper.SetDefault("SHUTDOWN_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("DB_MIGRATE_ON_START", true)
+
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
 	}
@@ -81,6 +235,13 @@ func LoadConfig() (*Config, error) {
 		AppPort: viper.GetString("APP_PORT"),
 		BaseURL: viper.GetString("BASE_URL"),
 
+		HTTPReadTimeoutSec:       viper.GetInt("HTTP_READ_TIMEOUT_SECONDS"),
+		HTTPReadHeaderTimeoutSec: viper.GetInt("HTTP_READ_HEADER_TIMEOUT_SECONDS"),
+		HTTPWriteTimeoutSec:      viper.GetInt("HTTP_WRITE_TIMEOUT_SECONDS"),
+		HTTPIdleTimeoutSec:       viper.GetInt("HTTP_IDLE_TIMEOUT_SECONDS"),
+		ShutdownTimeoutSec:       viper.GetInt("SHUTDOWN_TIMEOUT_SECONDS"),
+		ShutdownDrainDelaySec:    viper.GetInt("SHUTDOWN_DRAIN_DELAY_SECONDS"),
+
 		DBHost:     viper.GetString("DB_HOST"),
 		DBPort:     viper.GetString("DB_PORT"),
 		DBUser:     viper.GetString("DB_USER"),
@@ -88,6 +249,8 @@ func LoadConfig() (*Config, error) {
 		DBName:     viper.GetString("DB_NAME"),
 		DBUri:      viper.GetString("DB_URI"),
 
+		DBMigrateOnStart: viper.GetBool("DB_MIGRATE_ON_START"),
+
 		JWTSecretKey:              viper.GetString("JWT_SECRET_KEY"),
 		JWTExpirationMinutes:      viper.GetInt("JWT_EXPIRATION_MINUTES"),
 		RefreshTokenSecret:        viper.GetString("REFRESH_TOKEN_SECRET"),
@@ -101,18 +264,62 @@ func LoadConfig() (*Config, error) {
 		EmailFrom:     viper.GetString("EMAIL_FROM"),
 		EmailFromName: viper.GetString("EMAIL_FROM_NAME"),
 
+		EmailTemplateCacheSec: viper.GetInt("EMAIL_TEMPLATE_CACHE_SECONDS"),
+		EmailOutboxWorkers:    viper.GetInt("EMAIL_OUTBOX_WORKERS"),
+		EmailOutboxAttempts:   viper.GetInt("EMAIL_OUTBOX_MAX_ATTEMPTS"),
+		EmailOutboxBackoffSec: viper.GetInt("EMAIL_OUTBOX_BACKOFF_SECONDS"),
+		EmailOutboxSweepSec:   viper.GetInt("EMAIL_OUTBOX_SWEEP_SECONDS"),
+
+		NotificationChannels: loadNotificationChannelSettings(),
+
+		DigestSweepMinutes: viper.GetInt("DIGEST_SWEEP_MINUTES"),
+
+		NewsletterBounceSecret: viper.GetString("NEWSLETTER_BOUNCE_SECRET"),
+		NewsletterSweepMinutes: viper.GetInt("NEWSLETTER_SWEEP_MINUTES"),
+
 		AIApiKey: viper.GetString("AI_API_KEY"),
 		AIModelName:  viper.GetString("AI_MODEL_NAME"),
 		AIApiBaseUrl: viper.GetString("AI_API_BASE_URL"),
 		AIProvider:   viper.GetString("AI_PROVIDER"),
 
+		AIFallbackProviders:  splitList(viper.GetString("AI_FALLBACK_PROVIDERS")),
+		AIProviderSettings:   loadAIProviderSettings(),
+		AIRequestTimeoutSec:  viper.GetInt("AI_REQUEST_TIMEOUT_SECONDS"),
+		AIBreakerThreshold:   viper.GetInt("AI_BREAKER_FAILURE_THRESHOLD"),
+		AIBreakerCooldownSec: viper.GetInt("AI_BREAKER_COOLDOWN_SECONDS"),
+		AIChatTokenBudget:    viper.GetInt("AI_CHAT_TOKEN_BUDGET"),
+		AIRoleQuotas:         loadAIQuotaSettings(),
+		AICacheSize:          viper.GetInt("AI_CACHE_SIZE"),
+		AICacheTTLMinutes:    viper.GetInt("AI_CACHE_TTL_MINUTES"),
+		AIEnrichWorkers:      viper.GetInt("AI_ENRICH_WORKERS"),
+		AIEnrichMaxAttempts:  viper.GetInt("AI_ENRICH_MAX_ATTEMPTS"),
+		AIEnrichBackoffSec:   viper.GetInt("AI_ENRICH_BACKOFF_SECONDS"),
+		AIEnrichSweepMinutes: viper.GetInt("AI_ENRICH_SWEEP_MINUTES"),
+		AIEmbeddingProvider:  viper.GetString("AI_EMBEDDING_PROVIDER"),
+		AIEmbeddingModel:     viper.GetString("AI_EMBEDDING_MODEL"),
+		AIQATopK:             viper.GetInt("AI_QA_TOP_K"),
+		AIQAMinScore:         viper.GetFloat64("AI_QA_MIN_SCORE"),
+		AIPromptCacheSec:     viper.GetInt("AI_PROMPT_CACHE_SECONDS"),
+
+		RelatedBlogsRefreshMinutes: viper.GetInt("RELATED_BLOGS_REFRESH_MINUTES"),
+
+		CounterReconcileMinutes: viper.GetInt("COUNTER_RECONCILE_MINUTES"),
+		CounterReconcileRepair:  viper.GetBool("COUNTER_RECONCILE_REPAIR"),
+
 		DefaultPageSize: viper.GetInt("DEFAULT_PAGE_SIZE"),
 		MaxPageSize:     viper.GetInt("MAX_PAGE_SIZE"),
 
 		AllowedOrigins: strings.Split(viper.GetString("ALLOWED_ORIGINS"), ","),
 		LogLevel:       viper.GetString("LOG_LEVEL"),
+		LogFormat:      viper.GetString("LOG_FORMAT"),
 		Timezone:       viper.GetString("TIMEZONE"),
 
+		TracingExporter:    viper.GetString("TRACING_EXPORTER"),
+		TracingEndpoint:    viper.GetString("TRACING_ENDPOINT"),
+		TracingInsecure:    viper.GetBool("TRACING_INSECURE"),
+		TracingSampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+		TracingServiceName: viper.GetString("TRACING_SERVICE_NAME"),
+
 		GoogleClientID:       viper.GetString("GOOGLE_CLIENT_ID"),
 		GoogleClientSecret:   viper.GetString("GOOGLE_CLIENT_SECRET"),
 		GoogleRedirectURL:    viper.GetString("GOOGLE_REDIRECT_URL"),
@@ -122,7 +329,121 @@ func LoadConfig() (*Config, error) {
 		FacebookClientID:     viper.GetString("FACEBOOK_CLIENT_ID"),
 		FacebookClientSecret: viper.GetString("FACEBOOK_CLIENT_SECRET"),
 		FacebookRedirectURL:  viper.GetString("FACEBOOK_REDIRECT_URL"),
+		OAuthStateSecret:     viper.GetString("OAUTH_STATE_SECRET"),
+		OAuthStateTTLMinutes: viper.GetInt("OAUTH_STATE_TTL_MINUTES"),
+		OIDCProviders:        loadOIDCProviderSettings(),
+	}
+	// the state cookie only needs a server-side secret; reuse the JWT one if unset
+	if cfg.OAuthStateSecret == "" {
+		cfg.OAuthStateSecret = cfg.JWTSecretKey
+	}
+	// an empty key would let anyone forge the state cookie
+	if cfg.OAuthStateSecret == "" {
+		return nil, errors.New("OAUTH_STATE_SECRET or JWT_SECRET_KEY must be set")
 	}
 
 	return cfg, nil
 }
+
+// loadAIProviderSettings reads the provider specific AI settings for every supported provider.
+func loadAIProviderSettings() map[string]AIProviderSettings {
+	settings := make(map[string]AIProviderSettings, len(SupportedAIProviders))
+	for _, name := range SupportedAIProviders {
+		prefix := "AI_" + strings.ToUpper(name) + "_"
+		settings[name] = AIProviderSettings{
+			APIKey:     viper.GetString(prefix + "API_KEY"),
+			ModelName:  viper.GetString(prefix + "MODEL_NAME"),
+			APIBaseURL: viper.GetString(prefix + "API_BASE_URL"),
+
+			PromptPricePer1K:     viper.GetFloat64(prefix + "PROMPT_PRICE_PER_1K"),
+			CompletionPricePer1K: viper.GetFloat64(prefix + "COMPLETION_PRICE_PER_1K"),
+		}
+	}
+	return settings
+}
+
+// loadOIDCProviderSettings reads the settings of every provider listed in OIDC_PROVIDERS.
+func loadOIDCProviderSettings() map[string]OIDCProviderSettings {
+	settings := make(map[string]OIDCProviderSettings)
+	for _, name := range splitList(viper.GetString("OIDC_PROVIDERS")) {
+		// the name is part of the login URL
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		scopes := splitList(viper.GetString(prefix + "SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		redirectURL := viper.GetString(prefix + "REDIRECT_URL")
+		if redirectURL == "" {
+			redirectURL = "/oauth/" + name + "/callback"
+		}
+
+		settings[name] = OIDCProviderSettings{
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			Scopes:       scopes,
+			RedirectURL:  redirectURL,
+		}
+	}
+	return settings
+}
+
+// loadNotificationChannelSettings reads the settings of every channel listed
+// in NOTIFY_CHANNELS, in order.
+func loadNotificationChannelSettings() []NotificationChannelSettings {
+	var channels []NotificationChannelSettings
+	for _, name := range splitList(viper.GetString("NOTIFY_CHANNELS")) {
+		name = strings.ToLower(name)
+		prefix := "NOTIFY_" + strings.ToUpper(name) + "_"
+
+		channelType := strings.ToLower(viper.GetString(prefix + "TYPE"))
+		if channelType == "" {
+			channelType = name
+		}
+		// emails carry verification and reset links, so only SMTP channels,
+		// which deliver to the user, get every event unless told otherwise
+		events := splitList(viper.GetString(prefix + "EVENTS"))
+		if len(events) == 0 && channelType == "smtp" {
+			events = []string{"*"}
+		}
+
+		channels = append(channels, NotificationChannelSettings{
+			Name:   name,
+			Type:   channelType,
+			URL:    viper.GetString(prefix + "URL"),
+			Path:   viper.GetString(prefix + "PATH"),
+			Secret: viper.GetString(prefix + "SECRET"),
+			Events: events,
+		})
+	}
+	return channels
+}
+
+// loadAIQuotaSettings reads the default AI quota of every configurable role.
+func loadAIQuotaSettings() map[string]AIQuotaSettings {
+	quotas := make(map[string]AIQuotaSettings, len(AIQuotaRoles))
+	for _, role := range AIQuotaRoles {
+		prefix := "AI_QUOTA_" + role + "_"
+		quotas[role] = AIQuotaSettings{
+			DailyRequests:   viper.GetInt(prefix + "DAILY_REQUESTS"),
+			MonthlyRequests: viper.GetInt(prefix + "MONTHLY_REQUESTS"),
+			DailyTokens:     viper.GetInt(prefix + "DAILY_TOKENS"),
+			MonthlyTokens:   viper.GetInt(prefix + "MONTHLY_TOKENS"),
+		}
+	}
+	return quotas
+}
+
+// splitList splits a comma separated value, dropping blank entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
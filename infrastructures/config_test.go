@@ -68,4 +68,63 @@ TIMEZONE=Africa/Addis_Ababa
 	assert.Equal(t, 1440, cfg.RefreshTokenExpirationMin)
 	assert.Equal(t, []string{"http://localhost", "http://example.com"}, cfg.AllowedOrigins)
 	assert.Equal(t, "Africa/Addis_Ababa", cfg.Timezone)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "text", cfg.LogFormat)
+	assert.Equal(t, 30, cfg.HTTPWriteTimeoutSec)
+	assert.Equal(t, 30, cfg.ShutdownTimeoutSec)
+	assert.Equal(t, 0, cfg.ShutdownDrainDelaySec)
+	assert.True(t, cfg.DBMigrateOnStart)
+	assert.Equal(t, 1440, cfg.CounterReconcileMinutes)
+	assert.False(t, cfg.CounterReconcileRepair)
+	assert.Equal(t, "secret", cfg.OAuthStateSecret)
+}
+
+func TestLoadConfig_RequiresOAuthStateSecret(t *testing.T) {
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "config.env"), []byte("APP_PORT=8080\n"), 0644)
+	assert.NoError(t, err)
+
+	viper.Reset()
+	viper.AddConfigPath(tempDir)
+	viper.SetConfigName("config")
+	viper.SetConfigType("env")
+
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "OAUTH_STATE_SECRET")
+}
+
+func TestLoadConfig_NotificationChannels(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configContent := `
+JWT_SECRET_KEY=secret
+NOTIFY_CHANNELS=email,Ops,dev
+NOTIFY_EMAIL_EVENTS=verify_email,password_reset
+NOTIFY_OPS_TYPE=slack
+NOTIFY_OPS_URL=https://hooks.slack.com/services/x
+NOTIFY_DEV_TYPE=file
+NOTIFY_DEV_PATH=/tmp/mail.log
+`
+	err := os.WriteFile(filepath.Join(tempDir, "config.env"), []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	viper.Reset()
+	viper.AddConfigPath(tempDir)
+	viper.SetConfigName("config")
+	viper.SetConfigType("env")
+
+	cfg, err := LoadConfig()
+	assert.NoError(t, err)
+
+	channels, err := BuildNotificationChannelConfigs(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, channels, 3)
+	assert.Equal(t, "email", channels[0].Name)
+	assert.Equal(t, "smtp", channels[0].Type)
+	assert.Equal(t, []string{"verify_email", "password_reset"}, channels[0].Events)
+	assert.Equal(t, "ops", channels[1].Name)
+	assert.Equal(t, "slack", channels[1].Type)
+	assert.Equal(t, "https://hooks.slack.com/services/x", channels[1].URL)
+	assert.Empty(t, channels[1].Events)
+	assert.Equal(t, "/tmp/mail.log", channels[2].Path)
 }
@@ -0,0 +1,252 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sent emails are only kept for the admin view for a while
+const sentEmailRetention = 30 * 24 * time.Hour
+
+// All returns the schema's migrations. Append new ones with the next version;
+// never change or renumber one that has shipped.
+func All() []Migration {
+	return []Migration{
+		Indexes(1, "users_indexes", CollectionIndexes{Collection: "users", Indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			// accounts created through OAuth may have no username yet
+			{
+				Keys: bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+					"username": bson.M{"$type": "string"},
+				}),
+			},
+			// a provider account can be linked to one user only
+			{
+				Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+					"identities.subject_id": bson.M{"$exists": true},
+				}),
+			},
+		}}),
+
+		Indexes(2, "blogs_indexes", CollectionIndexes{Collection: "blogs", Indexes: []mongo.IndexModel{
+			// supported the sweep for blogs whose AI enrichment is pending,
+			// until migration 14 replaced it
+			enrichmentByUpdatedAt(),
+			// supports collecting new blogs for digests
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "tag_ids", Value: 1}}},
+			{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "content", Value: "text"}},
+				Options: options.Index().SetName("blogs_text").SetWeights(bson.D{
+					{Key: "title", Value: 5},
+					{Key: "content", Value: 1},
+				}),
+			},
+		}}),
+
+		// one reaction and one counted view per user
+		Indexes(3, "reactions_indexes",
+			CollectionIndexes{Collection: "blog_reactions", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			}},
+			CollectionIndexes{Collection: "comment_reactions", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			}},
+			CollectionIndexes{Collection: "blog_views", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			}},
+		),
+
+		{
+			Version: 4,
+			Name:    "comments_backfill_comment_id",
+			Up:      backfillCommentIDs,
+			// the backfilled ids equal the _id, which lookups accept either way
+			Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+		},
+
+		Indexes(5, "comments_indexes", CollectionIndexes{Collection: "comments", Indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "comment_id", Value: 1}}},
+			{Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		}}),
+
+		Indexes(6, "tags_indexes", CollectionIndexes{Collection: "tags", Indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "tag_name", Value: 1}}},
+		}}),
+
+		Indexes(7, "ai_indexes",
+			CollectionIndexes{Collection: "ai_conversations", Indexes: []mongo.IndexModel{
+				// supports listing a user's conversations by recent activity
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: -1}}},
+			}},
+			CollectionIndexes{Collection: "ai_quotas", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			}},
+			CollectionIndexes{Collection: "ai_response_cache", Indexes: []mongo.IndexModel{
+				// mongo removes entries once expires_at has passed
+				{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			}},
+			CollectionIndexes{Collection: "ai_usage", Indexes: []mongo.IndexModel{
+				// quota checks sum a user's usage since the start of the day or month
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			}},
+			CollectionIndexes{Collection: "blog_chunks", Indexes: []mongo.IndexModel{
+				// supports replacing a blog's chunks and scanning one model's vectors
+				{Keys: bson.D{{Key: "blog_id", Value: 1}}},
+				{Keys: bson.D{{Key: "model", Value: 1}}},
+			}},
+			CollectionIndexes{Collection: "ai_prompt_templates", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "name", Value: 1}, {Key: "version", Value: -1}}, Options: options.Index().SetUnique(true)},
+			}},
+			CollectionIndexes{Collection: "ai_prompt_rollouts", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+			}},
+		),
+
+		Indexes(8, "email_indexes",
+			CollectionIndexes{Collection: "email_templates", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "name", Value: 1}, {Key: "locale", Value: 1}}, Options: options.Index().SetUnique(true)},
+			}},
+			CollectionIndexes{Collection: "email_outbox", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+				{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+				{
+					Keys:    bson.D{{Key: "batch_id", Value: 1}, {Key: "status", Value: 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.M{"batch_id": bson.M{"$exists": true}}),
+				},
+				{
+					Keys:    bson.D{{Key: "sent_at", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(int32(sentEmailRetention.Seconds())),
+				},
+			}},
+		),
+
+		Indexes(9, "subscription_indexes",
+			CollectionIndexes{Collection: "digest_subscriptions", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "frequency", Value: 1}, {Key: "next_send_at", Value: 1}}},
+			}},
+			CollectionIndexes{Collection: "newsletter_issues", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				{Keys: bson.D{{Key: "status", Value: 1}, {Key: "lease_until", Value: 1}}},
+			}},
+			CollectionIndexes{Collection: "newsletter_subscribers", Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "email", Value: 1}}},
+			}},
+		),
+
+		{
+			Version: 10,
+			Name:    "users_uppercase_roles",
+			Up:      uppercaseRoles,
+			// lowercase roles never passed the role checks, so there is nothing to restore
+			Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+		},
+
+		{
+			Version: 11,
+			Name:    "users_updated_at_dates",
+			Up:      userUpdatedAtDates,
+			// the strings only kept the day, which the dates still hold, and
+			// nothing reads updated_at as a string
+			Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+		},
+
+		Indexes(12, "ai_quota_usage_indexes",
+			CollectionIndexes{Collection: "ai_quota_usage", Indexes: []mongo.IndexModel{
+				// a day's or month's usage is dropped once the period is over
+				{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			}},
+		),
+
+		{
+			Version: 13,
+			Name:    "blogs_content_updated_at",
+			Up:      backfillContentUpdatedAt,
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("blogs").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"content_updated_at": ""}})
+				return err
+			},
+		},
+
+		replacingIndex(
+			Indexes(14, "blogs_content_updated_at_index", CollectionIndexes{Collection: "blogs", Indexes: []mongo.IndexModel{
+				// supports the sweep for blogs whose AI enrichment is pending
+				{
+					Keys:    bson.D{{Key: "auto_enrich", Value: 1}, {Key: "content_updated_at", Value: 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.M{"auto_enrich": true}),
+				},
+			}}),
+			"blogs", enrichmentByUpdatedAt(),
+		),
+
+		Indexes(15, "newsletter_issues_unique_blog", CollectionIndexes{Collection: "newsletter_issues", Indexes: []mongo.IndexModel{
+			// a blog is sent to each list once
+			{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "blog_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		}}),
+	}
+}
+
+// enrichmentByUpdatedAt is the index migration 2 created for the enrichment
+// sweep, before the sweep went by content_updated_at.
+func enrichmentByUpdatedAt() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "auto_enrich", Value: 1}, {Key: "updated_at", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"auto_enrich": true}),
+	}
+}
+
+// uppercaseRoles fixes the roles promoting and demoting used to store as
+// "admin" and "user", which locked the users out of every route.
+func uppercaseRoles(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	for from, to := range map[string]string{"admin": "ADMIN", "user": "USER"} {
+		if _, err := users.UpdateMany(ctx, bson.M{"role": from}, bson.M{"$set": bson.M{"role": to}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userUpdatedAtDates turns the updated_at verifying an email used to store as
+// a "2006-01-02" string back into a date, which users could not be read with.
+func userUpdatedAtDates(ctx context.Context, db *mongo.Database) error {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: bson.D{{Key: "$toDate", Value: "$updated_at"}}}}}},
+	}
+	_, err := db.Collection("users").UpdateMany(ctx, bson.M{"updated_at": bson.M{"$type": "string"}}, update)
+	return err
+}
+
+// backfillContentUpdatedAt starts the content version of existing blogs at
+// their updated_at, which is what enrichment compared against before.
+func backfillContentUpdatedAt(ctx context.Context, db *mongo.Database) error {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "content_updated_at", Value: "$updated_at"}}}},
+	}
+	_, err := db.Collection("blogs").UpdateMany(ctx, bson.M{"content_updated_at": bson.M{"$exists": false}}, update)
+	return err
+}
+
+// backfillCommentIDs sets comment_id on comments whose create was interrupted
+// before it was mirrored from _id.
+func backfillCommentIDs(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{"$or": []bson.M{
+		{"comment_id": bson.M{"$exists": false}},
+		{"comment_id": ""},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "comment_id", Value: bson.D{{Key: "$toString", Value: "$_id"}}}}}},
+	}
+	_, err := db.Collection("comments").UpdateMany(ctx, filter, update)
+	return err
+}
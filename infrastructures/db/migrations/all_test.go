@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// updatesSent returns the query and update of every update statement sent to
+// collection, as extended JSON.
+func updatesSent(mt *mtest.T, collection string) [][2]string {
+	var updates [][2]string
+	for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+		if event.CommandName != "update" || event.Command.Lookup("update").StringValue() != collection {
+			continue
+		}
+		statements, err := event.Command.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		for _, statement := range statements {
+			doc := statement.Document()
+			updates = append(updates, [2]string{doc.Lookup("q").String(), doc.Lookup("u").String()})
+		}
+	}
+	return updates
+}
+
+func runMigration(mt *mtest.T, up func(context.Context, *mongo.Database) error, responses int) error {
+	for i := 0; i < responses; i++ {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+	}
+	return up(context.Background(), mt.DB)
+}
+
+func TestUppercaseRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("updates both roles", func(mt *mtest.T) {
+		require.NoError(mt, runMigration(mt, uppercaseRoles, 2))
+		assert.ElementsMatch(mt, [][2]string{
+			{`{"role": "admin"}`, `{"$set": {"role": "ADMIN"}}`},
+			{`{"role": "user"}`, `{"$set": {"role": "USER"}}`},
+		}, updatesSent(mt, "users"))
+	})
+
+	mt.Run("stops at a failed update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted"}))
+		assert.Error(mt, uppercaseRoles(context.Background(), mt.DB))
+		assert.Len(mt, updatesSent(mt, "users"), 1)
+	})
+}
+
+func TestUserUpdatedAtDates(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("converts only strings", func(mt *mtest.T) {
+		require.NoError(mt, runMigration(mt, userUpdatedAtDates, 1))
+		assert.Equal(mt, [][2]string{{
+			`{"updated_at": {"$type": "string"}}`,
+			`[{"$set": {"updated_at": {"$toDate": "$updated_at"}}}]`,
+		}}, updatesSent(mt, "users"))
+	})
+
+	mt.Run("reports a failed update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 241, Message: "cannot convert"}))
+		assert.Error(mt, userUpdatedAtDates(context.Background(), mt.DB))
+	})
+}
+
+func TestUserMigrations_DownIsNoOp(t *testing.T) {
+	for _, migration := range All() {
+		if migration.Version == 10 || migration.Version == 11 {
+			assert.NoError(t, migration.Down(context.Background(), nil), migration.Name)
+		}
+	}
+}
+
+// indexCommands returns the index commands sent, as the command name and the
+// names of the indexes it creates or drops.
+func indexCommands(mt *mtest.T) []string {
+	var commands []string
+	for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+		switch event.CommandName {
+		case "createIndexes":
+			indexes, err := event.Command.Lookup("indexes").Array().Values()
+			require.NoError(mt, err)
+			for _, index := range indexes {
+				commands = append(commands, "create "+index.Document().Lookup("name").StringValue())
+			}
+		case "dropIndexes":
+			commands = append(commands, "drop "+event.Command.Lookup("index").StringValue())
+		}
+	}
+	return commands
+}
+
+func TestContentUpdatedAtIndex_ReplacesUpdatedAtIndex(t *testing.T) {
+	var migration Migration
+	for _, m := range All() {
+		if m.Version == 14 {
+			migration = m
+		}
+	}
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("up", func(mt *mtest.T) {
+		require.NoError(mt, runMigration(mt, migration.Up, 2))
+		assert.Equal(mt, []string{
+			"create auto_enrich_1_content_updated_at_1",
+			"drop auto_enrich_1_updated_at_1",
+		}, indexCommands(mt))
+	})
+
+	mt.Run("up with the old index already gone", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: codeIndexNotFound, Message: "index not found"}),
+		)
+		assert.NoError(mt, migration.Up(context.Background(), mt.DB))
+	})
+
+	mt.Run("down", func(mt *mtest.T) {
+		require.NoError(mt, runMigration(mt, migration.Down, 2))
+		assert.Equal(mt, []string{
+			"create auto_enrich_1_updated_at_1",
+			"drop auto_enrich_1_content_updated_at_1",
+		}, indexCommands(mt))
+	})
+}
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage describes the migrate subcommand.
+const Usage = `usage: migrate up | down [steps] | status
+
+  up      apply every pending migration
+  down    roll back the latest applied migration, or the latest steps of them
+  status  list the migrations and whether each has been applied`
+
+var ErrUsage = errors.New(Usage)
+
+// RunCommand runs the migrate subcommand given by args and reports to out.
+func RunCommand(ctx context.Context, m *Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return ErrUsage
+		}
+		applied, err := m.Up(ctx)
+		report(out, "applied", applied)
+		return err
+	case "down":
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return ErrUsage
+			}
+			steps = n
+		} else if len(args) > 2 {
+			return ErrUsage
+		}
+		rolledBack, err := m.Down(ctx, steps)
+		report(out, "rolled back", rolledBack)
+		return err
+	case "status":
+		if len(args) != 1 {
+			return ErrUsage
+		}
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.Applied_at.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return ErrUsage
+	}
+}
+
+func report(out io.Writer, verb string, migrations []Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(out, "nothing %s\n", verb)
+		return
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(out, "%s %d %s\n", verb, m.Version, m.Name)
+	}
+}
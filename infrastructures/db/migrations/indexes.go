@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// error codes the server returns when there is nothing to drop
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+)
+
+// CollectionIndexes are the indexes declared on one collection.
+type CollectionIndexes struct {
+	Collection string
+	Indexes    []mongo.IndexModel
+}
+
+// Indexes returns a migration creating indexes, and dropping them on the way
+// down. Creating an index that already exists with the same definition is a
+// no-op, so indexes created before migrations existed are adopted as they are.
+// Unnamed indexes get the name the server would give them.
+func Indexes(version int, name string, collections ...CollectionIndexes) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for _, c := range collections {
+				if _, err := db.Collection(c.Collection).Indexes().CreateMany(ctx, c.Indexes); err != nil {
+					return fmt.Errorf("%s: %w", c.Collection, err)
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, c := range collections {
+				for _, index := range c.Indexes {
+					_, err := db.Collection(c.Collection).Indexes().DropOne(ctx, IndexName(index))
+					if err != nil && !isNotFound(err) {
+						return fmt.Errorf("%s: %w", c.Collection, err)
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// replacingIndex extends m to drop an index it makes unused from collection
+// once it is up, and to create that index again before it goes down.
+func replacingIndex(m Migration, collection string, replaced mongo.IndexModel) Migration {
+	up, down := m.Up, m.Down
+	m.Up = func(ctx context.Context, db *mongo.Database) error {
+		if err := up(ctx, db); err != nil {
+			return err
+		}
+		_, err := db.Collection(collection).Indexes().DropOne(ctx, IndexName(replaced))
+		if err != nil && !isNotFound(err) {
+			return fmt.Errorf("%s: %w", collection, err)
+		}
+		return nil
+	}
+	m.Down = func(ctx context.Context, db *mongo.Database) error {
+		if _, err := db.Collection(collection).Indexes().CreateOne(ctx, replaced); err != nil {
+			return fmt.Errorf("%s: %w", collection, err)
+		}
+		return down(ctx, db)
+	}
+	return m
+}
+
+// IndexName returns the index's name, or the one generated for its keys, e.g.
+// blog_id_1_user_id_1.
+func IndexName(index mongo.IndexModel) string {
+	if index.Options != nil && index.Options.Name != nil {
+		return *index.Options.Name
+	}
+	keys, ok := index.Keys.(bson.D)
+	if !ok {
+		return ""
+	}
+	parts := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+func isNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == codeIndexNotFound || cmdErr.Code == codeNamespaceNotFound
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+// Package migrations evolves the database schema through ordered, versioned
+// migrations. Applied versions are recorded in the schema_migrations
+// collection. Every migration is idempotent, so one interrupted half way, or
+// run by two instances starting together, can simply be run again.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection records the applied migrations.
+const Collection = "schema_migrations"
+
+var (
+	ErrIrreversible     = errors.New("migration cannot be rolled back")
+	ErrInvalidMigration = errors.New("invalid migration")
+)
+
+// Func changes the schema of db.
+type Func func(ctx context.Context, db *mongo.Database) error
+
+// Migration is one step of the schema. Down undoes Up; without it the
+// migration is irreversible.
+type Migration struct {
+	Version int
+	Name    string
+	Up      Func
+	Down    Func
+}
+
+// Status tells whether a migration has been applied, and when.
+type Status struct {
+	Version    int
+	Name       string
+	Applied    bool
+	Applied_at time.Time
+}
+
+// Record is a row of the schema_migrations collection.
+type Record struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	Applied_at time.Time `bson:"applied_at"`
+}
+
+type store interface {
+	Applied(ctx context.Context) (map[int]Record, error)
+	Save(ctx context.Context, record Record) error
+	Delete(ctx context.Context, version int) error
+}
+
+// Migrator applies and rolls back migrations.
+type Migrator struct {
+	db         *mongo.Database
+	store      store
+	migrations []Migration
+	now        func() time.Time
+}
+
+// New returns a migrator for migrations against db. Versions must be positive
+// and unique; they are applied in ascending order.
+func New(db *mongo.Database, migrations []Migration) (*Migrator, error) {
+	return newMigrator(db, &mongoStore{collection: db.Collection(Collection)}, migrations)
+}
+
+func newMigrator(db *mongo.Database, store store, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 || m.Name == "" || m.Up == nil {
+			return nil, fmt.Errorf("%w: version %d %q needs a positive version, a name and an up step", ErrInvalidMigration, m.Version, m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("%w: version %d is used twice", ErrInvalidMigration, m.Version)
+		}
+	}
+	return &Migrator{db: db, store: store, migrations: sorted, now: time.Now}, nil
+}
+
+// Status lists every migration in order with whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		statuses[i] = Status{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			Applied:    ok,
+			Applied_at: record.Applied_at,
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in order and returns those applied. It
+// stops at the first failure, leaving later migrations pending.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, migration, "up", migration.Up); err != nil {
+			return done, err
+		}
+		if err := m.store.Save(ctx, Record{Version: migration.Version, Name: migration.Name, Applied_at: m.now()}); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the latest steps applied migrations, newest first, and
+// returns those rolled back. It stops at an irreversible migration.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("%w: %d %s", ErrIrreversible, migration.Version, migration.Name)
+		}
+		if err := m.run(ctx, migration, "down", migration.Down); err != nil {
+			return done, err
+		}
+		if err := m.store.Delete(ctx, migration.Version); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration, direction string, fn Func) error {
+	start := time.Now()
+	if err := fn(ctx, m.db); err != nil {
+		return fmt.Errorf("migration %d %s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+	slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name, "direction", direction, "duration", time.Since(start))
+	return nil
+}
+
+type mongoStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoStore) Applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Save upserts, so an instance racing another one records the version once.
+func (s *mongoStore) Save(ctx context.Context, record Record) error {
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": record.Version}, record, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *mongoStore) Delete(ctx context.Context, version int) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": version})
+	return err
+}
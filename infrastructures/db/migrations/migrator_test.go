@@ -0,0 +1,182 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type memoryStore struct {
+	records map[int]Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[int]Record)}
+}
+
+func (s *memoryStore) Applied(ctx context.Context) (map[int]Record, error) {
+	applied := make(map[int]Record, len(s.records))
+	for version, record := range s.records {
+		applied[version] = record
+	}
+	return applied, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, record Record) error {
+	s.records[record.Version] = record
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, version int) error {
+	delete(s.records, version)
+	return nil
+}
+
+func (s *memoryStore) versions() []int {
+	var versions []int
+	for version := range s.records {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// recorder builds migrations that log their steps.
+type recorder struct {
+	steps []string
+}
+
+func (r *recorder) migration(version int, name string) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			r.steps = append(r.steps, "up "+name)
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			r.steps = append(r.steps, "down "+name)
+			return nil
+		},
+	}
+}
+
+func TestMigrator_UpAppliesPendingInOrder(t *testing.T) {
+	rec := &recorder{}
+	store := newMemoryStore()
+	store.records[2] = Record{Version: 2, Name: "two"}
+	m, err := newMigrator(nil, store, []Migration{rec.migration(3, "three"), rec.migration(1, "one"), rec.migration(2, "two")})
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []string{"up one", "up three"}, rec.steps)
+	assert.Equal(t, []int{1, 2, 3}, store.versions())
+
+	// running it again finds nothing to do
+	applied, err = m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrator_UpStopsAtFailure(t *testing.T) {
+	rec := &recorder{}
+	failure := errors.New("duplicate key")
+	broken := rec.migration(2, "two")
+	broken.Up = func(ctx context.Context, db *mongo.Database) error { return failure }
+	store := newMemoryStore()
+	m, err := newMigrator(nil, store, []Migration{rec.migration(1, "one"), broken, rec.migration(3, "three")})
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background())
+	assert.ErrorIs(t, err, failure)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, []int{1}, store.versions())
+}
+
+func TestMigrator_DownRollsBackNewestFirst(t *testing.T) {
+	rec := &recorder{}
+	store := newMemoryStore()
+	m, err := newMigrator(nil, store, []Migration{rec.migration(1, "one"), rec.migration(2, "two"), rec.migration(3, "three")})
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	rec.steps = nil
+
+	rolledBack, err := m.Down(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, 2)
+	assert.Equal(t, []string{"down three", "down two"}, rec.steps)
+	assert.Equal(t, []int{1}, store.versions())
+}
+
+func TestMigrator_DownStopsAtIrreversible(t *testing.T) {
+	rec := &recorder{}
+	oneWay := rec.migration(2, "two")
+	oneWay.Down = nil
+	store := newMemoryStore()
+	m, err := newMigrator(nil, store, []Migration{rec.migration(1, "one"), oneWay})
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+
+	_, err = m.Down(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrIrreversible)
+	assert.Equal(t, []int{1, 2}, store.versions())
+}
+
+func TestMigrator_RejectsDuplicateVersions(t *testing.T) {
+	rec := &recorder{}
+	_, err := newMigrator(nil, newMemoryStore(), []Migration{rec.migration(1, "one"), rec.migration(1, "again")})
+	assert.ErrorIs(t, err, ErrInvalidMigration)
+}
+
+func TestAll_IsValid(t *testing.T) {
+	_, err := newMigrator(nil, newMemoryStore(), All())
+	assert.NoError(t, err)
+}
+
+func TestIndexName(t *testing.T) {
+	assert.Equal(t, "blog_id_1_user_id_1", IndexName(mongo.IndexModel{
+		Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}},
+	}))
+	assert.Equal(t, "created_at_-1", IndexName(mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}}))
+	assert.Equal(t, "blogs_text", IndexName(mongo.IndexModel{
+		Keys:    bson.D{{Key: "title", Value: "text"}},
+		Options: options.Index().SetName("blogs_text"),
+	}))
+}
+
+func TestRunCommand(t *testing.T) {
+	rec := &recorder{}
+	m, err := newMigrator(nil, newMemoryStore(), []Migration{rec.migration(1, "one"), rec.migration(2, "two")})
+	require.NoError(t, err)
+	m.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	assert.NoError(t, RunCommand(ctx, m, []string{"up"}, &out))
+	assert.Equal(t, "applied 1 one\napplied 2 two\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, RunCommand(ctx, m, []string{"down"}, &out))
+	assert.Equal(t, "rolled back 2 two\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, RunCommand(ctx, m, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "1        one   2025-01-02T03:04:05Z")
+	assert.Contains(t, out.String(), "2        two   pending")
+
+	assert.ErrorIs(t, RunCommand(ctx, m, []string{"down", "zero"}, &out), ErrUsage)
+	assert.ErrorIs(t, RunCommand(ctx, m, []string{"sideways"}, &out), ErrUsage)
+}
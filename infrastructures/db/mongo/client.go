@@ -2,10 +2,14 @@ package mongo
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/InkForge/Blog_Website/infrastructures"
+	"github.com/InkForge/Blog_Website/infrastructures/db/monitor"
+	"github.com/InkForge/Blog_Website/infrastructures/metrics"
+	"github.com/InkForge/Blog_Website/infrastructures/tracing"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -17,13 +21,15 @@ func NewMongoClient() *mongo.Client {
 	// load configuration
 	cfg, err := infrastructures.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
 	}
 
 	// connect to MongoDB using the URI from config
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.DBUri))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.DBUri).SetMonitor(monitor.Combine(metrics.NewMongoMonitor(), tracing.NewMongoMonitor())))
 	if err != nil {
-		log.Fatalf("Mongo connection error: %v", err)
+		slog.Error("mongo connection failed", "error", err)
+		os.Exit(1)
 	}
 
 	return client
@@ -0,0 +1,87 @@
+// Package monitor attributes Mongo commands to the repository methods that
+// issue them, for the metrics and tracing command monitors.
+package monitor
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// prefix of the functions commands are attributed to
+const repositoriesPackage = "github.com/InkForge/Blog_Website/repositories"
+
+// CallingRepository returns the innermost repository function on the stack.
+// The driver calls a monitor's Started on the goroutine running the command,
+// so called from there it finds the method that issued the command.
+func CallingRepository() (repository, method string, ok bool) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if name, found := strings.CutPrefix(frame.Function, repositoriesPackage); found {
+			repository, method = splitFunction(name)
+			return repository, method, true
+		}
+		if !more {
+			return "", "", false
+		}
+	}
+}
+
+// splitFunction turns the part of a function name after the repositories
+// package path, e.g. ".(*BlogRepository).GetByID.func1" or
+// "/mongo.(*MongoTransactionManager).WithTransaction", into its receiver and
+// method. Plain functions have an empty receiver.
+func splitFunction(name string) (receiver, method string) {
+	// drop the subpackage, if any
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	// drop closures
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	receiver, method, found := strings.Cut(name, ".")
+	if !found {
+		return "", receiver
+	}
+	receiver = strings.TrimSuffix(strings.TrimPrefix(receiver, "(*"), ")")
+	if i := strings.Index(method, "."); i >= 0 {
+		method = method[:i]
+	}
+	return receiver, method
+}
+
+// Combine returns a monitor passing every event to each of monitors.
+func Combine(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestSplitFunction(t *testing.T) {
+	for in, want := range map[string][2]string{
+		".(*BlogRepository).GetByID":                                {"BlogRepository", "GetByID"},
+		".(*BlogRepository).FindPublished.func1":                    {"BlogRepository", "FindPublished"},
+		".CommentMongoRepository.GetByID":                           {"CommentMongoRepository", "GetByID"},
+		"/mongo.(*MongoTransactionManager).WithTransaction.func1.1": {"MongoTransactionManager", "WithTransaction"},
+		".NewBlogMongoRepository":                                   {"", "NewBlogMongoRepository"},
+	} {
+		receiver, method := splitFunction(in)
+		assert.Equal(t, want, [2]string{receiver, method}, in)
+	}
+}
+
+func TestCallingRepository_OutsideRepositories(t *testing.T) {
+	_, _, ok := CallingRepository()
+	assert.False(t, ok)
+}
+
+func TestCombine(t *testing.T) {
+	var calls []string
+	first := &event.CommandMonitor{
+		Started:   func(context.Context, *event.CommandStartedEvent) { calls = append(calls, "first started") },
+		Succeeded: func(context.Context, *event.CommandSucceededEvent) { calls = append(calls, "first succeeded") },
+	}
+	second := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { calls = append(calls, "second started") },
+		Failed:  func(context.Context, *event.CommandFailedEvent) { calls = append(calls, "second failed") },
+	}
+	m := Combine(first, second)
+
+	m.Started(context.Background(), &event.CommandStartedEvent{})
+	m.Succeeded(context.Background(), &event.CommandSucceededEvent{})
+	m.Failed(context.Background(), &event.CommandFailedEvent{})
+
+	assert.Equal(t, []string{"first started", "second started", "first succeeded", "second failed"}, calls)
+}
@@ -0,0 +1,365 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/infrastructures/ttlcache"
+)
+
+// names of the transactional emails
+const (
+	VerifyEmail       = domain.EmailTemplateVerifyEmail
+	PasswordReset     = domain.EmailTemplatePasswordReset
+	Digest            = domain.EmailTemplateDigest
+	NewsletterConfirm = domain.EmailTemplateNewsletterConfirm
+	NewsletterIssue   = domain.EmailTemplateNewsletterIssue
+)
+
+// DefaultLocale is used when a user has no language or one without templates.
+const DefaultLocale = "en"
+
+// DefaultOverrideTTL bounds how stale an admin's template edit can be on the
+// instances that did not save it.
+const DefaultOverrideTTL = 30 * time.Second
+
+// Every locale directory holds <name>.subject.tmpl, <name>.html.tmpl and
+// <name>.txt.tmpl per email, plus the footer partials. The layouts and the
+// other partials are shared by all locales.
+//
+//go:embed templates
+var defaultTemplates embed.FS
+
+// sampleData holds the fields each email is sent with. Previews render it, and
+// an override that refers to any other field fails validation.
+var sampleData = map[string]map[string]interface{}{
+	VerifyEmail:   {"Name": "Jane", "Link": "https://example.com/auth/verify?token=sample"},
+	PasswordReset: {"Name": "Jane", "Link": "https://example.com/auth/forget?token=sample"},
+	Digest: {
+		"Name":            "Jane",
+		"Link":            "https://example.com/blogs",
+		"UnsubscribeLink": "https://example.com/digests/unsubscribe?token=sample",
+		"Frequency":       "weekly",
+		"Posts": []map[string]interface{}{
+			{"Title": "Writing in public", "Summary": "Why sharing drafts early pays off.", "Link": "https://example.com/blogs/1"},
+			{"Title": "A short post", "Summary": "", "Link": "https://example.com/blogs/2"},
+		},
+	},
+	NewsletterConfirm: {
+		"Name":       "",
+		"Link":       "https://example.com/newsletter/confirm?token=sample",
+		"AuthorName": "Jane Doe",
+	},
+	NewsletterIssue: {
+		"Name":            "",
+		"Link":            "https://example.com/blogs/1",
+		"Title":           "Writing in public",
+		"Excerpt":         "Why sharing drafts early pays off.",
+		"AuthorName":      "Jane Doe",
+		"UnsubscribeLink": "https://example.com/newsletter/unsubscribe?token=sample",
+	},
+}
+
+var templateFuncs = map[string]interface{}{
+	// dict builds the argument of a partial, e.g. (dict "URL" .Link "Label" "Go")
+	"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict needs key and value pairs")
+		}
+		values := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, errors.New("dict keys must be strings")
+			}
+			values[key] = pairs[i+1]
+		}
+		return values, nil
+	},
+}
+
+// more than one blank line in a text email is left over from the layout
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+type templateKey struct {
+	name   string
+	locale string
+}
+
+type compiledEmail struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// Renderer renders emails from admin overrides, falling back to the embedded
+// templates.
+type Renderer struct {
+	repo        domain.IEmailTemplateRepository
+	appName     string
+	overrideTTL time.Duration
+	defaults    map[templateKey]domain.EmailTemplate
+	compiled    map[templateKey]*compiledEmail
+	locales     []string
+	now         func() time.Time
+	// overrides holds nil for a template without a usable override
+	overrides *ttlcache.Cache[templateKey, *compiledEmail]
+}
+
+// NewRenderer returns a renderer that looks for admin overrides in repo, or
+// sends only the built-in emails when repo is nil. Every template can use
+// appName as .AppName.
+func NewRenderer(repo domain.IEmailTemplateRepository, appName string, overrideTTL time.Duration) domain.IEmailRenderer {
+	return newRenderer(repo, appName, overrideTTL)
+}
+
+func newRenderer(repo domain.IEmailTemplateRepository, appName string, overrideTTL time.Duration) *Renderer {
+	if overrideTTL <= 0 {
+		overrideTTL = DefaultOverrideTTL
+	}
+	r := &Renderer{
+		repo:        repo,
+		appName:     appName,
+		overrideTTL: overrideTTL,
+		defaults:    loadDefaults(),
+		compiled:    make(map[templateKey]*compiledEmail),
+		now:         time.Now,
+	}
+	r.overrides = ttlcache.New[templateKey, *compiledEmail](overrideTTL, func() time.Time { return r.now() })
+
+	seen := make(map[string]bool)
+	for key, template := range r.defaults {
+		if !seen[key.locale] {
+			seen[key.locale] = true
+			r.locales = append(r.locales, key.locale)
+		}
+		// a broken embedded template is a bug, not something to recover from
+		compiled, err := compile(template)
+		if err != nil {
+			panic(fmt.Sprintf("email template %s/%s: %v", key.locale, key.name, err))
+		}
+		r.compiled[key] = compiled
+	}
+	sort.Strings(r.locales)
+	return r
+}
+
+func loadDefaults() map[templateKey]domain.EmailTemplate {
+	defaults := make(map[templateKey]domain.EmailTemplate)
+	locales, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+		subjects, err := fs.Glob(defaultTemplates, path.Join("templates", locale.Name(), "*.subject.tmpl"))
+		if err != nil {
+			panic(err)
+		}
+		for _, subject := range subjects {
+			name := strings.TrimSuffix(path.Base(subject), ".subject.tmpl")
+			defaults[templateKey{name: name, locale: locale.Name()}] = domain.EmailTemplate{
+				Name:    name,
+				Locale:  locale.Name(),
+				Subject: readTemplate(subject),
+				Html:    readTemplate(path.Join("templates", locale.Name(), name+".html.tmpl")),
+				Text:    readTemplate(path.Join("templates", locale.Name(), name+".txt.tmpl")),
+			}
+		}
+	}
+	return defaults
+}
+
+func readTemplate(name string) string {
+	body, err := defaultTemplates.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(body)
+}
+
+// Render executes name in the locale closest to locale. If the override fails
+// to execute with data, the email is still sent from the built-in template
+// and the failure is only logged.
+func (r *Renderer) Render(ctx context.Context, name, locale string, data map[string]interface{}) (domain.RenderedEmail, error) {
+	locale = r.resolveLocale(name, locale)
+	key := templateKey{name: name, locale: locale}
+	compiled, ok := r.compiled[key]
+	if !ok {
+		return domain.RenderedEmail{}, domain.ErrUnknownEmailTemplate
+	}
+
+	if override := r.override(ctx, key); override != nil {
+		rendered, err := r.execute(override, locale, data)
+		if err == nil {
+			return rendered, nil
+		}
+		slog.WarnContext(ctx, "email template override failed, using the default", "template", name, "locale", locale, "error", err)
+	}
+	return r.execute(compiled, locale, data)
+}
+
+func (r *Renderer) Preview(template domain.EmailTemplate) (domain.RenderedEmail, error) {
+	if _, ok := r.defaults[templateKey{name: template.Name, locale: template.Locale}]; !ok {
+		return domain.RenderedEmail{}, r.unknown(template.Name, template.Locale)
+	}
+	compiled, err := compile(template)
+	if err != nil {
+		return domain.RenderedEmail{}, err
+	}
+	return r.execute(compiled, template.Locale, sampleData[template.Name])
+}
+
+func (r *Renderer) Validate(template domain.EmailTemplate) error {
+	if strings.TrimSpace(template.Subject) == "" || strings.TrimSpace(template.Html) == "" || strings.TrimSpace(template.Text) == "" {
+		return fmt.Errorf("%w: subject, html and text are required", domain.ErrInvalidEmailTemplate)
+	}
+	_, err := r.Preview(template)
+	return err
+}
+
+func (r *Renderer) Default(name, locale string) (domain.EmailTemplate, bool) {
+	template, ok := r.defaults[templateKey{name: name, locale: locale}]
+	return template, ok
+}
+
+func (r *Renderer) Names() []string {
+	var names []string
+	for key := range r.defaults {
+		if key.locale == DefaultLocale {
+			names = append(names, key.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Renderer) Locales() []string {
+	return append([]string(nil), r.locales...)
+}
+
+func (r *Renderer) Invalidate(name, locale string) {
+	r.overrides.Invalidate(templateKey{name: name, locale: locale})
+}
+
+// unknown tells an unknown email apart from one missing in locale.
+func (r *Renderer) unknown(name, locale string) error {
+	if _, ok := r.defaults[templateKey{name: name, locale: DefaultLocale}]; ok {
+		return domain.ErrUnsupportedLocale
+	}
+	return domain.ErrUnknownEmailTemplate
+}
+
+// resolveLocale picks the template locale for a preference such as "fr-CA":
+// the exact locale, then its language, then the default.
+func (r *Renderer) resolveLocale(name, preferred string) string {
+	preferred = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(preferred), "_", "-"))
+	candidates := []string{preferred}
+	if language, _, ok := strings.Cut(preferred, "-"); ok {
+		candidates = append(candidates, language)
+	}
+	for _, locale := range candidates {
+		if _, ok := r.defaults[templateKey{name: name, locale: locale}]; ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// override returns the compiled admin edit of key, or nil to send the
+// embedded template. A missing, unreadable or broken override all mean nil,
+// and that answer is kept as long as a good one, so a database outage costs
+// one read per template and TTL rather than one per email.
+func (r *Renderer) override(ctx context.Context, key templateKey) *compiledEmail {
+	if r.repo == nil {
+		return nil
+	}
+	return r.overrides.Get(key, func() *compiledEmail {
+		template, err := r.repo.Get(ctx, key.name, key.locale)
+		switch {
+		case errors.Is(err, domain.ErrEmailTemplateNotFound):
+			return nil
+		case err != nil:
+			slog.ErrorContext(ctx, "failed to load email template, using the default", "template", key.name, "locale", key.locale, "error", err)
+			return nil
+		}
+		compiled, err := compile(template)
+		if err != nil {
+			slog.WarnContext(ctx, "email template override is invalid, using the default", "template", key.name, "locale", key.locale, "error", err)
+			return nil
+		}
+		return compiled
+	})
+}
+
+func (r *Renderer) execute(compiled *compiledEmail, locale string, data map[string]interface{}) (domain.RenderedEmail, error) {
+	values := make(map[string]interface{}, len(data)+1)
+	values["AppName"] = r.appName
+	for k, v := range data {
+		values[k] = v
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := compiled.subject.Execute(&subject, values); err != nil {
+		return domain.RenderedEmail{}, fmt.Errorf("%w: subject: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+	if err := compiled.html.ExecuteTemplate(&html, "layout", values); err != nil {
+		return domain.RenderedEmail{}, fmt.Errorf("%w: html: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+	if err := compiled.text.ExecuteTemplate(&text, "layout", values); err != nil {
+		return domain.RenderedEmail{}, fmt.Errorf("%w: text: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+
+	return domain.RenderedEmail{
+		// a subject is a single header line
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    strings.TrimSpace(html.String()),
+		Text:    strings.TrimSpace(blankLines.ReplaceAllString(text.String(), "\n\n")),
+		Locale:  locale,
+	}, nil
+}
+
+// compile parses template together with the layouts and partials of its locale.
+func compile(template domain.EmailTemplate) (*compiledEmail, error) {
+	localeDir := path.Join("templates", template.Locale)
+
+	subject, err := texttemplate.New("subject").Option("missingkey=error").Parse(template.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+
+	html, err := htmltemplate.New("layout").Funcs(templateFuncs).Option("missingkey=error").ParseFS(defaultTemplates,
+		"templates/layout.html.tmpl", "templates/partials.html.tmpl", path.Join(localeDir, "footer.html.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := html.New("content").Parse(template.Html); err != nil {
+		return nil, fmt.Errorf("%w: html: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+
+	text, err := texttemplate.New("layout").Funcs(templateFuncs).Option("missingkey=error").ParseFS(defaultTemplates,
+		"templates/layout.txt.tmpl", path.Join(localeDir, "footer.txt.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.New("content").Parse(template.Text); err != nil {
+		return nil, fmt.Errorf("%w: text: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+
+	return &compiledEmail{subject: subject, html: html, text: text}, nil
+}
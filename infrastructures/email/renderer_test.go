@@ -0,0 +1,220 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTemplateRepository struct {
+	templates map[templateKey]domain.EmailTemplate
+	getErr    error
+	reads     int
+}
+
+func newMemoryTemplateRepository() *memoryTemplateRepository {
+	return &memoryTemplateRepository{templates: make(map[templateKey]domain.EmailTemplate)}
+}
+
+func (m *memoryTemplateRepository) Get(ctx context.Context, name, locale string) (domain.EmailTemplate, error) {
+	m.reads++
+	if m.getErr != nil {
+		return domain.EmailTemplate{}, m.getErr
+	}
+	t, ok := m.templates[templateKey{name: name, locale: locale}]
+	if !ok {
+		return domain.EmailTemplate{}, domain.ErrEmailTemplateNotFound
+	}
+	return t, nil
+}
+
+func (m *memoryTemplateRepository) List(ctx context.Context) ([]domain.EmailTemplate, error) {
+	return nil, nil
+}
+
+func (m *memoryTemplateRepository) Upsert(ctx context.Context, t domain.EmailTemplate) error {
+	m.templates[templateKey{name: t.Name, locale: t.Locale}] = t
+	return nil
+}
+
+func (m *memoryTemplateRepository) Delete(ctx context.Context, name, locale string) error {
+	delete(m.templates, templateKey{name: name, locale: locale})
+	return nil
+}
+
+func TestRenderer_RendersEmbeddedTemplate(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+
+	email, err := r.Render(context.Background(), VerifyEmail, "", map[string]interface{}{
+		"Name": "Jane",
+		"Link": "https://example.com/auth/verify?token=a&b=<c>",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "en", email.Locale)
+	assert.Equal(t, "Verify your email address", email.Subject)
+	assert.Contains(t, email.HTML, "<!DOCTYPE html>")
+	assert.Contains(t, email.HTML, "Welcome, Jane!")
+	assert.Contains(t, email.HTML, `href="https://example.com/auth/verify?token=a&amp;b=%3cc%3e"`)
+	assert.Contains(t, email.HTML, "The InkForge Team")
+	assert.NotContains(t, email.Text, "<p>")
+	assert.Contains(t, email.Text, "https://example.com/auth/verify?token=a&b=<c>")
+	assert.Contains(t, email.Text, "ignore this email.\n\n— The InkForge Team")
+}
+
+func TestRenderer_RendersDigest(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+
+	email, err := r.Render(context.Background(), Digest, "en", sampleData[Digest])
+
+	require.NoError(t, err)
+	assert.Equal(t, "Your weekly InkForge digest: 2 new posts", email.Subject)
+	assert.Contains(t, email.HTML, `<a href="https://example.com/blogs/1" style="color: #333333;">Writing in public</a>`)
+	assert.Contains(t, email.HTML, `href="https://example.com/digests/unsubscribe?token=sample"`)
+	assert.Contains(t, email.Text, "* Writing in public\n  Why sharing drafts early pays off.\n  https://example.com/blogs/1\n")
+	assert.Contains(t, email.Text, "* A short post\n  https://example.com/blogs/2\n")
+	assert.Contains(t, email.Text, "Unsubscribe: https://example.com/digests/unsubscribe?token=sample")
+}
+
+func TestRenderer_RendersNewsletterIssue(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+
+	email, err := r.Render(context.Background(), NewsletterIssue, "fr", sampleData[NewsletterIssue])
+
+	require.NoError(t, err)
+	assert.Equal(t, "Writing in public", email.Subject)
+	assert.Contains(t, email.HTML, "par Jane Doe")
+	assert.Contains(t, email.Text, "Why sharing drafts early pays off.")
+	assert.Contains(t, email.Text, "Se désabonner : https://example.com/newsletter/unsubscribe?token=sample")
+}
+
+func TestRenderer_ChoosesClosestLocale(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+	data := map[string]interface{}{"Name": "", "Link": "https://example.com"}
+
+	for preferred, want := range map[string]string{"fr": "fr", "fr-CA": "fr", "FR_be": "fr", "de": "en", "": "en"} {
+		email, err := r.Render(context.Background(), PasswordReset, preferred, data)
+		require.NoError(t, err, preferred)
+		assert.Equal(t, want, email.Locale, preferred)
+	}
+
+	email, err := r.Render(context.Background(), PasswordReset, "fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Réinitialisez votre mot de passe", email.Subject)
+	assert.Contains(t, email.Text, "L’équipe InkForge")
+}
+
+func TestRenderer_EveryLocaleHasEveryTemplate(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+	assert.Equal(t, []string{"en", "fr"}, r.Locales())
+	assert.Len(t, r.Names(), len(sampleData))
+
+	for _, locale := range r.Locales() {
+		for _, name := range r.Names() {
+			template, ok := r.Default(name, locale)
+			require.True(t, ok, locale+"/"+name)
+			assert.NoError(t, r.Validate(template), locale+"/"+name)
+		}
+	}
+}
+
+func TestRenderer_MissingDataFails(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+
+	_, err := r.Render(context.Background(), VerifyEmail, "en", map[string]interface{}{"Name": "Jane"})
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailTemplate)
+
+	_, err = r.Render(context.Background(), "nope", "en", nil)
+	assert.ErrorIs(t, err, domain.ErrUnknownEmailTemplate)
+}
+
+func TestRenderer_ValidateRejectsBadTemplates(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+	valid := domain.EmailTemplate{Name: VerifyEmail, Locale: "en", Subject: "Hi", Html: "<p>{{.Link}}</p>", Text: "{{.Link}}"}
+	require.NoError(t, r.Validate(valid))
+
+	broken := valid
+	broken.Html = "<p>{{.Link</p>"
+	assert.ErrorIs(t, r.Validate(broken), domain.ErrInvalidEmailTemplate)
+
+	unknownField := valid
+	unknownField.Text = "{{.Token}}"
+	assert.ErrorIs(t, r.Validate(unknownField), domain.ErrInvalidEmailTemplate)
+
+	noText := valid
+	noText.Text = " "
+	assert.ErrorIs(t, r.Validate(noText), domain.ErrInvalidEmailTemplate)
+
+	otherLocale := valid
+	otherLocale.Locale = "de"
+	assert.ErrorIs(t, r.Validate(otherLocale), domain.ErrUnsupportedLocale)
+
+	otherName := valid
+	otherName.Name = "nope"
+	assert.ErrorIs(t, r.Validate(otherName), domain.ErrUnknownEmailTemplate)
+}
+
+func TestRenderer_UsesOverrideUntilInvalidated(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	r := newRenderer(repo, "InkForge", time.Minute)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	data := map[string]interface{}{"Name": "Jane", "Link": "https://example.com"}
+
+	_ = repo.Upsert(context.Background(), domain.EmailTemplate{
+		Name: VerifyEmail, Locale: "fr", Subject: "Bienvenue {{.Name}}",
+		Html: `{{template "button" (dict "URL" .Link "Label" "Go" "Color" "red")}}`, Text: "Lien : {{.Link}}",
+	})
+	email, err := r.Render(context.Background(), VerifyEmail, "fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Bienvenue Jane", email.Subject)
+	assert.Contains(t, email.HTML, ">Go</a>")
+	assert.Contains(t, email.Text, "L’équipe InkForge")
+
+	// cached until invalidated or expired
+	_ = repo.Delete(context.Background(), VerifyEmail, "fr")
+	_, _ = r.Render(context.Background(), VerifyEmail, "fr", data)
+	assert.Equal(t, 1, repo.reads)
+
+	r.Invalidate(VerifyEmail, "fr")
+	email, err = r.Render(context.Background(), VerifyEmail, "fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Confirmez votre adresse e-mail", email.Subject)
+	assert.Equal(t, 2, repo.reads)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = r.Render(context.Background(), VerifyEmail, "fr", data)
+	assert.Equal(t, 3, repo.reads)
+}
+
+func TestRenderer_FallsBackWhenOverrideFails(t *testing.T) {
+	repo := newMemoryTemplateRepository()
+	_ = repo.Upsert(context.Background(), domain.EmailTemplate{Name: PasswordReset, Locale: "en", Subject: "x", Html: "{{.Missing}}", Text: "x"})
+	r := newRenderer(repo, "InkForge", 0)
+	data := map[string]interface{}{"Name": "", "Link": "https://example.com"}
+
+	email, err := r.Render(context.Background(), PasswordReset, "en", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your password", email.Subject)
+
+	repo.getErr = errors.New("connection refused")
+	r.Invalidate(PasswordReset, "en")
+	email, err = r.Render(context.Background(), PasswordReset, "en", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your password", email.Subject)
+}
+
+func TestRenderer_PreviewUsesSampleData(t *testing.T) {
+	r := newRenderer(nil, "InkForge", 0)
+	template, _ := r.Default(PasswordReset, "fr")
+
+	email, err := r.Preview(template)
+	require.NoError(t, err)
+	assert.Contains(t, email.HTML, "Bonjour Jane")
+	assert.Contains(t, email.Text, "https://example.com/auth/forget?token=sample")
+}
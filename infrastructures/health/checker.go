@@ -0,0 +1,127 @@
+// Package health checks the service's dependencies for the readiness probe.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// Probe reports whether a dependency is usable.
+type Probe func(ctx context.Context) error
+
+// Check is a named probe. A failing critical check makes the service down,
+// any other failing check only degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    Probe
+}
+
+// Checker implements domain.IHealthChecker
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker returns a checker running every check concurrently, each with
+// timeout.
+func NewChecker(timeout time.Duration, checks ...Check) domain.IHealthChecker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+func (c *Checker) Check(ctx context.Context) domain.HealthReport {
+	results := make([]domain.DependencyHealth, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := domain.HealthReport{Status: domain.HealthUp, Checks: results}
+	for _, r := range results {
+		switch {
+		case r.Status == domain.HealthUp:
+		case r.Critical:
+			report.Status = domain.HealthDown
+		case report.Status == domain.HealthUp:
+			report.Status = domain.HealthDegraded
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) domain.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := domain.DependencyHealth{
+		Name:     check.Name,
+		Critical: check.Critical,
+		Status:   domain.HealthUp,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		result.Status = domain.HealthDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// MongoProbe pings the primary.
+func MongoProbe(client *mongo.Client) Probe {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// TCPProbe opens, and closes, a connection to addr, e.g. an SMTP server.
+func TCPProbe(addr string) Probe {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// AIProbe fails while the circuit of any AI provider is open.
+func AIProbe(monitor domain.IAIProviderMonitor) Probe {
+	return func(ctx context.Context) error {
+		providers := monitor.ProviderHealth()
+		if len(providers) == 0 {
+			return errors.New("no AI providers configured")
+		}
+		var open []string
+		for _, p := range providers {
+			if p.State == "open" {
+				open = append(open, p.Name)
+			}
+		}
+		if len(open) > 0 {
+			return fmt.Errorf("circuit open for %s", strings.Join(open, ", "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probeReturning(err error) Probe {
+	return func(ctx context.Context) error { return err }
+}
+
+func TestChecker_UpWhenEveryCheckPasses(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "mongo", Critical: true, Probe: probeReturning(nil)},
+		Check{Name: "smtp", Probe: probeReturning(nil)},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, domain.HealthUp, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "mongo", report.Checks[0].Name)
+	assert.Equal(t, domain.HealthUp, report.Checks[1].Status)
+}
+
+func TestChecker_DegradedWhenOptionalCheckFails(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "mongo", Critical: true, Probe: probeReturning(nil)},
+		Check{Name: "smtp", Probe: probeReturning(errors.New("connection refused"))},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, domain.HealthDegraded, report.Status)
+	assert.Equal(t, domain.HealthDown, report.Checks[1].Status)
+	assert.Equal(t, "connection refused", report.Checks[1].Error)
+}
+
+func TestChecker_DownWhenCriticalCheckFails(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "mongo", Critical: true, Probe: probeReturning(errors.New("no primary"))},
+		Check{Name: "smtp", Probe: probeReturning(errors.New("connection refused"))},
+	)
+
+	assert.Equal(t, domain.HealthDown, checker.Check(context.Background()).Status)
+}
+
+func TestChecker_TimesOutSlowProbes(t *testing.T) {
+	checker := NewChecker(20*time.Millisecond, Check{Name: "slow", Critical: true, Probe: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, domain.HealthDown, report.Status)
+	assert.Contains(t, report.Checks[0].Error, "deadline exceeded")
+}
+
+func TestTCPProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+
+	assert.NoError(t, TCPProbe(addr)(context.Background()))
+
+	ln.Close()
+	assert.Error(t, TCPProbe(addr)(context.Background()))
+}
+
+type fakeMonitor []domain.AIProviderHealth
+
+func (m fakeMonitor) ProviderHealth() []domain.AIProviderHealth { return m }
+
+func TestAIProbe_FailsWhileACircuitIsOpen(t *testing.T) {
+	healthy := fakeMonitor{{Name: "openai", State: "closed"}, {Name: "gemini", State: "half-open"}}
+	assert.NoError(t, AIProbe(healthy)(context.Background()))
+
+	tripped := fakeMonitor{{Name: "openai", State: "open"}, {Name: "gemini", State: "closed"}}
+	assert.EqualError(t, AIProbe(tripped)(context.Background()), "circuit open for openai")
+
+	assert.Error(t, AIProbe(fakeMonitor{})(context.Background()))
+}
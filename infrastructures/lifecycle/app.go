@@ -0,0 +1,204 @@
+// Package lifecycle runs the HTTP server and shuts the process down in order:
+// stop taking traffic, drain in-flight requests, then stop background work
+// and close connections.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrShuttingDown is reported by the readiness probe once shutdown has begun.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// State is where the app is in its life.
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateServing  State = "serving"
+	StateDraining State = "draining"
+	StateStopped  State = "stopped"
+)
+
+// Stopper is anything that is stopped on shutdown, such as a worker queue.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// StopFunc adapts a function to Stopper.
+type StopFunc func(ctx context.Context) error
+
+func (f StopFunc) Stop(ctx context.Context) error { return f(ctx) }
+
+// Options configures the HTTP server and shutdown.
+type Options struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds the whole shutdown, draining requests included.
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long the readiness probe fails before the server stops
+	// accepting connections, giving load balancers time to notice.
+	DrainDelay time.Duration
+	Logger     *slog.Logger
+}
+
+type namedStopper struct {
+	name    string
+	stopper Stopper
+}
+
+// App owns the HTTP server and everything stopped after it.
+type App struct {
+	opts Options
+
+	mu       sync.Mutex
+	state    State
+	stoppers []namedStopper
+}
+
+// New returns an app that has not started serving yet.
+func New(opts Options) *App {
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &App{opts: opts, state: StateStarting}
+}
+
+// OnStop registers stopper to run on shutdown, after the server has drained.
+// Stoppers run one at a time in the reverse of their registration order, so
+// a component registered right after its dependencies stops before them.
+func (a *App) OnStop(name string, stopper Stopper) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stoppers = append(a.stoppers, namedStopper{name: name, stopper: stopper})
+}
+
+// State returns the app's current state.
+func (a *App) State() State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state
+}
+
+// Ready is a readiness probe failing with ErrShuttingDown once shutdown has
+// begun.
+func (a *App) Ready(ctx context.Context) error {
+	switch a.State() {
+	case StateDraining, StateStopped:
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Run listens on the configured address and serves handler until ctx ends or
+// the process receives SIGINT or SIGTERM, then shuts down.
+func (a *App) Run(ctx context.Context, handler http.Handler) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", a.opts.Addr)
+	if err != nil {
+		return errors.Join(err, a.stop())
+	}
+	return a.Serve(ctx, listener, handler)
+}
+
+// Serve serves handler on listener until ctx ends, then drains in-flight
+// requests and runs the registered stoppers. Stoppers also run if the server
+// fails, and their errors are joined with its error.
+func (a *App) Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       a.opts.ReadTimeout,
+		ReadHeaderTimeout: a.opts.ReadHeaderTimeout,
+		WriteTimeout:      a.opts.WriteTimeout,
+		IdleTimeout:       a.opts.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(a.opts.Logger.Handler(), slog.LevelWarn),
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	a.setState(StateServing)
+	a.opts.Logger.Info("server started", "addr", listener.Addr().String())
+
+	select {
+	case err := <-served:
+		a.opts.Logger.Error("server failed", "error", err)
+		return errors.Join(err, a.stop())
+	case <-ctx.Done():
+	}
+
+	a.setState(StateDraining)
+	a.opts.Logger.Info("shutting down", "drain_delay", a.opts.DrainDelay, "timeout", a.opts.ShutdownTimeout)
+	if a.opts.DrainDelay > 0 {
+		time.Sleep(a.opts.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// requests still running past the timeout are cut off
+		a.opts.Logger.Warn("server did not drain in time, closing remaining connections", "error", err)
+		errs = append(errs, err, server.Close())
+	}
+	if err := <-served; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+	errs = append(errs, a.runStoppers(shutdownCtx))
+	a.opts.Logger.Info("shutdown complete")
+	return errors.Join(errs...)
+}
+
+// stop runs the stoppers when the server never served or failed.
+func (a *App) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+	defer cancel()
+	return a.runStoppers(ctx)
+}
+
+func (a *App) runStoppers(ctx context.Context) error {
+	a.mu.Lock()
+	a.state = StateStopped
+	stoppers := append([]namedStopper(nil), a.stoppers...)
+	a.mu.Unlock()
+
+	var errs []error
+	for i := len(stoppers) - 1; i >= 0; i-- {
+		s := stoppers[i]
+		start := time.Now()
+		if err := s.stopper.Stop(ctx); err != nil {
+			a.opts.Logger.Error("stop failed", "component", s.name, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		a.opts.Logger.Info("stopped", "component", s.name, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+func (a *App) setState(state State) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.state = state
+}
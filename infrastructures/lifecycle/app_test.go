@@ -0,0 +1,146 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestApp(opts Options) *App {
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(opts)
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return listener
+}
+
+func TestApp_DrainsInFlightRequestsBeforeStopping(t *testing.T) {
+	app := newTestApp(Options{ShutdownTimeout: 5 * time.Second})
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) StopFunc {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	app.OnStop("mongo", record("mongo"))
+	app.OnStop("queue", record("queue"))
+	app.OnStop("sweep", record("sweep"))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		mu.Lock()
+		order = append(order, "request")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	listener := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(ctx, listener, handler) }()
+
+	responded := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- 0
+			return
+		}
+		resp.Body.Close()
+		responded <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+	assert.Eventually(t, func() bool { return app.State() == StateDraining }, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, app.Ready(context.Background()), ErrShuttingDown)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-responded)
+	assert.NoError(t, <-served)
+	assert.Equal(t, []string{"request", "sweep", "queue", "mongo"}, order)
+	assert.Equal(t, StateStopped, app.State())
+}
+
+func TestApp_ReadyWhileServing(t *testing.T) {
+	app := newTestApp(Options{})
+	listener := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(ctx, listener, http.NotFoundHandler()) }()
+
+	assert.Eventually(t, func() bool { return app.State() == StateServing }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, app.Ready(context.Background()))
+
+	cancel()
+	assert.NoError(t, <-served)
+}
+
+func TestApp_CutsOffRequestsPastTheTimeout(t *testing.T) {
+	app := newTestApp(Options{ShutdownTimeout: 50 * time.Millisecond})
+	stopped := false
+	app.OnStop("queue", StopFunc(func(ctx context.Context) error {
+		stopped = true
+		return nil
+	}))
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	listener := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(ctx, listener, handler) }()
+	go func() {
+		if resp, err := http.Get("http://" + listener.Addr().String()); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	assert.True(t, stopped)
+}
+
+func TestApp_JoinsStopperErrors(t *testing.T) {
+	app := newTestApp(Options{})
+	failure := errors.New("disconnect failed")
+	ran := false
+	app.OnStop("mongo", StopFunc(func(ctx context.Context) error {
+		ran = true
+		return nil
+	}))
+	app.OnStop("broken", StopFunc(func(ctx context.Context) error { return failure }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := app.Serve(ctx, listen(t), http.NotFoundHandler())
+
+	assert.ErrorIs(t, err, failure)
+	assert.True(t, ran)
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger, _ := New(buf, "debug", FormatJSON)
+
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logger), Recovery(logger))
+	router.GET("/ok/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, RequestIDFromContext(c.Request.Context()))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return router
+}
+
+func TestRequestID_GeneratesAndPropagates(t *testing.T) {
+	var buf bytes.Buffer
+	router := newTestRouter(&buf)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok/1", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, w.Body.String())
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "request", line["msg"])
+	assert.Equal(t, id, line[RequestIDKey])
+	assert.Equal(t, "/ok/:id", line["route"])
+	assert.Equal(t, float64(http.StatusOK), line["status"])
+}
+
+func TestRequestID_KeepsClientID(t *testing.T) {
+	router := newTestRouter(&bytes.Buffer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ok/1", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-id-1", w.Header().Get(RequestIDHeader))
+}
+
+func TestRequestID_ReplacesUnusableClientID(t *testing.T) {
+	router := newTestRouter(&bytes.Buffer{})
+
+	for _, id := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/ok/1", nil)
+		req.Header.Set(RequestIDHeader, id)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotEqual(t, id, w.Header().Get(RequestIDHeader))
+		assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+	}
+}
+
+func TestRecovery_LogsPanicWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	router := newTestRouter(&buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"handler panicked"`)
+	assert.Contains(t, lines[0], `"request_id":"req-1"`)
+	assert.Contains(t, lines[1], `"level":"ERROR"`)
+}
@@ -0,0 +1,129 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP requests,
+// Mongo operations, AI provider calls, notification sends and counter
+// reconciliation.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "inkforge"
+
+// Outcomes used as the outcome label
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Registry holds every metric of the service, plus the Go runtime and
+// process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	mongoOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Latency of Mongo commands by the repository method that issued them.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method", "command", "outcome"})
+
+	aiProviderCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_provider_calls_total",
+		Help:      "Calls to AI providers by provider and outcome.",
+	}, []string{"provider", "outcome"})
+
+	aiProviderCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ai_provider_call_duration_seconds",
+		Help:      "Latency of calls to AI providers.",
+		Buckets:   []float64{.25, .5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"provider", "outcome"})
+
+	notificationSends = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_sends_total",
+		Help:      "Emails and other notifications sent by channel, event and outcome.",
+	}, []string{"channel", "event", "outcome"})
+
+	counterDiscrepancies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "counter_discrepancies",
+		Help:      "Blog and comment counters that differed from a recount in the last scheduled reconciliation, by collection and field.",
+	}, []string{"collection", "field"})
+
+	counterRepairs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "counter_repairs_total",
+		Help:      "Blog and comment counters set to their recount by scheduled reconciliation.",
+	})
+
+	counterReconciledAt = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "counter_reconciliation_last_finished_timestamp_seconds",
+		Help:      "When the last scheduled counter reconciliation finished, as a Unix timestamp.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		mongoOperationDuration,
+		aiProviderCalls,
+		aiProviderCallDuration,
+		notificationSends,
+		counterDiscrepancies,
+		counterRepairs,
+		counterReconciledAt,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// ObserveAICall records one call to an AI provider.
+func ObserveAICall(provider string, elapsed time.Duration, err error) {
+	o := outcome(err)
+	aiProviderCalls.WithLabelValues(provider, o).Inc()
+	aiProviderCallDuration.WithLabelValues(provider, o).Observe(elapsed.Seconds())
+}
+
+// ObserveNotification records one send of a notification to a channel.
+func ObserveNotification(channel, event string, err error) {
+	notificationSends.WithLabelValues(channel, event, outcome(err)).Inc()
+}
+
+// ObserveCounterReconciliation records a finished scheduled reconciliation,
+// replacing the discrepancies of the previous one.
+func ObserveCounterReconciliation(report domain.CounterReport) {
+	counterDiscrepancies.Reset()
+	for _, d := range report.Discrepancies {
+		counterDiscrepancies.WithLabelValues(d.Collection, d.Field).Inc()
+	}
+	counterRepairs.Add(float64(report.Repaired))
+	counterReconciledAt.Set(float64(report.Finished_at.Unix()))
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_LabelsRequestsByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTP())
+	router.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+
+	before := testutil.CollectAndCount(httpRequestDuration)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	// one series for the route, one for unmatched requests
+	assert.Equal(t, before+2, testutil.CollectAndCount(httpRequestDuration))
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `inkforge_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="418"} 2`)
+	assert.Contains(t, body, `route="unmatched",status="404"`)
+}
+
+func TestObserveAICall(t *testing.T) {
+	ObserveAICall("test-provider", time.Second, nil)
+	ObserveAICall("test-provider", time.Second, errors.New("rate limited"))
+	ObserveAICall("test-provider", time.Second, errors.New("rate limited"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(aiProviderCalls.WithLabelValues("test-provider", OutcomeSuccess)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(aiProviderCalls.WithLabelValues("test-provider", OutcomeError)))
+}
+
+func TestObserveNotification(t *testing.T) {
+	ObserveNotification("test-channel", "verify_email", errors.New("timeout"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(notificationSends.WithLabelValues("test-channel", "verify_email", OutcomeError)))
+}
+
+func TestObserveCounterReconciliation_ReplacesPreviousRun(t *testing.T) {
+	ObserveCounterReconciliation(domain.CounterReport{
+		Finished_at: time.Unix(1700000000, 0),
+		Discrepancies: []domain.CounterDiscrepancy{
+			{Collection: "blogs", Field: "like_count"},
+			{Collection: "blogs", Field: "like_count"},
+			{Collection: "comments", Field: "like"},
+		},
+		Repaired: 3,
+	})
+	assert.Equal(t, 2.0, testutil.ToFloat64(counterDiscrepancies.WithLabelValues("blogs", "like_count")))
+	assert.Equal(t, 1700000000.0, testutil.ToFloat64(counterReconciledAt))
+
+	before := testutil.ToFloat64(counterRepairs)
+	ObserveCounterReconciliation(domain.CounterReport{Finished_at: time.Unix(1700003600, 0)})
+	assert.Equal(t, 0, testutil.CollectAndCount(counterDiscrepancies))
+	assert.Equal(t, before, testutil.ToFloat64(counterRepairs))
+}
+
+func TestHandler_ServesRuntimeMetrics(t *testing.T) {
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "go_goroutines"))
+}
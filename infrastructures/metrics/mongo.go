@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/InkForge/Blog_Website/infrastructures/db/monitor"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// labels of commands issued outside the repositories, e.g. health checks
+const otherRepository = "other"
+
+// NewMongoMonitor returns a command monitor timing every Mongo command under
+// the repository method that issued it.
+func NewMongoMonitor() *event.CommandMonitor {
+	var pending sync.Map // request ID -> [2]string{repository, method}
+
+	finish := func(requestID int64, command string, seconds float64, outcome string) {
+		labels := [2]string{otherRepository, command}
+		if v, ok := pending.LoadAndDelete(requestID); ok {
+			labels = v.([2]string)
+		}
+		mongoOperationDuration.WithLabelValues(labels[0], labels[1], command, outcome).Observe(seconds)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if repository, method, ok := monitor.CallingRepository(); ok {
+				pending.Store(e.RequestID, [2]string{repository, method})
+			}
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, e.CommandName, e.Duration.Seconds(), OutcomeSuccess)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.CommandName, e.Duration.Seconds(), OutcomeError)
+		},
+	}
+}
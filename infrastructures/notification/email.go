@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type emailChannel struct {
+	name   string
+	mailer domain.INotificationService
+}
+
+// NewEmailChannel returns a channel that emails the notification's recipient.
+func NewEmailChannel(name string, mailer domain.INotificationService) domain.INotificationChannel {
+	return &emailChannel{name: name, mailer: mailer}
+}
+
+func (c *emailChannel) Name() string {
+	return c.name
+}
+
+func (c *emailChannel) Notify(ctx context.Context, n domain.Notification) error {
+	if n.To == "" {
+		return fmt.Errorf("%w: %s has no recipient", domain.ErrNotificationRejected, n.Event)
+	}
+	return c.mailer.Send(domain.EmailMessage{
+		To:      n.To,
+		Subject: n.Subject,
+		HTML:    n.HTML,
+		Text:    n.Text,
+	})
+}
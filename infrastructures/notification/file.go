@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type writerChannel struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// fileChannel is a writer channel that owns its file.
+type fileChannel struct {
+	*writerChannel
+	f *os.File
+}
+
+func (c *fileChannel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.f.Close()
+}
+
+// NewWriterChannel returns a channel that writes notifications to w, for
+// development without a mail server.
+func NewWriterChannel(name string, w io.Writer) domain.INotificationChannel {
+	return &writerChannel{name: name, w: w}
+}
+
+// OpenFileChannel returns a writer channel appending to path, or writing to
+// stdout when path is empty or "-". A channel writing to a file implements
+// io.Closer.
+func OpenFileChannel(name, path string) (domain.INotificationChannel, error) {
+	if path == "" || path == "-" {
+		return NewWriterChannel(name, os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("notification channel %q: %w", name, err)
+	}
+	return &fileChannel{writerChannel: &writerChannel{name: name, w: f}, f: f}, nil
+}
+
+func (c *writerChannel) Name() string {
+	return c.name
+}
+
+func (c *writerChannel) Notify(ctx context.Context, n domain.Notification) error {
+	body := n.Text
+	if body == "" {
+		body = n.HTML
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := fmt.Fprintf(c.w, "--- %s %s to %q\nSubject: %s\n\n%s\n\n",
+		time.Now().UTC().Format(time.RFC3339), n.Event, n.To, n.Subject, body)
+	return err
+}
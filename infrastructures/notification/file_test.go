@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterChannel_WritesTextBody(t *testing.T) {
+	var out bytes.Buffer
+	channel := NewWriterChannel("dev", &out)
+
+	require.NoError(t, channel.Notify(context.Background(), resetNotification))
+
+	assert.Contains(t, out.String(), `password_reset to "reader@example.com"`)
+	assert.Contains(t, out.String(), "Subject: Reset your password\n\nReset\n")
+	assert.NotContains(t, out.String(), "<p>")
+}
+
+func TestOpenFileChannel_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+
+	for i := 0; i < 2; i++ {
+		channel, err := OpenFileChannel("dev", path)
+		require.NoError(t, err)
+		require.NoError(t, channel.Notify(context.Background(), domain.Notification{Event: "verify_email", HTML: "<p>Hi</p>"}))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("verify_email")))
+	assert.Contains(t, string(data), "<p>Hi</p>")
+}
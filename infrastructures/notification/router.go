@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/infrastructures/metrics"
+)
+
+// AllEvents routes every event to a channel.
+const AllEvents = "*"
+
+// Router sends each notification to the channels routed for its event.
+type Router struct {
+	routes  []route
+	closers []io.Closer
+}
+
+type route struct {
+	channel domain.INotificationChannel
+	events  map[string]bool
+}
+
+// NewRouter builds a channel for every config and routes its events to it.
+// SMTP channels send with mailer; webhook, Slack and Discord channels post
+// with client, which defaults to an http.Client with a 10 second timeout.
+// Stop the router to close the channels that hold files open.
+func NewRouter(configs []domain.NotificationChannelConfig, mailer domain.INotificationService, client IHTTPDoer) (*Router, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	router := &Router{}
+	for _, cfg := range configs {
+		channel, err := NewChannel(cfg, mailer, client)
+		if err != nil {
+			router.Stop(context.Background())
+			return nil, err
+		}
+		if len(cfg.Events) == 0 {
+			slog.Warn("notification channel has no events routed to it", "channel", cfg.Name)
+			router.closers = appendCloser(router.closers, channel)
+			continue
+		}
+		router.Add(channel, cfg.Events...)
+	}
+	return router, nil
+}
+
+// NewChannel builds the channel described by cfg.
+func NewChannel(cfg domain.NotificationChannelConfig, mailer domain.INotificationService, client IHTTPDoer) (domain.INotificationChannel, error) {
+	switch cfg.Type {
+	case domain.NotificationChannelSMTP:
+		return NewEmailChannel(cfg.Name, mailer), nil
+	case domain.NotificationChannelWebhook, domain.NotificationChannelSlack, domain.NotificationChannelDiscord:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("notification channel %q needs a URL", cfg.Name)
+		}
+		return NewWebhookChannel(cfg.Name, cfg.Type, cfg.URL, cfg.Secret, client), nil
+	case domain.NotificationChannelFile:
+		return OpenFileChannel(cfg.Name, cfg.Path)
+	default:
+		return nil, fmt.Errorf("notification channel %q has unknown type %q", cfg.Name, cfg.Type)
+	}
+}
+
+// Add routes events to channel. Without events it receives every event.
+func (r *Router) Add(channel domain.INotificationChannel, events ...string) {
+	if len(events) == 0 {
+		events = []string{AllEvents}
+	}
+	rt := route{channel: channel, events: make(map[string]bool, len(events))}
+	for _, event := range events {
+		rt.events[event] = true
+	}
+	r.routes = append(r.routes, rt)
+	r.closers = appendCloser(r.closers, channel)
+}
+
+// Stop closes the channels that hold a file open.
+func (r *Router) Stop(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+	r.closers = nil
+	return errors.Join(errs...)
+}
+
+func appendCloser(closers []io.Closer, channel domain.INotificationChannel) []io.Closer {
+	if c, ok := channel.(io.Closer); ok {
+		return append(closers, c)
+	}
+	return closers
+}
+
+// Notify delivers n to each of its channels not in delivered, in turn. A
+// failing channel does not stop the others, and the returned error joins every
+// failure. The channels that succeeded are added to delivered, so a caller that
+// retries with it only sends again to the ones that failed.
+func (r *Router) Notify(ctx context.Context, n domain.Notification, delivered []string) ([]string, error) {
+	delivered = slices.Clone(delivered)
+	var errs []error
+	matched := false
+	for _, rt := range r.routes {
+		if !rt.events[n.Event] && !rt.events[AllEvents] {
+			continue
+		}
+		matched = true
+		name := rt.channel.Name()
+		if slices.Contains(delivered, name) {
+			continue
+		}
+		err := rt.channel.Notify(ctx, n)
+		metrics.ObserveNotification(name, n.Event, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		delivered = append(delivered, name)
+	}
+	if !matched {
+		return delivered, fmt.Errorf("%w %q", domain.ErrNoNotificationChannel, n.Event)
+	}
+	return delivered, errors.Join(errs...)
+}
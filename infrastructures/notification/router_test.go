@@ -0,0 +1,146 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingChannel struct {
+	name string
+	err  error
+	got  []domain.Notification
+}
+
+func (c *recordingChannel) Name() string {
+	return c.name
+}
+
+func (c *recordingChannel) Notify(ctx context.Context, n domain.Notification) error {
+	c.got = append(c.got, n)
+	return c.err
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) SendEmail(to, subject, body string) error {
+	return m.Called(to, subject, body).Error(0)
+}
+
+func (m *mockMailer) Send(msg domain.EmailMessage) error {
+	return m.Called(msg).Error(0)
+}
+
+func TestRouter_RoutesByEvent(t *testing.T) {
+	email := &recordingChannel{name: "email"}
+	ops := &recordingChannel{name: "ops"}
+	all := &recordingChannel{name: "log"}
+
+	router := &Router{}
+	router.Add(email, "verify_email", "password_reset")
+	router.Add(ops, "password_reset")
+	router.Add(all)
+
+	for _, event := range []string{"verify_email", "password_reset", "digest"} {
+		_, err := router.Notify(context.Background(), domain.Notification{Event: event}, nil)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, email.got, 2)
+	assert.Len(t, ops.got, 1)
+	assert.Equal(t, "password_reset", ops.got[0].Event)
+	assert.Len(t, all.got, 3)
+}
+
+func TestRouter_NoChannelForEvent(t *testing.T) {
+	router := &Router{}
+	router.Add(&recordingChannel{name: "email"}, "verify_email")
+
+	_, err := router.Notify(context.Background(), domain.Notification{Event: "password_reset"}, nil)
+	assert.ErrorIs(t, err, domain.ErrNoNotificationChannel)
+}
+
+func TestRouter_FailingChannelDoesNotStopOthers(t *testing.T) {
+	broken := &recordingChannel{name: "slack", err: errors.New("boom")}
+	email := &recordingChannel{name: "email"}
+
+	router := &Router{}
+	router.Add(broken)
+	router.Add(email)
+
+	delivered, err := router.Notify(context.Background(), domain.Notification{Event: "verify_email"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slack: boom")
+	assert.Len(t, email.got, 1)
+	assert.Equal(t, []string{"email"}, delivered)
+
+	// the retry only goes to the channel that failed
+	broken.err = nil
+	delivered, err = router.Notify(context.Background(), domain.Notification{Event: "verify_email"}, delivered)
+	require.NoError(t, err)
+	assert.Len(t, email.got, 1)
+	assert.Len(t, broken.got, 2)
+	assert.ElementsMatch(t, []string{"email", "slack"}, delivered)
+}
+
+func TestNewRouter_BuildsChannels(t *testing.T) {
+	mailer := new(mockMailer)
+	mailer.On("Send", domain.EmailMessage{To: "a@example.com", Subject: "Hi", HTML: "<p>Hi</p>", Text: "Hi"}).Return(nil).Once()
+
+	notifier, err := NewRouter([]domain.NotificationChannelConfig{
+		{Name: "email", Type: domain.NotificationChannelSMTP, Events: []string{"verify_email"}},
+	}, mailer, nil)
+	require.NoError(t, err)
+
+	_, err = notifier.Notify(context.Background(), domain.Notification{
+		Event: "verify_email", To: "a@example.com", Subject: "Hi", HTML: "<p>Hi</p>", Text: "Hi",
+	}, nil)
+	assert.NoError(t, err)
+	mailer.AssertExpectations(t)
+}
+
+func TestNewRouter_ChannelWithoutEventsGetsNone(t *testing.T) {
+	notifier, err := NewRouter([]domain.NotificationChannelConfig{
+		{Name: "ops", Type: domain.NotificationChannelSlack, URL: "https://hooks.slack.com/services/x"},
+	}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = notifier.Notify(context.Background(), domain.Notification{Event: "password_reset"}, nil)
+	assert.ErrorIs(t, err, domain.ErrNoNotificationChannel)
+}
+
+func TestNewRouter_StopClosesFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	notifier, err := NewRouter([]domain.NotificationChannelConfig{
+		{Name: "dev", Type: domain.NotificationChannelFile, Path: path, Events: []string{"*"}},
+	}, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Stop(context.Background()))
+	_, err = notifier.Notify(context.Background(), domain.Notification{Event: "verify_email"}, nil)
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestNewRouter_InvalidChannels(t *testing.T) {
+	_, err := NewRouter([]domain.NotificationChannelConfig{{Name: "ops", Type: domain.NotificationChannelSlack}}, nil, nil)
+	assert.ErrorContains(t, err, "needs a URL")
+
+	_, err = NewRouter([]domain.NotificationChannelConfig{{Name: "pager", Type: "sms"}}, nil, nil)
+	assert.ErrorContains(t, err, "unknown type")
+}
+
+func TestEmailChannel_RequiresRecipient(t *testing.T) {
+	mailer := new(mockMailer)
+	err := NewEmailChannel("email", mailer).Notify(context.Background(), domain.Notification{Event: "digest"})
+	assert.ErrorIs(t, err, domain.ErrNotificationRejected)
+	mailer.AssertNotCalled(t, "Send", mock.Anything)
+}
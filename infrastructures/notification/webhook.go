@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// IHTTPDoer sends webhook requests. *http.Client implements it.
+type IHTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body when the channel
+// has a secret, as "sha256=<hex>".
+const SignatureHeader = "X-InkForge-Signature"
+
+// Discord rejects messages longer than this
+const discordMaxContent = 2000
+
+type webhookChannel struct {
+	name   string
+	format string
+	url    string
+	secret string
+	client IHTTPDoer
+}
+
+type webhookPayload struct {
+	Event   string    `json:"event"`
+	To      string    `json:"to,omitempty"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text,omitempty"`
+	HTML    string    `json:"html,omitempty"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// NewWebhookChannel returns a channel that posts notifications to url. format
+// is domain.NotificationChannelWebhook for the full notification as JSON, or
+// domain.NotificationChannelSlack / domain.NotificationChannelDiscord for a
+// chat message built from the subject and the plain-text body.
+func NewWebhookChannel(name, format, url, secret string, client IHTTPDoer) domain.INotificationChannel {
+	return &webhookChannel{name: name, format: format, url: url, secret: secret, client: client}
+}
+
+func (c *webhookChannel) Name() string {
+	return c.name
+}
+
+func (c *webhookChannel) Notify(ctx context.Context, n domain.Notification) error {
+	body, err := json.Marshal(c.payload(n))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-InkForge-Event", n.Event)
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: webhook returned %d", domain.ErrNotificationRejected, resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *webhookChannel) payload(n domain.Notification) any {
+	switch c.format {
+	case domain.NotificationChannelSlack:
+		return map[string]string{"text": chatMessage("*"+n.Subject+"*", n)}
+	case domain.NotificationChannelDiscord:
+		return map[string]string{"content": truncate(chatMessage("**"+n.Subject+"**", n), discordMaxContent)}
+	default:
+		return webhookPayload{
+			Event:   n.Event,
+			To:      n.To,
+			Subject: n.Subject,
+			Text:    n.Text,
+			HTML:    n.HTML,
+			SentAt:  time.Now().UTC(),
+		}
+	}
+}
+
+func chatMessage(title string, n domain.Notification) string {
+	if n.Text == "" {
+		return title
+	}
+	return title + "\n" + n.Text
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
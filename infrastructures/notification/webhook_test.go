@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockHTTPDoer struct {
+	mock.Mock
+}
+
+func (m *MockHTTPDoer) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req)
+	resp, _ := args.Get(0).(*http.Response)
+	return resp, args.Error(1)
+}
+
+func response(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+// captureBody records the request body the channel posted.
+func captureBody(body *[]byte, req **http.Request) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		*req = args.Get(0).(*http.Request)
+		*body, _ = io.ReadAll((*req).Body)
+	}
+}
+
+var resetNotification = domain.Notification{
+	Event:   "password_reset",
+	To:      "reader@example.com",
+	Subject: "Reset your password",
+	HTML:    "<p>Reset</p>",
+	Text:    "Reset",
+}
+
+func TestWebhookChannel_PostsSignedJSON(t *testing.T) {
+	doer := new(MockHTTPDoer)
+	var body []byte
+	var req *http.Request
+	doer.On("Do", mock.Anything).Run(captureBody(&body, &req)).Return(response(http.StatusNoContent), nil).Once()
+
+	channel := NewWebhookChannel("audit", domain.NotificationChannelWebhook, "https://hooks.example.com/in", "s3cret", doer)
+	require.NoError(t, channel.Notify(context.Background(), resetNotification))
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "https://hooks.example.com/in", req.URL.String())
+	assert.Equal(t, "password_reset", req.Header.Get("X-InkForge-Event"))
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get(SignatureHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "password_reset", payload["event"])
+	assert.Equal(t, "reader@example.com", payload["to"])
+	assert.Equal(t, "<p>Reset</p>", payload["html"])
+	assert.NotEmpty(t, payload["sent_at"])
+}
+
+func TestWebhookChannel_ChatFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		field  string
+		want   string
+	}{
+		{domain.NotificationChannelSlack, "text", "*Reset your password*\nReset"},
+		{domain.NotificationChannelDiscord, "content", "**Reset your password**\nReset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			doer := new(MockHTTPDoer)
+			var body []byte
+			var req *http.Request
+			doer.On("Do", mock.Anything).Run(captureBody(&body, &req)).Return(response(http.StatusOK), nil).Once()
+
+			channel := NewWebhookChannel("ops", tt.format, "https://chat.example.com/hook", "", doer)
+			require.NoError(t, channel.Notify(context.Background(), resetNotification))
+
+			var payload map[string]string
+			require.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, map[string]string{tt.field: tt.want}, payload)
+			assert.Empty(t, req.Header.Get(SignatureHeader))
+		})
+	}
+}
+
+func TestWebhookChannel_DiscordTruncatesLongMessages(t *testing.T) {
+	doer := new(MockHTTPDoer)
+	var body []byte
+	var req *http.Request
+	doer.On("Do", mock.Anything).Run(captureBody(&body, &req)).Return(response(http.StatusOK), nil).Once()
+
+	n := resetNotification
+	n.Text = strings.Repeat("é", 3000)
+	require.NoError(t, NewWebhookChannel("ops", domain.NotificationChannelDiscord, "https://chat.example.com/hook", "", doer).Notify(context.Background(), n))
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Len(t, []rune(payload["content"]), discordMaxContent)
+}
+
+func TestWebhookChannel_RejectedStatus(t *testing.T) {
+	doer := new(MockHTTPDoer)
+	doer.On("Do", mock.Anything).Return(response(http.StatusBadGateway), nil).Once()
+
+	err := NewWebhookChannel("audit", domain.NotificationChannelWebhook, "https://hooks.example.com/in", "", doer).Notify(context.Background(), resetNotification)
+	assert.ErrorIs(t, err, domain.ErrNotificationRejected)
+	assert.ErrorContains(t, err, "502")
+}
@@ -0,0 +1,28 @@
+package infrastructures
+
+import (
+	"errors"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// BuildNotificationChannelConfigs returns the channels listed in
+// NOTIFY_CHANNELS, in order, with the events routed to each.
+func BuildNotificationChannelConfigs(cfg *Config) ([]domain.NotificationChannelConfig, error) {
+	if len(cfg.NotificationChannels) == 0 {
+		return nil, errors.New("NOTIFY_CHANNELS lists no notification channels")
+	}
+
+	configs := make([]domain.NotificationChannelConfig, 0, len(cfg.NotificationChannels))
+	for _, channel := range cfg.NotificationChannels {
+		configs = append(configs, domain.NotificationChannelConfig{
+			Name:   channel.Name,
+			Type:   channel.Type,
+			URL:    channel.URL,
+			Path:   channel.Path,
+			Secret: channel.Secret,
+			Events: channel.Events,
+		})
+	}
+	return configs, nil
+}
@@ -1,6 +1,8 @@
 package infrastructures
 
 import (
+	"fmt"
+
 	"golang.org/x/oauth2/facebook"
 	"golang.org/x/oauth2/github"
 	"golang.org/x/oauth2/google"
@@ -14,7 +16,7 @@ func BuildProviderConfigs() (map[string]domain.OAuth2ProviderConfig, error) {
 		return nil, err
 	}
 
-	return map[string]domain.OAuth2ProviderConfig{
+	providers := map[string]domain.OAuth2ProviderConfig{
 		"google": {
 			ClientID:     configs.GoogleClientID,
 			ClientSecret: configs.GoogleClientSecret,
@@ -36,5 +38,23 @@ func BuildProviderConfigs() (map[string]domain.OAuth2ProviderConfig, error) {
 			Scopes:       []string{"email", "public_profile"},
 			Endpoint:     facebook.Endpoint,
 		},
-	}, nil
+	}
+
+	for name, oidc := range configs.OIDCProviders {
+		if _, ok := providers[name]; ok {
+			return nil, fmt.Errorf("OIDC provider %q clashes with a built-in provider", name)
+		}
+		if oidc.Issuer == "" || oidc.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer and a client ID", name)
+		}
+		providers[name] = domain.OAuth2ProviderConfig{
+			ClientID:     oidc.ClientID,
+			ClientSecret: oidc.ClientSecret,
+			RedirectURL:  configs.BaseURL + oidc.RedirectURL,
+			Scopes:       oidc.Scopes,
+			Issuer:       oidc.Issuer,
+		}
+	}
+
+	return providers, nil
 }
@@ -10,17 +10,19 @@ type ISMTPDialer interface {
 }
 
 type SMTPService struct {
-	dialer    ISMTPDialer
-	EmailFrom string
+	dialer        ISMTPDialer
+	EmailFrom     string
+	EmailFromName string
 }
 
 // NetSMTPService expects configuration settings needed for settings
 // up SMTP services and returns a reference to SMTPService object
-func NewSMTPService(SMTPHost string, SMTPPort int, SMTPUsername, SMTPPassowrd string, EmailFrom string) domain.INotificationService {
+func NewSMTPService(SMTPHost string, SMTPPort int, SMTPUsername, SMTPPassowrd string, EmailFrom, EmailFromName string) domain.INotificationService {
 	d := gomail.NewDialer(SMTPHost, SMTPPort, SMTPUsername, SMTPPassowrd)
 	return &SMTPService{
-		dialer:    d,
-		EmailFrom: EmailFrom,
+		dialer:        d,
+		EmailFrom:     EmailFrom,
+		EmailFromName: EmailFromName,
 	}
 }
 
@@ -30,11 +32,35 @@ func NewSMTPService(SMTPHost string, SMTPPort int, SMTPUsername, SMTPPassowrd st
 // body -> html body of the email content
 func (s *SMTPService) SendEmail(to, subject, body string) error {
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.EmailFrom)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
+	m := s.newMessage(to, subject)
 	m.SetBody("text/html", body)
 
 	return s.dialer.DialAndSend(m)
 }
+
+// Send sends msg with its plain-text body first and the HTML one as the
+// preferred alternative.
+func (s *SMTPService) Send(msg domain.EmailMessage) error {
+
+	m := s.newMessage(msg.To, msg.Subject)
+	if msg.Text == "" {
+		m.SetBody("text/html", msg.HTML)
+	} else {
+		m.SetBody("text/plain", msg.Text)
+		m.AddAlternative("text/html", msg.HTML)
+	}
+
+	return s.dialer.DialAndSend(m)
+}
+
+func (s *SMTPService) newMessage(to, subject string) *gomail.Message {
+	m := gomail.NewMessage()
+	if s.EmailFromName != "" {
+		m.SetAddressHeader("From", s.EmailFrom, s.EmailFromName)
+	} else {
+		m.SetHeader("From", s.EmailFrom)
+	}
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	return m
+}
@@ -1,8 +1,11 @@
 package infrastructures
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/InkForge/Blog_Website/domain"
+
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/gomail.v2"
@@ -49,6 +52,31 @@ func (s *SMTPServiceTestSuite) TestSendEmail_Success() {
 	s.mockDialer.AssertExpectations(s.T())
 }
 
+func (s *SMTPServiceTestSuite) TestSend_MultipartWithFromName() {
+	s.smtpService.EmailFromName = "InkForge"
+	var sent *gomail.Message
+	s.mockDialer.On("DialAndSend", mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(0).([]*gomail.Message)[0]
+	}).Return(nil).Once()
+
+	err := s.smtpService.Send(domain.EmailMessage{
+		To:      "receiver@example.com",
+		Subject: "Welcome",
+		HTML:    "<p>Hello!</p>",
+		Text:    "Hello!",
+	})
+	s.NoError(err)
+	s.Require().NotNil(sent)
+	s.Equal([]string{`"InkForge" <noreply@example.com>`}, sent.GetHeader("From"))
+
+	var raw bytes.Buffer
+	_, err = sent.WriteTo(&raw)
+	s.NoError(err)
+	s.Contains(raw.String(), "multipart/alternative")
+	s.Contains(raw.String(), "text/plain")
+	s.Contains(raw.String(), "<p>Hello!</p>")
+}
+
 // Run the suite
 func TestSMTPServiceSuite(t *testing.T) {
 	suite.Run(t, new(SMTPServiceTestSuite))
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/InkForge/Blog_Website/infrastructures/db/monitor"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewMongoMonitor returns a command monitor recording a client span per Mongo
+// command, named after the repository method that issued it, e.g.
+// "BlogRepository.GetByID find".
+func NewMongoMonitor() *event.CommandMonitor {
+	var spans sync.Map // request ID -> trace.Span
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			_, span := Tracer().Start(ctx, e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBOperationName(e.CommandName),
+					semconv.DBNamespace(e.DatabaseName),
+				),
+			)
+			if !span.IsRecording() {
+				return
+			}
+			// the collection is the value of the command's first element
+			if first, err := e.Command.IndexErr(0); err == nil {
+				if collection, ok := first.Value().StringValueOK(); ok {
+					span.SetAttributes(semconv.DBCollectionName(collection))
+				}
+			}
+			if repository, method, ok := monitor.CallingRepository(); ok {
+				if repository != "" {
+					method = repository + "." + method
+				}
+				span.SetName(method + " " + e.CommandName)
+			}
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			if v, ok := spans.LoadAndDelete(e.RequestID); ok {
+				v.(trace.Span).End()
+			}
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			if v, ok := spans.LoadAndDelete(e.RequestID); ok {
+				span := v.(trace.Span)
+				span.SetStatus(codes.Error, e.Failure)
+				span.End()
+			}
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the HTTP
+// router and Mongo commands. Trace context travels in W3C trace headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters spans can be sent to
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// InstrumentationName names the tracer of the service's own spans.
+const InstrumentationName = "github.com/InkForge/Blog_Website"
+
+// Options configures the tracer provider.
+type Options struct {
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector, as host:port or a URL.
+	Endpoint string
+	// Insecure sends to the collector over plain HTTP.
+	Insecure    bool
+	SampleRatio float64
+	ServiceName string
+}
+
+// Setup installs the global tracer provider and W3C propagators, and returns
+// a function flushing buffered spans on shutdown. With the none exporter
+// spans are not recorded, but incoming trace context is still passed on.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(strings.TrimSpace(opts.Exporter)) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlpOptions(opts)...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := NewProvider(sdktrace.WithBatcher(exporter), opts)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func otlpOptions(opts Options) []otlptracehttp.Option {
+	var options []otlptracehttp.Option
+	switch {
+	case strings.Contains(opts.Endpoint, "://"):
+		options = append(options, otlptracehttp.WithEndpointURL(opts.Endpoint))
+	case opts.Endpoint != "":
+		options = append(options, otlptracehttp.WithEndpoint(opts.Endpoint))
+	}
+	if opts.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return options
+}
+
+// NewProvider returns a tracer provider sending spans through processor,
+// sampling root spans at opts.SampleRatio. Tests pass a syncer over an
+// in-memory exporter.
+func NewProvider(processor sdktrace.TracerProviderOption, opts Options) (*sdktrace.TracerProvider, error) {
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = "inkforge"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := opts.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	return sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	), nil
+}
+
+// Tracer returns the tracer of the service's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryExporter installs a provider recording every span until the
+// test ends.
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := NewProvider(sdktrace.WithSyncer(exporter), Options{ServiceName: "test"})
+	require.NoError(t, err)
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestHTTP_ContinuesIncomingTrace(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTP())
+	router.GET("/blogs/:id", func(c *gin.Context) {
+		_, child := Tracer().Start(c.Request.Context(), "BlogRepository.GetByID find")
+		child.End()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/blogs/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "GET /blogs/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, int64(http.StatusOK), attr(server, semconv.HTTPResponseStatusCodeKey).AsInt64())
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+}
+
+func TestHTTP_MarksServerErrors(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTP())
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.False(t, spans[0].Parent.IsValid())
+}
+
+func TestMongoMonitor_RecordsCommandSpans(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	monitor := NewMongoMonitor()
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "blogs"}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "blogdb", CommandName: "find", RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "blogdb", CommandName: "find", RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 2}, Failure: "not primary"})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	ok, failed := spans[0], spans[1]
+	// issued outside the repositories, so named after the command only
+	assert.Equal(t, "find", ok.Name)
+	assert.Equal(t, trace.SpanKindClient, ok.SpanKind)
+	assert.Equal(t, "blogs", attr(ok, semconv.DBCollectionNameKey).AsString())
+	assert.Equal(t, "mongodb", attr(ok, semconv.DBSystemKey).AsString())
+	assert.Equal(t, parent.SpanContext().SpanID(), ok.Parent.SpanID())
+	assert.Equal(t, codes.Error, failed.Status.Code)
+	assert.Equal(t, "not primary", failed.Status.Description)
+}
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,54 @@
+// Package ttlcache keeps values read from the database for a short time, so
+// settings an admin edits on one instance reach the others without every
+// request reading them.
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache holds the value loaded for each key until the TTL passes.
+type Cache[K comparable, V any] struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+}
+
+// New returns a cache keeping values for ttl as measured by now.
+func New[K comparable, V any](ttl time.Duration, now func() time.Time) *Cache[K, V] {
+	return &Cache[K, V]{ttl: ttl, now: now, entries: make(map[K]entry[V])}
+}
+
+// Get returns the cached value of key, calling load once it expired. Whatever
+// load returns is cached, so a caller that falls back on a failed read caches
+// the fallback and does not hit an unavailable database on every call. Two
+// callers missing at once may both load.
+func (c *Cache[K, V]) Get(key K, load func() V) V {
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		return cached.value
+	}
+
+	value := load()
+	c.mu.Lock()
+	c.entries[key] = entry[V]{value: value, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value
+}
+
+// Invalidate drops key, so the next Get loads it again.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,30 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_LoadsOnceUntilExpiredOrInvalidated(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[string, int](time.Minute, func() time.Time { return now })
+	loads := 0
+	load := func() int {
+		loads++
+		return loads
+	}
+
+	assert.Equal(t, 1, c.Get("k", load))
+	now = now.Add(59 * time.Second)
+	assert.Equal(t, 1, c.Get("k", load))
+	assert.Equal(t, 2, c.Get("other", load), "keys are cached apart")
+
+	now = now.Add(time.Second)
+	assert.Equal(t, 3, c.Get("k", load))
+
+	c.Invalidate("k")
+	assert.Equal(t, 4, c.Get("k", load))
+	assert.Equal(t, 2, c.Get("other", load))
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Periodic calls a function on a fixed interval until stopped.
+type Periodic struct {
+	interval time.Duration
+	fn       func(ctx context.Context)
+
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPeriodic returns a runner that calls fn every interval once started.
+func NewPeriodic(interval time.Duration, fn func(ctx context.Context)) *Periodic {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Periodic{
+		interval: interval,
+		fn:       fn,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins the ticking. The first call happens after one interval.
+func (p *Periodic) Start() {
+	p.once.Do(func() {
+		go p.loop(false)
+	})
+}
+
+// StartNow is Start, but makes the first call right away.
+func (p *Periodic) StartNow() {
+	p.once.Do(func() {
+		go p.loop(true)
+	})
+}
+
+func (p *Periodic) loop(now bool) {
+	defer close(p.done)
+	if now {
+		p.fn(p.ctx)
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.fn(p.ctx)
+		}
+	}
+}
+
+// Stop cancels the running call, if any, and waits for it to return or for
+// ctx to end.
+func (p *Periodic) Stop(ctx context.Context) error {
+	p.cancel()
+	started := true
+	p.once.Do(func() { started = false })
+	if !started {
+		return nil
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
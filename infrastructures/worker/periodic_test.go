@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodic_StartNowRunsUnderStop(t *testing.T) {
+	started := make(chan struct{})
+	returned := make(chan struct{})
+	p := NewPeriodic(time.Hour, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(returned)
+	})
+	p.StartNow()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first call did not start right away")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, p.Stop(ctx))
+	select {
+	case <-returned:
+	default:
+		assert.Fail(t, "Stop returned before the running call")
+	}
+}
@@ -0,0 +1,264 @@
+// Package worker runs background jobs in process: a keyed queue with retries
+// and a periodic runner.
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/InkForge/Blog_Website/infrastructures/worker")
+
+// Handler processes the job identified by key.
+type Handler func(ctx context.Context, key string) error
+
+// Options configures a Queue. Zero values fall back to the defaults below.
+type Options struct {
+	// Name is logged with the queue's log lines.
+	Name        string
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles on every
+	// further attempt up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	JobTimeout  time.Duration
+	// OnGiveUp is called once a job has failed MaxAttempts times or returned a
+	// Permanent error.
+	OnGiveUp func(key string, err error)
+}
+
+const (
+	defaultWorkers     = 1
+	defaultQueueSize   = 256
+	defaultMaxAttempts = 3
+	defaultBaseBackoff = 5 * time.Second
+	defaultMaxBackoff  = 5 * time.Minute
+	defaultJobTimeout  = time.Minute
+)
+
+// ErrQueueStopped is returned by Stop when called twice.
+var ErrQueueStopped = errors.New("worker queue already stopped")
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+type job struct {
+	key     string
+	attempt int
+}
+
+// Queue runs a handler for enqueued keys on a fixed pool of goroutines.
+// A key that is already waiting is not queued twice, and failed jobs are
+// retried with exponential backoff.
+type Queue struct {
+	handler Handler
+	opts    Options
+
+	jobs chan job
+	quit chan struct{}
+	ctx  context.Context
+	// cancel aborts running handlers when Stop runs out of time
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	waiting map[string]bool
+	started bool
+	stopped bool
+}
+
+// NewQueue returns a queue that is ready to accept jobs; call Start to begin
+// processing them.
+func NewQueue(handler Handler, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = defaultJobTimeout
+	}
+	if opts.Name == "" {
+		opts.Name = "worker"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		handler: handler,
+		opts:    opts,
+		jobs:    make(chan job, opts.QueueSize),
+		quit:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		waiting: make(map[string]bool),
+	}
+}
+
+// Enqueue schedules key for processing without blocking. It returns false if
+// the queue is full or stopped; a key that is already waiting counts as queued.
+func (q *Queue) Enqueue(key string) bool {
+	return q.push(job{key: key, attempt: 1})
+}
+
+func (q *Queue) push(j job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return false
+	}
+	if q.waiting[j.key] {
+		return true
+	}
+	select {
+	case q.jobs <- j:
+		q.waiting[j.key] = true
+		return true
+	default:
+		slog.Warn("worker queue full, dropping job", "queue", q.opts.Name, "key", j.key)
+		return false
+	}
+}
+
+// Start launches the workers. Calling it more than once has no effect.
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started || q.stopped {
+		return
+	}
+	q.started = true
+
+	for i := 0; i < q.opts.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Stop stops accepting jobs and waits for running handlers to return. Jobs
+// still waiting are dropped. If ctx ends first, running handlers are cancelled
+// and ctx's error is returned.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return ErrQueueStopped
+	}
+	q.stopped = true
+	close(q.quit)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.quit:
+			return
+		case j := <-q.jobs:
+			q.mu.Lock()
+			delete(q.waiting, j.key)
+			q.mu.Unlock()
+			q.run(j)
+		}
+	}
+}
+
+func (q *Queue) run(j job) {
+	ctx, cancel := context.WithTimeout(q.ctx, q.opts.JobTimeout)
+	// every job is a trace of its own
+	ctx, span := tracer.Start(ctx, q.opts.Name+" job", trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("job.key", j.key), attribute.Int("job.attempt", j.attempt)))
+	err := q.safeHandle(ctx, j.key)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	cancel()
+	if err == nil {
+		return
+	}
+
+	var permanent permanentError
+	if errors.As(err, &permanent) || j.attempt >= q.opts.MaxAttempts {
+		slog.Error("worker giving up on job", "queue", q.opts.Name, "key", j.key, "attempts", j.attempt, "error", err)
+		if q.opts.OnGiveUp != nil {
+			q.opts.OnGiveUp(j.key, err)
+		}
+		return
+	}
+
+	delay := q.backoff(j.attempt)
+	slog.Warn("worker job failed, retrying", "queue", q.opts.Name, "key", j.key, "attempt", j.attempt, "retry_in", delay, "error", err)
+	time.AfterFunc(delay, func() {
+		q.push(job{key: j.key, attempt: j.attempt + 1})
+	})
+}
+
+// safeHandle turns a panicking handler into a failed attempt.
+func (q *Queue) safeHandle(ctx context.Context, key string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(errors.New("handler panicked"))
+			slog.Error("worker job panicked", "queue", q.opts.Name, "key", key, "panic", r)
+		}
+	}()
+	return q.handler(ctx, key)
+}
+
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.opts.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= q.opts.MaxBackoff {
+			return q.opts.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_ProcessesJobs(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	done := make(chan struct{}, 2)
+	q := NewQueue(func(ctx context.Context, key string) error {
+		mu.Lock()
+		seen = append(seen, key)
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	}, Options{})
+	q.Start()
+
+	assert.True(t, q.Enqueue("a"))
+	assert.True(t, q.Enqueue("b"))
+	waitFor(t, done, 2)
+
+	assert.NoError(t, q.Stop(context.Background()))
+	assert.ElementsMatch(t, []string{"a", "b"}, seen)
+}
+
+func TestQueue_CollapsesWaitingKeys(t *testing.T) {
+	q := NewQueue(func(ctx context.Context, key string) error { return nil }, Options{})
+
+	// not started yet, so both stay waiting
+	assert.True(t, q.Enqueue("a"))
+	assert.True(t, q.Enqueue("a"))
+	assert.Len(t, q.jobs, 1)
+}
+
+func TestQueue_RetriesThenGivesUp(t *testing.T) {
+	var calls int32
+	gaveUp := make(chan error, 1)
+	q := NewQueue(func(ctx context.Context, key string) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("provider down")
+	}, Options{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		OnGiveUp:    func(key string, err error) { gaveUp <- err },
+	})
+	q.Start()
+	defer q.Stop(context.Background())
+
+	q.Enqueue("a")
+
+	select {
+	case err := <-gaveUp:
+		assert.EqualError(t, err, "provider down")
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was never given up on")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestQueue_PermanentErrorSkipsRetries(t *testing.T) {
+	var calls int32
+	gaveUp := make(chan struct{}, 1)
+	q := NewQueue(func(ctx context.Context, key string) error {
+		atomic.AddInt32(&calls, 1)
+		return Permanent(errors.New("bad input"))
+	}, Options{
+		MaxAttempts: 5,
+		BaseBackoff: time.Millisecond,
+		OnGiveUp:    func(key string, err error) { gaveUp <- struct{}{} },
+	})
+	q.Start()
+	defer q.Stop(context.Background())
+
+	q.Enqueue("a")
+	waitFor(t, gaveUp, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestQueue_StopRejectsNewJobs(t *testing.T) {
+	q := NewQueue(func(ctx context.Context, key string) error { return nil }, Options{})
+	q.Start()
+
+	assert.NoError(t, q.Stop(context.Background()))
+	assert.False(t, q.Enqueue("a"))
+	assert.ErrorIs(t, q.Stop(context.Background()), ErrQueueStopped)
+}
+
+func TestQueue_BackoffIsCapped(t *testing.T) {
+	q := NewQueue(nil, Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 4*time.Second, q.backoff(3))
+	assert.Equal(t, 5*time.Second, q.backoff(4))
+}
+
+func TestPeriodic_RunsUntilStopped(t *testing.T) {
+	ticks := make(chan struct{}, 10)
+	p := NewPeriodic(time.Millisecond, func(ctx context.Context) {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	})
+	p.Start()
+	waitFor(t, ticks, 2)
+
+	assert.NoError(t, p.Stop(context.Background()))
+}
+
+func waitFor[T any](t *testing.T, ch <-chan T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d of %d events", i, n)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AIConversationMongoRepository struct {
+	conversationCollection *mongo.Collection
+}
+
+func NewAIConversationRepository(db *mongo.Database) domain.IAIConversationRepository {
+	return &AIConversationMongoRepository{
+		conversationCollection: db.Collection("ai_conversations"),
+	}
+}
+
+func (r *AIConversationMongoRepository) Create(ctx context.Context, conversation domain.AIConversation) (string, error) {
+	mongoConversation, err := models.FromDomainAIConversation(&conversation)
+	if err != nil {
+		return "", domain.ErrInvalidConversationID
+	}
+	if mongoConversation.Created_at.IsZero() {
+		mongoConversation.Created_at = time.Now()
+	}
+	if mongoConversation.Updated_at.IsZero() {
+		mongoConversation.Updated_at = mongoConversation.Created_at
+	}
+
+	result, err := r.conversationCollection.InsertOne(ctx, mongoConversation)
+	if err != nil {
+		return "", dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	objectID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", domain.ErrInvalidConversationID
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (r *AIConversationMongoRepository) GetByID(ctx context.Context, conversationID string) (domain.AIConversation, error) {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return domain.AIConversation{}, domain.ErrInvalidConversationID
+	}
+
+	var mongoConversation models.MongoAIConversation
+	err = r.conversationCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&mongoConversation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.AIConversation{}, domain.ErrAIConversationNotFound
+		}
+		return domain.AIConversation{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	return *mongoConversation.ToDomain(), nil
+}
+
+func (r *AIConversationMongoRepository) ListByUser(ctx context.Context, userID string, page, limit int) ([]domain.AIConversation, int, error) {
+	filter := bson.M{"user_id": userID}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"messages": 0})
+
+	total, err := r.conversationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	cursor, err := r.conversationCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	conversations := []domain.AIConversation{}
+	for cursor.Next(ctx) {
+		var mongoConversation models.MongoAIConversation
+		if err := cursor.Decode(&mongoConversation); err != nil {
+			return nil, 0, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		conversations = append(conversations, *mongoConversation.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+
+	return conversations, int(total), nil
+}
+
+func (r *AIConversationMongoRepository) AppendMessages(ctx context.Context, conversationID string, messages []domain.AIMessage) error {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return domain.ErrInvalidConversationID
+	}
+
+	update := bson.M{
+		"$push": bson.M{"messages": bson.M{"$each": models.FromDomainAIMessages(messages)}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	result, err := r.conversationCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrAIConversationNotFound
+	}
+	return nil
+}
+
+func (r *AIConversationMongoRepository) UpdateTitle(ctx context.Context, conversationID, title string) error {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return domain.ErrInvalidConversationID
+	}
+
+	update := bson.M{"$set": bson.M{"title": title, "updated_at": time.Now()}}
+	result, err := r.conversationCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrAIConversationNotFound
+	}
+	return nil
+}
+
+func (r *AIConversationMongoRepository) Delete(ctx context.Context, conversationID string) error {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return domain.ErrInvalidConversationID
+	}
+
+	result, err := r.conversationCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrDeletingDocument)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrAIConversationNotFound
+	}
+	return nil
+}
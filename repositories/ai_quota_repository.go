@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AIQuotaRepository struct {
+	collection *mongo.Collection
+	usage      *mongo.Collection
+}
+
+func NewAIQuotaRepository(db *mongo.Database) domain.IAIQuotaRepository {
+	return &AIQuotaRepository{
+		collection: db.Collection("ai_quotas"),
+		usage:      db.Collection("ai_quota_usage"),
+	}
+}
+
+func (r *AIQuotaRepository) GetUserQuota(ctx context.Context, userID string) (domain.AIQuota, error) {
+	var quota models.MongoAIQuota
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&quota)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.AIQuota{}, domain.ErrAIQuotaNotFound
+		}
+		return domain.AIQuota{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return quota.ToDomain(), nil
+}
+
+func (r *AIQuotaRepository) UpsertUserQuota(ctx context.Context, userID string, quota domain.AIQuota) error {
+	update := bson.M{"$set": models.FromDomainAIQuota(userID, quota)}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"user_id": userID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+// periodID names the usage document of a period, e.g. "<user>:day:2025-03-01".
+func periodID(period domain.AIQuotaPeriod) string {
+	return period.User_id + ":" + period.Name + ":" + period.Start.UTC().Format("2006-01-02")
+}
+
+func (r *AIQuotaRepository) ReserveRequest(ctx context.Context, period domain.AIQuotaPeriod, requestLimit, tokenLimit int) error {
+	filter := bson.M{"_id": periodID(period)}
+	if requestLimit > 0 {
+		filter["requests"] = bson.M{"$lt": requestLimit}
+	}
+	if tokenLimit > 0 {
+		filter["tokens"] = bson.M{"$lt": tokenLimit}
+	}
+	update := bson.M{
+		"$inc": bson.M{"requests": 1},
+		"$setOnInsert": bson.M{
+			"user_id":    period.User_id,
+			"period":     period.Name,
+			"start":      period.Start,
+			"tokens":     0,
+			"expires_at": period.End,
+		},
+	}
+
+	// A period at its limit fails the filter, so the upsert tries to insert a
+	// second document with the same _id. The same happens to the loser of two
+	// first requests in a period, which is why a duplicate is tried once more.
+	for attempt := 0; ; attempt++ {
+		_, err := r.usage.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return dbError(ctx, err, domain.ErrUpdatingDocument)
+		}
+		if attempt == 1 {
+			return domain.ErrAIQuotaExceeded
+		}
+	}
+}
+
+func (r *AIQuotaRepository) ReleaseRequest(ctx context.Context, period domain.AIQuotaPeriod) error {
+	filter := bson.M{"_id": periodID(period), "requests": bson.M{"$gt": 0}}
+	if _, err := r.usage.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"requests": -1}}); err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+func (r *AIQuotaRepository) AddTokens(ctx context.Context, period domain.AIQuotaPeriod, tokens int) error {
+	if tokens <= 0 {
+		return nil
+	}
+	if _, err := r.usage.UpdateOne(ctx, bson.M{"_id": periodID(period)}, bson.M{"$inc": bson.M{"tokens": tokens}}); err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+func (r *AIQuotaRepository) PeriodUsage(ctx context.Context, period domain.AIQuotaPeriod) (domain.AIUsageTotals, error) {
+	var doc struct {
+		Requests int `bson:"requests"`
+		Tokens   int `bson:"tokens"`
+	}
+	err := r.usage.FindOne(ctx, bson.M{"_id": periodID(period)}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AIUsageTotals{}, nil
+	}
+	if err != nil {
+		return domain.AIUsageTotals{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return domain.AIUsageTotals{Requests: doc.Requests, Tokens: doc.Tokens}, nil
+}
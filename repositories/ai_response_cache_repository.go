@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type aiCacheEntry struct {
+	Key       string    `bson:"_id"`
+	Value     string    `bson:"value"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type AIResponseCacheRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAIResponseCacheRepository(db *mongo.Database) domain.IAIResponseCacheRepository {
+	return &AIResponseCacheRepository{
+		collection: db.Collection("ai_response_cache"),
+	}
+}
+
+func (r *AIResponseCacheRepository) Get(ctx context.Context, key string) (string, error) {
+	// the TTL monitor runs about once a minute, so filter out stale entries too
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+
+	var entry aiCacheEntry
+	err := r.collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", domain.ErrAICacheMiss
+		}
+		return "", dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return entry.Value, nil
+}
+
+func (r *AIResponseCacheRepository) Set(ctx context.Context, key, value string, expiresAt time.Time) error {
+	entry := aiCacheEntry{Key: key, Value: value, ExpiresAt: expiresAt}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": key}, entry, options.Replace().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
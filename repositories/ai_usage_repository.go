@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AIUsageRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAIUsageRepository(db *mongo.Database) domain.IAIUsageRepository {
+	return &AIUsageRepository{
+		collection: db.Collection("ai_usage"),
+	}
+}
+
+func (r *AIUsageRepository) Create(ctx context.Context, record domain.AIUsageRecord) error {
+	if _, err := r.collection.InsertOne(ctx, models.FromDomainAIUsageRecord(&record)); err != nil {
+		return dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	return nil
+}
+
+func (r *AIUsageRepository) Report(ctx context.Context, from, to time.Time) ([]domain.AIUsageReportRow, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               bson.M{"provider": "$provider", "model": "$model"},
+			"requests":          bson.M{"$sum": 1},
+			"failures":          bson.M{"$sum": bson.M{"$cond": bson.A{"$success", 0, 1}}},
+			"prompt_tokens":     bson.M{"$sum": "$prompt_tokens"},
+			"completion_tokens": bson.M{"$sum": "$completion_tokens"},
+			"estimated_cost":    bson.M{"$sum": "$estimated_cost"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "estimated_cost", Value: -1}, {Key: "requests", Value: -1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	rows := []domain.AIUsageReportRow{}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID struct {
+				Provider string `bson:"provider"`
+				Model    string `bson:"model"`
+			} `bson:"_id"`
+			Requests         int     `bson:"requests"`
+			Failures         int     `bson:"failures"`
+			PromptTokens     int     `bson:"prompt_tokens"`
+			CompletionTokens int     `bson:"completion_tokens"`
+			EstimatedCost    float64 `bson:"estimated_cost"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		rows = append(rows, domain.AIUsageReportRow{
+			Provider:          row.ID.Provider,
+			Model:             row.ID.Model,
+			Requests:          row.Requests,
+			Failures:          row.Failures,
+			Prompt_tokens:     row.PromptTokens,
+			Completion_tokens: row.CompletionTokens,
+			Estimated_cost:    row.EstimatedCost,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+
+	return rows, nil
+}
+
+func (r *AIUsageRepository) PromptVersionStats(ctx context.Context, name string, from, to time.Time) ([]domain.PromptVersionStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"prompt_template": name, "created_at": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              "$prompt_version",
+			"requests":         bson.M{"$sum": 1},
+			"failures":         bson.M{"$sum": bson.M{"$cond": bson.A{"$success", 0, 1}}},
+			"avg_latency_ms":   bson.M{"$avg": "$latency_ms"},
+			"avg_total_tokens": bson.M{"$avg": "$total_tokens"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	stats := []domain.PromptVersionStats{}
+	for cursor.Next(ctx) {
+		var row struct {
+			Version        int     `bson:"_id"`
+			Requests       int     `bson:"requests"`
+			Failures       int     `bson:"failures"`
+			AvgLatencyMs   float64 `bson:"avg_latency_ms"`
+			AvgTotalTokens float64 `bson:"avg_total_tokens"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		stats = append(stats, domain.PromptVersionStats{
+			Version:          row.Version,
+			Requests:         row.Requests,
+			Failures:         row.Failures,
+			Avg_latency_ms:   row.AvgLatencyMs,
+			Avg_total_tokens: row.AvgTotalTokens,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+
+	return stats, nil
+}
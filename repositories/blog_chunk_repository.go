@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type BlogChunkMongoRepository struct {
+	chunkCollection *mongo.Collection
+}
+
+func NewBlogChunkRepository(db *mongo.Database) domain.IBlogChunkRepository {
+	return &BlogChunkMongoRepository{
+		chunkCollection: db.Collection("blog_chunks"),
+	}
+}
+
+// ReplaceForBlog deletes the blog's chunks and inserts the new ones. Run it
+// in a transaction to keep searches from seeing a half indexed blog.
+func (r *BlogChunkMongoRepository) ReplaceForBlog(ctx context.Context, blogID string, chunks []domain.BlogChunk) error {
+	if err := r.DeleteByBlog(ctx, blogID); err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, 0, len(chunks))
+	for i := range chunks {
+		doc := models.FromDomainBlogChunk(&chunks[i])
+		doc.Blog_id = blogID
+		if doc.Created_at.IsZero() {
+			doc.Created_at = now
+		}
+		docs = append(docs, doc)
+	}
+
+	if _, err := r.chunkCollection.InsertMany(ctx, docs); err != nil {
+		return dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	return nil
+}
+
+func (r *BlogChunkMongoRepository) DeleteByBlog(ctx context.Context, blogID string) error {
+	if _, err := r.chunkCollection.DeleteMany(ctx, bson.M{"blog_id": blogID}); err != nil {
+		return dbError(ctx, err, domain.ErrDeletingDocument)
+	}
+	return nil
+}
+
+func (r *BlogChunkMongoRepository) ForEachByModel(ctx context.Context, model string, fn func(chunk domain.BlogChunk) error) error {
+	cursor, err := r.chunkCollection.Find(ctx, bson.M{"model": model})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mongoChunk models.MongoBlogChunk
+		if err := cursor.Decode(&mongoChunk); err != nil {
+			return dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		if err := fn(*mongoChunk.ToDomain()); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return nil
+}
@@ -26,7 +26,7 @@ func (r *BlogReactionRepository) CreateReaction(ctx context.Context, blogReactio
 	}
 	_, err = r.collection.InsertOne(ctx, mongoBlogReaction)
 	if err != nil {
-		return domain.ErrInsertingDocuments
+		return dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 	return nil
 }
@@ -43,7 +43,7 @@ func (r *BlogReactionRepository) GetReactionByUserAndBlog(ctx context.Context, b
 		if err == mongo.ErrNoDocuments {
 			return domain.BlogReaction{}, domain.ErrBlogReactionNotFound
 		}
-		return domain.BlogReaction{}, domain.ErrDecodingDocument
+		return domain.BlogReaction{}, dbError(ctx, err, domain.ErrDecodingDocument)
 	}
 
 	return *mongoBlogReaction.ToDomainBlogReaction(), nil
@@ -83,3 +83,7 @@ func (r *BlogReactionRepository) DeleteReaction(ctx context.Context, blogID, use
 	}
 	return nil
 }
+
+func (r *BlogReactionRepository) LikedBlogsByUser(ctx context.Context) (map[string][]string, error) {
+	return blogIDsByUser(ctx, r.collection, bson.M{"reaction_type": 1})
+}
@@ -36,10 +36,13 @@ func (b *BlogMongoRepository) Create(ctx context.Context, blog domain.Blog) (str
 	if mongoBlog.Updated_at.IsZero() {
 		mongoBlog.Updated_at = time.Now()
 	}
+	if mongoBlog.Content_updated_at.IsZero() {
+		mongoBlog.Content_updated_at = mongoBlog.Updated_at
+	}
 
 	result, err := b.blogCollection.InsertOne(ctx, mongoBlog)
 	if err != nil {
-		return "", domain.ErrInsertingDocuments
+		return "", dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 	// type assertion
 	objectID, ok := result.InsertedID.(primitive.ObjectID)
@@ -62,25 +65,25 @@ func (b *BlogMongoRepository) GetAll(ctx context.Context, page, limit int) ([]do
 
 	total, err := b.blogCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		return nil, 0, domain.ErrRetrievingDocuments
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	cursor, err := b.blogCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return nil, 0, domain.ErrRetrievingDocuments
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
 		var mongoBlog models.MongoBlog
 		if err := cursor.Decode(&mongoBlog); err != nil {
-			return nil, 0, domain.ErrDecodingDocument
+			return nil, 0, dbError(ctx, err, domain.ErrDecodingDocument)
 		}
 		blogs = append(blogs, *mongoBlog.ToDomain())
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, 0, domain.ErrCursorIteration
+		return nil, 0, dbError(ctx, err, domain.ErrCursorIteration)
 	}
 
 	return blogs, int(total), nil
@@ -100,7 +103,7 @@ func (b *BlogMongoRepository) GetByID(ctx context.Context, blogID string) (domai
 		if err == mongo.ErrNoDocuments {
 			return domain.Blog{}, domain.ErrBlogNotFound
 		}
-		return domain.Blog{}, domain.ErrRetrievingDocuments
+		return domain.Blog{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	return *mongoBlog.ToDomain(), nil
@@ -118,7 +121,7 @@ func (b *BlogMongoRepository) Update(ctx context.Context, blog domain.Blog) erro
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return domain.ErrBlogNotFound
 		}
-		return domain.ErrRetrievingDocuments
+		return dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	if blog.Title == existingMongoBlog.Title &&
@@ -134,19 +137,22 @@ func (b *BlogMongoRepository) Update(ctx context.Context, blog domain.Blog) erro
 	}
 	mongoBlog.Updated_at = time.Now()
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":      mongoBlog.Title,
-			"content":    mongoBlog.Content,
-			"images":     mongoBlog.Images,
-			"tag_ids":    mongoBlog.Tag_ids,
-			"updated_at": mongoBlog.Updated_at,
-		},
+	set := bson.M{
+		"title":      mongoBlog.Title,
+		"content":    mongoBlog.Content,
+		"images":     mongoBlog.Images,
+		"tag_ids":    mongoBlog.Tag_ids,
+		"updated_at": mongoBlog.Updated_at,
+	}
+	// only a new title or content makes the AI enrichment stale
+	if blog.Title != existingMongoBlog.Title || blog.Content != existingMongoBlog.Content {
+		set["content_updated_at"] = mongoBlog.Updated_at
 	}
+	update := bson.M{"$set": set}
 
 	result, err := b.blogCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	if result.MatchedCount == 0 {
 		return domain.ErrBlogNotFound
@@ -165,7 +171,7 @@ func (b *BlogMongoRepository) Delete(ctx context.Context, blogID string) error {
 
 	result, err := b.blogCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return domain.ErrDeletingDocument
+		return dbError(ctx, err, domain.ErrDeletingDocument)
 	}
 
 	if result.DeletedCount == 0 {
@@ -191,12 +197,12 @@ func (b *BlogMongoRepository) Search(ctx context.Context, title string, user_ids
 
 	total, err := b.blogCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		return nil, 0, domain.ErrRetrievingDocuments
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	cursor, err := b.blogCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return nil, 0, domain.ErrQueryFailed
+		return nil, 0, dbError(ctx, err, domain.ErrQueryFailed)
 	}
 	defer cursor.Close(ctx)
 
@@ -204,13 +210,13 @@ func (b *BlogMongoRepository) Search(ctx context.Context, title string, user_ids
 	for cursor.Next(ctx) {
 		var mongoBlog models.MongoBlog
 		if err := cursor.Decode(&mongoBlog); err != nil {
-			return nil, 0, domain.ErrDocumentDecoding
+			return nil, 0, dbError(ctx, err, domain.ErrDocumentDecoding)
 		}
 		blogs = append(blogs, *mongoBlog.ToDomain())
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, 0, domain.ErrCursorFailed
+		return nil, 0, dbError(ctx, err, domain.ErrCursorFailed)
 	}
 	return blogs, int(total), nil
 }
@@ -227,7 +233,7 @@ func (r *BlogMongoRepository) IncrementLike(ctx context.Context, blogID string)
 		"$inc": bson.M{"like_count": 1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -242,7 +248,7 @@ func (r *BlogMongoRepository) DecrementLike(ctx context.Context, blogID string)
 		"$inc": bson.M{"like_count": -1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -257,7 +263,7 @@ func (r *BlogMongoRepository) IncrementDisLike(ctx context.Context, blogID strin
 		"$inc": bson.M{"dislike_count": 1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -272,7 +278,7 @@ func (r *BlogMongoRepository) DecrementDisLike(ctx context.Context, blogID strin
 		"$inc": bson.M{"dislike_count": -1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -299,7 +305,7 @@ func (r *BlogMongoRepository) ToggleLikeDislikeCounts(ctx context.Context, blogI
 		"$inc": update,
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -315,7 +321,7 @@ func (r *BlogMongoRepository) IncrementView(ctx context.Context, blogID string)
 		"$inc": bson.M{"view_count": 1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -348,12 +354,12 @@ func (b *BlogMongoRepository) Filter(ctx context.Context, params domain.FilterPa
 
 	total, err := b.blogCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		return nil, 0, domain.ErrRetrievingDocuments
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	cursor, err := b.blogCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return nil, 0, domain.ErrRetrievingDocuments
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 	defer cursor.Close(ctx)
 
@@ -361,12 +367,12 @@ func (b *BlogMongoRepository) Filter(ctx context.Context, params domain.FilterPa
 	for cursor.Next(ctx) {
 		var mongoBlog models.MongoBlog
 		if err := cursor.Decode(&mongoBlog); err != nil {
-			return nil, 0, domain.ErrDecodingDocument
+			return nil, 0, dbError(ctx, err, domain.ErrDecodingDocument)
 		}
 		blogs = append(blogs, *mongoBlog.ToDomain())
 	}
 	if err := cursor.Err(); err != nil {
-		return nil, 0, domain.ErrCursorIteration
+		return nil, 0, dbError(ctx, err, domain.ErrCursorIteration)
 	}
 	return blogs, int(total), nil
 }
@@ -382,7 +388,7 @@ func (r *BlogMongoRepository) AddCommentID(ctx context.Context, blogID, commentI
 		"$inc":  bson.M{"comment_count": 1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
@@ -397,7 +403,227 @@ func (r *BlogMongoRepository) RemoveCommentID(ctx context.Context, blogID, comme
 		"$inc":  bson.M{"comment_count": -1},
 	})
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+// related to AI enrichment
+
+func (r *BlogMongoRepository) SaveEnrichment(ctx context.Context, blogID string, version time.Time, summary string, suggestedTags []string) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrInvalidBlogIdFormat
+	}
+
+	// matching on content_updated_at drops results for content that changed meanwhile
+	_, err = r.blogCollection.UpdateOne(ctx, bson.M{"_id": objID, "content_updated_at": version}, bson.M{
+		"$set": bson.M{
+			"summary":           summary,
+			"suggested_tags":    suggestedTags,
+			"enrichment_status": domain.EnrichmentDone,
+			"enriched_version":  version,
+		},
+	})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+func (r *BlogMongoRepository) MarkEnrichmentFailed(ctx context.Context, blogID string, version time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrInvalidBlogIdFormat
+	}
+
+	_, err = r.blogCollection.UpdateOne(ctx, bson.M{"_id": objID, "content_updated_at": version}, bson.M{
+		"$set": bson.M{
+			"enrichment_status": domain.EnrichmentFailed,
+			"enriched_version":  version,
+		},
+	})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	return nil
 }
+
+func (r *BlogMongoRepository) SetAutoEnrich(ctx context.Context, blogID string, enabled bool) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrInvalidBlogIdFormat
+	}
+
+	result, err := r.blogCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{"auto_enrich": enabled},
+	})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+func (r *BlogMongoRepository) UpdateTagSuggestions(ctx context.Context, blogID string, tagIDs, suggestedTags []string) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrInvalidBlogIdFormat
+	}
+
+	// updated_at and content_updated_at are left alone: the content did not
+	// change, so neither does the enrichment state
+	result, err := r.blogCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{
+			"tag_ids":        tagIDs,
+			"suggested_tags": suggestedTags,
+		},
+	})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+func (r *BlogMongoRepository) FindStaleEnrichments(ctx context.Context, updatedBefore time.Time, limit int) ([]string, error) {
+	filter := bson.M{
+		"auto_enrich": true,
+		"content_updated_at": bson.M{"$lt": updatedBefore},
+		// a missing enriched_version sorts before any date
+		"$expr": bson.M{"$lt": bson.A{"$enriched_version", "$content_updated_at"}},
+	}
+	findOptions := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.M{"content_updated_at": 1}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.blogCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var blogIDs []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		blogIDs = append(blogIDs, doc.ID.Hex())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return blogIDs, nil
+}
+
+// FindPublished returns the newest blogs created in (since, until] that are
+// by one of authorIDs or tagged with one of tagIDs.
+func (r *BlogMongoRepository) FindPublished(ctx context.Context, since, until time.Time, authorIDs, tagIDs []string, limit int) ([]domain.Blog, error) {
+	if len(authorIDs) == 0 && len(tagIDs) == 0 {
+		return []domain.Blog{}, nil
+	}
+	filter := bson.M{
+		"created_at": bson.M{"$gt": since, "$lte": until},
+		"$or": bson.A{
+			bson.M{"user_id": bson.M{"$in": nonNil(authorIDs)}},
+			bson.M{"tag_ids": bson.M{"$in": nonNil(tagIDs)}},
+		},
+	}
+	findOptions := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.blogCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	blogs := []domain.Blog{}
+	for cursor.Next(ctx) {
+		var mongoBlog models.MongoBlog
+		if err := cursor.Decode(&mongoBlog); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		blogs = append(blogs, *mongoBlog.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return blogs, nil
+}
+
+// nonNil keeps $in from receiving a null array.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
+
+// related to recommendations
+
+func (r *BlogMongoRepository) FindByIDs(ctx context.Context, blogIDs []string) ([]domain.Blog, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(blogIDs))
+	for _, id := range blogIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, domain.ErrInvalidBlogID
+		}
+		objIDs = append(objIDs, objID)
+	}
+	if len(objIDs) == 0 {
+		return []domain.Blog{}, nil
+	}
+
+	cursor, err := r.blogCollection.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	blogs := []domain.Blog{}
+	for cursor.Next(ctx) {
+		var mongoBlog models.MongoBlog
+		if err := cursor.Decode(&mongoBlog); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		blogs = append(blogs, *mongoBlog.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return blogs, nil
+}
+
+func (r *BlogMongoRepository) TagIDsByBlog(ctx context.Context) (map[string][]string, error) {
+	cursor, err := r.blogCollection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"tag_ids": 1}))
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	tagsByBlog := make(map[string][]string)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			TagIDs []string           `bson:"tag_ids"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		tagsByBlog[doc.ID.Hex()] = doc.TagIDs
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return tagsByBlog, nil
+}
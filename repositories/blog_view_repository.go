@@ -8,7 +8,6 @@ import (
 	"github.com/InkForge/Blog_Website/repositories/mongo/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BlogViewRepository struct {
@@ -16,17 +15,8 @@ type BlogViewRepository struct {
 }
 
 func NewBlogViewRepository(db *mongo.Database) domain.IBlogViewRepository {
-	collection := db.Collection("blog_views")
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}},
-		Options: (&options.IndexOptions{}).SetUnique(true),
-	}
-	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
-	if err != nil {
-	}
-
 	return &BlogViewRepository{
-		collection: collection,
+		collection: db.Collection("blog_views"),
 	}
 }
 
@@ -45,7 +35,60 @@ func (r *BlogViewRepository) CreateViewRecord(ctx context.Context, blogID string
 		if mongo.IsDuplicateKeyError(err) {
 			return domain.ErrViewRecordAlreadyExists
 		}
-		return domain.ErrInsertingDocuments
+		return dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 	return nil
 }
+
+func (r *BlogViewRepository) ViewedBlogsByUser(ctx context.Context) (map[string][]string, error) {
+	return blogIDsByUser(ctx, r.collection, bson.M{})
+}
+
+func (r *BlogViewRepository) FilterViewed(ctx context.Context, userID string, blogIDs []string) ([]string, error) {
+	if userID == "" || len(blogIDs) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{"user_id": userID, "blog_id": bson.M{"$in": blogIDs}}
+	viewed, err := r.collection.Distinct(ctx, "blog_id", filter)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+
+	ids := make([]string, 0, len(viewed))
+	for _, v := range viewed {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// blogIDsByUser groups the blog_id of the documents matching match by user_id.
+func blogIDsByUser(ctx context.Context, collection *mongo.Collection, match bson.M) (map[string][]string, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id", "blog_ids": bson.M{"$addToSet": "$blog_id"}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	byUser := make(map[string][]string)
+	for cursor.Next(ctx) {
+		var row struct {
+			UserID  string   `bson:"_id"`
+			BlogIDs []string `bson:"blog_ids"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		byUser[row.UserID] = row.BlogIDs
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return byUser, nil
+}
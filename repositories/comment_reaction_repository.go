@@ -24,7 +24,7 @@ func (c CommentReactionMongoRepository) Create(ctx context.Context, reaction dom
 	reactionMongo := models.FromDomainCommentReaction(&reaction)
 	result, err := c.reactionCollection.InsertOne(ctx, reactionMongo)
 	if err != nil {
-		return domain.ErrInsertingDocuments
+		return dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 	
 	// Safe type assertion with error handling
@@ -46,7 +46,7 @@ func (c CommentReactionMongoRepository) GetByCommentAndUser(ctx context.Context,
 		if err == mongo.ErrNoDocuments {
 			return domain.CommentReaction{}, domain.ErrCommentReactionNotFound
 		}
-		return domain.CommentReaction{}, domain.ErrRetrievingDocuments
+		return domain.CommentReaction{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	return *reactionModel.ToDomain(), nil
@@ -68,7 +68,7 @@ func (c CommentReactionMongoRepository) Update(ctx context.Context, reaction dom
 
 	result, err := c.reactionCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	if result.MatchedCount == 0 {
 		return domain.ErrCommentReactionNotFound
@@ -84,7 +84,7 @@ func (c CommentReactionMongoRepository) Delete(ctx context.Context, commentID, u
 
 	result, err := c.reactionCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return domain.ErrDeletingDocument
+		return dbError(ctx, err, domain.ErrDeletingDocument)
 	}
 
 	if result.DeletedCount == 0 {
@@ -102,7 +102,7 @@ func (c CommentReactionMongoRepository) GetReactionCounts(ctx context.Context, c
 	}
 	likeCount, err := c.reactionCollection.CountDocuments(ctx, likeFilter)
 	if err != nil {
-		return 0, 0, domain.ErrRetrievingDocuments
+		return 0, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	// Count dislikes (action = -1)
@@ -112,7 +112,7 @@ func (c CommentReactionMongoRepository) GetReactionCounts(ctx context.Context, c
 	}
 	dislikeCount, err := c.reactionCollection.CountDocuments(ctx, dislikeFilter)
 	if err != nil {
-		return 0, 0, domain.ErrRetrievingDocuments
+		return 0, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 
 	return int(likeCount), int(dislikeCount), nil
@@ -25,7 +25,7 @@ func (c CommentMongoRepository) Create(ctx context.Context, comment domain.Comme
 	commentMongo := models.FromDomainComment(&comment)
 	result, err := c.commentCollection.InsertOne(ctx, commentMongo)
 	if err != nil {
-		return "", domain.ErrInsertingDocuments
+		return "", dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 
     // Safe type assertion with error handling
@@ -56,7 +56,7 @@ func (c CommentMongoRepository) GetByID(ctx context.Context, commentID string) (
         if err == mongo.ErrNoDocuments {
             return domain.Comment{}, domain.ErrCommentNotFound
         }
-        return domain.Comment{}, domain.ErrRetrievingDocuments
+        return domain.Comment{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
     }
 
     return *commentModel.ToDomain(), nil
@@ -67,7 +67,7 @@ func (c CommentMongoRepository) GetByBlogID(ctx context.Context, blogID string)
 	filter := bson.M{"blog_id": blogID}
 	cursor, err := c.commentCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, domain.ErrRetrievingDocuments
+		return nil, dbError(ctx, err, domain.ErrRetrievingDocuments)
 	}
 	defer cursor.Close(ctx)
 	
@@ -75,12 +75,12 @@ func (c CommentMongoRepository) GetByBlogID(ctx context.Context, blogID string)
 		var commentMongo models.CommentMongo
 		err := cursor.Decode(&commentMongo)
 		if err != nil {
-			return nil, domain.ErrDecodingDocument
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 		}
 		comments = append(comments, *commentMongo.ToDomain())
 	}
 	if err := cursor.Err(); err != nil {
-		return nil, domain.ErrCursorIteration
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
 	}
 	return comments, nil
 }
@@ -98,7 +98,7 @@ func (c CommentMongoRepository) Update(ctx context.Context, comment domain.Comme
 
 	result, err := c.commentCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	if result.MatchedCount == 0 {
 		return domain.ErrCommentNotFound
@@ -111,7 +111,7 @@ func (c CommentMongoRepository) Delete(ctx context.Context, commentID string) er
 
 	result, err := c.commentCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return domain.ErrDeletingDocument
+		return dbError(ctx, err, domain.ErrDeletingDocument)
 	}
 
 	if result.DeletedCount == 0 {
@@ -134,7 +134,7 @@ func (c CommentMongoRepository) UpdateReactionCounts(ctx context.Context, commen
 
 	result, err := c.commentCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return domain.ErrUpdatingDocument
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
 	}
 	if result.MatchedCount == 0 {
 		return domain.ErrCommentNotFound
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ContentRepository struct {
+	tags     *mongo.Collection
+	blogs    *mongo.Collection
+	comments *mongo.Collection
+}
+
+func NewContentRepository(db *mongo.Database) domain.IContentRepository {
+	return &ContentRepository{
+		tags:     db.Collection("tags"),
+		blogs:    db.Collection("blogs"),
+		comments: db.Collection("comments"),
+	}
+}
+
+func (r *ContentRepository) EachTag(ctx context.Context, fn func(domain.Tag) error) error {
+	return eachDocument(ctx, r.tags, func(tag *models.MongoTag) error {
+		return fn(*tag.ToDomain())
+	})
+}
+
+func (r *ContentRepository) EachBlog(ctx context.Context, fn func(domain.Blog) error) error {
+	return eachDocument(ctx, r.blogs, func(blog *models.MongoBlog) error {
+		return fn(*blog.ToDomain())
+	})
+}
+
+func (r *ContentRepository) EachComment(ctx context.Context, fn func(domain.Comment) error) error {
+	return eachDocument(ctx, r.comments, func(comment *models.CommentMongo) error {
+		c := comment.ToDomain()
+		// comments whose create was interrupted only have the _id
+		if c.Comment_id == "" {
+			c.Comment_id = comment.ID.Hex()
+		}
+		return fn(*c)
+	})
+}
+
+func (r *ContentRepository) UpsertTag(ctx context.Context, tag domain.Tag) error {
+	if tag.Tag_id == "" {
+		return domain.ErrInvalidInput
+	}
+	model, err := models.TagFromDomain(&tag)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+	return upsertByID(ctx, r.tags, model.Tag_id, model)
+}
+
+func (r *ContentRepository) UpsertBlog(ctx context.Context, blog domain.Blog) error {
+	if blog.Blog_id == "" {
+		return domain.ErrInvalidBlogID
+	}
+	model, err := models.FromDomain(&blog)
+	if err != nil {
+		return err
+	}
+	return upsertByID(ctx, r.blogs, model.Blog_id, model)
+}
+
+func (r *ContentRepository) UpsertComment(ctx context.Context, comment domain.Comment) error {
+	objID, err := primitive.ObjectIDFromHex(comment.Comment_id)
+	if err != nil {
+		return domain.ErrInvalidCommentID
+	}
+	model := models.FromDomainComment(&comment)
+	model.ID = objID
+	return upsertByID(ctx, r.comments, objID, model)
+}
+
+// eachDocument decodes every document of collection into a T and calls fn with it.
+func eachDocument[T any](ctx context.Context, collection *mongo.Collection, fn func(*T) error) error {
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		doc := new(T)
+		if err := cursor.Decode(doc); err != nil {
+			return dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return nil
+}
+
+func upsertByID(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, doc interface{}) error {
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reactionCounts are likes and dislikes counted from a reactions collection.
+type reactionCounts struct {
+	ID       string `bson:"_id"`
+	Likes    int    `bson:"likes"`
+	Dislikes int    `bson:"dislikes"`
+}
+
+type CounterRepository struct {
+	blogs            *mongo.Collection
+	blogReactions    *mongo.Collection
+	blogViews        *mongo.Collection
+	comments         *mongo.Collection
+	commentReactions *mongo.Collection
+}
+
+func NewCounterRepository(db *mongo.Database) domain.ICounterRepository {
+	return &CounterRepository{
+		blogs:            db.Collection("blogs"),
+		blogReactions:    db.Collection("blog_reactions"),
+		blogViews:        db.Collection("blog_views"),
+		comments:         db.Collection("comments"),
+		commentReactions: db.Collection("comment_reactions"),
+	}
+}
+
+// FindBlogDiscrepancies counts reactions, views and comments per blog with one
+// aggregation each, then compares them with the counters of every blog.
+func (r *CounterRepository) FindBlogDiscrepancies(ctx context.Context, fn func(domain.CounterDiscrepancy) error) (int, error) {
+	reactions, err := r.countReactions(ctx, r.blogReactions, "blog_id", "reaction_type")
+	if err != nil {
+		return 0, err
+	}
+	views, err := r.countBy(ctx, r.blogViews, "blog_id")
+	if err != nil {
+		return 0, err
+	}
+	comments, err := r.countBy(ctx, r.comments, "blog_id")
+	if err != nil {
+		return 0, err
+	}
+
+	projection := bson.M{"like_count": 1, "dislike_count": 1, "view_count": 1, "comment_count": 1}
+	cursor, err := r.blogs.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	checked := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID           primitive.ObjectID `bson:"_id"`
+			LikeCount    int                `bson:"like_count"`
+			DislikeCount int                `bson:"dislike_count"`
+			ViewCount    int                `bson:"view_count"`
+			CommentCount int                `bson:"comment_count"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return checked, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		checked++
+
+		id := doc.ID.Hex()
+		err := compareCounters(fn, "blogs", id, []counterPair{
+			{"like_count", doc.LikeCount, reactions[id].Likes},
+			{"dislike_count", doc.DislikeCount, reactions[id].Dislikes},
+			{"view_count", doc.ViewCount, views[id]},
+			{"comment_count", doc.CommentCount, comments[id]},
+		})
+		if err != nil {
+			return checked, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return checked, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return checked, nil
+}
+
+func (r *CounterRepository) FindCommentDiscrepancies(ctx context.Context, fn func(domain.CounterDiscrepancy) error) (int, error) {
+	reactions, err := r.countReactions(ctx, r.commentReactions, "comment_id", "action")
+	if err != nil {
+		return 0, err
+	}
+
+	projection := bson.M{"comment_id": 1, "like": 1, "dislike": 1}
+	cursor, err := r.comments.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	checked := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			CommentID string             `bson:"comment_id"`
+			Like      int                `bson:"like"`
+			Dislike   int                `bson:"dislike"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return checked, dbError(ctx, err, domain.ErrDocumentDecoding)
+		}
+		checked++
+
+		id := doc.CommentID
+		if id == "" {
+			id = doc.ID.Hex()
+		}
+		err := compareCounters(fn, "comments", id, []counterPair{
+			{"like", doc.Like, reactions[id].Likes},
+			{"dislike", doc.Dislike, reactions[id].Dislikes},
+		})
+		if err != nil {
+			return checked, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return checked, dbError(ctx, err, domain.ErrCursorFailed)
+	}
+	return checked, nil
+}
+
+// Repair counts again right before writing: a reaction added between the
+// aggregation and the read of the counter would otherwise look like drift and
+// be taken back. The update only matches while the counter still holds the
+// stored value, so one counted in the meantime is not overwritten either.
+func (r *CounterRepository) Repair(ctx context.Context, d domain.CounterDiscrepancy) error {
+	var collection *mongo.Collection
+	filter := bson.M{d.Field: d.Stored}
+	switch d.Collection {
+	case "blogs":
+		objID, err := primitive.ObjectIDFromHex(d.Document_id)
+		if err != nil {
+			return domain.ErrInvalidBlogID
+		}
+		collection = r.blogs
+		filter["_id"] = objID
+	case "comments":
+		collection = r.comments
+		filter["comment_id"] = d.Document_id
+		if objID, err := primitive.ObjectIDFromHex(d.Document_id); err == nil {
+			delete(filter, "comment_id")
+			filter["$or"] = []bson.M{{"comment_id": d.Document_id}, {"_id": objID}}
+		}
+	default:
+		return domain.ErrInvalidInput
+	}
+
+	actual, err := r.recount(ctx, d)
+	if err != nil {
+		return err
+	}
+	if actual == d.Stored {
+		return domain.ErrCounterChanged
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{d.Field: actual}})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCounterChanged
+	}
+	return nil
+}
+
+// recount counts the documents one counter summarizes.
+func (r *CounterRepository) recount(ctx context.Context, d domain.CounterDiscrepancy) (int, error) {
+	var collection *mongo.Collection
+	var filter bson.M
+	switch d.Collection + "." + d.Field {
+	case "blogs.like_count":
+		collection, filter = r.blogReactions, bson.M{"blog_id": d.Document_id, "reaction_type": 1}
+	case "blogs.dislike_count":
+		collection, filter = r.blogReactions, bson.M{"blog_id": d.Document_id, "reaction_type": -1}
+	case "blogs.view_count":
+		collection, filter = r.blogViews, bson.M{"blog_id": d.Document_id}
+	case "blogs.comment_count":
+		collection, filter = r.comments, bson.M{"blog_id": d.Document_id}
+	case "comments.like":
+		collection, filter = r.commentReactions, bson.M{"comment_id": d.Document_id, "action": 1}
+	case "comments.dislike":
+		collection, filter = r.commentReactions, bson.M{"comment_id": d.Document_id, "action": -1}
+	default:
+		return 0, domain.ErrInvalidInput
+	}
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	return int(count), nil
+}
+
+// countReactions sums likes (1) and dislikes (-1) in field per key.
+func (r *CounterRepository) countReactions(ctx context.Context, collection *mongo.Collection, key, field string) (map[string]reactionCounts, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + key},
+			{Key: "likes", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$" + field, 1}}}, 1, 0}}}}}},
+			{Key: "dislikes", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$" + field, -1}}}, 1, 0}}}}}},
+		}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	var rows []reactionCounts
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+	}
+	counts := make(map[string]reactionCounts, len(rows))
+	for _, row := range rows {
+		counts[row.ID] = row
+	}
+	return counts, nil
+}
+
+// countBy counts the documents per value of key.
+func (r *CounterRepository) countBy(ctx context.Context, collection *mongo.Collection, key string) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + key}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	var rows []struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts, nil
+}
+
+type counterPair struct {
+	field  string
+	stored int
+	actual int
+}
+
+func compareCounters(fn func(domain.CounterDiscrepancy) error, collection, id string, pairs []counterPair) error {
+	for _, p := range pairs {
+		if p.stored == p.actual {
+			continue
+		}
+		err := fn(domain.CounterDiscrepancy{
+			Collection:  collection,
+			Document_id: id,
+			Field:       p.field,
+			Stored:      p.stored,
+			Actual:      p.actual,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DigestSubscriptionRepository stores each reader's digest preferences and
+// the watermark of their last digest.
+type DigestSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDigestSubscriptionRepository(db *mongo.Database) domain.IDigestSubscriptionRepository {
+	return &DigestSubscriptionRepository{collection: db.Collection("digest_subscriptions")}
+}
+
+func (r *DigestSubscriptionRepository) Get(ctx context.Context, userID string) (domain.DigestSubscription, error) {
+	var sub models.MongoDigestSubscription
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&sub)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.DigestSubscription{}, domain.ErrDigestSubscriptionNotFound
+		}
+		return domain.DigestSubscription{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return sub.ToDomain(), nil
+}
+
+func (r *DigestSubscriptionRepository) Upsert(ctx context.Context, sub domain.DigestSubscription) error {
+	filter := bson.M{"user_id": sub.User_id}
+	_, err := r.collection.ReplaceOne(ctx, filter, models.FromDomainDigestSubscription(&sub), options.Replace().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+// FindDue returns subscriptions with digests on whose next digest is due, oldest first.
+func (r *DigestSubscriptionRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.DigestSubscription, error) {
+	filter := bson.M{
+		"frequency":    bson.M{"$in": []domain.DigestFrequency{domain.DigestDaily, domain.DigestWeekly}},
+		"next_send_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_send_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var subs []domain.DigestSubscription
+	for cursor.Next(ctx) {
+		var sub models.MongoDigestSubscription
+		if err := cursor.Decode(&sub); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		subs = append(subs, sub.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return subs, nil
+}
+
+// Advance claims a due digest in a single update, so when two instances send
+// digests at once only one of them sends a given user's.
+func (r *DigestSubscriptionRepository) Advance(ctx context.Context, userID string, now, sentAt, nextSendAt time.Time) (domain.DigestSubscription, error) {
+	filter := bson.M{
+		"user_id":      userID,
+		"frequency":    bson.M{"$in": []domain.DigestFrequency{domain.DigestDaily, domain.DigestWeekly}},
+		"next_send_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"last_sent_at": sentAt, "next_send_at": nextSendAt}}
+	// the digest is built from the watermark it replaces
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var sub models.MongoDigestSubscription
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return domain.DigestSubscription{}, domain.ErrDigestNotDue
+	}
+	if err != nil {
+		return domain.DigestSubscription{}, dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return sub.ToDomain(), nil
+}
+
+func (r *DigestSubscriptionRepository) SetFrequency(ctx context.Context, userID string, frequency domain.DigestFrequency) error {
+	update := bson.M{"$set": bson.M{"frequency": frequency, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"user_id": userID}, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrDigestSubscriptionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmailOutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewEmailOutboxRepository(db *mongo.Database) domain.IEmailOutboxRepository {
+	return &EmailOutboxRepository{collection: db.Collection("email_outbox")}
+}
+
+func (r *EmailOutboxRepository) Insert(ctx context.Context, email domain.OutboxEmail) (domain.OutboxEmail, error) {
+	result, err := r.collection.InsertOne(ctx, models.FromDomainOutboxEmail(&email))
+	if err != nil {
+		return domain.OutboxEmail{}, dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		email.Email_id = id.Hex()
+	}
+	return email, nil
+}
+
+func (r *EmailOutboxRepository) InsertMany(ctx context.Context, emails []domain.OutboxEmail) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	docs := make([]interface{}, len(emails))
+	for i := range emails {
+		docs[i] = models.FromDomainOutboxEmail(&emails[i])
+	}
+	result, err := r.collection.InsertMany(ctx, docs)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	ids := make([]string, len(result.InsertedIDs))
+	for i, insertedID := range result.InsertedIDs {
+		if id, ok := insertedID.(primitive.ObjectID); ok {
+			ids[i] = id.Hex()
+		}
+	}
+	return ids, nil
+}
+
+// Claim takes a pending email, or a sending one whose lease ran out, in a
+// single update so only one worker gets it.
+func (r *EmailOutboxRepository) Claim(ctx context.Context, emailID string, now, leaseUntil time.Time) (domain.OutboxEmail, error) {
+	id, err := primitive.ObjectIDFromHex(emailID)
+	if err != nil {
+		return domain.OutboxEmail{}, domain.ErrOutboxEmailNotFound
+	}
+	filter := bson.M{
+		"_id":             id,
+		"status":          bson.M{"$in": []domain.OutboxStatus{domain.OutboxPending, domain.OutboxSending}},
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"status": domain.OutboxSending, "next_attempt_at": leaseUntil}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var email models.MongoOutboxEmail
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&email)
+	if err == mongo.ErrNoDocuments {
+		return domain.OutboxEmail{}, domain.ErrOutboxEmailNotDue
+	}
+	if err != nil {
+		return domain.OutboxEmail{}, dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return email.ToDomain(), nil
+}
+
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, emailID string, sentAt time.Time) error {
+	return r.update(ctx, emailID, bson.M{
+		"$set":   bson.M{"status": domain.OutboxSent, "sent_at": sentAt},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"last_error": ""},
+	})
+}
+
+func (r *EmailOutboxRepository) MarkFailed(ctx context.Context, emailID string, status domain.OutboxStatus, attempts int, lastError string, delivered []string, nextAttemptAt time.Time) error {
+	return r.update(ctx, emailID, bson.M{"$set": bson.M{
+		"status":             status,
+		"attempts":           attempts,
+		"last_error":         lastError,
+		"delivered_channels": delivered,
+		"next_attempt_at":    nextAttemptAt,
+	}})
+}
+
+func (r *EmailOutboxRepository) update(ctx context.Context, emailID string, update bson.M) error {
+	id, err := primitive.ObjectIDFromHex(emailID)
+	if err != nil {
+		return domain.ErrOutboxEmailNotFound
+	}
+	result, err := r.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrOutboxEmailNotFound
+	}
+	return nil
+}
+
+// FindDue returns pending emails and abandoned sends whose time has come, oldest first.
+func (r *EmailOutboxRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	filter := bson.M{
+		"status":          bson.M{"$in": []domain.OutboxStatus{domain.OutboxPending, domain.OutboxSending}},
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return ids, nil
+}
+
+func (r *EmailOutboxRepository) List(ctx context.Context, status domain.OutboxStatus, page, limit int) ([]domain.OutboxEmail, int, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"html": 0, "text": 0})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	emails := []domain.OutboxEmail{}
+	for cursor.Next(ctx) {
+		var email models.MongoOutboxEmail
+		if err := cursor.Decode(&email); err != nil {
+			return nil, 0, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		emails = append(emails, email.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return emails, int(total), nil
+}
+
+func (r *EmailOutboxRepository) CountByStatus(ctx context.Context) (map[domain.OutboxStatus]int, error) {
+	return r.countByStatus(ctx, bson.M{})
+}
+
+func (r *EmailOutboxRepository) CountByBatch(ctx context.Context, batchID string) (map[domain.OutboxStatus]int, error) {
+	return r.countByStatus(ctx, bson.M{"batch_id": batchID})
+}
+
+func (r *EmailOutboxRepository) countByStatus(ctx context.Context, filter bson.M) (map[domain.OutboxStatus]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	counts := map[domain.OutboxStatus]int{
+		domain.OutboxPending: 0,
+		domain.OutboxSending: 0,
+		domain.OutboxSent:    0,
+		domain.OutboxDead:    0,
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Status string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		counts[domain.OutboxStatus(row.Status)] = row.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return counts, nil
+}
+
+func (r *EmailOutboxRepository) Requeue(ctx context.Context, emailID string, now time.Time) error {
+	id, err := primitive.ObjectIDFromHex(emailID)
+	if err != nil {
+		return domain.ErrOutboxEmailNotFound
+	}
+	filter := bson.M{"_id": id, "status": domain.OutboxDead}
+	update := bson.M{"$set": bson.M{"status": domain.OutboxPending, "attempts": 0, "next_attempt_at": now}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+		if err == nil && count == 0 {
+			return domain.ErrOutboxEmailNotFound
+		}
+		return domain.ErrOutboxEmailNotDead
+	}
+	return nil
+}
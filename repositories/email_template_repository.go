@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EmailTemplateRepository stores admin overrides of the built-in email templates.
+type EmailTemplateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewEmailTemplateRepository(db *mongo.Database) domain.IEmailTemplateRepository {
+	return &EmailTemplateRepository{collection: db.Collection("email_templates")}
+}
+
+func (r *EmailTemplateRepository) Get(ctx context.Context, name, locale string) (domain.EmailTemplate, error) {
+	var template models.MongoEmailTemplate
+	err := r.collection.FindOne(ctx, bson.M{"name": name, "locale": locale}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.EmailTemplate{}, domain.ErrEmailTemplateNotFound
+		}
+		return domain.EmailTemplate{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return template.ToDomain(), nil
+}
+
+// List returns every override, ordered by name and locale.
+func (r *EmailTemplateRepository) List(ctx context.Context) ([]domain.EmailTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "locale", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	templates := []domain.EmailTemplate{}
+	for cursor.Next(ctx) {
+		var template models.MongoEmailTemplate
+		if err := cursor.Decode(&template); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		templates = append(templates, template.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return templates, nil
+}
+
+// Upsert replaces the override of the template's name and locale.
+func (r *EmailTemplateRepository) Upsert(ctx context.Context, template domain.EmailTemplate) error {
+	if template.Updated_at.IsZero() {
+		template.Updated_at = time.Now()
+	}
+	filter := bson.M{"name": template.Name, "locale": template.Locale}
+	_, err := r.collection.ReplaceOne(ctx, filter, models.FromDomainEmailTemplate(&template), options.Replace().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+func (r *EmailTemplateRepository) Delete(ctx context.Context, name, locale string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"name": name, "locale": locale})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrDeletingDocument)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrEmailTemplateNotFound
+	}
+	return nil
+}
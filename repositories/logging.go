@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// dbError logs the driver error behind a failed operation and returns kind,
+// the repository error callers see in its place.
+func dbError(ctx context.Context, err, kind error) error {
+	op := "unknown"
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			op = strings.TrimPrefix(fn.Name(), "github.com/InkForge/Blog_Website/repositories.")
+		}
+	}
+	slog.ErrorContext(ctx, "database operation failed", "op", op, "kind", kind, "error", err)
+	return kind
+}
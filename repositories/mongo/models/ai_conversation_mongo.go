@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoAIMessage struct {
+	Role       string    `bson:"role"`
+	Content    string    `bson:"content"`
+	Created_at time.Time `bson:"created_at"`
+}
+
+type MongoAIConversation struct {
+	Conversation_id primitive.ObjectID `bson:"_id,omitempty"`
+	User_id         string             `bson:"user_id"`
+	Blog_id         string             `bson:"blog_id,omitempty"`
+	Title           string             `bson:"title"`
+	Messages        []MongoAIMessage   `bson:"messages"`
+
+	Created_at time.Time `bson:"created_at"`
+	Updated_at time.Time `bson:"updated_at"`
+}
+
+func FromDomainAIMessages(messages []domain.AIMessage) []MongoAIMessage {
+	out := make([]MongoAIMessage, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, MongoAIMessage{Role: m.Role, Content: m.Content, Created_at: m.Created_at})
+	}
+	return out
+}
+
+func FromDomainAIConversation(conversation *domain.AIConversation) (*MongoAIConversation, error) {
+	var objID primitive.ObjectID
+	if conversation.Conversation_id != "" {
+		var err error
+		objID, err = primitive.ObjectIDFromHex(conversation.Conversation_id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidConversationID, err)
+		}
+	}
+	return &MongoAIConversation{
+		Conversation_id: objID,
+		User_id:         conversation.User_id,
+		Blog_id:         conversation.Blog_id,
+		Title:           conversation.Title,
+		Messages:        FromDomainAIMessages(conversation.Messages),
+		Created_at:      conversation.Created_at,
+		Updated_at:      conversation.Updated_at,
+	}, nil
+}
+
+func (c *MongoAIConversation) ToDomain() *domain.AIConversation {
+	messages := make([]domain.AIMessage, 0, len(c.Messages))
+	for _, m := range c.Messages {
+		messages = append(messages, domain.AIMessage{Role: m.Role, Content: m.Content, Created_at: m.Created_at})
+	}
+	return &domain.AIConversation{
+		Conversation_id: c.Conversation_id.Hex(),
+		User_id:         c.User_id,
+		Blog_id:         c.Blog_id,
+		Title:           c.Title,
+		Messages:        messages,
+		Created_at:      c.Created_at,
+		Updated_at:      c.Updated_at,
+	}
+}
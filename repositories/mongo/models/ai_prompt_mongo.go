@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoPromptTemplate struct {
+	Template_id primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Version     int                `bson:"version"`
+	Body        string             `bson:"body"`
+	Created_by  string             `bson:"created_by"`
+	Created_at  time.Time          `bson:"created_at"`
+}
+
+type MongoPromptRolloutEntry struct {
+	Version int `bson:"version"`
+	Weight  int `bson:"weight"`
+}
+
+type MongoPromptRollout struct {
+	Name       string                    `bson:"name"`
+	Versions   []MongoPromptRolloutEntry `bson:"versions"`
+	Updated_at time.Time                 `bson:"updated_at"`
+}
+
+func FromDomainPromptTemplate(t *domain.PromptTemplate) *MongoPromptTemplate {
+	return &MongoPromptTemplate{
+		Name:       t.Name,
+		Version:    t.Version,
+		Body:       t.Body,
+		Created_by: t.Created_by,
+		Created_at: t.Created_at,
+	}
+}
+
+func (t *MongoPromptTemplate) ToDomain() domain.PromptTemplate {
+	return domain.PromptTemplate{
+		Template_id: t.Template_id.Hex(),
+		Name:        t.Name,
+		Version:     t.Version,
+		Body:        t.Body,
+		Created_by:  t.Created_by,
+		Created_at:  t.Created_at,
+	}
+}
+
+func FromDomainPromptRollout(name string, rollout []domain.PromptRollout) *MongoPromptRollout {
+	versions := make([]MongoPromptRolloutEntry, len(rollout))
+	for i, r := range rollout {
+		versions[i] = MongoPromptRolloutEntry{Version: r.Version, Weight: r.Weight}
+	}
+	return &MongoPromptRollout{Name: name, Versions: versions, Updated_at: time.Now()}
+}
+
+func (r *MongoPromptRollout) ToDomain() []domain.PromptRollout {
+	rollout := make([]domain.PromptRollout, len(r.Versions))
+	for i, v := range r.Versions {
+		rollout[i] = domain.PromptRollout{Version: v.Version, Weight: v.Weight}
+	}
+	return rollout
+}
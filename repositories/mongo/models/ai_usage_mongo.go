@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoAIUsageRecord struct {
+	Usage_id          primitive.ObjectID `bson:"_id,omitempty"`
+	User_id           string             `bson:"user_id"`
+	Role              string             `bson:"role"`
+	Operation         string             `bson:"operation"`
+	Provider          string             `bson:"provider"`
+	Model             string             `bson:"model"`
+	Prompt_tokens     int                `bson:"prompt_tokens"`
+	Completion_tokens int                `bson:"completion_tokens"`
+	Total_tokens      int                `bson:"total_tokens"`
+	Tokens_estimated  bool               `bson:"tokens_estimated"`
+	Estimated_cost    float64            `bson:"estimated_cost"`
+	Latency_ms        int64              `bson:"latency_ms"`
+	Success           bool               `bson:"success"`
+	Error             string             `bson:"error,omitempty"`
+	Prompt_template   string             `bson:"prompt_template,omitempty"`
+	Prompt_version    int                `bson:"prompt_version"`
+	Created_at        time.Time          `bson:"created_at"`
+}
+
+type MongoAIQuota struct {
+	User_id          string    `bson:"user_id"`
+	Daily_requests   int       `bson:"daily_requests"`
+	Monthly_requests int       `bson:"monthly_requests"`
+	Daily_tokens     int       `bson:"daily_tokens"`
+	Monthly_tokens   int       `bson:"monthly_tokens"`
+	Updated_at       time.Time `bson:"updated_at"`
+}
+
+func FromDomainAIUsageRecord(r *domain.AIUsageRecord) *MongoAIUsageRecord {
+	return &MongoAIUsageRecord{
+		User_id:           r.User_id,
+		Role:              string(r.Role),
+		Operation:         r.Operation,
+		Provider:          r.Provider,
+		Model:             r.Model,
+		Prompt_tokens:     r.Prompt_tokens,
+		Completion_tokens: r.Completion_tokens,
+		Total_tokens:      r.Total_tokens,
+		Tokens_estimated:  r.Tokens_estimated,
+		Estimated_cost:    r.Estimated_cost,
+		Latency_ms:        r.Latency_ms,
+		Success:           r.Success,
+		Error:             r.Error,
+		Prompt_template:   r.Prompt_template,
+		Prompt_version:    r.Prompt_version,
+		Created_at:        r.Created_at,
+	}
+}
+
+func FromDomainAIQuota(userID string, q domain.AIQuota) *MongoAIQuota {
+	return &MongoAIQuota{
+		User_id:          userID,
+		Daily_requests:   q.Daily_requests,
+		Monthly_requests: q.Monthly_requests,
+		Daily_tokens:     q.Daily_tokens,
+		Monthly_tokens:   q.Monthly_tokens,
+		Updated_at:       time.Now(),
+	}
+}
+
+func (q *MongoAIQuota) ToDomain() domain.AIQuota {
+	return domain.AIQuota{
+		Daily_requests:   q.Daily_requests,
+		Monthly_requests: q.Monthly_requests,
+		Daily_tokens:     q.Daily_tokens,
+		Monthly_tokens:   q.Monthly_tokens,
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoBlogChunk struct {
+	Chunk_id   primitive.ObjectID `bson:"_id,omitempty"`
+	Blog_id    string             `bson:"blog_id"`
+	Blog_title string             `bson:"blog_title"`
+	Position   int                `bson:"position"`
+	Content    string             `bson:"content"`
+	Embedding  []float32          `bson:"embedding"`
+	Model      string             `bson:"model"`
+	Created_at time.Time          `bson:"created_at"`
+}
+
+func FromDomainBlogChunk(chunk *domain.BlogChunk) *MongoBlogChunk {
+	return &MongoBlogChunk{
+		Blog_id:    chunk.Blog_id,
+		Blog_title: chunk.Blog_title,
+		Position:   chunk.Position,
+		Content:    chunk.Content,
+		Embedding:  chunk.Embedding,
+		Model:      chunk.Model,
+		Created_at: chunk.Created_at,
+	}
+}
+
+func (c *MongoBlogChunk) ToDomain() *domain.BlogChunk {
+	return &domain.BlogChunk{
+		Chunk_id:   c.Chunk_id.Hex(),
+		Blog_id:    c.Blog_id,
+		Blog_title: c.Blog_title,
+		Position:   c.Position,
+		Content:    c.Content,
+		Embedding:  c.Embedding,
+		Model:      c.Model,
+		Created_at: c.Created_at,
+	}
+}
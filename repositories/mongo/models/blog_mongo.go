@@ -22,8 +22,15 @@ type MongoBlog struct {
 	Dislike_count int `bson:"dislike_count"`
 	View_count    int `bson:"view_count"`
 
-	Created_at time.Time `bson:"created_at"`
-	Updated_at time.Time `bson:"updated_at"`
+	Auto_enrich       bool      `bson:"auto_enrich"`
+	Summary           string    `bson:"summary,omitempty"`
+	Suggested_tags    []string  `bson:"suggested_tags,omitempty"`
+	Enrichment_status string    `bson:"enrichment_status,omitempty"`
+	Enriched_version  time.Time `bson:"enriched_version,omitempty"`
+
+	Created_at         time.Time `bson:"created_at"`
+	Updated_at         time.Time `bson:"updated_at"`
+	Content_updated_at time.Time `bson:"content_updated_at"`
 }
 
 func FromDomain(blog *domain.Blog) (*MongoBlog, error) {
@@ -49,8 +56,15 @@ func FromDomain(blog *domain.Blog) (*MongoBlog, error) {
 		Dislike_count: blog.Dislike_count,
 		View_count:    blog.View_count,
 
-		Created_at: blog.Created_at,
-		Updated_at: blog.Updated_at,
+		Auto_enrich:       blog.Auto_enrich,
+		Summary:           blog.Summary,
+		Suggested_tags:    blog.Suggested_tags,
+		Enrichment_status: blog.Enrichment_status,
+		Enriched_version:  blog.Enriched_version,
+
+		Created_at:         blog.Created_at,
+		Updated_at:         blog.Updated_at,
+		Content_updated_at: blog.Content_updated_at,
 	}, nil
 }
 
@@ -70,7 +84,14 @@ func (b *MongoBlog) ToDomain() *domain.Blog {
 		Dislike_count: b.Dislike_count,
 		View_count:    b.View_count,
 
-		Created_at: b.Created_at,
-		Updated_at: b.Updated_at,
+		Auto_enrich:       b.Auto_enrich,
+		Summary:           b.Summary,
+		Suggested_tags:    b.Suggested_tags,
+		Enrichment_status: b.Enrichment_status,
+		Enriched_version:  b.Enriched_version,
+
+		Created_at:         b.Created_at,
+		Updated_at:         b.Updated_at,
+		Content_updated_at: b.Content_updated_at,
 	}
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type MongoDigestSubscription struct {
+	User_id      string    `bson:"user_id"`
+	Frequency    string    `bson:"frequency"`
+	Author_ids   []string  `bson:"author_ids"`
+	Tag_ids      []string  `bson:"tag_ids"`
+	Last_sent_at time.Time `bson:"last_sent_at"`
+	Next_send_at time.Time `bson:"next_send_at"`
+	Created_at   time.Time `bson:"created_at"`
+	Updated_at   time.Time `bson:"updated_at"`
+}
+
+func FromDomainDigestSubscription(s *domain.DigestSubscription) *MongoDigestSubscription {
+	return &MongoDigestSubscription{
+		User_id:      s.User_id,
+		Frequency:    string(s.Frequency),
+		Author_ids:   s.Author_ids,
+		Tag_ids:      s.Tag_ids,
+		Last_sent_at: s.Last_sent_at,
+		Next_send_at: s.Next_send_at,
+		Created_at:   s.Created_at,
+		Updated_at:   s.Updated_at,
+	}
+}
+
+func (s *MongoDigestSubscription) ToDomain() domain.DigestSubscription {
+	return domain.DigestSubscription{
+		User_id:      s.User_id,
+		Frequency:    domain.DigestFrequency(s.Frequency),
+		Author_ids:   s.Author_ids,
+		Tag_ids:      s.Tag_ids,
+		Last_sent_at: s.Last_sent_at,
+		Next_send_at: s.Next_send_at,
+		Created_at:   s.Created_at,
+		Updated_at:   s.Updated_at,
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoOutboxEmail struct {
+	Email_id        primitive.ObjectID `bson:"_id,omitempty"`
+	To              string             `bson:"to"`
+	Subject         string             `bson:"subject"`
+	Html            string             `bson:"html"`
+	Text            string             `bson:"text"`
+	Template        string             `bson:"template,omitempty"`
+	Batch_id        string             `bson:"batch_id,omitempty"`
+	Status          string             `bson:"status"`
+	Attempts        int                `bson:"attempts"`
+	Last_error      string             `bson:"last_error,omitempty"`
+	Next_attempt_at time.Time          `bson:"next_attempt_at"`
+	Created_at      time.Time          `bson:"created_at"`
+	// only set once sent, so the TTL index leaves unsent emails alone
+	Sent_at *time.Time `bson:"sent_at,omitempty"`
+	// channels that accepted the email before a failed attempt
+	Delivered_channels []string `bson:"delivered_channels,omitempty"`
+}
+
+func FromDomainOutboxEmail(e *domain.OutboxEmail) *MongoOutboxEmail {
+	m := &MongoOutboxEmail{
+		To:              e.To,
+		Subject:         e.Subject,
+		Html:            e.Html,
+		Text:            e.Text,
+		Template:        e.Template,
+		Batch_id:        e.Batch_id,
+		Status:          string(e.Status),
+		Attempts:        e.Attempts,
+		Last_error:      e.Last_error,
+		Next_attempt_at: e.Next_attempt_at,
+		Created_at:      e.Created_at,
+
+		Delivered_channels: e.Delivered_channels,
+	}
+	if !e.Sent_at.IsZero() {
+		sentAt := e.Sent_at
+		m.Sent_at = &sentAt
+	}
+	return m
+}
+
+func (m *MongoOutboxEmail) ToDomain() domain.OutboxEmail {
+	e := domain.OutboxEmail{
+		Email_id:        m.Email_id.Hex(),
+		To:              m.To,
+		Subject:         m.Subject,
+		Html:            m.Html,
+		Text:            m.Text,
+		Template:        m.Template,
+		Batch_id:        m.Batch_id,
+		Status:          domain.OutboxStatus(m.Status),
+		Attempts:        m.Attempts,
+		Last_error:      m.Last_error,
+		Next_attempt_at: m.Next_attempt_at,
+		Created_at:      m.Created_at,
+
+		Delivered_channels: m.Delivered_channels,
+	}
+	if m.Sent_at != nil {
+		e.Sent_at = *m.Sent_at
+	}
+	return e
+}
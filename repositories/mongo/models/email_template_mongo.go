@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+type MongoEmailTemplate struct {
+	Name       string    `bson:"name"`
+	Locale     string    `bson:"locale"`
+	Subject    string    `bson:"subject"`
+	Html       string    `bson:"html"`
+	Text       string    `bson:"text"`
+	Updated_by string    `bson:"updated_by"`
+	Updated_at time.Time `bson:"updated_at"`
+}
+
+func FromDomainEmailTemplate(t *domain.EmailTemplate) *MongoEmailTemplate {
+	return &MongoEmailTemplate{
+		Name:       t.Name,
+		Locale:     t.Locale,
+		Subject:    t.Subject,
+		Html:       t.Html,
+		Text:       t.Text,
+		Updated_by: t.Updated_by,
+		Updated_at: t.Updated_at,
+	}
+}
+
+func (t *MongoEmailTemplate) ToDomain() domain.EmailTemplate {
+	return domain.EmailTemplate{
+		Name:       t.Name,
+		Locale:     t.Locale,
+		Subject:    t.Subject,
+		Html:       t.Html,
+		Text:       t.Text,
+		Updated_by: t.Updated_by,
+		Updated_at: t.Updated_at,
+	}
+}
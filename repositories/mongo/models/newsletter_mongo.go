@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MongoNewsletterSubscriber struct {
+	Subscriber_id        primitive.ObjectID `bson:"_id,omitempty"`
+	Email                string             `bson:"email"`
+	Author_id            string             `bson:"author_id"`
+	Language             string             `bson:"language,omitempty"`
+	Status               string             `bson:"status"`
+	Confirmation_sent_at time.Time          `bson:"confirmation_sent_at"`
+	Created_at           time.Time          `bson:"created_at"`
+	Confirmed_at         time.Time          `bson:"confirmed_at,omitempty"`
+	Left_at              time.Time          `bson:"left_at,omitempty"`
+}
+
+func FromDomainNewsletterSubscriber(s *domain.NewsletterSubscriber) (*MongoNewsletterSubscriber, error) {
+	m := &MongoNewsletterSubscriber{
+		Email:                s.Email,
+		Author_id:            s.Author_id,
+		Language:             s.Language,
+		Status:               string(s.Status),
+		Confirmation_sent_at: s.Confirmation_sent_at,
+		Created_at:           s.Created_at,
+		Confirmed_at:         s.Confirmed_at,
+		Left_at:              s.Left_at,
+	}
+	if s.Subscriber_id != "" {
+		id, err := primitive.ObjectIDFromHex(s.Subscriber_id)
+		if err != nil {
+			return nil, domain.ErrSubscriberNotFound
+		}
+		m.Subscriber_id = id
+	}
+	return m, nil
+}
+
+func (m *MongoNewsletterSubscriber) ToDomain() domain.NewsletterSubscriber {
+	return domain.NewsletterSubscriber{
+		Subscriber_id:        m.Subscriber_id.Hex(),
+		Email:                m.Email,
+		Author_id:            m.Author_id,
+		Language:             m.Language,
+		Status:               domain.SubscriberStatus(m.Status),
+		Confirmation_sent_at: m.Confirmation_sent_at,
+		Created_at:           m.Created_at,
+		Confirmed_at:         m.Confirmed_at,
+		Left_at:              m.Left_at,
+	}
+}
+
+type MongoNewsletterIssue struct {
+	Issue_id     primitive.ObjectID `bson:"_id,omitempty"`
+	Author_id    string             `bson:"author_id"`
+	Blog_id      string             `bson:"blog_id"`
+	Title        string             `bson:"title"`
+	Status       string             `bson:"status"`
+	Cursor       string             `bson:"cursor"`
+	Lease_until  time.Time          `bson:"lease_until"`
+	Created_at   time.Time          `bson:"created_at"`
+	Sent_at      time.Time          `bson:"sent_at,omitempty"`
+	Recipients   int                `bson:"recipients"`
+	Bounced      int                `bson:"bounced"`
+	Unsubscribed int                `bson:"unsubscribed"`
+	Delivered    int                `bson:"delivered"`
+	Failed       int                `bson:"failed"`
+	Finalized    bool               `bson:"finalized"`
+}
+
+func FromDomainNewsletterIssue(i *domain.NewsletterIssue) *MongoNewsletterIssue {
+	return &MongoNewsletterIssue{
+		Author_id:    i.Author_id,
+		Blog_id:      i.Blog_id,
+		Title:        i.Title,
+		Status:       string(i.Status),
+		Cursor:       i.Cursor,
+		Lease_until:  i.Lease_until,
+		Created_at:   i.Created_at,
+		Sent_at:      i.Sent_at,
+		Recipients:   i.Recipients,
+		Bounced:      i.Bounced,
+		Unsubscribed: i.Unsubscribed,
+		Delivered:    i.Delivered,
+		Failed:       i.Failed,
+		Finalized:    i.Finalized,
+	}
+}
+
+func (m *MongoNewsletterIssue) ToDomain() domain.NewsletterIssue {
+	return domain.NewsletterIssue{
+		Issue_id:     m.Issue_id.Hex(),
+		Author_id:    m.Author_id,
+		Blog_id:      m.Blog_id,
+		Title:        m.Title,
+		Status:       domain.IssueStatus(m.Status),
+		Cursor:       m.Cursor,
+		Lease_until:  m.Lease_until,
+		Created_at:   m.Created_at,
+		Sent_at:      m.Sent_at,
+		Recipients:   m.Recipients,
+		Bounced:      m.Bounced,
+		Unsubscribed: m.Unsubscribed,
+		Delivered:    m.Delivered,
+		Failed:       m.Failed,
+		Finalized:    m.Finalized,
+	}
+}
@@ -26,9 +26,38 @@ type User struct {
 	Provider string         `bson:"provider"`
 	RawData  map[string]any `bson:"raw_data"`
 
+	Identities []LinkedIdentity `bson:"identities,omitempty"`
+
+	Language string `bson:"language,omitempty"`
+
 	Role string `bson:"role"`
 }
 
+type LinkedIdentity struct {
+	Provider   string    `bson:"provider"`
+	Subject_id string    `bson:"subject_id"`
+	Email      string    `bson:"email"`
+	Linked_at  time.Time `bson:"linked_at"`
+}
+
+func LinkedIdentityFromDomain(i domain.LinkedIdentity) LinkedIdentity {
+	return LinkedIdentity{
+		Provider:   i.Provider,
+		Subject_id: i.Subject_id,
+		Email:      i.Email,
+		Linked_at:  i.Linked_at,
+	}
+}
+
+func (i LinkedIdentity) ToDomain() domain.LinkedIdentity {
+	return domain.LinkedIdentity{
+		Provider:   i.Provider,
+		Subject_id: i.Subject_id,
+		Email:      i.Email,
+		Linked_at:  i.Linked_at,
+	}
+}
+
 func UserFromDomain(u domain.User) (*User, error) {
 	var objID primitive.ObjectID
 	var err error
@@ -40,6 +69,11 @@ func UserFromDomain(u domain.User) (*User, error) {
 		}
 	}
 
+	var identities []LinkedIdentity
+	for _, identity := range u.Identities {
+		identities = append(identities, LinkedIdentityFromDomain(identity))
+	}
+
 	return &User{
 		UserID: objID,
 		Name:   u.Name,
@@ -60,11 +94,20 @@ func UserFromDomain(u domain.User) (*User, error) {
 		Provider: u.Provider,
 		RawData:  u.RawData,
 
+		Identities: identities,
+
+		Language: u.Language,
+
 		Role: string(u.Role),
 	}, nil
 }
 
 func (u *User) ToDomain() domain.User {
+	var identities []domain.LinkedIdentity
+	for _, identity := range u.Identities {
+		identities = append(identities, identity.ToDomain())
+	}
+
 	return domain.User{
 		UserID:         u.UserID.Hex(),
 		Name:           u.Name,
@@ -84,6 +127,10 @@ func (u *User) ToDomain() domain.User {
 		Provider: u.Provider,
 		RawData:  u.RawData,
 
+		Identities: identities,
+
+		Language: u.Language,
+
 		Role: domain.Role(u.Role),
 	}
 }
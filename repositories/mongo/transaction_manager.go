@@ -4,8 +4,12 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("github.com/InkForge/Blog_Website/repositories/mongo")
+
 // MongoTransactionManager implements domain.ITransactionManager
 type MongoTransactionManager struct {
 	client *mongo.Client
@@ -17,7 +21,17 @@ func NewMongoTransactionManager(client *mongo.Client) *MongoTransactionManager {
 	}
 }
 
-func (m *MongoTransactionManager) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+// WithTransaction runs fn in a transaction under one span, so the time spent
+// in fn can be told apart from the commit and any retries.
+func (m *MongoTransactionManager) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) (err error) {
+	ctx, span := tracer.Start(ctx, "MongoTransactionManager.WithTransaction")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	session, err := m.client.StartSession()
 	if err != nil {
 		return err
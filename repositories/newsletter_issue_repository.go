@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewsletterIssueRepository stores sent and sending newsletter issues.
+type NewsletterIssueRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNewsletterIssueRepository(db *mongo.Database) domain.INewsletterIssueRepository {
+	return &NewsletterIssueRepository{collection: db.Collection("newsletter_issues")}
+}
+
+func (r *NewsletterIssueRepository) Create(ctx context.Context, issue domain.NewsletterIssue) (domain.NewsletterIssue, error) {
+	result, err := r.collection.InsertOne(ctx, models.FromDomainNewsletterIssue(&issue))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.NewsletterIssue{}, domain.ErrIssueAlreadySent
+		}
+		return domain.NewsletterIssue{}, dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		issue.Issue_id = id.Hex()
+	}
+	return issue, nil
+}
+
+func (r *NewsletterIssueRepository) Get(ctx context.Context, issueID string) (domain.NewsletterIssue, error) {
+	id, err := primitive.ObjectIDFromHex(issueID)
+	if err != nil {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotFound
+	}
+	var issue models.MongoNewsletterIssue
+	err = r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&issue)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.NewsletterIssue{}, domain.ErrIssueNotFound
+		}
+		return domain.NewsletterIssue{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return issue.ToDomain(), nil
+}
+
+func (r *NewsletterIssueRepository) List(ctx context.Context, authorID string, page, limit int) ([]domain.NewsletterIssue, int, error) {
+	filter := bson.M{"author_id": authorID}
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	issues := []domain.NewsletterIssue{}
+	for cursor.Next(ctx) {
+		var issue models.MongoNewsletterIssue
+		if err := cursor.Decode(&issue); err != nil {
+			return nil, 0, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		issues = append(issues, issue.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return issues, int(total), nil
+}
+
+func (r *NewsletterIssueRepository) Claim(ctx context.Context, issueID string, now, leaseUntil time.Time) (domain.NewsletterIssue, error) {
+	id, err := primitive.ObjectIDFromHex(issueID)
+	if err != nil {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotFound
+	}
+	filter := bson.M{"_id": id, "status": domain.IssueSending, "lease_until": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"lease_until": leaseUntil}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var issue models.MongoNewsletterIssue
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&issue)
+	if err == mongo.ErrNoDocuments {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotClaimable
+	}
+	if err != nil {
+		return domain.NewsletterIssue{}, dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return issue.ToDomain(), nil
+}
+
+func (r *NewsletterIssueRepository) Advance(ctx context.Context, issueID, cursor string, queued int, leaseUntil time.Time) error {
+	return r.update(ctx, issueID, bson.M{
+		"$set": bson.M{"cursor": cursor, "lease_until": leaseUntil},
+		"$inc": bson.M{"recipients": queued},
+	})
+}
+
+func (r *NewsletterIssueRepository) MarkSent(ctx context.Context, issueID string, sentAt time.Time) error {
+	return r.update(ctx, issueID, bson.M{"$set": bson.M{"status": domain.IssueSent, "sent_at": sentAt}})
+}
+
+func (r *NewsletterIssueRepository) Increment(ctx context.Context, issueID string, bounced, unsubscribed int) error {
+	return r.update(ctx, issueID, bson.M{"$inc": bson.M{"bounced": bounced, "unsubscribed": unsubscribed}})
+}
+
+func (r *NewsletterIssueRepository) Finalize(ctx context.Context, issueID string, delivered, failed int) error {
+	return r.update(ctx, issueID, bson.M{"$set": bson.M{"delivered": delivered, "failed": failed, "finalized": true}})
+}
+
+func (r *NewsletterIssueRepository) update(ctx context.Context, issueID string, update bson.M) error {
+	id, err := primitive.ObjectIDFromHex(issueID)
+	if err != nil {
+		return domain.ErrIssueNotFound
+	}
+	result, err := r.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrIssueNotFound
+	}
+	return nil
+}
+
+func (r *NewsletterIssueRepository) FindUnsent(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return r.findIDs(ctx, bson.M{"status": domain.IssueSending, "lease_until": bson.M{"$lte": now}}, limit)
+}
+
+func (r *NewsletterIssueRepository) FindUnfinalized(ctx context.Context, limit int) ([]string, error) {
+	return r.findIDs(ctx, bson.M{"status": domain.IssueSent, "finalized": false}, limit)
+}
+
+func (r *NewsletterIssueRepository) findIDs(ctx context.Context, filter bson.M, limit int) ([]string, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return ids, nil
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewsletterSubscriberRepository stores the emails subscribed to each
+// author's newsletter and to the site-wide one.
+type NewsletterSubscriberRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNewsletterSubscriberRepository(db *mongo.Database) domain.INewsletterSubscriberRepository {
+	return &NewsletterSubscriberRepository{collection: db.Collection("newsletter_subscribers")}
+}
+
+func (r *NewsletterSubscriberRepository) Create(ctx context.Context, sub domain.NewsletterSubscriber) (domain.NewsletterSubscriber, error) {
+	doc, err := models.FromDomainNewsletterSubscriber(&sub)
+	if err != nil {
+		return domain.NewsletterSubscriber{}, err
+	}
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return domain.NewsletterSubscriber{}, dbError(ctx, err, domain.ErrInsertingDocuments)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		sub.Subscriber_id = id.Hex()
+	}
+	return sub, nil
+}
+
+func (r *NewsletterSubscriberRepository) Get(ctx context.Context, subscriberID string) (domain.NewsletterSubscriber, error) {
+	id, err := primitive.ObjectIDFromHex(subscriberID)
+	if err != nil {
+		return domain.NewsletterSubscriber{}, domain.ErrSubscriberNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *NewsletterSubscriberRepository) FindByEmail(ctx context.Context, email, authorID string) (domain.NewsletterSubscriber, error) {
+	return r.findOne(ctx, bson.M{"email": email, "author_id": authorID})
+}
+
+func (r *NewsletterSubscriberRepository) findOne(ctx context.Context, filter bson.M) (domain.NewsletterSubscriber, error) {
+	var sub models.MongoNewsletterSubscriber
+	err := r.collection.FindOne(ctx, filter).Decode(&sub)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.NewsletterSubscriber{}, domain.ErrSubscriberNotFound
+		}
+		return domain.NewsletterSubscriber{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return sub.ToDomain(), nil
+}
+
+func (r *NewsletterSubscriberRepository) Update(ctx context.Context, sub domain.NewsletterSubscriber) error {
+	doc, err := models.FromDomainNewsletterSubscriber(&sub)
+	if err != nil {
+		return err
+	}
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.Subscriber_id}, doc)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSubscriberNotFound
+	}
+	return nil
+}
+
+// SetStatusByEmail only changes subscriptions that still receive or await
+// issues, so an earlier unsubscribe or bounce keeps its date.
+func (r *NewsletterSubscriberRepository) SetStatusByEmail(ctx context.Context, email string, status domain.SubscriberStatus, at time.Time) (int, error) {
+	filter := bson.M{
+		"email":  email,
+		"status": bson.M{"$in": []domain.SubscriberStatus{domain.SubscriberPending, domain.SubscriberActive}},
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": status, "left_at": at}})
+	if err != nil {
+		return 0, dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return int(result.ModifiedCount), nil
+}
+
+func (r *NewsletterSubscriberRepository) List(ctx context.Context, authorID string, status domain.SubscriberStatus, page, limit int) ([]domain.NewsletterSubscriber, int, error) {
+	filter := bson.M{"author_id": authorID}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	subs, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return subs, int(total), nil
+}
+
+func (r *NewsletterSubscriberRepository) Each(ctx context.Context, authorID string, fn func(domain.NewsletterSubscriber) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "email", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"author_id": authorID}, opts)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var sub models.MongoNewsletterSubscriber
+		if err := cursor.Decode(&sub); err != nil {
+			return dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		if err := fn(sub.ToDomain()); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return nil
+}
+
+func (r *NewsletterSubscriberRepository) Recipients(ctx context.Context, authorID, afterEmail string, limit int) ([]domain.NewsletterSubscriber, error) {
+	filter := bson.M{
+		"author_id": authorID,
+		"status":    domain.SubscriberActive,
+		"email":     bson.M{"$gt": afterEmail},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "email", Value: 1}}).
+		SetLimit(int64(limit))
+	return r.find(ctx, filter, opts)
+}
+
+func (r *NewsletterSubscriberRepository) Delete(ctx context.Context, subscriberID, authorID string) error {
+	id, err := primitive.ObjectIDFromHex(subscriberID)
+	if err != nil {
+		return domain.ErrSubscriberNotFound
+	}
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "author_id": authorID})
+	if err != nil {
+		return dbError(ctx, err, domain.ErrDeletingDocument)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrSubscriberNotFound
+	}
+	return nil
+}
+
+func (r *NewsletterSubscriberRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.NewsletterSubscriber, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	return r.decodeAll(ctx, cursor)
+}
+
+func (r *NewsletterSubscriberRepository) decodeAll(ctx context.Context, cursor *mongo.Cursor) ([]domain.NewsletterSubscriber, error) {
+	defer cursor.Close(ctx)
+
+	subs := []domain.NewsletterSubscriber{}
+	for cursor.Next(ctx) {
+		var sub models.MongoNewsletterSubscriber
+		if err := cursor.Decode(&sub); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		subs = append(subs, sub.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return subs, nil
+}
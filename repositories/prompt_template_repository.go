@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/InkForge/Blog_Website/repositories/mongo/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// two admins saving a version at once both pick the same number; the loser retries
+const createVersionAttempts = 3
+
+type PromptTemplateRepository struct {
+	templates *mongo.Collection
+	rollouts  *mongo.Collection
+}
+
+func NewPromptTemplateRepository(db *mongo.Database) domain.IPromptTemplateRepository {
+	return &PromptTemplateRepository{
+		templates: db.Collection("ai_prompt_templates"),
+		rollouts:  db.Collection("ai_prompt_rollouts"),
+	}
+}
+
+// CreateVersion stores the template as the version after the latest one of its name.
+func (r *PromptTemplateRepository) CreateVersion(ctx context.Context, template domain.PromptTemplate) (domain.PromptTemplate, error) {
+	if template.Created_at.IsZero() {
+		template.Created_at = time.Now()
+	}
+
+	for attempt := 0; attempt < createVersionAttempts; attempt++ {
+		latest, err := r.latestVersion(ctx, template.Name)
+		if err != nil {
+			return domain.PromptTemplate{}, err
+		}
+		template.Version = latest + 1
+
+		result, err := r.templates.InsertOne(ctx, models.FromDomainPromptTemplate(&template))
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return domain.PromptTemplate{}, dbError(ctx, err, domain.ErrInsertingDocuments)
+		}
+		if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+			template.Template_id = id.Hex()
+		}
+		return template, nil
+	}
+	return domain.PromptTemplate{}, domain.ErrInsertingDocuments
+}
+
+func (r *PromptTemplateRepository) latestVersion(ctx context.Context, name string) (int, error) {
+	var latest models.MongoPromptTemplate
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}).SetProjection(bson.M{"version": 1})
+	err := r.templates.FindOne(ctx, bson.M{"name": name}, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return latest.Version, nil
+}
+
+func (r *PromptTemplateRepository) GetVersion(ctx context.Context, name string, version int) (domain.PromptTemplate, error) {
+	var template models.MongoPromptTemplate
+	err := r.templates.FindOne(ctx, bson.M{"name": name, "version": version}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.PromptTemplate{}, domain.ErrPromptVersionNotFound
+		}
+		return domain.PromptTemplate{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return template.ToDomain(), nil
+}
+
+// ListVersions returns the stored versions of name, newest first.
+func (r *PromptTemplateRepository) ListVersions(ctx context.Context, name string) ([]domain.PromptTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := r.templates.Find(ctx, bson.M{"name": name}, opts)
+	if err != nil {
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
+	}
+	defer cursor.Close(ctx)
+
+	templates := []domain.PromptTemplate{}
+	for cursor.Next(ctx) {
+		var template models.MongoPromptTemplate
+		if err := cursor.Decode(&template); err != nil {
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+		}
+		templates = append(templates, template.ToDomain())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
+	}
+	return templates, nil
+}
+
+// GetRollout returns the rollout of name, or none if it was never set.
+func (r *PromptTemplateRepository) GetRollout(ctx context.Context, name string) ([]domain.PromptRollout, error) {
+	var rollout models.MongoPromptRollout
+	err := r.rollouts.FindOne(ctx, bson.M{"name": name}).Decode(&rollout)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+	return rollout.ToDomain(), nil
+}
+
+func (r *PromptTemplateRepository) SetRollout(ctx context.Context, name string, rollout []domain.PromptRollout) error {
+	update := bson.M{"$set": models.FromDomainPromptRollout(name, rollout)}
+	_, err := r.rollouts.UpdateOne(ctx, bson.M{"name": name}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type relatedBlogEntry struct {
+	Blog_id string  `bson:"blog_id"`
+	Score   float64 `bson:"score"`
+}
+
+type blogRelationsDocument struct {
+	Blog_id     string             `bson:"_id"`
+	Related     []relatedBlogEntry `bson:"related"`
+	Computed_at time.Time          `bson:"computed_at"`
+}
+
+type RelatedBlogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRelatedBlogRepository(db *mongo.Database) domain.IRelatedBlogRepository {
+	return &RelatedBlogRepository{
+		collection: db.Collection("blog_related"),
+	}
+}
+
+func (r *RelatedBlogRepository) SaveAll(ctx context.Context, relations []domain.BlogRelations) error {
+	if len(relations) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(relations))
+	for _, rel := range relations {
+		doc := blogRelationsDocument{
+			Blog_id:     rel.Blog_id,
+			Related:     make([]relatedBlogEntry, 0, len(rel.Related)),
+			Computed_at: rel.Computed_at,
+		}
+		for _, related := range rel.Related {
+			doc.Related = append(doc.Related, relatedBlogEntry{Blog_id: related.Blog_id, Score: related.Score})
+		}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": rel.Blog_id}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+
+	if _, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+	return nil
+}
+
+func (r *RelatedBlogRepository) Get(ctx context.Context, blogID string) (domain.BlogRelations, error) {
+	var doc blogRelationsDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": blogID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.BlogRelations{}, domain.ErrNoRelatedBlogs
+		}
+		return domain.BlogRelations{}, dbError(ctx, err, domain.ErrRetrievingDocuments)
+	}
+
+	relations := domain.BlogRelations{
+		Blog_id:     doc.Blog_id,
+		Related:     make([]domain.RelatedBlog, 0, len(doc.Related)),
+		Computed_at: doc.Computed_at,
+	}
+	for _, related := range doc.Related {
+		relations.Related = append(relations.Related, domain.RelatedBlog{Blog_id: related.Blog_id, Score: related.Score})
+	}
+	return relations, nil
+}
+
+func (r *RelatedBlogRepository) DeleteComputedBefore(ctx context.Context, before time.Time) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"computed_at": bson.M{"$lt": before}}); err != nil {
+		return dbError(ctx, err, domain.ErrDeletingDocument)
+	}
+	return nil
+}
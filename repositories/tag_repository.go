@@ -25,13 +25,13 @@ func (t *TagMongoRepository) FindByNames(ctx context.Context, names []string) ([
 
 	cursor, err := t.tagCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, domain.ErrQueryFailed
+		return nil, dbError(ctx, err, domain.ErrQueryFailed)
 	}
 	defer cursor.Close(ctx)
 
 	var mongoTags []models.MongoTag
 	if err := cursor.All(ctx, &mongoTags); err != nil {
-		return nil, domain.ErrDocumentDecoding
+		return nil, dbError(ctx, err, domain.ErrDocumentDecoding)
 	}
 
 	var tags []domain.Tag
@@ -50,7 +50,7 @@ func (t *TagMongoRepository) CreateMany(ctx context.Context, names []string) ([]
 
 	result, err := t.tagCollection.InsertMany(ctx, toInsert)
 	if err != nil {
-		return nil, domain.ErrInsertingDocuments
+		return nil, dbError(ctx, err, domain.ErrInsertingDocuments)
 	}
 
 	var tags []domain.Tag
@@ -51,7 +51,7 @@ func (ur *UserRepository) IsEmailVerified(ctx context.Context, id string) (bool,
 
 	var userModel models.User
 	if err := result.Decode(&userModel); err != nil {
-		return false, domain.ErrDecodingDocument
+		return false, dbError(ctx, err, domain.ErrDecodingDocument)
 	}
 	return userModel.IsVerified, nil
 }
@@ -68,7 +68,7 @@ func (ur *UserRepository) SetEmailVerified(ctx context.Context, id string) error
 	update := bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "is_verified", Value: true},
-			{Key: "updated_at", Value: time.Now().Format("2006-01-02")},
+			{Key: "updated_at", Value: time.Now()},
 		}},
 	}
 
@@ -100,7 +100,7 @@ func (ur *UserRepository) FindByID(ctx context.Context, id string) (*domain.User
 
 	var userModel models.User
 	if err := result.Decode(&userModel); err != nil {
-		return nil, domain.ErrDecodingDocument
+		return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 	}
 	user := userModel.ToDomain()
 
@@ -117,7 +117,7 @@ func (ur *UserRepository) DeleteByID(ctx context.Context, id string) error {
 	filter := bson.D{{Key: "_id", Value: objID}}
 	result, err := ur.userCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return domain.ErrDeletingDocument
+		return dbError(ctx, err, domain.ErrDeletingDocument)
 	}
 	if result.DeletedCount == 0 {
 		return domain.ErrUserNotFound
@@ -173,7 +173,7 @@ func (ur *UserRepository) FindByEmail(ctx context.Context, email string) (*domai
 
 	var userModel models.User
 	if err := result.Decode(&userModel); err != nil {
-		return nil, domain.ErrDecodingDocument
+		return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 	}
 
 	user := userModel.ToDomain()
@@ -192,7 +192,7 @@ func (ur *UserRepository) FindByUserName(ctx context.Context, username string) (
 
 	var userModel models.User
 	if err := result.Decode(&userModel); err != nil {
-		return nil, domain.ErrDecodingDocument
+		return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 	}
 
 	user := userModel.ToDomain()
@@ -280,12 +280,12 @@ func (ur *UserRepository) GetAllUsers(ctx context.Context) ([]domain.User, error
 	for cursor.Next(ctx) {
 		var user models.User
 		if err := cursor.Decode(&user); err != nil {
-			return nil, domain.ErrDecodingDocument
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 		}
 		users = append(users, user.ToDomain())
 	}
 	if err := cursor.Err(); err != nil {
-		return nil, domain.ErrCursorIteration
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
 	}
 	return users, nil
 }
@@ -313,20 +313,20 @@ func (ur *UserRepository) SearchUsers(ctx context.Context, q string) ([]domain.U
 	for cursor.Next(ctx) {
 		var user models.User
 		if err := cursor.Decode(&user); err != nil {
-			return nil, domain.ErrDecodingDocument
+			return nil, dbError(ctx, err, domain.ErrDecodingDocument)
 		}
 		users = append(users, user.ToDomain())
 	}
 	if err := cursor.Err(); err != nil {
-		return nil, domain.ErrCursorIteration
+		return nil, dbError(ctx, err, domain.ErrCursorIteration)
 	}
 
 	return users, nil
 }
 
-// UpdateRole updates the role of a user. Only "admin" or "user" roles are allowed.
-func (ur *UserRepository) UpdateRole(ctx context.Context, userID string, role string) error {
-	if role != "admin" && role != "user" {
+// UpdateRole updates the role of a user. Only domain.RoleAdmin or domain.RoleUser are allowed.
+func (ur *UserRepository) UpdateRole(ctx context.Context, userID string, role domain.Role) error {
+	if role != domain.RoleAdmin && role != domain.RoleUser {
 		return domain.ErrInvalidRole
 	}
 
@@ -351,3 +351,105 @@ func (ur *UserRepository) UpdateRole(ctx context.Context, userID string, role st
 
 	return nil
 }
+
+// FindByIdentity finds the user who linked the given provider account
+// returns ErrUserNotFound if there is none
+func (ur *UserRepository) FindByIdentity(ctx context.Context, provider, subjectID string) (*domain.User, error) {
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject_id": subjectID}}}
+	result := ur.userCollection.FindOne(ctx, filter)
+
+	if err := consolidateUserError(result.Err()); err != nil {
+		return nil, err
+	}
+
+	var userModel models.User
+	if err := result.Decode(&userModel); err != nil {
+		return nil, dbError(ctx, err, domain.ErrDecodingDocument)
+	}
+
+	user := userModel.ToDomain()
+
+	return &user, nil
+}
+
+// AddIdentity links a provider account to the user, unless the user already
+// has one from that provider or the account belongs to someone else
+func (ur *UserRepository) AddIdentity(ctx context.Context, userID string, identity domain.LinkedIdentity) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	filter := bson.M{"_id": objID, "identities.provider": bson.M{"$ne": identity.Provider}}
+	update := bson.M{
+		"$push": bson.M{"identities": models.LinkedIdentityFromDomain(identity)},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	result, err := ur.userCollection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrIdentityAlreadyLinked
+	}
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := ur.userCollection.CountDocuments(ctx, bson.M{"_id": objID})
+		if err != nil {
+			return dbError(ctx, err, domain.ErrQueryFailed)
+		}
+		if count == 0 {
+			return domain.ErrUserNotFound
+		}
+		return domain.ErrIdentityAlreadyLinked
+	}
+
+	return nil
+}
+
+// RemoveIdentity unlinks the user's identity at provider, and forgets it as
+// the provider the user registered with. It never removes the user's last
+// way to log in, returning ErrLastLoginMethod instead.
+// returns ErrIdentityNotLinked if the user has no identity at provider
+func (ur *UserRepository) RemoveIdentity(ctx context.Context, userID, provider string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	filter := bson.M{
+		"_id":                 objID,
+		"identities.provider": provider,
+		// another identity or a password must remain
+		"$or": bson.A{
+			bson.M{"identities.1": bson.M{"$exists": true}},
+			bson.M{"password": bson.M{"$type": "string", "$ne": ""}},
+		},
+	}
+	update := bson.M{"$pull": bson.M{"identities": bson.M{"provider": provider}}}
+	result, err := ur.userCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := ur.userCollection.CountDocuments(ctx, bson.M{"_id": objID, "identities.provider": provider})
+		if err != nil {
+			return dbError(ctx, err, domain.ErrQueryFailed)
+		}
+		if count > 0 {
+			return domain.ErrLastLoginMethod
+		}
+		return domain.ErrIdentityNotLinked
+	}
+
+	_, err = ur.userCollection.UpdateOne(ctx,
+		bson.M{"_id": objID, "provider": provider},
+		bson.M{"$set": bson.M{"provider": "", "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return dbError(ctx, err, domain.ErrUpdatingDocument)
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	defaultChatTokenBudget = 3000
+	maxChatMessageChars    = 4000
+	maxConversationTitle   = 80
+)
+
+// AIConversationUseCase implements domain.IAIConversationUseCase
+type AIConversationUseCase struct {
+	conversationRepo domain.IAIConversationRepository
+	blogRepo         domain.IBlogRepository
+	AIService        domain.IAIContentService
+	usage            domain.IAIUsageUseCase
+	tokenBudget      int
+}
+
+// NewAIConversationUseCase returns a conversation usecase that keeps the history
+// sent to the model within tokenBudget estimated tokens. Model calls are metered
+// and quota checked through usage, which may be nil.
+func NewAIConversationUseCase(
+	conversationRepo domain.IAIConversationRepository,
+	blogRepo domain.IBlogRepository,
+	AIService domain.IAIContentService,
+	usage domain.IAIUsageUseCase,
+	tokenBudget int,
+) domain.IAIConversationUseCase {
+	if tokenBudget <= 0 {
+		tokenBudget = defaultChatTokenBudget
+	}
+	return &AIConversationUseCase{
+		conversationRepo: conversationRepo,
+		blogRepo:         blogRepo,
+		AIService:        AIService,
+		usage:            usage,
+		tokenBudget:      tokenBudget,
+	}
+}
+
+// CreateConversation starts an empty conversation. When blogID is set, the
+// user must be the author of that blog.
+func (uc *AIConversationUseCase) CreateConversation(ctx context.Context, userID, blogID string) (*domain.AIConversation, error) {
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+
+	blogID = strings.TrimSpace(blogID)
+	if blogID != "" {
+		if _, err := uc.anchoredBlog(ctx, blogID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	conversation := domain.AIConversation{
+		User_id:    userID,
+		Blog_id:    blogID,
+		Messages:   []domain.AIMessage{},
+		Created_at: now,
+		Updated_at: now,
+	}
+	id, err := uc.conversationRepo.Create(ctx, conversation)
+	if err != nil {
+		return nil, err
+	}
+	conversation.Conversation_id = id
+
+	return &conversation, nil
+}
+
+// ListConversations returns the user's conversations without their messages.
+func (uc *AIConversationUseCase) ListConversations(ctx context.Context, userID string, page, limit int) ([]domain.AIConversation, domain.Pagination, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	conversations, total, err := uc.conversationRepo.ListByUser(ctx, userID, page, limit)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	return conversations, domain.Pagination{Page: page, Limit: limit, Total: total}, nil
+}
+
+// GetConversation returns a conversation with its messages if it belongs to the user.
+func (uc *AIConversationUseCase) GetConversation(ctx context.Context, conversationID, userID string) (*domain.AIConversation, error) {
+	conversation, err := uc.ownedConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// RenameConversation replaces the conversation title.
+// It returns domain.ErrEmptyConversationTitle if the title is blank.
+func (uc *AIConversationUseCase) RenameConversation(ctx context.Context, conversationID, userID, title string) error {
+	title = trimToMax(strings.TrimSpace(title), maxConversationTitle)
+	if title == "" {
+		return domain.ErrEmptyConversationTitle
+	}
+	if _, err := uc.ownedConversation(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	return uc.conversationRepo.UpdateTitle(ctx, conversationID, title)
+}
+
+// DeleteConversation removes the conversation and all its messages.
+func (uc *AIConversationUseCase) DeleteConversation(ctx context.Context, conversationID, userID string) error {
+	if _, err := uc.ownedConversation(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	return uc.conversationRepo.Delete(ctx, conversationID)
+}
+
+// SendMessage adds a user message to the conversation and returns the assistant's reply.
+// The history sent to the model is trimmed to the token budget, oldest messages first,
+// and an anchored blog's current content is always included. The title is generated
+// from the first exchange.
+// It returns domain.ErrContentMissing if the content is blank.
+func (uc *AIConversationUseCase) SendMessage(ctx context.Context, conversationID string, requester domain.AIRequestInfo, content string) (domain.AIMessage, error) {
+	content = trimToMax(strings.TrimSpace(content), maxChatMessageChars)
+	if content == "" {
+		return domain.AIMessage{}, domain.ErrContentMissing
+	}
+
+	userID := requester.UserID
+	conversation, err := uc.ownedConversation(ctx, conversationID, userID)
+	if err != nil {
+		return domain.AIMessage{}, err
+	}
+
+	var anchor []domain.AIMessage
+	if conversation.Blog_id != "" {
+		// the blog may have been deleted or changed hands since; chat on without it
+		if blog, err := uc.anchoredBlog(ctx, conversation.Blog_id, userID); err == nil {
+			anchor = append(anchor, blogContextMessage(blog, uc.tokenBudget/2))
+		}
+	}
+
+	userMessage := domain.AIMessage{Role: "user", Content: content, Created_at: time.Now()}
+	history := append(append([]domain.AIMessage{}, conversation.Messages...), userMessage)
+	prompt := append(anchor, fitToTokenBudget(history, uc.tokenBudget-estimateMessagesTokens(anchor))...)
+
+	var reply domain.AIMessage
+	err = meterAICall(ctx, uc.usage, requester, "conversation_message", func(ctx context.Context) (err error) {
+		reply, err = uc.AIService.Chat(ctx, prompt)
+		return err
+	})
+	if err != nil {
+		return domain.AIMessage{}, err
+	}
+	reply.Role = "assistant"
+	reply.Created_at = time.Now()
+
+	if err := uc.conversationRepo.AppendMessages(ctx, conversationID, []domain.AIMessage{userMessage, reply}); err != nil {
+		return domain.AIMessage{}, err
+	}
+
+	if conversation.Title == "" {
+		// a missing title is not worth failing the turn over
+		_ = uc.conversationRepo.UpdateTitle(ctx, conversationID, uc.titleFor(ctx, requester, userMessage, reply))
+	}
+
+	return reply, nil
+}
+
+func (uc *AIConversationUseCase) ownedConversation(ctx context.Context, conversationID, userID string) (domain.AIConversation, error) {
+	if conversationID == "" {
+		return domain.AIConversation{}, domain.ErrInvalidConversationID
+	}
+	conversation, err := uc.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return domain.AIConversation{}, err
+	}
+	// don't reveal that someone else's conversation exists
+	if conversation.User_id != userID {
+		return domain.AIConversation{}, domain.ErrAIConversationNotFound
+	}
+	return conversation, nil
+}
+
+func (uc *AIConversationUseCase) anchoredBlog(ctx context.Context, blogID, userID string) (domain.Blog, error) {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if err != nil {
+		return domain.Blog{}, err
+	}
+	if blog.User_id != userID {
+		return domain.Blog{}, domain.ErrNotBlogAuthor
+	}
+	return blog, nil
+}
+
+// titleFor asks the model for a title for the first exchange and falls back to
+// the start of the user's message.
+func (uc *AIConversationUseCase) titleFor(ctx context.Context, requester domain.AIRequestInfo, userMessage, reply domain.AIMessage) string {
+	exchange := trimToMax(userMessage.Content+"\n\n"+reply.Content, 500)
+	var title string
+	err := meterAICall(ctx, uc.usage, requester, "conversation_title", func(ctx context.Context) (err error) {
+		title, err = uc.AIService.GenerateTitle(ctx, exchange, "casual")
+		return err
+	})
+	if err == nil {
+		if title = strings.TrimSpace(title); title != "" {
+			return trimToMax(title, maxConversationTitle)
+		}
+	}
+
+	fallback := strings.Join(strings.Fields(userMessage.Content), " ")
+	if utf8.RuneCountInString(fallback) > maxConversationTitle {
+		fallback = trimToMax(fallback, maxConversationTitle-3) + "..."
+	}
+	return fallback
+}
+
+// blogContextMessage shows the draft to the assistant, cut down to maxTokens.
+func blogContextMessage(blog domain.Blog, maxTokens int) domain.AIMessage {
+	content := trimToMax(blog.Content, maxTokens*charsPerToken)
+	return domain.AIMessage{
+		Role:    "system",
+		Content: "The user is drafting the following blog post.\nTitle: " + blog.Title + "\nContent:\n" + content,
+	}
+}
+
+// charsPerToken is a rough average for English text, good enough for budgeting.
+const charsPerToken = 4
+
+// perMessageTokens accounts for the role and framing of each message.
+const perMessageTokens = 4
+
+func estimateTokens(message domain.AIMessage) int {
+	return (utf8.RuneCountInString(message.Content)+charsPerToken-1)/charsPerToken + perMessageTokens
+}
+
+func estimateMessagesTokens(messages []domain.AIMessage) int {
+	total := 0
+	for _, m := range messages {
+		total += estimateTokens(m)
+	}
+	return total
+}
+
+// fitToTokenBudget keeps the most recent messages whose estimated size fits in
+// budget. The last message is always kept.
+func fitToTokenBudget(messages []domain.AIMessage, budget int) []domain.AIMessage {
+	if len(messages) == 0 {
+		return messages
+	}
+
+	used := estimateTokens(messages[len(messages)-1])
+	start := len(messages) - 1
+	for start > 0 {
+		cost := estimateTokens(messages[start-1])
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		start--
+	}
+	return messages[start:]
+}
@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// message returns a message costing exactly tokens estimated tokens.
+func message(role string, tokens int) domain.AIMessage {
+	return domain.AIMessage{Role: role, Content: strings.Repeat("a", (tokens-perMessageTokens)*charsPerToken)}
+}
+
+func TestFitToTokenBudget(t *testing.T) {
+	oldest, middle, latest := message("user", 10), message("assistant", 10), message("user", 10)
+	history := []domain.AIMessage{oldest, middle, latest}
+
+	tests := []struct {
+		name     string
+		messages []domain.AIMessage
+		budget   int
+		want     []domain.AIMessage
+	}{
+		{"empty history", nil, 100, nil},
+		{"everything fits", history, 100, history},
+		{"exactly the budget", history, 30, history},
+		{"one token short drops the oldest", history, 29, []domain.AIMessage{middle, latest}},
+		{"only the latest fits", history, 19, []domain.AIMessage{latest}},
+		{"latest kept over budget", history, 5, []domain.AIMessage{latest}},
+		{"latest kept with no budget left", history, -40, []domain.AIMessage{latest}},
+		{"stops at the first message that does not fit", []domain.AIMessage{message("user", 5), message("assistant", 25), latest}, 20, []domain.AIMessage{latest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fitToTokenBudget(tt.messages, tt.budget))
+		})
+	}
+}
+
+type fakeConversationRepo struct {
+	domain.IAIConversationRepository
+	conversation domain.AIConversation
+}
+
+func (r *fakeConversationRepo) GetByID(ctx context.Context, conversationID string) (domain.AIConversation, error) {
+	return r.conversation, nil
+}
+
+func (r *fakeConversationRepo) AppendMessages(ctx context.Context, conversationID string, messages []domain.AIMessage) error {
+	return nil
+}
+
+type fakeBlogRepo struct {
+	domain.IBlogRepository
+	blog domain.Blog
+}
+
+func (r *fakeBlogRepo) GetByID(ctx context.Context, blogID string) (domain.Blog, error) {
+	if blogID != r.blog.Blog_id {
+		return domain.Blog{}, domain.ErrBlogNotFound
+	}
+	return r.blog, nil
+}
+
+// fakeChat records the prompt it is sent.
+type fakeChat struct {
+	domain.IAIContentService
+	prompt []domain.AIMessage
+}
+
+func (s *fakeChat) Chat(ctx context.Context, messages []domain.AIMessage) (domain.AIMessage, error) {
+	s.prompt = messages
+	return domain.AIMessage{Content: "reply"}, nil
+}
+
+func TestSendMessage_Prompt(t *testing.T) {
+	history := []domain.AIMessage{message("user", 300), message("assistant", 300)}
+	blog := domain.Blog{Blog_id: "blog-1", User_id: "author", Title: "Draft", Content: strings.Repeat("b", 400)}
+
+	tests := []struct {
+		name        string
+		blogID      string
+		budget      int
+		wantContext bool
+		wantLen     int
+	}{
+		{"no anchored blog", "", 1000, false, 3},
+		{"anchored blog first", "blog-1", 1000, true, 4},
+		{"anchored blog gone", "blog-2", 1000, false, 3},
+		{"history trimmed around the blog", "blog-1", 400, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{}
+			uc := NewAIConversationUseCase(
+				&fakeConversationRepo{conversation: domain.AIConversation{User_id: "author", Blog_id: tt.blogID, Title: "set", Messages: history}},
+				&fakeBlogRepo{blog: blog},
+				chat, nil, tt.budget,
+			)
+
+			_, err := uc.SendMessage(context.Background(), "conv-1", domain.AIRequestInfo{UserID: "author"}, "latest question")
+			require.NoError(t, err)
+
+			require.Len(t, chat.prompt, tt.wantLen)
+			assert.Equal(t, tt.wantContext, strings.Contains(chat.prompt[0].Content, "Title: Draft"))
+			last := chat.prompt[len(chat.prompt)-1]
+			assert.Equal(t, "user", last.Role)
+			assert.Equal(t, "latest question", last.Content)
+		})
+	}
+}
+
+func TestSendMessage_KeepsOversizedLatestMessage(t *testing.T) {
+	chat := &fakeChat{}
+	uc := NewAIConversationUseCase(
+		&fakeConversationRepo{conversation: domain.AIConversation{User_id: "author", Title: "set", Messages: []domain.AIMessage{message("user", 5)}}},
+		&fakeBlogRepo{}, chat, nil, 10,
+	)
+
+	question := strings.Repeat("q", maxChatMessageChars)
+	_, err := uc.SendMessage(context.Background(), "conv-1", domain.AIRequestInfo{UserID: "author"}, question)
+	require.NoError(t, err)
+
+	require.Len(t, chat.prompt, 1)
+	assert.Equal(t, question, chat.prompt[0].Content)
+}
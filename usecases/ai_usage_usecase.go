@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// AIUsageUseCase implements domain.IAIUsageUseCase
+type AIUsageUseCase struct {
+	usageRepo  domain.IAIUsageRepository
+	quotaRepo  domain.IAIQuotaRepository
+	roleQuotas map[domain.Role]domain.AIQuota
+	prices     map[string]domain.AIPrice
+	now        func() time.Time
+}
+
+// NewAIUsageUseCase returns a usage usecase. roleQuotas holds the default quota
+// of each role, which a per-user override replaces; a role without an entry is
+// unlimited. prices is keyed by provider name.
+func NewAIUsageUseCase(
+	usageRepo domain.IAIUsageRepository,
+	quotaRepo domain.IAIQuotaRepository,
+	roleQuotas map[domain.Role]domain.AIQuota,
+	prices map[string]domain.AIPrice,
+) domain.IAIUsageUseCase {
+	return &AIUsageUseCase{
+		usageRepo:  usageRepo,
+		quotaRepo:  quotaRepo,
+		roleQuotas: roleQuotas,
+		prices:     prices,
+		now:        time.Now,
+	}
+}
+
+// ReserveQuota counts one request in the requester's day and month before an
+// AI call, as long as neither has used up its limits. Work done by the system
+// itself is never limited.
+func (uc *AIUsageUseCase) ReserveQuota(ctx context.Context, requester domain.AIRequestInfo) (*domain.AIQuotaReservation, error) {
+	quota, limited, err := uc.effectiveQuota(ctx, requester)
+	if err != nil || !limited {
+		return nil, err
+	}
+
+	day, month := uc.periods(requester.UserID)
+	if err := uc.quotaRepo.ReserveRequest(ctx, day, quota.Daily_requests, quota.Daily_tokens); err != nil {
+		return nil, err
+	}
+	if err := uc.quotaRepo.ReserveRequest(ctx, month, quota.Monthly_requests, quota.Monthly_tokens); err != nil {
+		uc.release(ctx, day)
+		return nil, err
+	}
+	return &domain.AIQuotaReservation{Periods: []domain.AIQuotaPeriod{day, month}}, nil
+}
+
+// Track stores the usage of one AI operation. Failing to store it is logged
+// rather than returned, so metering never breaks the feature itself.
+func (uc *AIUsageUseCase) Track(ctx context.Context, requester domain.AIRequestInfo, reservation *domain.AIQuotaReservation, operation string, meter *domain.AIUsageMeter, latency time.Duration, callErr error) {
+	record := domain.AIUsageRecord{
+		User_id:    requester.UserID,
+		Role:       requester.Role,
+		Operation:  operation,
+		Latency_ms: latency.Milliseconds(),
+		Success:    callErr == nil,
+		Created_at: uc.now(),
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+	if meter != nil {
+		record.Provider = meter.Provider
+		record.Model = meter.Model
+		record.Prompt_tokens = meter.PromptTokens
+		record.Completion_tokens = meter.CompletionTokens
+		record.Total_tokens = meter.PromptTokens + meter.CompletionTokens
+		record.Tokens_estimated = meter.Estimated
+		record.Estimated_cost = uc.cost(meter.Provider, meter.PromptTokens, meter.CompletionTokens)
+		record.Prompt_template = meter.PromptTemplate
+		record.Prompt_version = meter.PromptVersion
+	}
+
+	// the request context may already be done, e.g. a client that hung up mid-stream
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := uc.usageRepo.Create(storeCtx, record); err != nil {
+		slog.ErrorContext(ctx, "failed to record AI usage", "user_id", requester.UserID, "error", err)
+	}
+
+	if reservation == nil {
+		return
+	}
+	for _, period := range reservation.Periods {
+		if callErr != nil {
+			uc.release(storeCtx, period)
+			continue
+		}
+		if err := uc.quotaRepo.AddTokens(storeCtx, period, record.Total_tokens); err != nil {
+			slog.ErrorContext(ctx, "failed to charge AI tokens", "user_id", requester.UserID, "period", period.Name, "error", err)
+		}
+	}
+}
+
+// release gives back a reserved request; failing to is only logged, as it
+// errs on the user's side by at most one request.
+func (uc *AIUsageUseCase) release(ctx context.Context, period domain.AIQuotaPeriod) {
+	if err := uc.quotaRepo.ReleaseRequest(ctx, period); err != nil {
+		slog.ErrorContext(ctx, "failed to release AI quota", "user_id", period.User_id, "period", period.Name, "error", err)
+	}
+}
+
+// periods returns the requester's current day and month (UTC).
+func (uc *AIUsageUseCase) periods(userID string) (domain.AIQuotaPeriod, domain.AIQuotaPeriod) {
+	now := uc.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return domain.AIQuotaPeriod{User_id: userID, Name: "day", Start: dayStart, End: dayStart.AddDate(0, 0, 1)},
+		domain.AIQuotaPeriod{User_id: userID, Name: "month", Start: monthStart, End: monthStart.AddDate(0, 1, 0)}
+}
+
+// QuotaStatus reports the requester's usage against the effective quota.
+func (uc *AIUsageUseCase) QuotaStatus(ctx context.Context, requester domain.AIRequestInfo) (domain.AIQuotaStatus, error) {
+	quota, limited, err := uc.effectiveQuota(ctx, requester)
+	if err != nil {
+		return domain.AIQuotaStatus{}, err
+	}
+	if !limited {
+		return domain.AIQuotaStatus{Unlimited: true}, nil
+	}
+
+	day, month := uc.periods(requester.UserID)
+	daily, err := uc.quotaRepo.PeriodUsage(ctx, day)
+	if err != nil {
+		return domain.AIQuotaStatus{}, err
+	}
+	monthly, err := uc.quotaRepo.PeriodUsage(ctx, month)
+	if err != nil {
+		return domain.AIQuotaStatus{}, err
+	}
+
+	return domain.AIQuotaStatus{
+		Daily: domain.AIQuotaWindow{
+			Requests_used:  daily.Requests,
+			Requests_limit: quota.Daily_requests,
+			Tokens_used:    daily.Tokens,
+			Tokens_limit:   quota.Daily_tokens,
+			Resets_at:      day.End,
+		},
+		Monthly: domain.AIQuotaWindow{
+			Requests_used:  monthly.Requests,
+			Requests_limit: quota.Monthly_requests,
+			Tokens_used:    monthly.Tokens,
+			Tokens_limit:   quota.Monthly_tokens,
+			Resets_at:      month.End,
+		},
+	}, nil
+}
+
+// Report aggregates usage and estimated cost per provider and model between from and to.
+func (uc *AIUsageUseCase) Report(ctx context.Context, from, to time.Time) (domain.AIUsageReport, error) {
+	rows, err := uc.usageRepo.Report(ctx, from, to)
+	if err != nil {
+		return domain.AIUsageReport{}, err
+	}
+
+	report := domain.AIUsageReport{From: from, To: to, Rows: rows}
+	for _, row := range rows {
+		report.Total.Requests += row.Requests
+		report.Total.Failures += row.Failures
+		report.Total.Prompt_tokens += row.Prompt_tokens
+		report.Total.Completion_tokens += row.Completion_tokens
+		report.Total.Estimated_cost += row.Estimated_cost
+	}
+	return report, nil
+}
+
+// SetUserQuota overrides the role default quota for one user.
+// It returns domain.ErrInvalidAIQuota if any limit is negative.
+func (uc *AIUsageUseCase) SetUserQuota(ctx context.Context, userID string, quota domain.AIQuota) error {
+	if userID == "" {
+		return domain.ErrInvalidUserID
+	}
+	if quota.Daily_requests < 0 || quota.Monthly_requests < 0 || quota.Daily_tokens < 0 || quota.Monthly_tokens < 0 {
+		return domain.ErrInvalidAIQuota
+	}
+	return uc.quotaRepo.UpsertUserQuota(ctx, userID, quota)
+}
+
+// effectiveQuota returns the user's override or their role default, and
+// whether any limit applies at all.
+func (uc *AIUsageUseCase) effectiveQuota(ctx context.Context, requester domain.AIRequestInfo) (domain.AIQuota, bool, error) {
+	if requester.Role == domain.RoleSystem {
+		return domain.AIQuota{}, false, nil
+	}
+
+	quota, err := uc.quotaRepo.GetUserQuota(ctx, requester.UserID)
+	if errors.Is(err, domain.ErrAIQuotaNotFound) {
+		quota, err = uc.roleQuotas[requester.Role], nil
+	}
+	if err != nil {
+		return domain.AIQuota{}, false, err
+	}
+
+	limited := quota.Daily_requests > 0 || quota.Monthly_requests > 0 || quota.Daily_tokens > 0 || quota.Monthly_tokens > 0
+	return quota, limited, nil
+}
+
+func (uc *AIUsageUseCase) cost(provider string, promptTokens, completionTokens int) float64 {
+	price := uc.prices[provider]
+	return float64(promptTokens)/1000*price.Prompt_per_1k + float64(completionTokens)/1000*price.Completion_per_1k
+}
+
+// meterAICall reserves the requester's quota, runs call with a usage meter in
+// its context and records the result, charging the quota only if call
+// succeeded. A nil usage usecase disables metering.
+func meterAICall(ctx context.Context, usage domain.IAIUsageUseCase, requester domain.AIRequestInfo, operation string, call func(ctx context.Context) error) error {
+	if usage == nil {
+		return call(ctx)
+	}
+	reservation, err := usage.ReserveQuota(ctx, requester)
+	if err != nil {
+		return err
+	}
+
+	meter := &domain.AIUsageMeter{}
+	start := time.Now()
+	err = call(domain.WithAIUsageMeter(ctx, meter))
+	usage.Track(ctx, requester, reservation, operation, meter, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuotaRepo keeps period usage in memory, keyed by period name.
+type fakeQuotaRepo struct {
+	domain.IAIQuotaRepository
+	usage map[string]domain.AIUsageTotals
+}
+
+func (r *fakeQuotaRepo) GetUserQuota(ctx context.Context, userID string) (domain.AIQuota, error) {
+	return domain.AIQuota{}, domain.ErrAIQuotaNotFound
+}
+
+func (r *fakeQuotaRepo) ReserveRequest(ctx context.Context, period domain.AIQuotaPeriod, requestLimit, tokenLimit int) error {
+	u := r.usage[period.Name]
+	if (requestLimit > 0 && u.Requests >= requestLimit) || (tokenLimit > 0 && u.Tokens >= tokenLimit) {
+		return domain.ErrAIQuotaExceeded
+	}
+	u.Requests++
+	r.usage[period.Name] = u
+	return nil
+}
+
+func (r *fakeQuotaRepo) ReleaseRequest(ctx context.Context, period domain.AIQuotaPeriod) error {
+	u := r.usage[period.Name]
+	u.Requests--
+	r.usage[period.Name] = u
+	return nil
+}
+
+func (r *fakeQuotaRepo) AddTokens(ctx context.Context, period domain.AIQuotaPeriod, tokens int) error {
+	u := r.usage[period.Name]
+	u.Tokens += tokens
+	r.usage[period.Name] = u
+	return nil
+}
+
+func (r *fakeQuotaRepo) PeriodUsage(ctx context.Context, period domain.AIQuotaPeriod) (domain.AIUsageTotals, error) {
+	return r.usage[period.Name], nil
+}
+
+type fakeUsageRepo struct {
+	domain.IAIUsageRepository
+}
+
+func (fakeUsageRepo) Create(ctx context.Context, record domain.AIUsageRecord) error { return nil }
+
+func newTestUsage(quota domain.AIQuota) (domain.IAIUsageUseCase, *fakeQuotaRepo) {
+	quotaRepo := &fakeQuotaRepo{usage: map[string]domain.AIUsageTotals{}}
+	uc := NewAIUsageUseCase(fakeUsageRepo{}, quotaRepo, map[domain.Role]domain.AIQuota{domain.RoleUser: quota}, nil)
+	return uc, quotaRepo
+}
+
+func TestMeterAICall_ChargesOnlySuccessfulCalls(t *testing.T) {
+	usage, repo := newTestUsage(domain.AIQuota{Daily_requests: 5, Monthly_requests: 50})
+	user := domain.AIRequestInfo{UserID: "u1", Role: domain.RoleUser}
+
+	err := meterAICall(context.Background(), usage, user, "test", func(ctx context.Context) error {
+		domain.AIUsageMeterFrom(ctx).Add("p", "m", 10, 20, false)
+		return nil
+	})
+	require.NoError(t, err)
+
+	failure := errors.New("provider down")
+	err = meterAICall(context.Background(), usage, user, "test", func(ctx context.Context) error { return failure })
+	assert.ErrorIs(t, err, failure)
+
+	assert.Equal(t, domain.AIUsageTotals{Requests: 1, Tokens: 30}, repo.usage["day"])
+	assert.Equal(t, domain.AIUsageTotals{Requests: 1, Tokens: 30}, repo.usage["month"])
+}
+
+func TestReserveQuota_ReleasesDayWhenMonthIsUsedUp(t *testing.T) {
+	usage, repo := newTestUsage(domain.AIQuota{Daily_requests: 5, Monthly_requests: 2})
+	repo.usage["month"] = domain.AIUsageTotals{Requests: 2}
+
+	_, err := usage.ReserveQuota(context.Background(), domain.AIRequestInfo{UserID: "u1", Role: domain.RoleUser})
+	assert.ErrorIs(t, err, domain.ErrAIQuotaExceeded)
+	assert.Equal(t, 0, repo.usage["day"].Requests)
+}
+
+func TestReserveQuota_SystemIsUnlimited(t *testing.T) {
+	usage, repo := newTestUsage(domain.AIQuota{Daily_requests: 1})
+
+	for i := 0; i < 3; i++ {
+		reservation, err := usage.ReserveQuota(context.Background(), domain.AIRequestInfo{Role: domain.RoleSystem})
+		require.NoError(t, err)
+		assert.Nil(t, reservation)
+	}
+	assert.Empty(t, repo.usage)
+}
+
+func TestQuotaStatus_ReadsChargedUsage(t *testing.T) {
+	usage, repo := newTestUsage(domain.AIQuota{Daily_requests: 5, Daily_tokens: 100})
+	repo.usage["day"] = domain.AIUsageTotals{Requests: 3, Tokens: 40}
+
+	status, err := usage.QuotaStatus(context.Background(), domain.AIRequestInfo{UserID: "u1", Role: domain.RoleUser})
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Daily.Requests_used)
+	assert.Equal(t, 40, status.Daily.Tokens_used)
+	assert.True(t, status.Daily.Resets_at.After(time.Now()))
+}
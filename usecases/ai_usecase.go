@@ -2,6 +2,10 @@ package usecases
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -10,13 +14,26 @@ import (
 
 // AIUseCase implements domain.IAiUseCase
 type AIUseCase struct{
-	AIService domain.IAIUseCase
+	AIService       domain.IAIContentService
+	ProviderMonitor domain.IAIProviderMonitor
+	Usage           domain.IAIUsageUseCase
+	Cache           domain.IAIResponseCache
+	Prompts         domain.IPromptRenderer
 }
 
-// NewAIUseCase returns an instance of new AIUsecase
-func NewAIUsecase(AIService domain.IAIContentService) domain.IAIUseCase {
+// NewAIUseCase returns an instance of new AIUsecase. Every call is checked against
+// the requester's quota and metered through usage; a nil usage disables both.
+// Results of SuggestTags, Summarize and GenerateTitle are kept in cache, which may be nil,
+// under the prompt version prompts picks for them and the model that answered, so a
+// rollout change is not served answers from the previous version, nor the primary
+// provider answers from a fallback. prompts must be the renderer AIService uses.
+func NewAIUsecase(AIService domain.IAIContentService, providerMonitor domain.IAIProviderMonitor, usage domain.IAIUsageUseCase, cache domain.IAIResponseCache, prompts domain.IPromptRenderer) domain.IAIUseCase {
 	return &AIUseCase{
-		AIService: AIService,
+		AIService:       AIService,
+		ProviderMonitor: providerMonitor,
+		Usage:           usage,
+		Cache:           cache,
+		Prompts:         prompts,
 	}
 }
 
@@ -31,7 +48,7 @@ func trimToMax(input string, maxChars int) string {
 
 // SuggestTags makes sure the title, content and maxTags are in the appropriate size.
 // It returns domain.ErrContentMissing if content length is zero.
-func (aiu *AIUseCase) SuggestTags(ctx context.Context, title, content string, maxTags int) ([]string, error) {
+func (aiu *AIUseCase) SuggestTags(ctx context.Context, requester domain.AIRequestInfo, title, content string, maxTags int) ([]string, error) {
 	content = strings.TrimSpace(content)
 	content = trimToMax(content, 500)
 	title = strings.TrimSpace(title)
@@ -43,12 +60,18 @@ func (aiu *AIUseCase) SuggestTags(ctx context.Context, title, content string, ma
 	if maxTags <= 0 || maxTags > 5 {
 		maxTags = 5
 	}
-	return aiu.AIService.SuggestTags(ctx, title, content, maxTags)
+	var tags []string
+	params := []string{strconv.Itoa(maxTags)}
+	err := aiu.cachedAICall(ctx, requester, "suggest_tags", params, title+"\x00"+content, &tags, func(ctx context.Context) (err error) {
+		tags, err = aiu.AIService.SuggestTags(ctx, title, content, maxTags)
+		return err
+	})
+	return tags, err
 }
 
 // Summarize makes sure the content length and maxWords are in the appropriate range.
 // It returns domain.ErrContentMissing if the content length is zero.
-func (aiu *AIUseCase) Summarize(ctx context.Context, content string, maxWords int) (string, error) {
+func (aiu *AIUseCase) Summarize(ctx context.Context, requester domain.AIRequestInfo, content string, maxWords int) (string, error) {
 	content = strings.TrimSpace(content)
 	content = trimToMax(content, 500)
 
@@ -60,12 +83,18 @@ func (aiu *AIUseCase) Summarize(ctx context.Context, content string, maxWords in
 		maxWords = 200
 	}
 
-	return aiu.AIService.Summarize(ctx, content, maxWords)
+	var summary string
+	params := []string{strconv.Itoa(maxWords)}
+	err := aiu.cachedAICall(ctx, requester, "summarize", params, content, &summary, func(ctx context.Context) (err error) {
+		summary, err = aiu.AIService.Summarize(ctx, content, maxWords)
+		return err
+	})
+	return summary, err
 }
 
 // GenerateTitle makes sure the style is appropriate and the content length is withing the required range.
 // It returns domain.ErrContentMissing if content length is zeor.
-func (aiu *AIUseCase) GenerateTitle(ctx context.Context, content, style string) (string, error) {
+func (aiu *AIUseCase) GenerateTitle(ctx context.Context, requester domain.AIRequestInfo, content, style string) (string, error) {
 	content = strings.TrimSpace(content)
 	content = trimToMax(content, 500)
 
@@ -93,11 +122,38 @@ func (aiu *AIUseCase) GenerateTitle(ctx context.Context, content, style string)
 		style = "formal"
 	}
 
-	return aiu.AIService.GenerateTitle(ctx, content, style)
+	var title string
+	params := []string{style}
+	err := aiu.cachedAICall(ctx, requester, "generate_title", params, content, &title, func(ctx context.Context) (err error) {
+		title, err = aiu.AIService.GenerateTitle(ctx, content, style)
+		return err
+	})
+	return title, err
 }
 
 // SuggestContent makes sure the style and wordCount appropriate and within the allowed style and count.
-func (aiu *AIUseCase) SuggestContent(ctx context.Context, keywords, style string, wordCount int) (string, error) {
+func (aiu *AIUseCase) SuggestContent(ctx context.Context, requester domain.AIRequestInfo, keywords, style string, wordCount int) (string, error) {
+	keywords, style, wordCount = normalizeSuggestContentInput(keywords, style, wordCount)
+	var content string
+	err := meterAICall(ctx, aiu.Usage, requester, "suggest_content", func(ctx context.Context) (err error) {
+		content, err = aiu.AIService.SuggestContent(ctx, keywords, style, wordCount)
+		return err
+	})
+	return content, err
+}
+
+// SuggestContentStream applies the same limits as SuggestContent and streams the drafted article.
+func (aiu *AIUseCase) SuggestContentStream(ctx context.Context, requester domain.AIRequestInfo, keywords, style string, wordCount int, onDelta domain.AIStreamHandler) (string, error) {
+	keywords, style, wordCount = normalizeSuggestContentInput(keywords, style, wordCount)
+	var content string
+	err := meterAICall(ctx, aiu.Usage, requester, "suggest_content_stream", func(ctx context.Context) (err error) {
+		content, err = aiu.AIService.SuggestContentStream(ctx, keywords, style, wordCount, onDelta)
+		return err
+	})
+	return content, err
+}
+
+func normalizeSuggestContentInput(keywords, style string, wordCount int) (string, string, int) {
 	if wordCount <= 0 || wordCount > 500 {
 		wordCount = 500
 	}
@@ -124,18 +180,45 @@ func (aiu *AIUseCase) SuggestContent(ctx context.Context, keywords, style string
 	if !allowedStyles[style] {
 		style = "formal"
 	}
-
-	return aiu.AIService.SuggestContent(ctx, keywords, style, wordCount)
+	return keywords, style, wordCount
 }
 
 // ImproveContent makes sure the content length is not off limit and set the focus to common areas.
 // It returns domain.ErrContentMissing if the content length is zero.
-func (aiu *AIUseCase) ImproveContent(ctx context.Context, content, focus string) (domain.ImprovementResult, error) {
+func (aiu *AIUseCase) ImproveContent(ctx context.Context, requester domain.AIRequestInfo, content, focus string) (domain.ImprovementResult, error) {
+	content, focus, err := normalizeImproveContentInput(content, focus)
+	if err != nil {
+		return domain.ImprovementResult{}, err
+	}
+	var result domain.ImprovementResult
+	err = meterAICall(ctx, aiu.Usage, requester, "improve_content", func(ctx context.Context) (err error) {
+		result, err = aiu.AIService.ImproveContent(ctx, content, focus)
+		return err
+	})
+	return result, err
+}
+
+// ImproveContentStream applies the same limits as ImproveContent and streams the improved text.
+// It returns domain.ErrContentMissing if the content length is zero.
+func (aiu *AIUseCase) ImproveContentStream(ctx context.Context, requester domain.AIRequestInfo, content, focus string, onDelta domain.AIStreamHandler) (string, error) {
+	content, focus, err := normalizeImproveContentInput(content, focus)
+	if err != nil {
+		return "", err
+	}
+	var improved string
+	err = meterAICall(ctx, aiu.Usage, requester, "improve_content_stream", func(ctx context.Context) (err error) {
+		improved, err = aiu.AIService.ImproveContentStream(ctx, content, focus, onDelta)
+		return err
+	})
+	return improved, err
+}
+
+func normalizeImproveContentInput(content, focus string) (string, string, error) {
 	content = strings.TrimSpace(content)
 	content = trimToMax(content, 500)
 
 	if content == "" {
-		return domain.ImprovementResult{}, domain.ErrContentMissing
+		return "", "", domain.ErrContentMissing
 	}
 
 	focus = strings.TrimSpace(focus)
@@ -157,18 +240,164 @@ func (aiu *AIUseCase) ImproveContent(ctx context.Context, content, focus string)
 	if !commonFocusAreas[focus] {
 		focus = "grammar"
 	}
-	return aiu.AIService.ImproveContent(ctx, content, focus)
+	return content, focus, nil
 }
 
 // Chat makes sure the AI messages history are not off limit and contains a valid message.
 // Top of the slice is considered the most recent message between the client and AI
-func (aiu *AIUseCase) Chat(ctx context.Context, messages []domain.AIMessage) (domain.AIMessage, error) {
+func (aiu *AIUseCase) Chat(ctx context.Context, requester domain.AIRequestInfo, messages []domain.AIMessage) (domain.AIMessage, error) {
+	messages = trimChatHistory(messages)
+	var reply domain.AIMessage
+	err := meterAICall(ctx, aiu.Usage, requester, "chat", func(ctx context.Context) (err error) {
+		reply, err = aiu.AIService.Chat(ctx, messages)
+		return err
+	})
+	return reply, err
+}
+
+// ChatStream applies the same history limits as Chat and streams the assistant reply.
+func (aiu *AIUseCase) ChatStream(ctx context.Context, requester domain.AIRequestInfo, messages []domain.AIMessage, onDelta domain.AIStreamHandler) (domain.AIMessage, error) {
+	messages = trimChatHistory(messages)
+	var reply domain.AIMessage
+	err := meterAICall(ctx, aiu.Usage, requester, "chat_stream", func(ctx context.Context) (err error) {
+		reply, err = aiu.AIService.ChatStream(ctx, messages, onDelta)
+		return err
+	})
+	return reply, err
+}
+
+func trimChatHistory(messages []domain.AIMessage) []domain.AIMessage {
 	if len(messages) > 5 {
 		messages = messages[len(messages)-5:]
 	}
 	for idx, message := range messages {
 		messages[idx].Content = trimToMax(message.Content, 200)
 	}
-	return aiu.AIService.Chat(ctx, messages)
+	return messages
+}
+
+// cachedAICall returns the cached result of an identical earlier call into out
+// when there is one, and otherwise runs call through metering and caches what it
+// stored in out. Cache hits cost nothing, so they are not counted against quota.
+// requester.Fresh skips the lookup but still refreshes the cache.
+//
+// The prompt version of operation is picked first and pinned for call, so the
+// key names the version the cached answer was produced with. When the renderer
+// falls back to the default, e.g. because the pinned version is gone, the
+// answer is cached under the default instead. Likewise the key names the model
+// expected to answer, and an answer from a fallback provider is cached under
+// the model that produced it, so it is served only while that model would answer.
+func (aiu *AIUseCase) cachedAICall(ctx context.Context, requester domain.AIRequestInfo, operation string, params []string, input string, out interface{}, call func(ctx context.Context) error) error {
+	if aiu.Cache == nil {
+		return meterAICall(ctx, aiu.Usage, requester, operation, call)
+	}
+
+	version, promptID, err := aiu.promptID(ctx, operation)
+	if err != nil {
+		return err
+	}
+	ctx = domain.WithPromptVersion(ctx, operation, version)
+
+	model := aiu.expectedModel()
+	key := aiCacheKey(operation, model, promptID, params, input)
+	if !requester.Fresh {
+		if cached, ok := aiu.Cache.Get(ctx, key); ok && json.Unmarshal([]byte(cached), out) == nil {
+			domain.AIUsageMeterFrom(ctx).SetPrompt(operation, version)
+			return nil
+		}
+	}
+
+	rendered, answeredBy := version, model
+	err = meterAICall(ctx, aiu.Usage, requester, operation, func(ctx context.Context) error {
+		meter := domain.AIUsageMeterFrom(ctx)
+		if meter == nil {
+			meter = &domain.AIUsageMeter{}
+			ctx = domain.WithAIUsageMeter(ctx, meter)
+		}
+		if err := call(ctx); err != nil {
+			return err
+		}
+		if meter.PromptTemplate == operation {
+			rendered = meter.PromptVersion
+		}
+		if meter.Model != "" {
+			answeredBy = meter.Provider + "/" + meter.Model
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if rendered != version || answeredBy != model {
+		key = aiCacheKey(operation, answeredBy, aiu.templateID(operation, rendered), params, input)
+	}
+	if encoded, err := json.Marshal(out); err == nil {
+		aiu.Cache.Set(ctx, key, string(encoded))
+	}
+	return nil
+}
+
+// expectedModel identifies the provider and model a call would go to: the first
+// in the chain whose circuit breaker is not open.
+func (aiu *AIUseCase) expectedModel() string {
+	if aiu.ProviderMonitor == nil {
+		return ""
+	}
+	for _, p := range aiu.ProviderMonitor.ProviderHealth() {
+		if p.State != "open" {
+			return p.Name + "/" + p.Model
+		}
+	}
+	return ""
+}
+
+// promptID picks the prompt version of name and returns it with an ID of the
+// template. Stored versions never change, while the built-in default changes
+// with the binary, so version 0 is identified by a hash of its body.
+func (aiu *AIUseCase) promptID(ctx context.Context, name string) (int, string, error) {
+	if aiu.Prompts == nil {
+		return 0, "", nil
+	}
+	version, err := aiu.Prompts.PickVersion(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	return version, aiu.templateID(name, version), nil
 }
 
+func (aiu *AIUseCase) templateID(name string, version int) string {
+	if aiu.Prompts == nil {
+		return ""
+	}
+	id := name + "@" + strconv.Itoa(version)
+	if version == 0 {
+		body, _ := aiu.Prompts.DefaultBody(name)
+		sum := sha256.Sum256([]byte(body))
+		id += ":" + hex.EncodeToString(sum[:8])
+	}
+	return id
+}
+
+// aiCacheKey derives a content-addressed key from the operation, model,
+// prompt template, normalized parameters and a hash of the whitespace-normalized input.
+func aiCacheKey(operation, model, prompt string, params []string, input string) string {
+	inputHash := sha256.Sum256([]byte(strings.Join(strings.Fields(input), " ")))
+
+	h := sha256.New()
+	for _, part := range append([]string{operation, model, prompt}, params...) {
+		h.Write([]byte(strings.ToLower(strings.TrimSpace(part))))
+		h.Write([]byte{0})
+	}
+	h.Write(inputHash[:])
+
+	return operation + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// ProviderHealth returns the circuit breaker state of each configured AI provider.
+// It returns an empty slice when no provider monitor is configured.
+func (aiu *AIUseCase) ProviderHealth(ctx context.Context) []domain.AIProviderHealth {
+	if aiu.ProviderMonitor == nil {
+		return []domain.AIProviderHealth{}
+	}
+	return aiu.ProviderMonitor.ProviderHealth()
+}
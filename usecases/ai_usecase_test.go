@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryCache map[string]string
+
+func (c memoryCache) Get(ctx context.Context, key string) (string, bool) {
+	value, ok := c[key]
+	return value, ok
+}
+
+func (c memoryCache) Set(ctx context.Context, key, value string) { c[key] = value }
+
+// fakeRenderer rolls out one version of every prompt.
+type fakeRenderer struct {
+	domain.IPromptRenderer
+	version int
+}
+
+func (r *fakeRenderer) PickVersion(ctx context.Context, name string) (int, error) {
+	return r.version, nil
+}
+
+func (r *fakeRenderer) DefaultBody(name string) (string, bool) { return "default", true }
+
+// fakeSummarizer answers with the prompt version pinned for the call, or with
+// the default when that version is missing.
+type fakeSummarizer struct {
+	domain.IAIContentService
+	calls   int
+	missing int
+	// answeredBy, when set, is the provider reported as having answered
+	answeredBy string
+}
+
+func (s *fakeSummarizer) Summarize(ctx context.Context, content string, maxWords int) (string, error) {
+	s.calls++
+	version, _ := domain.PinnedPromptVersion(ctx, "summarize")
+	if version == s.missing {
+		version = 0
+	}
+	domain.AIUsageMeterFrom(ctx).SetPrompt("summarize", version)
+	if s.answeredBy != "" {
+		domain.AIUsageMeterFrom(ctx).Add(s.answeredBy, "model", 10, 5, false)
+		return "summary by " + s.answeredBy, nil
+	}
+	return "summary v" + strconv.Itoa(version), nil
+}
+
+// fakeProviders reports a fixed provider chain.
+type fakeProviders []domain.AIProviderHealth
+
+func (p fakeProviders) ProviderHealth() []domain.AIProviderHealth { return p }
+
+func TestSummarize_CachesPerPromptVersion(t *testing.T) {
+	service := &fakeSummarizer{}
+	renderer := &fakeRenderer{version: 1}
+	uc := NewAIUsecase(service, nil, nil, memoryCache{}, renderer)
+	user := domain.AIRequestInfo{UserID: "u1"}
+
+	summary, err := uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v1", summary)
+
+	meter := &domain.AIUsageMeter{}
+	summary, err = uc.Summarize(domain.WithAIUsageMeter(context.Background(), meter), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v1", summary)
+	assert.Equal(t, 1, service.calls, "second call is a cache hit")
+	assert.Equal(t, "summarize", meter.PromptTemplate)
+	assert.Equal(t, 1, meter.PromptVersion)
+
+	renderer.version = 2
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v2", summary)
+	assert.Equal(t, 2, service.calls)
+}
+
+func TestSummarize_CachesUnderRenderedVersion(t *testing.T) {
+	service := &fakeSummarizer{missing: 7}
+	renderer := &fakeRenderer{version: 7}
+	uc := NewAIUsecase(service, nil, nil, memoryCache{}, renderer)
+	user := domain.AIRequestInfo{UserID: "u1"}
+
+	summary, err := uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v0", summary)
+
+	// version 7 is stored and rolled out again, so it must not be served the default
+	service.missing = -1
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v7", summary)
+	assert.Equal(t, 2, service.calls)
+
+	renderer.version = 0
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary v0", summary)
+	assert.Equal(t, 2, service.calls, "the default's answer was cached under the default")
+}
+
+func TestSummarize_CachesUnderAnsweringModel(t *testing.T) {
+	service := &fakeSummarizer{answeredBy: "deepseek"}
+	providers := fakeProviders{{Name: "groq", Model: "model", State: "closed"}, {Name: "deepseek", Model: "model", State: "closed"}}
+	uc := NewAIUsecase(service, providers, nil, memoryCache{}, &fakeRenderer{})
+	user := domain.AIRequestInfo{UserID: "u1"}
+
+	// groq failed on this call, so deepseek answered
+	summary, err := uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary by deepseek", summary)
+
+	service.answeredBy = "groq"
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary by groq", summary, "groq is expected to answer, so the deepseek answer is not served")
+	assert.Equal(t, 2, service.calls)
+
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary by groq", summary)
+
+	providers[0].State = "open"
+	summary, err = uc.Summarize(context.Background(), user, "some text", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "summary by deepseek", summary)
+	assert.Equal(t, 2, service.calls, "served from cache while groq is down")
+}
@@ -2,8 +2,10 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 	"unicode"
 
@@ -11,22 +13,26 @@ import (
 )
 
 type AuthUseCase struct {
-	UserRepo            domain.IUserRepository
-	PasswordService     domain.IPasswordService
-	JWTService          domain.IJWTService
-	NotificationService domain.INotificationService
-	BaseURL             string
-	ContextTimeout      time.Duration
+	UserRepo        domain.IUserRepository
+	PasswordService domain.IPasswordService
+	JWTService      domain.IJWTService
+	EmailOutbox     domain.IEmailOutbox
+	TxManager       domain.ITransactionManager
+	EmailRenderer   domain.IEmailRenderer
+	BaseURL         string
+	ContextTimeout  time.Duration
 }
 
-func NewAuthUseCase(repo domain.IUserRepository, ps domain.IPasswordService, jw domain.IJWTService, ns domain.INotificationService, bs string, timeout time.Duration) domain.IAuthUsecase {
+func NewAuthUseCase(repo domain.IUserRepository, ps domain.IPasswordService, jw domain.IJWTService, eo domain.IEmailOutbox, er domain.IEmailRenderer, tx domain.ITransactionManager, bs string, timeout time.Duration) domain.IAuthUsecase {
 	return &AuthUseCase{
-		UserRepo:            repo,
-		PasswordService:     ps,
-		JWTService:          jw,
-		NotificationService: ns,
-		BaseURL:             bs,
-		ContextTimeout:      timeout,
+		UserRepo:        repo,
+		PasswordService: ps,
+		JWTService:      jw,
+		EmailOutbox:     eo,
+		TxManager:       tx,
+		EmailRenderer:   er,
+		BaseURL:         bs,
+		ContextTimeout:  timeout,
 	}
 }
 
@@ -82,6 +88,18 @@ func (uc *AuthUseCase) Register(ctx context.Context, input *domain.User, oauthUs
 		hashedPassword = &hashed
 	}
 
+	var identities []domain.LinkedIdentity
+	isVerified := false
+	if oauthUser != nil {
+		identities = []domain.LinkedIdentity{identityFromOAuthUser(oauthUser)}
+		// trusted only when the provider asserts it checked the address
+		isVerified = oauthUser.IsVerified
+	}
+	language := oauthUserLocale(oauthUser)
+	if input != nil && input.Language != "" {
+		language = input.Language
+	}
+
 	// construct user model
 	newUser := domain.User{
 		Role:           role,
@@ -93,28 +111,37 @@ func (uc *AuthUseCase) Register(ctx context.Context, input *domain.User, oauthUs
 		Password:       hashedPassword,
 		ProfilePicture: oauthUserPicture(oauthUser),
 		Provider:       oauthUserProvider(oauthUser),
+		Identities:     identities,
+		IsVerified:     isVerified,
+		Language:       normalizeLanguage(language),
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
 
-	// save user to the database
-	err = uc.UserRepo.CreateUser(ctx, &newUser)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", domain.ErrUserCreationFailed, err)
-	}
+	// save the user and its verification email together, so a user is never
+	// left without one and no email goes out for a user that wasn't saved
+	var emailID string
+	err = uc.TxManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := uc.UserRepo.CreateUser(txCtx, &newUser); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrUserCreationFailed, err)
+		}
 
-	// only send verification email if not registered via OAuth
-	if oauthUser == nil {
+		// a provider that verified the address spares the verification email
+		if isVerified {
+			return nil
+		}
 		verificationToken, err := uc.JWTService.GenerateVerificationToken(fmt.Sprint(newUser.UserID))
 		if err != nil {
-			return nil, fmt.Errorf("%w: %v", domain.ErrTokenGenerationFailed, err)
+			return fmt.Errorf("%w: %v", domain.ErrTokenGenerationFailed, err)
 		}
 		verificationLink := fmt.Sprintf("%s/auth/verify?token=%s", uc.BaseURL, verificationToken)
-		emailBody := generateVerificationEmailBody(verificationLink)
-		if err = uc.NotificationService.SendEmail(newUser.Email, "Verify Your Email Address", emailBody); err != nil {
-			return nil,err
-		}
+		emailID, err = uc.queueEmail(txCtx, &newUser, domain.EmailTemplateVerifyEmail, verificationLink)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	uc.EmailOutbox.Dispatch(emailID)
 
 	return &newUser, nil
 }
@@ -139,8 +166,8 @@ func (uc *AuthUseCase) Login(ctx context.Context, input *domain.User) (*domain.L
 		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCredentials, err)
 	}
 
-	// reject login if registered via OAuth
-	if user.Provider != "" {
+	// reject login if the account only has OAuth identities
+	if user.Password == nil || *user.Password == "" {
 		return nil, fmt.Errorf("%w", domain.ErrOAuthUserCannotLoginWithPassword)
 	}
 
@@ -195,27 +222,17 @@ func (uc *AuthUseCase) OAuthLogin(ctx context.Context, oauthUser *domain.User) (
 	ctx, cancel := context.WithTimeout(ctx, uc.ContextTimeout)
 	defer cancel()
 
-	if oauthUser == nil || oauthUser.Email == "" {
+	if oauthUser == nil || oauthUser.Email == "" || oauthUser.UserID == "" {
 		return nil, domain.ErrInvalidOAuthUserData
 	}
 
-	// check if the user exists
-	user, err := uc.UserRepo.FindByEmail(ctx, oauthUser.Email)
-	if err != nil {
-		// if user doesn't exist, register them
-		if err == domain.ErrUserNotFound {
-			user, err = uc.Register(ctx, nil, oauthUser)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, domain.ErrDatabaseOperationFailed
-		}
+	// a linked identity logs in whatever email the provider reports now
+	user, err := uc.UserRepo.FindByIdentity(ctx, oauthUser.Provider, oauthUser.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = uc.oauthUserByEmail(ctx, oauthUser)
 	}
-
-	// ensure this user was created via the same provider
-	if user.Provider != oauthUser.Provider {
-		return nil, fmt.Errorf("%w: expected %s but got %s", domain.ErrOAuthProviderMismatch, user.Provider, oauthUser.Provider)
+	if err != nil {
+		return nil, err
 	}
 
 	// generate access token
@@ -248,7 +265,112 @@ func (uc *AuthUseCase) OAuthLogin(ctx context.Context, oauthUser *domain.User) (
 	}, nil
 }
 
+// oauthUserByEmail registers a new user for an unknown email. An existing
+// account is only logged into if it was registered with this provider before
+// identities were recorded; any other provider must be linked first.
+func (uc *AuthUseCase) oauthUserByEmail(ctx context.Context, oauthUser *domain.User) (*domain.User, error) {
+	user, err := uc.UserRepo.FindByEmail(ctx, oauthUser.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return uc.Register(ctx, nil, oauthUser)
+	}
+	if err != nil {
+		return nil, domain.ErrDatabaseOperationFailed
+	}
+
+	_, linked := user.Identity(oauthUser.Provider)
+	if user.Provider != oauthUser.Provider || linked {
+		return nil, fmt.Errorf("%w: log in and link %s to your account first", domain.ErrOAuthProviderMismatch, oauthUser.Provider)
+	}
+
+	identity := identityFromOAuthUser(oauthUser)
+	if err := uc.UserRepo.AddIdentity(ctx, user.UserID, identity); err != nil {
+		return nil, err
+	}
+	user.Identities = append(user.Identities, identity)
+	return user, nil
+}
+
+// ListIdentities returns the OAuth identities linked to the user
+func (uc *AuthUseCase) ListIdentities(ctx context.Context, userID string) ([]domain.LinkedIdentity, error) {
+	user, err := uc.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Identities == nil {
+		return []domain.LinkedIdentity{}, nil
+	}
+	return user.Identities, nil
+}
+
+// LinkIdentity links the provider account to the user, so either can be used to log in
+func (uc *AuthUseCase) LinkIdentity(ctx context.Context, userID string, oauthUser *domain.User) (domain.LinkedIdentity, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ContextTimeout)
+	defer cancel()
+
+	if oauthUser == nil || oauthUser.UserID == "" {
+		return domain.LinkedIdentity{}, domain.ErrInvalidOAuthUserData
+	}
+
+	identity := identityFromOAuthUser(oauthUser)
+	if err := uc.UserRepo.AddIdentity(ctx, userID, identity); err != nil {
+		return domain.LinkedIdentity{}, err
+	}
+	return identity, nil
+}
+
+// UnlinkIdentity removes the user's identity at provider, unless it is their last way to log in
+func (uc *AuthUseCase) UnlinkIdentity(ctx context.Context, userID, provider string) error {
+	user, err := uc.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := user.Identity(provider); !ok {
+		return domain.ErrIdentityNotLinked
+	}
+	if user.LoginMethods() <= 1 {
+		return domain.ErrLastLoginMethod
+	}
+	return uc.UserRepo.RemoveIdentity(ctx, userID, provider)
+}
+
+// SetPassword lets a user who signed up with OAuth also log in with a password
+func (uc *AuthUseCase) SetPassword(ctx context.Context, userID, newPassword string) error {
+	if !validatePasswordStrength(newPassword) {
+		return fmt.Errorf("%w", domain.ErrWeakPassword)
+	}
+
+	user, err := uc.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password != nil && *user.Password != "" {
+		return domain.ErrPasswordAlreadySet
+	}
+
+	hashedPassword, err := uc.PasswordService.HashPassword(newPassword)
+	if err != nil {
+		return domain.ErrPasswordHashingFailed
+	}
+
+	user.Password = &hashedPassword
+	user.UpdatedAt = time.Now()
+
+	if err := uc.UserRepo.UpdateUser(ctx, user); err != nil {
+		return domain.ErrDatabaseOperationFailed
+	}
+	return nil
+}
+
 // helper functions
+func identityFromOAuthUser(oauthUser *domain.User) domain.LinkedIdentity {
+	return domain.LinkedIdentity{
+		Provider:   oauthUser.Provider,
+		Subject_id: oauthUser.UserID,
+		Email:      oauthUser.Email,
+		Linked_at:  time.Now(),
+	}
+}
+
 func chooseNonEmpty(primary *string, fallback *string) *string {
 	if primary != nil && *primary != "" {
 		return primary
@@ -399,23 +521,18 @@ func (uc *AuthUseCase) ResendVerificationEmail(ctx context.Context, email string
 
 	//find id
 	user, err := uc.UserRepo.FindByEmail(ctx, email)
-	userID := user.UserID
 	if err != nil {
 		return domain.ErrDatabaseOperationFailed
 	}
 	//generate verification token
-	verificationToken, err := uc.JWTService.GenerateVerificationToken(userID)
+	verificationToken, err := uc.JWTService.GenerateVerificationToken(user.UserID)
 	if err != nil {
 		return domain.ErrTokenGenerationFailed
 	}
 
 	//send the verfication link
 	verificationLink := fmt.Sprintf("%s/verify?token=%s", uc.BaseURL, verificationToken)
-	emailBody := generateVerificationEmailBody(verificationLink)
-	if err = uc.NotificationService.SendEmail(email, "Verify Your Email Address", emailBody); err != nil {
-		fmt.Println("email sending failed:", err)
-	}
-	return nil
+	return uc.sendEmail(ctx, user, domain.EmailTemplateVerifyEmail, verificationLink)
 }
 
 // Request password reset
@@ -442,22 +559,7 @@ func (uc *AuthUseCase) RequestPasswordReset(ctx context.Context, email string) e
 	// can be extracted and send to reset password along with the new password
 	resetLink := fmt.Sprintf("%s/auth/forget?token=%s", uc.BaseURL, resetToken)
 
-	emailBody := fmt.Sprintf(`
-    <html>
-      <body style="font-family: Arial, sans-serif; line-height: 1.6;">
-        <h2>Password Reset Requested</h2>
-        <p>We received a request to reset your password. Click the link below to proceed. This link is one-time use and expires soon.</p>
-        <p>
-          <a href="%s" style="display: inline-block; padding: 10px 20px; background-color: #f39c12;
-          color: white; text-decoration: none; border-radius: 4px;">Reset Password</a>
-        </p>
-        <p>If you didn't request this, you can safely ignore this email.</p>
-        <p>— The Team</p>
-      </body>
-    </html>
-    `, resetLink)
-
-	if err := uc.NotificationService.SendEmail(user.Email, "reset your password", emailBody); err != nil {
+	if err := uc.sendEmail(ctx, user, domain.EmailTemplatePasswordReset, resetLink); err != nil {
 		return err
 
 	}
@@ -522,6 +624,11 @@ func (uc *AuthUseCase) ChangePassword(ctx context.Context, userID string, oldPas
 		return domain.ErrUserNotFound
 	}
 
+	// an OAuth-only account sets its first password with SetPassword
+	if user.Password == nil {
+		return domain.ErrPasswordNotSet
+	}
+
 	//verify old password
 
 	ok := uc.PasswordService.ComparePassword(*user.Password, oldPassword)
@@ -579,20 +686,55 @@ func validatePasswordStrength(password string) bool {
 
 //function to generate verification email body
 
-func generateVerificationEmailBody(verificationLink string) string {
-	return fmt.Sprintf(`
-    <html>
-      <body style="font-family: Arial, sans-serif; line-height: 1.6;">
-        <h2>Welcome!</h2>
-        <p>Thanks for signing up. Please verify your email address by clicking the link below.</p>
-        <p>This is a one-time link and may expire soon.</p>
-        <p>
-          <a href="%s" style="display: inline-block; padding: 10px 20px; background-color: #4CAF50;
-          color: white; text-decoration: none; border-radius: 4px;">Verify Email</a>
-        </p>
-        <p>If you didn’t request this, feel free to ignore this email.</p>
-        <p>— The Team</p>
-      </body>
-    </html>
-  `, verificationLink)
+// sendEmail queues the named email and hands it to the delivery workers.
+func (uc *AuthUseCase) sendEmail(ctx context.Context, user *domain.User, name, link string) error {
+	emailID, err := uc.queueEmail(ctx, user, name, link)
+	if err != nil {
+		return err
+	}
+	uc.EmailOutbox.Dispatch(emailID)
+	return nil
+}
+
+// queueEmail renders the named email with a link in the user's language and
+// puts it in the outbox.
+func (uc *AuthUseCase) queueEmail(ctx context.Context, user *domain.User, name, link string) (string, error) {
+	email, err := uc.EmailRenderer.Render(ctx, name, user.Language, map[string]interface{}{
+		"Name": recipientName(user),
+		"Link": link,
+	})
+	if err != nil {
+		return "", err
+	}
+	return uc.EmailOutbox.Queue(ctx, domain.EmailMessage{
+		To:      user.Email,
+		Subject: email.Subject,
+		HTML:    email.HTML,
+		Text:    email.Text,
+	}, name)
+}
+
+// recipientName is how an email greets the user.
+func recipientName(user *domain.User) string {
+	if user.FirstName != nil {
+		return *user.FirstName
+	}
+	if user.Username != nil {
+		return *user.Username
+	}
+	return ""
+}
+
+// normalizeLanguage turns a language tag such as "fr_CA" into "fr-ca".
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// oauthUserLocale returns the locale the provider reports for the user, if any.
+func oauthUserLocale(oauthUser *domain.User) string {
+	if oauthUser == nil {
+		return ""
+	}
+	locale, _ := oauthUser.RawData["locale"].(string)
+	return locale
 }
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthTest(users ...*domain.User) (domain.IAuthUsecase, *memoryUserRepo) {
+	repo := newMemoryUserRepo(users...)
+	return NewAuthUseCase(repo, fakePasswords{}, fakeTokens{}, nil, nil, nil, "", time.Second), repo
+}
+
+func googleIdentity(subject string) domain.LinkedIdentity {
+	return domain.LinkedIdentity{Provider: "google", Subject_id: subject, Email: "reader@gmail.com"}
+}
+
+func TestLinkIdentity(t *testing.T) {
+	uc, users := newAuthTest(
+		&domain.User{UserID: "owner", Email: "owner@example.com", Identities: []domain.LinkedIdentity{googleIdentity("sub-1")}},
+		&domain.User{UserID: "reader", Email: "reader@example.com", Password: strPtr("hashed:s3cretpassword")},
+	)
+	ctx := context.Background()
+
+	_, err := uc.LinkIdentity(ctx, "reader", &domain.User{Provider: "google", UserID: "sub-1", Email: "reader@gmail.com"})
+	assert.ErrorIs(t, err, domain.ErrIdentityAlreadyLinked, "the account belongs to another user")
+	assert.Empty(t, users.users["reader"].Identities)
+
+	_, err = uc.LinkIdentity(ctx, "reader", &domain.User{Provider: "google", Email: "reader@gmail.com"})
+	assert.ErrorIs(t, err, domain.ErrInvalidOAuthUserData)
+
+	identity, err := uc.LinkIdentity(ctx, "reader", &domain.User{Provider: "google", UserID: "sub-2", Email: "reader@gmail.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "sub-2", identity.Subject_id)
+	linked, ok := users.users["reader"].Identity("google")
+	require.True(t, ok)
+	assert.Equal(t, "sub-2", linked.Subject_id)
+
+	_, err = uc.LinkIdentity(ctx, "reader", &domain.User{Provider: "google", UserID: "sub-3", Email: "reader@gmail.com"})
+	assert.ErrorIs(t, err, domain.ErrIdentityAlreadyLinked, "one identity per provider")
+}
+
+func TestUnlinkIdentity_KeepsLastLoginMethod(t *testing.T) {
+	github := domain.LinkedIdentity{Provider: "github", Subject_id: "gh-1"}
+	uc, users := newAuthTest(
+		&domain.User{UserID: "oauth-only", Identities: []domain.LinkedIdentity{googleIdentity("sub-1")}},
+		&domain.User{UserID: "two-providers", Identities: []domain.LinkedIdentity{googleIdentity("sub-2"), github}},
+		&domain.User{UserID: "with-password", Password: strPtr("hashed:s3cretpassword"), Identities: []domain.LinkedIdentity{googleIdentity("sub-3")}},
+	)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, uc.UnlinkIdentity(ctx, "oauth-only", "google"), domain.ErrLastLoginMethod)
+	assert.Len(t, users.users["oauth-only"].Identities, 1)
+
+	assert.ErrorIs(t, uc.UnlinkIdentity(ctx, "oauth-only", "github"), domain.ErrIdentityNotLinked)
+
+	require.NoError(t, uc.UnlinkIdentity(ctx, "two-providers", "google"))
+	assert.Equal(t, []domain.LinkedIdentity{github}, users.users["two-providers"].Identities)
+	assert.ErrorIs(t, uc.UnlinkIdentity(ctx, "two-providers", "github"), domain.ErrLastLoginMethod)
+
+	require.NoError(t, uc.UnlinkIdentity(ctx, "with-password", "google"))
+	assert.Empty(t, users.users["with-password"].Identities)
+}
+
+func TestSetPassword(t *testing.T) {
+	uc, users := newAuthTest(
+		&domain.User{UserID: "oauth-only", Identities: []domain.LinkedIdentity{googleIdentity("sub-1")}},
+		&domain.User{UserID: "with-password", Password: strPtr("hashed:s3cretpassword")},
+	)
+	ctx := context.Background()
+
+	for _, weak := range []string{"short1", "lettersonly", "1234567890"} {
+		assert.ErrorIs(t, uc.SetPassword(ctx, "oauth-only", weak), domain.ErrWeakPassword, weak)
+	}
+	assert.Nil(t, users.users["oauth-only"].Password)
+
+	require.NoError(t, uc.SetPassword(ctx, "oauth-only", "n3wpassword"))
+	assert.Equal(t, "hashed:n3wpassword", *users.users["oauth-only"].Password)
+	assert.Equal(t, 2, users.users["oauth-only"].LoginMethods())
+
+	assert.ErrorIs(t, uc.SetPassword(ctx, "with-password", "n3wpassword"), domain.ErrPasswordAlreadySet)
+	assert.Equal(t, "hashed:s3cretpassword", *users.users["with-password"].Password)
+}
+
+func TestOAuthLogin_ResolvesLinkedIdentity(t *testing.T) {
+	uc, users := newAuthTest(
+		&domain.User{UserID: "linked", Email: "someone@example.com", Role: domain.RoleUser, Identities: []domain.LinkedIdentity{googleIdentity("sub-1")}},
+		&domain.User{UserID: "other", Email: "changed@gmail.com", Password: strPtr("hashed:s3cretpassword")},
+	)
+	ctx := context.Background()
+
+	// the provider now reports the email of another account
+	result, err := uc.OAuthLogin(ctx, &domain.User{Provider: "google", UserID: "sub-1", Email: "changed@gmail.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "linked", result.User.UserID)
+	assert.Equal(t, "access|linked", result.AccessToken)
+	assert.Equal(t, "refresh|linked", *users.users["linked"].RefreshToken)
+	assert.Nil(t, users.users["other"].AccessToken)
+
+	// an unlinked provider account is not logged into an account by email
+	_, err = uc.OAuthLogin(ctx, &domain.User{Provider: "google", UserID: "sub-9", Email: "changed@gmail.com"})
+	assert.ErrorIs(t, err, domain.ErrOAuthProviderMismatch)
+	assert.Nil(t, users.users["other"].AccessToken)
+}
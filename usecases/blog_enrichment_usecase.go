@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	enrichmentSummaryWords = 60
+	enrichmentMaxTags      = 5
+	// blogs saved more recently than this are left to the change listener
+	enrichmentSweepGrace = time.Minute
+	enrichmentSweepBatch = 100
+)
+
+// BlogEnrichmentUseCase implements domain.IBlogEnrichmentUseCase
+type BlogEnrichmentUseCase struct {
+	blogRepo  domain.IBlogRepository
+	tagRepo   domain.ITagRepository
+	AIUsecase domain.IAIUseCase
+	queue     domain.IJobQueue
+}
+
+// NewBlogEnrichmentUseCase returns an enrichment usecase that queues blog IDs on
+// queue. The queue's handler is expected to call Enrich.
+func NewBlogEnrichmentUseCase(
+	blogRepo domain.IBlogRepository,
+	tagRepo domain.ITagRepository,
+	AIUsecase domain.IAIUseCase,
+	queue domain.IJobQueue,
+) domain.IBlogEnrichmentUseCase {
+	return &BlogEnrichmentUseCase{
+		blogRepo:  blogRepo,
+		tagRepo:   tagRepo,
+		AIUsecase: AIUsecase,
+		queue:     queue,
+	}
+}
+
+// OnBlogSaved queues the blog if it opted in. A full queue is not an error:
+// the periodic sweep picks the blog up later.
+func (uc *BlogEnrichmentUseCase) OnBlogSaved(ctx context.Context, blog domain.Blog) {
+	if !blog.Auto_enrich || blog.Blog_id == "" {
+		return
+	}
+	if !uc.queue.Enqueue(blog.Blog_id) {
+		slog.WarnContext(ctx, "blog enrichment: could not queue blog, leaving it to the sweep", "blog_id", blog.Blog_id)
+	}
+}
+
+// OnBlogDeleted does nothing; a queued job for a deleted blog finds it gone.
+func (uc *BlogEnrichmentUseCase) OnBlogDeleted(ctx context.Context, blogID string) {}
+
+// Enrich generates the summary and suggested tags for the blog's current
+// content. The AI calls run as the system, so they are metered against the
+// author but never blocked by their quota.
+func (uc *BlogEnrichmentUseCase) Enrich(ctx context.Context, blogID string) error {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if errors.Is(err, domain.ErrBlogNotFound) || errors.Is(err, domain.ErrInvalidBlogID) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !blog.Auto_enrich || !blog.Enriched_version.Before(blog.Content_updated_at) {
+		return nil
+	}
+
+	requester := domain.AIRequestInfo{UserID: blog.User_id, Role: domain.RoleSystem}
+
+	summary, err := uc.AIUsecase.Summarize(ctx, requester, blog.Content, enrichmentSummaryWords)
+	if errors.Is(err, domain.ErrContentMissing) {
+		// retrying will not make the content appear
+		uc.markFailed(ctx, blog)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tags, err := uc.AIUsecase.SuggestTags(ctx, requester, blog.Title, blog.Content, enrichmentMaxTags)
+	if err != nil {
+		return err
+	}
+	suggested, err := uc.newTags(ctx, blog, tags)
+	if err != nil {
+		return err
+	}
+
+	return uc.blogRepo.SaveEnrichment(ctx, blog.Blog_id, blog.Content_updated_at, strings.TrimSpace(summary), suggested)
+}
+
+// MarkFailed records the failure for the blog's current content, so the sweep
+// leaves it alone until the author edits it again.
+func (uc *BlogEnrichmentUseCase) MarkFailed(ctx context.Context, blogID string, cause error) {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if err != nil {
+		return
+	}
+	slog.ErrorContext(ctx, "blog enrichment: giving up on blog", "blog_id", blogID, "error", cause)
+	uc.markFailed(ctx, blog)
+}
+
+func (uc *BlogEnrichmentUseCase) markFailed(ctx context.Context, blog domain.Blog) {
+	if err := uc.blogRepo.MarkEnrichmentFailed(ctx, blog.Blog_id, blog.Content_updated_at); err != nil {
+		slog.ErrorContext(ctx, "blog enrichment: failed to mark blog as failed", "blog_id", blog.Blog_id, "error", err)
+	}
+}
+
+// RequeueStale queues blogs whose enrichment is behind their content.
+func (uc *BlogEnrichmentUseCase) RequeueStale(ctx context.Context) {
+	blogIDs, err := uc.blogRepo.FindStaleEnrichments(ctx, time.Now().Add(-enrichmentSweepGrace), enrichmentSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "blog enrichment: sweep failed", "error", err)
+		return
+	}
+	for _, blogID := range blogIDs {
+		if !uc.queue.Enqueue(blogID) {
+			return
+		}
+	}
+}
+
+// newTags cleans up the model's suggestions and drops the tags the blog
+// already has.
+func (uc *BlogEnrichmentUseCase) newTags(ctx context.Context, blog domain.Blog, tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var names []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, tag)
+	}
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	existing, err := uc.tagRepo.FindByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	attached := make(map[string]bool, len(blog.Tag_ids))
+	for _, id := range blog.Tag_ids {
+		attached[id] = true
+	}
+	skip := make(map[string]bool)
+	for _, tag := range existing {
+		if attached[tag.Tag_id] {
+			skip[strings.ToLower(tag.TagName)] = true
+		}
+	}
+
+	suggested := []string{}
+	for _, name := range names {
+		if !skip[strings.ToLower(name)] {
+			suggested = append(suggested, name)
+		}
+	}
+	return suggested, nil
+}
@@ -0,0 +1,284 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	defaultQATopK     = 5
+	maxQuestionChars  = 1000
+	qaChunkWords      = 200
+	qaChunkOverlap    = 40
+	qaExcerptChars    = 240
+	qaReindexPageSize = 100
+	qaNoAnswer        = "I couldn't find anything about that in our blog posts."
+)
+
+// citationMarker matches the [n] references the model is asked to use.
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// BlogQAUseCase implements domain.IBlogQAUseCase
+type BlogQAUseCase struct {
+	blogRepo           domain.IBlogRepository
+	chunkRepo          domain.IBlogChunkRepository
+	index              domain.IBlogChunkIndex
+	embedder           domain.IEmbeddingClient
+	AIService          domain.IAIContentService
+	usage              domain.IAIUsageUseCase
+	transactionManager domain.ITransactionManager
+	queue              domain.IJobQueue
+	topK               int
+	minScore           float64
+}
+
+// NewBlogQAUseCase returns a question answering usecase. Blogs are indexed by
+// a background job on queue, whose handler is expected to call IndexBlog.
+// Questions are answered from the topK most similar chunks scoring at least
+// minScore.
+func NewBlogQAUseCase(
+	blogRepo domain.IBlogRepository,
+	chunkRepo domain.IBlogChunkRepository,
+	index domain.IBlogChunkIndex,
+	embedder domain.IEmbeddingClient,
+	AIService domain.IAIContentService,
+	usage domain.IAIUsageUseCase,
+	transactionManager domain.ITransactionManager,
+	queue domain.IJobQueue,
+	topK int,
+	minScore float64,
+) domain.IBlogQAUseCase {
+	if topK <= 0 {
+		topK = defaultQATopK
+	}
+	return &BlogQAUseCase{
+		blogRepo:           blogRepo,
+		chunkRepo:          chunkRepo,
+		index:              index,
+		embedder:           embedder,
+		AIService:          AIService,
+		usage:              usage,
+		transactionManager: transactionManager,
+		queue:              queue,
+		topK:               topK,
+		minScore:           minScore,
+	}
+}
+
+func (uc *BlogQAUseCase) OnBlogSaved(ctx context.Context, blog domain.Blog) {
+	uc.enqueue(ctx, blog.Blog_id)
+}
+
+func (uc *BlogQAUseCase) OnBlogDeleted(ctx context.Context, blogID string) {
+	uc.enqueue(ctx, blogID)
+}
+
+func (uc *BlogQAUseCase) enqueue(ctx context.Context, blogID string) {
+	if blogID != "" && !uc.queue.Enqueue(blogID) {
+		slog.WarnContext(ctx, "blog Q&A: could not queue blog for indexing", "blog_id", blogID)
+	}
+}
+
+// IndexBlog splits the blog into overlapping passages, embeds them and
+// replaces the blog's stored chunks. A blog that no longer exists loses its chunks.
+func (uc *BlogQAUseCase) IndexBlog(ctx context.Context, blogID string) error {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if errors.Is(err, domain.ErrBlogNotFound) || errors.Is(err, domain.ErrInvalidBlogID) {
+		return uc.chunkRepo.DeleteByBlog(ctx, blogID)
+	}
+	if err != nil {
+		return err
+	}
+
+	passages := chunkWords(blog.Content, qaChunkWords, qaChunkOverlap)
+	if len(passages) == 0 {
+		return uc.chunkRepo.DeleteByBlog(ctx, blogID)
+	}
+	inputs := make([]string, len(passages))
+	for i, passage := range passages {
+		// the title gives each passage the context of the whole post
+		inputs[i] = blog.Title + "\n\n" + passage
+	}
+
+	var vectors [][]float32
+	requester := domain.AIRequestInfo{UserID: blog.User_id, Role: domain.RoleSystem}
+	err = meterAICall(ctx, uc.usage, requester, "embed_blog", func(ctx context.Context) (err error) {
+		vectors, err = uc.embedder.Embed(ctx, inputs)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(passages) {
+		return fmt.Errorf("%w: got %d embeddings for %d passages", domain.ErrAIEmbeddingFailed, len(vectors), len(passages))
+	}
+
+	chunks := make([]domain.BlogChunk, len(passages))
+	for i, passage := range passages {
+		chunks[i] = domain.BlogChunk{
+			Blog_id:    blog.Blog_id,
+			Blog_title: blog.Title,
+			Position:   i,
+			Content:    passage,
+			Embedding:  vectors[i],
+			Model:      uc.embedder.Model(),
+		}
+	}
+
+	return uc.transactionManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		return uc.chunkRepo.ReplaceForBlog(txCtx, blog.Blog_id, chunks)
+	})
+}
+
+// ReindexAll queues every blog, e.g. after switching the embedding model.
+func (uc *BlogQAUseCase) ReindexAll(ctx context.Context) (int, error) {
+	queued := 0
+	for page := 1; ; page++ {
+		blogs, total, err := uc.blogRepo.GetAll(ctx, page, qaReindexPageSize)
+		if err != nil {
+			return queued, err
+		}
+		for _, blog := range blogs {
+			if uc.queue.Enqueue(blog.Blog_id) {
+				queued++
+			}
+		}
+		if len(blogs) == 0 || page*qaReindexPageSize >= total {
+			return queued, nil
+		}
+	}
+}
+
+// Ask answers the question from the most similar blog passages and cites the
+// posts the answer used.
+// It returns domain.ErrEmptyQuestion if the question is blank.
+func (uc *BlogQAUseCase) Ask(ctx context.Context, requester domain.AIRequestInfo, question string) (domain.AIAnswer, error) {
+	question = trimToMax(strings.TrimSpace(question), maxQuestionChars)
+	if question == "" {
+		return domain.AIAnswer{}, domain.ErrEmptyQuestion
+	}
+
+	var answer domain.AIAnswer
+	err := meterAICall(ctx, uc.usage, requester, "ask", func(ctx context.Context) error {
+		vectors, err := uc.embedder.Embed(ctx, []string{question})
+		if err != nil {
+			return err
+		}
+		if len(vectors) != 1 {
+			return fmt.Errorf("%w: no embedding for the question", domain.ErrAIEmbeddingFailed)
+		}
+
+		hits, err := uc.index.Search(ctx, uc.embedder.Model(), vectors[0], uc.topK)
+		if err != nil {
+			return err
+		}
+		hits = relevantHits(hits, uc.minScore)
+		if len(hits) == 0 {
+			answer = domain.AIAnswer{Answer: qaNoAnswer, Citations: []domain.AICitation{}}
+			return nil
+		}
+
+		reply, err := uc.AIService.Chat(ctx, qaMessages(question, hits))
+		if err != nil {
+			return err
+		}
+		answer = domain.AIAnswer{
+			Answer:    strings.TrimSpace(reply.Content),
+			Citations: citationsFor(reply.Content, hits),
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.AIAnswer{}, err
+	}
+	return answer, nil
+}
+
+func relevantHits(hits []domain.ScoredBlogChunk, minScore float64) []domain.ScoredBlogChunk {
+	relevant := hits[:0:0]
+	for _, hit := range hits {
+		if hit.Score >= minScore {
+			relevant = append(relevant, hit)
+		}
+	}
+	return relevant
+}
+
+func qaMessages(question string, hits []domain.ScoredBlogChunk) []domain.AIMessage {
+	var sources strings.Builder
+	for i, hit := range hits {
+		fmt.Fprintf(&sources, "[%d] %s\n%s\n\n", i+1, hit.Chunk.Blog_title, hit.Chunk.Content)
+	}
+	return []domain.AIMessage{
+		{
+			Role: "system",
+			Content: "Answer the reader's question using only the numbered blog excerpts below. " +
+				"Cite every excerpt you use with its number in square brackets, like [1]. " +
+				"If the excerpts do not answer the question, say that the blog does not cover it.\n\n" +
+				sources.String(),
+		},
+		{Role: "user", Content: question},
+	}
+}
+
+// citationsFor lists the blogs behind the excerpts the answer cites, once
+// each, in citation order. An answer that cites nothing gets every excerpt's blog.
+func citationsFor(answer string, hits []domain.ScoredBlogChunk) []domain.AICitation {
+	var cited []domain.ScoredBlogChunk
+	for _, match := range citationMarker.FindAllStringSubmatch(answer, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err == nil && n >= 1 && n <= len(hits) {
+			cited = append(cited, hits[n-1])
+		}
+	}
+	if len(cited) == 0 {
+		cited = hits
+	}
+
+	seen := make(map[string]bool, len(cited))
+	citations := []domain.AICitation{}
+	for _, hit := range cited {
+		if seen[hit.Chunk.Blog_id] {
+			continue
+		}
+		seen[hit.Chunk.Blog_id] = true
+		citations = append(citations, domain.AICitation{
+			Blog_id: hit.Chunk.Blog_id,
+			Title:   hit.Chunk.Blog_title,
+			Excerpt: trimToMax(hit.Chunk.Content, qaExcerptChars),
+			Score:   hit.Score,
+		})
+	}
+	return citations
+}
+
+// chunkWords splits text into passages of size words, each starting overlap
+// words before the previous one ended. An overlap that would not move the
+// next passage forward is ignored.
+func chunkWords(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 || size <= 0 {
+		return nil
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	step := size - overlap
+	var passages []string
+	for start := 0; ; start += step {
+		end := start + size
+		if end >= len(words) {
+			passages = append(passages, strings.Join(words[start:], " "))
+			return passages
+		}
+		passages = append(passages, strings.Join(words[start:end], " "))
+	}
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func (fakeEmbedder) Model() string { return "test-embed" }
+
+// fakeChunkIndex returns the same hits for every query.
+type fakeChunkIndex struct{ hits []domain.ScoredBlogChunk }
+
+func (i fakeChunkIndex) Search(ctx context.Context, model string, query []float32, k int) ([]domain.ScoredBlogChunk, error) {
+	if len(i.hits) > k {
+		return i.hits[:k], nil
+	}
+	return i.hits, nil
+}
+
+// qaChat replies with reply and records the prompt.
+type qaChat struct {
+	domain.IAIContentService
+	reply  string
+	prompt []domain.AIMessage
+}
+
+func (s *qaChat) Chat(ctx context.Context, messages []domain.AIMessage) (domain.AIMessage, error) {
+	s.prompt = messages
+	return domain.AIMessage{Role: "assistant", Content: s.reply}, nil
+}
+
+func hit(blogID, content string, score float64) domain.ScoredBlogChunk {
+	return domain.ScoredBlogChunk{Chunk: domain.BlogChunk{Blog_id: blogID, Blog_title: "Title of " + blogID, Content: content}, Score: score}
+}
+
+func newQATest(chat *qaChat, minScore float64, hits ...domain.ScoredBlogChunk) domain.IBlogQAUseCase {
+	return NewBlogQAUseCase(nil, nil, fakeChunkIndex{hits}, fakeEmbedder{}, chat, nil, nil, nil, 5, minScore)
+}
+
+func TestAsk_NoAnswerBelowMinScore(t *testing.T) {
+	chat := &qaChat{reply: "made up"}
+	uc := newQATest(chat, 0.5, hit("b1", "about goroutines", 0.49), hit("b2", "about channels", 0.2))
+
+	answer, err := uc.Ask(context.Background(), domain.AIRequestInfo{UserID: "u1"}, "How do I bake bread?")
+	require.NoError(t, err)
+	assert.Equal(t, qaNoAnswer, answer.Answer)
+	assert.Equal(t, []domain.AICitation{}, answer.Citations)
+	assert.Nil(t, chat.prompt, "the model is not asked without excerpts")
+
+	_, err = uc.Ask(context.Background(), domain.AIRequestInfo{UserID: "u1"}, "   ")
+	assert.ErrorIs(t, err, domain.ErrEmptyQuestion)
+}
+
+func TestAsk_CitesTheExcerptsUsed(t *testing.T) {
+	chat := &qaChat{reply: "Use a channel [2], or a mutex [3][2]. See also [9]."}
+	uc := newQATest(chat, 0.5,
+		hit("b1", "about goroutines", 0.9),
+		hit("b2", "about channels", 0.8),
+		hit("b3", "about mutexes", 0.7),
+		hit("b4", "about nothing", 0.3),
+	)
+
+	answer, err := uc.Ask(context.Background(), domain.AIRequestInfo{UserID: "u1"}, "How do goroutines share data?")
+	require.NoError(t, err)
+	assert.Equal(t, chat.reply, answer.Answer)
+	assert.Equal(t, []domain.AICitation{
+		{Blog_id: "b2", Title: "Title of b2", Excerpt: "about channels", Score: 0.8},
+		{Blog_id: "b3", Title: "Title of b3", Excerpt: "about mutexes", Score: 0.7},
+	}, answer.Citations, "in citation order, once each, ignoring numbers without an excerpt")
+
+	// the numbers the model cites are those of the excerpts in the prompt
+	system := chat.prompt[0].Content
+	assert.Contains(t, system, "[2] Title of b2\nabout channels")
+	assert.Contains(t, system, "[3] Title of b3\nabout mutexes")
+	assert.NotContains(t, system, "about nothing")
+}
+
+func TestAsk_CitesEveryExcerptWhenAnswerCitesNone(t *testing.T) {
+	chat := &qaChat{reply: "Goroutines share memory by communicating."}
+	uc := newQATest(chat, 0.5, hit("b1", "first", 0.9), hit("b1", "second", 0.8), hit("b2", "third", 0.6))
+
+	answer, err := uc.Ask(context.Background(), domain.AIRequestInfo{UserID: "u1"}, "How do goroutines share data?")
+	require.NoError(t, err)
+	require.Len(t, answer.Citations, 2)
+	assert.Equal(t, "b1", answer.Citations[0].Blog_id)
+	assert.Equal(t, "first", answer.Citations[0].Excerpt, "a blog is cited by its best excerpt")
+	assert.Equal(t, "b2", answer.Citations[1].Blog_id)
+}
+
+func TestChunkWords(t *testing.T) {
+	words := func(n int) string {
+		w := make([]string, n)
+		for i := range w {
+			w[i] = string(rune('a' + i))
+		}
+		return strings.Join(w, " ")
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		size, overlap int
+		want          []string
+	}{
+		{name: "empty", text: "", size: 3, overlap: 1, want: nil},
+		{name: "only whitespace", text: " \n\t ", size: 3, overlap: 1, want: nil},
+		{name: "shorter than a chunk", text: "a  b\n", size: 3, overlap: 1, want: []string{"a b"}},
+		{name: "exactly one chunk", text: words(3), size: 3, overlap: 1, want: []string{"a b c"}},
+		{name: "overlapping", text: words(7), size: 3, overlap: 1, want: []string{"a b c", "c d e", "e f g"}},
+		{name: "overlap equal to size", text: words(5), size: 3, overlap: 3, want: []string{"a b c", "d e"}},
+		{name: "overlap larger than size", text: words(5), size: 2, overlap: 5, want: []string{"a b", "c d", "e"}},
+		{name: "no size", text: words(5), size: 0, overlap: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, chunkWords(tt.text, tt.size, tt.overlap))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/InkForge/Blog_Website/domain"
@@ -14,13 +15,17 @@ type BlogUsecase struct {
 	tagRepo            domain.ITagRepository
 	userRepo           domain.IUserRepository
 	transactionManager domain.ITransactionManager
+	listeners          []domain.IBlogChangeListener
 }
 
+// NewBlogUsecase returns a blog usecase. listeners are told about every
+// committed create, content change and delete.
 func NewBlogUsecase(blogRepo domain.IBlogRepository,
 	blogViewRepo domain.IBlogViewRepository,
 	tagRepo domain.ITagRepository,
 	userRepo domain.IUserRepository,
 	transactionManager domain.ITransactionManager,
+	listeners ...domain.IBlogChangeListener,
 ) domain.IBlogUseCase {
 
 	return &BlogUsecase{
@@ -29,6 +34,19 @@ func NewBlogUsecase(blogRepo domain.IBlogRepository,
 		tagRepo:            tagRepo,
 		userRepo:           userRepo,
 		transactionManager: transactionManager,
+		listeners:          listeners,
+	}
+}
+
+func (bu *BlogUsecase) notifySaved(ctx context.Context, blog domain.Blog) {
+	for _, l := range bu.listeners {
+		l.OnBlogSaved(ctx, blog)
+	}
+}
+
+func (bu *BlogUsecase) notifyDeleted(ctx context.Context, blogID string) {
+	for _, l := range bu.listeners {
+		l.OnBlogDeleted(ctx, blogID)
 	}
 }
 
@@ -102,6 +120,7 @@ func (bu *BlogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog) (strin
 		now := time.Now()
 		blog.Created_at = now
 		blog.Updated_at = now
+		blog.Content_updated_at = now
 		blog.Like_count = 0
 		blog.Dislike_count = 0
 		blog.View_count = 0
@@ -115,8 +134,14 @@ func (bu *BlogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog) (strin
 
 		return nil
 	})
+	if err != nil {
+		return "", err
+	}
+
+	blog.Blog_id = blogID
+	bu.notifySaved(ctx, *blog)
 
-	return blogID, err
+	return blogID, nil
 }
 
 func (bu *BlogUsecase) GetAllBlogs(ctx context.Context, page, limit int) (*domain.PaginatedBlogs, error) {
@@ -181,11 +206,15 @@ func (bu *BlogUsecase) UpdateBlog(ctx context.Context, blog *domain.Blog, userID
 		return domain.ErrNotBlogAuthor
 	}
 
-	return bu.transactionManager.WithTransaction(ctx, func(txCtx context.Context) error {
+	var saved domain.Blog
+	contentChanged := false
+	err := bu.transactionManager.WithTransaction(ctx, func(txCtx context.Context) error {
 		existing, err := bu.blogRepo.GetByID(txCtx, blog.Blog_id)
 		if err != nil {
 			return err
 		}
+		contentChanged = (blog.Title != "" && blog.Title != existing.Title) ||
+			(blog.Content != "" && blog.Content != existing.Content)
 
 		if blog.Title != "" {
 			existing.Title = blog.Title
@@ -206,17 +235,131 @@ func (bu *BlogUsecase) UpdateBlog(ctx context.Context, blog *domain.Blog, userID
 		}
 
 		existing.Updated_at = time.Now()
+		saved = existing
 
 		return bu.blogRepo.Update(txCtx, existing)
 	})
+	if err != nil {
+		return err
+	}
+
+	if contentChanged {
+		bu.notifySaved(ctx, saved)
+	}
+	return nil
 }
 
 func (bu *BlogUsecase) DeleteBlog(ctx context.Context, blogID string) error {
 	if blogID == "" {
 		return domain.ErrBlogIDRequired
 	}
-	return bu.blogRepo.Delete(ctx, blogID)
+	if err := bu.blogRepo.Delete(ctx, blogID); err != nil {
+		return err
+	}
+
+	bu.notifyDeleted(ctx, blogID)
+	return nil
+}
+
+// SetAutoEnrich turns background AI enrichment on or off for the author's blog.
+// Turning it on queues the blog straight away.
+func (bu *BlogUsecase) SetAutoEnrich(ctx context.Context, blogID, userID string, enabled bool) error {
+	if blogID == "" {
+		return domain.ErrBlogIDRequired
+	}
+	blog, err := bu.blogRepo.GetByID(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	if blog.User_id != userID {
+		return domain.ErrNotBlogAuthor
+	}
+	if blog.Auto_enrich == enabled {
+		return nil
+	}
+
+	if err := bu.blogRepo.SetAutoEnrich(ctx, blogID, enabled); err != nil {
+		return err
+	}
+	if enabled {
+		blog.Auto_enrich = true
+		bu.notifySaved(ctx, blog)
+	}
+	return nil
+}
+
+func (bu *BlogUsecase) AcceptSuggestedTags(ctx context.Context, blogID, userID string, tags []string) error {
+	return bu.reviewSuggestedTags(ctx, blogID, userID, tags, true)
+}
+
+func (bu *BlogUsecase) RejectSuggestedTags(ctx context.Context, blogID, userID string, tags []string) error {
+	return bu.reviewSuggestedTags(ctx, blogID, userID, tags, false)
+}
+
+// reviewSuggestedTags removes the chosen tags from the blog's suggestions and,
+// when accepting, attaches them to the blog. It returns domain.ErrNoSuggestedTags
+// if none of the chosen tags is currently suggested.
+func (bu *BlogUsecase) reviewSuggestedTags(ctx context.Context, blogID, userID string, tags []string, accept bool) error {
+	if blogID == "" {
+		return domain.ErrBlogIDRequired
+	}
+
+	return bu.transactionManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		blog, err := bu.blogRepo.GetByID(txCtx, blogID)
+		if err != nil {
+			return err
+		}
+		if blog.User_id != userID {
+			return domain.ErrNotBlogAuthor
+		}
+
+		chosen, remaining := splitSuggestedTags(blog.Suggested_tags, tags)
+		if len(chosen) == 0 {
+			return domain.ErrNoSuggestedTags
+		}
 
+		tagIDs := blog.Tag_ids
+		if accept {
+			newIDs, err := bu.ensureTagsExist(txCtx, chosen)
+			if err != nil {
+				return err
+			}
+			tagIDs = mergeIDs(tagIDs, newIDs)
+		}
+
+		return bu.blogRepo.UpdateTagSuggestions(txCtx, blogID, tagIDs, remaining)
+	})
+}
+
+// splitSuggestedTags separates the suggestions named in chosen (all of them if
+// chosen is empty) from the rest. Names are compared case-insensitively.
+func splitSuggestedTags(suggested, chosen []string) (picked, remaining []string) {
+	wanted := make(map[string]bool, len(chosen))
+	for _, name := range chosen {
+		wanted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	remaining = []string{}
+	for _, name := range suggested {
+		if len(chosen) == 0 || wanted[strings.ToLower(name)] {
+			picked = append(picked, name)
+		} else {
+			remaining = append(remaining, name)
+		}
+	}
+	return picked, remaining
+}
+
+func mergeIDs(ids, extra []string) []string {
+	seen := make(map[string]bool, len(ids)+len(extra))
+	merged := make([]string, 0, len(ids)+len(extra))
+	for _, id := range append(append([]string{}, ids...), extra...) {
+		if !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	return merged
 }
 
 func (bu *BlogUsecase) FilterBlogs(ctx context.Context, params domain.FilterParams) (*domain.PaginatedBlogs, error) {
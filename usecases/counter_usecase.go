@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// CounterUseCase implements domain.ICounterUseCase
+type CounterUseCase struct {
+	counterRepo domain.ICounterRepository
+	now         func() time.Time
+
+	// running keeps a scheduled run and one started by an admin apart
+	running sync.Mutex
+}
+
+func NewCounterUseCase(counterRepo domain.ICounterRepository) domain.ICounterUseCase {
+	return &CounterUseCase{counterRepo: counterRepo, now: time.Now}
+}
+
+func (uc *CounterUseCase) Reconcile(ctx context.Context, repair bool) (domain.CounterReport, error) {
+	if !uc.running.TryLock() {
+		return domain.CounterReport{}, domain.ErrCounterReconcileRunning
+	}
+	defer uc.running.Unlock()
+
+	report := domain.CounterReport{Started_at: uc.now()}
+	collect := func(d domain.CounterDiscrepancy) error {
+		report.Discrepancies = append(report.Discrepancies, d)
+		return nil
+	}
+
+	var err error
+	if report.Blogs_checked, err = uc.counterRepo.FindBlogDiscrepancies(ctx, collect); err != nil {
+		return report, err
+	}
+	if report.Comments_checked, err = uc.counterRepo.FindCommentDiscrepancies(ctx, collect); err != nil {
+		return report, err
+	}
+
+	if repair {
+		for _, d := range report.Discrepancies {
+			err := uc.counterRepo.Repair(ctx, d)
+			switch {
+			case err == nil:
+				report.Repaired++
+			case errors.Is(err, domain.ErrCounterChanged):
+				report.Skipped++
+			default:
+				return report, err
+			}
+		}
+	}
+
+	report.Finished_at = uc.now()
+	if len(report.Discrepancies) > 0 {
+		slog.WarnContext(ctx, "counters drifted", "discrepancies", len(report.Discrepancies), "repaired", report.Repaired, "skipped", report.Skipped)
+	}
+	return report, nil
+}
@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCounterRepo reports fixed discrepancies and records repairs.
+type fakeCounterRepo struct {
+	blogs    []domain.CounterDiscrepancy
+	comments []domain.CounterDiscrepancy
+	// changed are the fields whose repair finds the counter changed
+	changed  map[string]bool
+	repaired []domain.CounterDiscrepancy
+	// started and release, when set, hold the blog recount until released
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *fakeCounterRepo) FindBlogDiscrepancies(ctx context.Context, fn func(domain.CounterDiscrepancy) error) (int, error) {
+	if r.started != nil {
+		close(r.started)
+		<-r.release
+	}
+	for _, d := range r.blogs {
+		if err := fn(d); err != nil {
+			return 0, err
+		}
+	}
+	return 10, nil
+}
+
+func (r *fakeCounterRepo) FindCommentDiscrepancies(ctx context.Context, fn func(domain.CounterDiscrepancy) error) (int, error) {
+	for _, d := range r.comments {
+		if err := fn(d); err != nil {
+			return 0, err
+		}
+	}
+	return 20, nil
+}
+
+func (r *fakeCounterRepo) Repair(ctx context.Context, d domain.CounterDiscrepancy) error {
+	if r.changed[d.Field] {
+		return domain.ErrCounterChanged
+	}
+	r.repaired = append(r.repaired, d)
+	return nil
+}
+
+func newFakeCounterRepo() *fakeCounterRepo {
+	return &fakeCounterRepo{
+		blogs: []domain.CounterDiscrepancy{
+			{Collection: "blogs", Document_id: "b1", Field: "like_count", Stored: 3, Actual: 4},
+			{Collection: "blogs", Document_id: "b1", Field: "view_count", Stored: 10, Actual: 12},
+		},
+		comments: []domain.CounterDiscrepancy{
+			{Collection: "comments", Document_id: "c1", Field: "dislike", Stored: 1, Actual: 0},
+		},
+	}
+}
+
+func TestReconcile_ReportsDiscrepancies(t *testing.T) {
+	repo := newFakeCounterRepo()
+	report, err := NewCounterUseCase(repo).Reconcile(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, report.Blogs_checked)
+	assert.Equal(t, 20, report.Comments_checked)
+	assert.Equal(t, append(repo.blogs, repo.comments...), report.Discrepancies)
+	assert.Equal(t, 3, report.Repaired)
+	assert.Equal(t, report.Discrepancies, repo.repaired)
+	assert.False(t, report.Finished_at.Before(report.Started_at))
+}
+
+func TestReconcile_WithoutRepairLeavesCounters(t *testing.T) {
+	repo := newFakeCounterRepo()
+	report, err := NewCounterUseCase(repo).Reconcile(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.Len(t, report.Discrepancies, 3)
+	assert.Zero(t, report.Repaired)
+	assert.Empty(t, repo.repaired)
+}
+
+func TestReconcile_SkipsCountersThatChanged(t *testing.T) {
+	repo := newFakeCounterRepo()
+	repo.changed = map[string]bool{"view_count": true}
+	report, err := NewCounterUseCase(repo).Reconcile(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Repaired)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Len(t, report.Discrepancies, 3, "a skipped counter is still reported")
+}
+
+func TestReconcile_OneRunAtATime(t *testing.T) {
+	repo := newFakeCounterRepo()
+	repo.started, repo.release = make(chan struct{}), make(chan struct{})
+	uc := NewCounterUseCase(repo)
+
+	done := make(chan error)
+	go func() {
+		_, err := uc.Reconcile(context.Background(), true)
+		done <- err
+	}()
+	<-repo.started
+
+	_, err := uc.Reconcile(context.Background(), false)
+	assert.ErrorIs(t, err, domain.ErrCounterReconcileRunning)
+
+	close(repo.release)
+	require.NoError(t, <-done)
+	repo.started = nil
+	_, err = uc.Reconcile(context.Background(), false)
+	assert.NoError(t, err, "runs again once the first one finished")
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// DigestUnsubscribePurpose scopes the tokens of digest unsubscribe links.
+const DigestUnsubscribePurpose = "digest_unsubscribe"
+
+const (
+	digestSweepBatch  = 100
+	digestMaxPosts    = 20
+	digestMaxFollows  = 50
+	digestSummaryRune = 240
+	// unsubscribe links keep working long after the digest they came in
+	digestUnsubscribeTTL = 90 * 24 * time.Hour
+)
+
+// DigestUseCase implements domain.IDigestUseCase
+type DigestUseCase struct {
+	digestRepo  domain.IDigestSubscriptionRepository
+	blogRepo    domain.IBlogRepository
+	userRepo    domain.IUserRepository
+	renderer    domain.IEmailRenderer
+	emailOutbox domain.IEmailOutbox
+	jwtService  domain.IJWTService
+	txManager   domain.ITransactionManager
+	baseURL     string
+	now         func() time.Time
+}
+
+func NewDigestUseCase(
+	digestRepo domain.IDigestSubscriptionRepository,
+	blogRepo domain.IBlogRepository,
+	userRepo domain.IUserRepository,
+	renderer domain.IEmailRenderer,
+	emailOutbox domain.IEmailOutbox,
+	jwtService domain.IJWTService,
+	txManager domain.ITransactionManager,
+	baseURL string,
+) domain.IDigestUseCase {
+	return &DigestUseCase{
+		digestRepo:  digestRepo,
+		blogRepo:    blogRepo,
+		userRepo:    userRepo,
+		renderer:    renderer,
+		emailOutbox: emailOutbox,
+		jwtService:  jwtService,
+		txManager:   txManager,
+		baseURL:     baseURL,
+		now:         time.Now,
+	}
+}
+
+// GetPreferences returns the user's digest preferences, with digests off if
+// the user never subscribed.
+func (uc *DigestUseCase) GetPreferences(ctx context.Context, userID string) (domain.DigestSubscription, error) {
+	sub, err := uc.digestRepo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrDigestSubscriptionNotFound) {
+		return domain.DigestSubscription{User_id: userID, Frequency: domain.DigestOff}, nil
+	}
+	return sub, err
+}
+
+// UpdatePreferences replaces the followed authors and tags. Turning digests
+// on, or changing how often they come, starts a new period from now.
+func (uc *DigestUseCase) UpdatePreferences(ctx context.Context, userID string, frequency domain.DigestFrequency, authorIDs, tagIDs []string) (domain.DigestSubscription, error) {
+	switch frequency {
+	case domain.DigestOff, domain.DigestDaily, domain.DigestWeekly:
+	default:
+		return domain.DigestSubscription{}, domain.ErrInvalidDigestFrequency
+	}
+	authorIDs, err := uniqueIDs(authorIDs)
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	tagIDs, err = uniqueIDs(tagIDs)
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+
+	now := uc.now()
+	sub, err := uc.digestRepo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrDigestSubscriptionNotFound) {
+		sub = domain.DigestSubscription{User_id: userID, Frequency: domain.DigestOff, Created_at: now}
+	} else if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+
+	if frequency != sub.Frequency && frequency != domain.DigestOff {
+		sub.Last_sent_at = now
+		sub.Next_send_at = now.Add(frequency.Period())
+	}
+	sub.Frequency = frequency
+	sub.Author_ids = authorIDs
+	sub.Tag_ids = tagIDs
+	sub.Updated_at = now
+
+	if err := uc.digestRepo.Upsert(ctx, sub); err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	return sub, nil
+}
+
+// uniqueIDs drops blank and repeated IDs and caps how many can be followed.
+func uniqueIDs(ids []string) ([]string, error) {
+	seen := make(map[string]bool, len(ids))
+	unique := []string{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > digestMaxFollows {
+		return nil, fmt.Errorf("%w: at most %d authors and %d tags", domain.ErrInvalidDigestPreferences, digestMaxFollows, digestMaxFollows)
+	}
+	return unique, nil
+}
+
+func (uc *DigestUseCase) SendDue(ctx context.Context) {
+	subs, err := uc.digestRepo.FindDue(ctx, uc.now(), digestSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "digest: finding due digests failed", "error", err)
+		return
+	}
+	for _, sub := range subs {
+		if err := uc.send(ctx, sub); err != nil {
+			slog.ErrorContext(ctx, "digest: sending a digest failed", "user_id", sub.User_id, "error", err)
+		}
+	}
+}
+
+// send moves the watermark and queues the digest in one transaction, so a
+// digest is queued exactly when its posts leave the next one.
+func (uc *DigestUseCase) send(ctx context.Context, due domain.DigestSubscription) error {
+	var emailID string
+	err := uc.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		emailID = ""
+		now := uc.now()
+		sub, err := uc.digestRepo.Advance(txCtx, due.User_id, now, now, now.Add(due.Frequency.Period()))
+		if err != nil {
+			return err
+		}
+
+		user, err := uc.userRepo.FindByID(txCtx, sub.User_id)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return uc.digestRepo.SetFrequency(txCtx, sub.User_id, domain.DigestOff)
+		}
+		if err != nil {
+			return err
+		}
+		if !user.IsVerified {
+			return nil
+		}
+
+		blogs, err := uc.blogRepo.FindPublished(txCtx, sub.Last_sent_at, now, sub.Author_ids, sub.Tag_ids, digestMaxPosts)
+		if err != nil {
+			return err
+		}
+		posts := make([]map[string]interface{}, 0, len(blogs))
+		for _, blog := range blogs {
+			if blog.User_id == sub.User_id {
+				continue
+			}
+			posts = append(posts, map[string]interface{}{
+				"Title":   blog.Title,
+				"Summary": blogExcerpt(blog, digestSummaryRune),
+				"Link":    fmt.Sprintf("%s/blogs/%s", uc.baseURL, blog.Blog_id),
+			})
+		}
+		if len(posts) == 0 {
+			return nil
+		}
+
+		token, err := uc.jwtService.GenerateScopedToken(sub.User_id, DigestUnsubscribePurpose, digestUnsubscribeTTL)
+		if err != nil {
+			return err
+		}
+		email, err := uc.renderer.Render(txCtx, domain.EmailTemplateDigest, user.Language, map[string]interface{}{
+			"Name":            recipientName(user),
+			"Link":            uc.baseURL + "/blogs",
+			"UnsubscribeLink": fmt.Sprintf("%s/digests/unsubscribe?token=%s", uc.baseURL, url.QueryEscape(token)),
+			"Frequency":       string(sub.Frequency),
+			"Posts":           posts,
+		})
+		if err != nil {
+			return err
+		}
+		emailID, err = uc.emailOutbox.Queue(txCtx, domain.EmailMessage{
+			To:      user.Email,
+			Subject: email.Subject,
+			HTML:    email.HTML,
+			Text:    email.Text,
+		}, domain.EmailTemplateDigest)
+		return err
+	})
+	if errors.Is(err, domain.ErrDigestNotDue) {
+		// sent by another instance, or the user changed their preferences
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	uc.emailOutbox.Dispatch(emailID)
+	return nil
+}
+
+// blogExcerpt is the AI summary of the blog, or the start of its content,
+// cut to maxRunes.
+func blogExcerpt(blog domain.Blog, maxRunes int) string {
+	summary := blog.Summary
+	if summary == "" {
+		summary = blog.Content
+	}
+	summary = strings.Join(strings.Fields(summary), " ")
+	if runes := []rune(summary); len(runes) > maxRunes {
+		summary = strings.TrimSpace(string(runes[:maxRunes])) + "…"
+	}
+	return summary
+}
+
+func (uc *DigestUseCase) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := uc.jwtService.ValidateScopedToken(token, DigestUnsubscribePurpose)
+	if err != nil {
+		return domain.ErrInvalidUnsubscribeToken
+	}
+	err = uc.digestRepo.SetFrequency(ctx, userID, domain.DigestOff)
+	if errors.Is(err, domain.ErrDigestSubscriptionNotFound) {
+		return nil
+	}
+	return err
+}
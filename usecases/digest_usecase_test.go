@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDigestRepo keeps subscriptions in memory, by user ID.
+type memoryDigestRepo struct {
+	domain.IDigestSubscriptionRepository
+	subs map[string]domain.DigestSubscription
+}
+
+func (r *memoryDigestRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.DigestSubscription, error) {
+	var due []domain.DigestSubscription
+	for _, sub := range r.subs {
+		if sub.Frequency != domain.DigestOff && !sub.Next_send_at.After(now) {
+			due = append(due, sub)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryDigestRepo) Advance(ctx context.Context, userID string, now, sentAt, nextSendAt time.Time) (domain.DigestSubscription, error) {
+	sub, ok := r.subs[userID]
+	if !ok || sub.Frequency == domain.DigestOff || sub.Next_send_at.After(now) {
+		return domain.DigestSubscription{}, domain.ErrDigestNotDue
+	}
+	advanced := sub
+	advanced.Last_sent_at, advanced.Next_send_at = sentAt, nextSendAt
+	r.subs[userID] = advanced
+	return sub, nil
+}
+
+func (r *memoryDigestRepo) SetFrequency(ctx context.Context, userID string, frequency domain.DigestFrequency) error {
+	sub, ok := r.subs[userID]
+	if !ok {
+		return domain.ErrDigestSubscriptionNotFound
+	}
+	sub.Frequency = frequency
+	r.subs[userID] = sub
+	return nil
+}
+
+// rollbackTx undoes the subscription changes of a failed transaction.
+type rollbackTx struct{ repo *memoryDigestRepo }
+
+func (tx rollbackTx) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	saved := make(map[string]domain.DigestSubscription, len(tx.repo.subs))
+	for userID, sub := range tx.repo.subs {
+		saved[userID] = sub
+	}
+	if err := fn(ctx); err != nil {
+		tx.repo.subs = saved
+		return err
+	}
+	return nil
+}
+
+// publishedBlogs filters blogs the way FindPublished does.
+type publishedBlogs struct {
+	domain.IBlogRepository
+	blogs []domain.Blog
+}
+
+func (r *publishedBlogs) FindPublished(ctx context.Context, since, until time.Time, authorIDs, tagIDs []string, limit int) ([]domain.Blog, error) {
+	var found []domain.Blog
+	for _, blog := range r.blogs {
+		if !blog.Created_at.After(since) || blog.Created_at.After(until) {
+			continue
+		}
+		if containsAny(authorIDs, blog.User_id) || containsAny(tagIDs, blog.Tag_ids...) {
+			found = append(found, blog)
+		}
+	}
+	return found, nil
+}
+
+func containsAny(ids []string, values ...string) bool {
+	for _, id := range ids {
+		for _, value := range values {
+			if id == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// digestRenderer records the data of every rendered digest.
+type digestRenderer struct {
+	fakeEmailRenderer
+	rendered []map[string]interface{}
+}
+
+func (r *digestRenderer) Render(ctx context.Context, name, locale string, data map[string]interface{}) (domain.RenderedEmail, error) {
+	r.rendered = append(r.rendered, data)
+	return r.fakeEmailRenderer.Render(ctx, name, locale, data)
+}
+
+type digestTest struct {
+	uc       *DigestUseCase
+	subs     *memoryDigestRepo
+	blogs    *publishedBlogs
+	users    *fakeUserRepo
+	renderer *digestRenderer
+	outbox   *fakeOutbox
+	now      time.Time
+}
+
+// newDigestTest has a verified reader whose daily digest is due, following
+// author-1 and the tag "go".
+func newDigestTest() *digestTest {
+	tt := &digestTest{
+		now:      time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		blogs:    &publishedBlogs{},
+		renderer: &digestRenderer{},
+		outbox:   &fakeOutbox{},
+		users: &fakeUserRepo{users: map[string]*domain.User{
+			"reader": {UserID: "reader", Email: "reader@example.com", IsVerified: true},
+		}},
+	}
+	tt.subs = &memoryDigestRepo{subs: map[string]domain.DigestSubscription{
+		"reader": {
+			User_id:      "reader",
+			Frequency:    domain.DigestDaily,
+			Author_ids:   []string{"author-1"},
+			Tag_ids:      []string{"go"},
+			Last_sent_at: tt.now.Add(-24 * time.Hour),
+			Next_send_at: tt.now,
+		},
+	}}
+	tt.uc = NewDigestUseCase(tt.subs, tt.blogs, tt.users, tt.renderer, tt.outbox, fakeTokens{}, rollbackTx{tt.subs}, "https://inkforge.example").(*DigestUseCase)
+	tt.uc.now = func() time.Time { return tt.now }
+	return tt
+}
+
+func (tt *digestTest) publish(blogID, authorID string, ago time.Duration, tagIDs ...string) {
+	tt.blogs.blogs = append(tt.blogs.blogs, domain.Blog{Blog_id: blogID, User_id: authorID, Title: blogID, Tag_ids: tagIDs, Created_at: tt.now.Add(-ago)})
+}
+
+// postTitles returns the titles of the posts in each rendered digest.
+func (tt *digestTest) postTitles() [][]string {
+	var titles [][]string
+	for _, data := range tt.renderer.rendered {
+		var digest []string
+		for _, post := range data["Posts"].([]map[string]interface{}) {
+			digest = append(digest, post["Title"].(string))
+		}
+		titles = append(titles, digest)
+	}
+	return titles
+}
+
+func TestSendDue_QueuesFollowedPostsAndAdvancesWatermark(t *testing.T) {
+	tt := newDigestTest()
+	tt.publish("by-author", "author-1", time.Hour)
+	tt.publish("with-tag", "author-2", 2*time.Hour, "go")
+	tt.publish("unfollowed", "author-2", time.Hour, "rust")
+	tt.publish("before-watermark", "author-1", 25*time.Hour)
+	tt.publish("own-post", "reader", time.Hour, "go")
+
+	tt.uc.SendDue(context.Background())
+
+	assert.Equal(t, [][]string{{"by-author", "with-tag"}}, tt.postTitles())
+	assert.Equal(t, []string{"reader@example.com"}, tt.outbox.recipients())
+	assert.Equal(t, []string{"email-1"}, tt.outbox.dispatched)
+	assert.Equal(t, "https://inkforge.example/digests/unsubscribe?token=digest_unsubscribe%7Creader", tt.renderer.rendered[0]["UnsubscribeLink"])
+
+	sub := tt.subs.subs["reader"]
+	assert.Equal(t, tt.now, sub.Last_sent_at)
+	assert.Equal(t, tt.now.Add(24*time.Hour), sub.Next_send_at)
+
+	tt.uc.SendDue(context.Background())
+	assert.Len(t, tt.outbox.queued, 1, "not due again until tomorrow")
+}
+
+func TestSendDue_KeepsWatermarkWhenQueueingFails(t *testing.T) {
+	tt := newDigestTest()
+	tt.publish("by-author", "author-1", time.Hour)
+	before := tt.subs.subs["reader"]
+
+	tt.outbox.failQueue = true
+	tt.uc.SendDue(context.Background())
+	assert.Empty(t, tt.outbox.queued)
+	assert.Empty(t, tt.outbox.dispatched)
+	assert.Equal(t, before, tt.subs.subs["reader"], "the posts stay in the next digest")
+
+	tt.outbox.failQueue = false
+	tt.now = tt.now.Add(time.Minute)
+	tt.uc.SendDue(context.Background())
+	assert.Equal(t, []string{"reader@example.com"}, tt.outbox.recipients())
+	assert.Equal(t, []string{"by-author"}, tt.postTitles()[1])
+	assert.Equal(t, tt.now, tt.subs.subs["reader"].Last_sent_at)
+}
+
+func TestSendDue_SkipsUnverifiedUsers(t *testing.T) {
+	tt := newDigestTest()
+	tt.users.users["reader"].IsVerified = false
+	tt.publish("by-author", "author-1", time.Hour)
+
+	tt.uc.SendDue(context.Background())
+
+	assert.Empty(t, tt.outbox.queued)
+	assert.Equal(t, tt.now.Add(24*time.Hour), tt.subs.subs["reader"].Next_send_at, "checked again next period")
+}
+
+func TestSendDue_DoesNotSendEmptyDigest(t *testing.T) {
+	tt := newDigestTest()
+	tt.publish("unfollowed", "author-2", time.Hour, "rust")
+
+	tt.uc.SendDue(context.Background())
+
+	assert.Empty(t, tt.renderer.rendered)
+	assert.Empty(t, tt.outbox.queued)
+	assert.Equal(t, tt.now, tt.subs.subs["reader"].Last_sent_at)
+}
+
+func TestSendDue_TurnsOffDigestOfDeletedUser(t *testing.T) {
+	tt := newDigestTest()
+	delete(tt.users.users, "reader")
+	tt.publish("by-author", "author-1", time.Hour)
+
+	tt.uc.SendDue(context.Background())
+
+	assert.Empty(t, tt.outbox.queued)
+	assert.Equal(t, domain.DigestOff, tt.subs.subs["reader"].Frequency)
+}
+
+func TestDigestUnsubscribe(t *testing.T) {
+	tt := newDigestTest()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, tt.uc.Unsubscribe(ctx, "newsletter_unsubscribe|reader"), domain.ErrInvalidUnsubscribeToken)
+	assert.ErrorIs(t, tt.uc.Unsubscribe(ctx, "garbage"), domain.ErrInvalidUnsubscribeToken)
+	assert.Equal(t, domain.DigestDaily, tt.subs.subs["reader"].Frequency)
+
+	require.NoError(t, tt.uc.Unsubscribe(ctx, DigestUnsubscribePurpose+"|reader"))
+	assert.Equal(t, domain.DigestOff, tt.subs.subs["reader"].Frequency)
+
+	require.NoError(t, tt.uc.Unsubscribe(ctx, DigestUnsubscribePurpose+"|never-subscribed"))
+}
@@ -0,0 +1,209 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	// a worker holds a claimed email this long; a send still running after
+	// that may be repeated by another worker
+	outboxClaimLease   = 2 * time.Minute
+	outboxSweepBatch   = 100
+	outboxMaxBackoff   = 6 * time.Hour
+	outboxErrorMaxSize = 500
+)
+
+// EmailOutboxOptions configures retries of failed deliveries.
+type EmailOutboxOptions struct {
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles on every
+	// further attempt up to six hours.
+	BaseBackoff time.Duration
+}
+
+// EmailOutboxUseCase implements domain.IEmailOutboxUseCase
+type EmailOutboxUseCase struct {
+	outboxRepo domain.IEmailOutboxRepository
+	notifier   domain.INotifier
+	queue      domain.IJobQueue
+	opts       EmailOutboxOptions
+	now        func() time.Time
+}
+
+// NewEmailOutboxUseCase returns an outbox that queues email IDs on queue.
+// The queue's handler is expected to call Deliver.
+func NewEmailOutboxUseCase(
+	outboxRepo domain.IEmailOutboxRepository,
+	notifier domain.INotifier,
+	queue domain.IJobQueue,
+	opts EmailOutboxOptions,
+) domain.IEmailOutboxUseCase {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 30 * time.Second
+	}
+	return &EmailOutboxUseCase{
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+		queue:      queue,
+		opts:       opts,
+		now:        time.Now,
+	}
+}
+
+func (uc *EmailOutboxUseCase) Queue(ctx context.Context, msg domain.EmailMessage, template string) (string, error) {
+	now := uc.now()
+	email, err := uc.outboxRepo.Insert(ctx, domain.OutboxEmail{
+		To:              msg.To,
+		Subject:         msg.Subject,
+		Html:            msg.HTML,
+		Text:            msg.Text,
+		Template:        template,
+		Status:          domain.OutboxPending,
+		Next_attempt_at: now,
+		Created_at:      now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return email.Email_id, nil
+}
+
+func (uc *EmailOutboxUseCase) QueueBatch(ctx context.Context, batchID string, msgs []domain.EmailMessage, template string) ([]string, error) {
+	now := uc.now()
+	emails := make([]domain.OutboxEmail, len(msgs))
+	for i, msg := range msgs {
+		emails[i] = domain.OutboxEmail{
+			To:              msg.To,
+			Subject:         msg.Subject,
+			Html:            msg.HTML,
+			Text:            msg.Text,
+			Template:        template,
+			Batch_id:        batchID,
+			Status:          domain.OutboxPending,
+			Next_attempt_at: now,
+			Created_at:      now,
+		}
+	}
+	return uc.outboxRepo.InsertMany(ctx, emails)
+}
+
+// Dispatch does not fail: an email that cannot be queued now is found by the
+// sweep. It stops at the first email the queue refuses, since a full queue
+// refuses the rest too.
+func (uc *EmailOutboxUseCase) Dispatch(emailIDs ...string) {
+	for i, emailID := range emailIDs {
+		if emailID == "" {
+			continue
+		}
+		if !uc.queue.Enqueue(emailID) {
+			slog.Warn("email outbox: could not queue emails, leaving them to the sweep", "count", len(emailIDs)-i)
+			return
+		}
+	}
+}
+
+func (uc *EmailOutboxUseCase) Deliver(ctx context.Context, emailID string) error {
+	now := uc.now()
+	email, err := uc.outboxRepo.Claim(ctx, emailID, now, now.Add(outboxClaimLease))
+	if errors.Is(err, domain.ErrOutboxEmailNotDue) || errors.Is(err, domain.ErrOutboxEmailNotFound) {
+		// sent, dead, waiting for a retry or taken by another worker
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the template name is the event the notification channels are routed by;
+	// channels that took the email on an earlier attempt do not get it twice
+	delivered, sendErr := uc.notifier.Notify(ctx, domain.Notification{
+		Event:   email.Template,
+		To:      email.To,
+		Subject: email.Subject,
+		HTML:    email.Html,
+		Text:    email.Text,
+	}, email.Delivered_channels)
+	if sendErr == nil {
+		return uc.outboxRepo.MarkSent(ctx, emailID, uc.now())
+	}
+
+	attempts := email.Attempts + 1
+	status := domain.OutboxPending
+	if attempts >= uc.opts.MaxAttempts {
+		status = domain.OutboxDead
+		slog.ErrorContext(ctx, "email outbox: giving up on email", "email_id", emailID, "template", email.Template, "attempts", attempts, "error", sendErr)
+	}
+	lastError := sendErr.Error()
+	if len(lastError) > outboxErrorMaxSize {
+		lastError = lastError[:outboxErrorMaxSize]
+	}
+	return uc.outboxRepo.MarkFailed(ctx, emailID, status, attempts, lastError, delivered, uc.now().Add(uc.backoff(attempts)))
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (uc *EmailOutboxUseCase) backoff(attempts int) time.Duration {
+	delay := uc.opts.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
+
+func (uc *EmailOutboxUseCase) RequeueDue(ctx context.Context) {
+	emailIDs, err := uc.outboxRepo.FindDue(ctx, uc.now(), outboxSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "email outbox: sweep failed", "error", err)
+		return
+	}
+	for _, emailID := range emailIDs {
+		if !uc.queue.Enqueue(emailID) {
+			return
+		}
+	}
+}
+
+func (uc *EmailOutboxUseCase) ListEmails(ctx context.Context, status domain.OutboxStatus, page, limit int) ([]domain.OutboxEmail, domain.Pagination, error) {
+	switch status {
+	case "", domain.OutboxPending, domain.OutboxSending, domain.OutboxSent, domain.OutboxDead:
+	default:
+		return nil, domain.Pagination{}, domain.ErrInvalidOutboxStatus
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	emails, total, err := uc.outboxRepo.List(ctx, status, page, limit)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+	return emails, domain.Pagination{Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *EmailOutboxUseCase) CountByStatus(ctx context.Context) (map[domain.OutboxStatus]int, error) {
+	return uc.outboxRepo.CountByStatus(ctx)
+}
+
+func (uc *EmailOutboxUseCase) BatchStatus(ctx context.Context, batchID string) (map[domain.OutboxStatus]int, error) {
+	return uc.outboxRepo.CountByBatch(ctx, batchID)
+}
+
+func (uc *EmailOutboxUseCase) Retry(ctx context.Context, emailID string) error {
+	if err := uc.outboxRepo.Requeue(ctx, emailID, uc.now()); err != nil {
+		return err
+	}
+	uc.Dispatch(emailID)
+	return nil
+}
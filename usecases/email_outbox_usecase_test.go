@@ -0,0 +1,255 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutboxRepo keeps emails in memory and claims them like the Mongo
+// repository does.
+type memoryOutboxRepo struct {
+	domain.IEmailOutboxRepository
+	emails map[string]*domain.OutboxEmail
+}
+
+func (r *memoryOutboxRepo) Insert(ctx context.Context, email domain.OutboxEmail) (domain.OutboxEmail, error) {
+	if r.emails == nil {
+		r.emails = make(map[string]*domain.OutboxEmail)
+	}
+	email.Email_id = fmt.Sprintf("email-%d", len(r.emails)+1)
+	r.emails[email.Email_id] = &email
+	return email, nil
+}
+
+func (r *memoryOutboxRepo) Claim(ctx context.Context, emailID string, now, leaseUntil time.Time) (domain.OutboxEmail, error) {
+	email, ok := r.emails[emailID]
+	if !ok {
+		return domain.OutboxEmail{}, domain.ErrOutboxEmailNotFound
+	}
+	if !r.due(email, now) {
+		return domain.OutboxEmail{}, domain.ErrOutboxEmailNotDue
+	}
+	email.Status = domain.OutboxSending
+	email.Next_attempt_at = leaseUntil
+	return *email, nil
+}
+
+func (r *memoryOutboxRepo) due(email *domain.OutboxEmail, now time.Time) bool {
+	return (email.Status == domain.OutboxPending || email.Status == domain.OutboxSending) && !email.Next_attempt_at.After(now)
+}
+
+func (r *memoryOutboxRepo) MarkSent(ctx context.Context, emailID string, sentAt time.Time) error {
+	email := r.emails[emailID]
+	email.Status = domain.OutboxSent
+	email.Sent_at = sentAt
+	email.Attempts++
+	email.Last_error = ""
+	return nil
+}
+
+func (r *memoryOutboxRepo) MarkFailed(ctx context.Context, emailID string, status domain.OutboxStatus, attempts int, lastError string, delivered []string, nextAttemptAt time.Time) error {
+	email := r.emails[emailID]
+	email.Status = status
+	email.Attempts = attempts
+	email.Last_error = lastError
+	email.Delivered_channels = delivered
+	email.Next_attempt_at = nextAttemptAt
+	return nil
+}
+
+func (r *memoryOutboxRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	for id, email := range r.emails {
+		if r.due(email, now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *memoryOutboxRepo) Requeue(ctx context.Context, emailID string, now time.Time) error {
+	email, ok := r.emails[emailID]
+	if !ok {
+		return domain.ErrOutboxEmailNotFound
+	}
+	if email.Status != domain.OutboxDead {
+		return domain.ErrOutboxEmailNotDead
+	}
+	email.Status = domain.OutboxPending
+	email.Attempts = 0
+	email.Next_attempt_at = now
+	return nil
+}
+
+// fakeNotifier accepts every notification on the "email" channel, unless err
+// is set; the "slack" channel always accepts.
+type fakeNotifier struct {
+	err  error
+	sent []domain.Notification
+	// skipped are the delivered channels passed in on each call
+	skipped [][]string
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification domain.Notification, delivered []string) ([]string, error) {
+	n.skipped = append(n.skipped, delivered)
+	if len(delivered) == 0 {
+		delivered = append(delivered, "slack")
+	}
+	if n.err != nil {
+		return delivered, n.err
+	}
+	n.sent = append(n.sent, notification)
+	return append(delivered, "email"), nil
+}
+
+type outboxTest struct {
+	uc       *EmailOutboxUseCase
+	repo     *memoryOutboxRepo
+	notifier *fakeNotifier
+	queue    *fakeQueue
+	now      time.Time
+}
+
+func newOutboxTest(opts EmailOutboxOptions) *outboxTest {
+	tt := &outboxTest{
+		repo:     &memoryOutboxRepo{},
+		notifier: &fakeNotifier{},
+		queue:    &fakeQueue{},
+		now:      time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	tt.uc = NewEmailOutboxUseCase(tt.repo, tt.notifier, tt.queue, opts).(*EmailOutboxUseCase)
+	tt.uc.now = func() time.Time { return tt.now }
+	return tt
+}
+
+func (tt *outboxTest) queueEmail(t *testing.T) string {
+	emailID, err := tt.uc.Queue(context.Background(), domain.EmailMessage{To: "reader@example.com", Subject: "Hello"}, "welcome")
+	require.NoError(t, err)
+	return emailID
+}
+
+func TestDeliver_MarksEmailSent(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{})
+	emailID := tt.queueEmail(t)
+
+	require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+
+	email := tt.repo.emails[emailID]
+	assert.Equal(t, domain.OutboxSent, email.Status)
+	assert.Equal(t, 1, email.Attempts)
+	assert.Equal(t, tt.now, email.Sent_at)
+	require.Len(t, tt.notifier.sent, 1)
+	assert.Equal(t, "welcome", tt.notifier.sent[0].Event)
+	assert.Equal(t, "reader@example.com", tt.notifier.sent[0].To)
+
+	require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+	assert.Len(t, tt.notifier.sent, 1, "a sent email is not delivered again")
+}
+
+func TestDeliver_BacksOffAfterFailure(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{MaxAttempts: 5, BaseBackoff: time.Minute})
+	tt.notifier.err = errors.New("smtp unavailable")
+	emailID := tt.queueEmail(t)
+
+	for attempt, delay := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+		email := tt.repo.emails[emailID]
+		assert.Equal(t, domain.OutboxPending, email.Status)
+		assert.Equal(t, attempt+1, email.Attempts)
+		assert.Equal(t, "smtp unavailable", email.Last_error)
+		assert.Equal(t, tt.now.Add(delay), email.Next_attempt_at)
+
+		// not due until the backoff passed
+		require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+		assert.Equal(t, attempt+1, tt.repo.emails[emailID].Attempts)
+		tt.now = email.Next_attempt_at
+	}
+
+	// channels that took the email are skipped on the retries
+	assert.Equal(t, []string{"slack"}, tt.notifier.skipped[len(tt.notifier.skipped)-1])
+}
+
+func TestDeliver_CapsBackoff(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{BaseBackoff: time.Hour})
+	assert.Equal(t, time.Hour, tt.uc.backoff(1))
+	assert.Equal(t, 4*time.Hour, tt.uc.backoff(3))
+	assert.Equal(t, outboxMaxBackoff, tt.uc.backoff(4))
+	assert.Equal(t, outboxMaxBackoff, tt.uc.backoff(20))
+}
+
+func TestDeliver_GivesUpAfterMaxAttempts(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{MaxAttempts: 3, BaseBackoff: time.Minute})
+	tt.notifier.err = errors.New("mailbox full")
+	emailID := tt.queueEmail(t)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+		tt.now = tt.repo.emails[emailID].Next_attempt_at
+	}
+
+	email := tt.repo.emails[emailID]
+	assert.Equal(t, domain.OutboxDead, email.Status)
+	assert.Equal(t, 3, email.Attempts)
+
+	tt.now = tt.now.Add(24 * time.Hour)
+	require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+	assert.Len(t, tt.notifier.skipped, 3, "a dead email is not attempted again")
+	ids, _ := tt.repo.FindDue(context.Background(), tt.now, 10)
+	assert.Empty(t, ids)
+}
+
+func TestRetry_RequeuesDeadEmail(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{MaxAttempts: 1})
+	tt.notifier.err = errors.New("mailbox full")
+	emailID := tt.queueEmail(t)
+	require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+	require.Equal(t, domain.OutboxDead, tt.repo.emails[emailID].Status)
+
+	require.NoError(t, tt.uc.Retry(context.Background(), emailID))
+	email := tt.repo.emails[emailID]
+	assert.Equal(t, domain.OutboxPending, email.Status)
+	assert.Zero(t, email.Attempts)
+	assert.Equal(t, []string{emailID}, tt.queue.keys)
+
+	tt.notifier.err = nil
+	require.NoError(t, tt.uc.Deliver(context.Background(), emailID))
+	assert.Equal(t, domain.OutboxSent, tt.repo.emails[emailID].Status)
+
+	assert.ErrorIs(t, tt.uc.Retry(context.Background(), emailID), domain.ErrOutboxEmailNotDead)
+	assert.ErrorIs(t, tt.uc.Retry(context.Background(), "missing"), domain.ErrOutboxEmailNotFound)
+}
+
+func TestRequeueDue_PicksUpExpiredLeases(t *testing.T) {
+	tt := newOutboxTest(EmailOutboxOptions{})
+	pending := tt.queueEmail(t)
+	claimed := tt.queueEmail(t)
+	sent := tt.queueEmail(t)
+	require.NoError(t, tt.uc.Deliver(context.Background(), sent))
+
+	// a worker claimed an email and died before marking it
+	_, err := tt.repo.Claim(context.Background(), claimed, tt.now, tt.now.Add(outboxClaimLease))
+	require.NoError(t, err)
+
+	tt.uc.RequeueDue(context.Background())
+	assert.Equal(t, []string{pending}, tt.queue.keys, "a held lease is left alone")
+
+	tt.queue.keys = nil
+	tt.now = tt.now.Add(outboxClaimLease)
+	tt.uc.RequeueDue(context.Background())
+	assert.Equal(t, []string{pending, claimed}, tt.queue.keys)
+
+	require.NoError(t, tt.uc.Deliver(context.Background(), claimed))
+	assert.Equal(t, domain.OutboxSent, tt.repo.emails[claimed].Status)
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// EmailTemplateUseCase implements domain.IEmailTemplateUseCase
+type EmailTemplateUseCase struct {
+	templateRepo domain.IEmailTemplateRepository
+	renderer     domain.IEmailRenderer
+}
+
+func NewEmailTemplateUseCase(templateRepo domain.IEmailTemplateRepository, renderer domain.IEmailRenderer) domain.IEmailTemplateUseCase {
+	return &EmailTemplateUseCase{
+		templateRepo: templateRepo,
+		renderer:     renderer,
+	}
+}
+
+// ListTemplates returns every email in every locale and whether it is overridden.
+func (uc *EmailTemplateUseCase) ListTemplates(ctx context.Context) ([]domain.EmailTemplateInfo, error) {
+	overrides, err := uc.templateRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overridden := make(map[[2]string]domain.EmailTemplate, len(overrides))
+	for _, o := range overrides {
+		overridden[[2]string{o.Name, o.Locale}] = o
+	}
+
+	var infos []domain.EmailTemplateInfo
+	for _, name := range uc.renderer.Names() {
+		for _, locale := range uc.renderer.Locales() {
+			if _, ok := uc.renderer.Default(name, locale); !ok {
+				continue
+			}
+			info := domain.EmailTemplateInfo{Name: name, Locale: locale}
+			if o, ok := overridden[[2]string{name, locale}]; ok {
+				info.Overridden = true
+				info.Updated_by = o.Updated_by
+				info.Updated_at = o.Updated_at
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (uc *EmailTemplateUseCase) GetTemplate(ctx context.Context, name, locale string) (domain.EmailTemplate, bool, error) {
+	builtIn, err := uc.builtIn(name, locale)
+	if err != nil {
+		return domain.EmailTemplate{}, false, err
+	}
+	override, err := uc.templateRepo.Get(ctx, name, locale)
+	if err == domain.ErrEmailTemplateNotFound {
+		return builtIn, false, nil
+	}
+	if err != nil {
+		return domain.EmailTemplate{}, false, err
+	}
+	return override, true, nil
+}
+
+// OverrideTemplate replaces the built-in template of the name and locale.
+// It returns domain.ErrInvalidEmailTemplate if the template does not parse
+// or does not render with the data the sender provides.
+func (uc *EmailTemplateUseCase) OverrideTemplate(ctx context.Context, template domain.EmailTemplate) (domain.EmailTemplate, error) {
+	if err := uc.renderer.Validate(template); err != nil {
+		return domain.EmailTemplate{}, err
+	}
+	template.Updated_at = time.Now()
+	if err := uc.templateRepo.Upsert(ctx, template); err != nil {
+		return domain.EmailTemplate{}, err
+	}
+	uc.renderer.Invalidate(template.Name, template.Locale)
+	return template, nil
+}
+
+func (uc *EmailTemplateUseCase) ResetTemplate(ctx context.Context, name, locale string) error {
+	if _, err := uc.builtIn(name, locale); err != nil {
+		return err
+	}
+	if err := uc.templateRepo.Delete(ctx, name, locale); err != nil {
+		return err
+	}
+	uc.renderer.Invalidate(name, locale)
+	return nil
+}
+
+func (uc *EmailTemplateUseCase) Preview(ctx context.Context, name, locale string, draft *domain.EmailTemplate) (domain.RenderedEmail, error) {
+	if draft != nil {
+		draft.Name, draft.Locale = name, locale
+		if err := uc.renderer.Validate(*draft); err != nil {
+			return domain.RenderedEmail{}, err
+		}
+		return uc.renderer.Preview(*draft)
+	}
+
+	template, _, err := uc.GetTemplate(ctx, name, locale)
+	if err != nil {
+		return domain.RenderedEmail{}, err
+	}
+	return uc.renderer.Preview(template)
+}
+
+func (uc *EmailTemplateUseCase) builtIn(name, locale string) (domain.EmailTemplate, error) {
+	if template, ok := uc.renderer.Default(name, locale); ok {
+		return template, nil
+	}
+	for _, known := range uc.renderer.Names() {
+		if known == name {
+			return domain.EmailTemplate{}, domain.ErrUnsupportedLocale
+		}
+	}
+	return domain.EmailTemplate{}, domain.ErrUnknownEmailTemplate
+}
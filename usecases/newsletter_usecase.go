@@ -0,0 +1,515 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// Purposes of the tokens in newsletter links
+const (
+	NewsletterConfirmPurpose     = "newsletter_confirm"
+	NewsletterUnsubscribePurpose = "newsletter_unsubscribe"
+)
+
+const (
+	newsletterConfirmTTL     = 48 * time.Hour
+	newsletterUnsubscribeTTL = 365 * 24 * time.Hour
+	// a pending subscriber asking again within this gets no new confirmation,
+	// so the form cannot be used to flood an inbox
+	newsletterConfirmCooldown = 10 * time.Minute
+	newsletterBatchSize       = 200
+	// a worker holds an issue this long; the lease is renewed with every batch
+	newsletterIssueLease   = 2 * time.Minute
+	newsletterSweepBatch   = 20
+	newsletterExcerptRunes = 600
+)
+
+// NewsletterUseCase implements domain.INewsletterUseCase
+type NewsletterUseCase struct {
+	subscriberRepo domain.INewsletterSubscriberRepository
+	issueRepo      domain.INewsletterIssueRepository
+	blogRepo       domain.IBlogRepository
+	userRepo       domain.IUserRepository
+	renderer       domain.IEmailRenderer
+	emailOutbox    domain.IEmailOutboxUseCase
+	jwtService     domain.IJWTService
+	txManager      domain.ITransactionManager
+	queue          domain.IJobQueue
+	baseURL        string
+	now            func() time.Time
+}
+
+// NewNewsletterUseCase returns a newsletter that sends issues by queuing their
+// IDs on queue, whose handler is expected to call DeliverIssue.
+func NewNewsletterUseCase(
+	subscriberRepo domain.INewsletterSubscriberRepository,
+	issueRepo domain.INewsletterIssueRepository,
+	blogRepo domain.IBlogRepository,
+	userRepo domain.IUserRepository,
+	renderer domain.IEmailRenderer,
+	emailOutbox domain.IEmailOutboxUseCase,
+	jwtService domain.IJWTService,
+	txManager domain.ITransactionManager,
+	queue domain.IJobQueue,
+	baseURL string,
+) domain.INewsletterUseCase {
+	return &NewsletterUseCase{
+		subscriberRepo: subscriberRepo,
+		issueRepo:      issueRepo,
+		blogRepo:       blogRepo,
+		userRepo:       userRepo,
+		renderer:       renderer,
+		emailOutbox:    emailOutbox,
+		jwtService:     jwtService,
+		txManager:      txManager,
+		queue:          queue,
+		baseURL:        baseURL,
+		now:            time.Now,
+	}
+}
+
+func (uc *NewsletterUseCase) Subscribe(ctx context.Context, email, authorID, language string) error {
+	email, err := normalizeSubscriberEmail(email)
+	if err != nil {
+		return err
+	}
+	language, err = uc.subscriberLanguage(language)
+	if err != nil {
+		return err
+	}
+	authorName := ""
+	if authorID != "" {
+		author, err := uc.userRepo.FindByID(ctx, authorID)
+		if err != nil {
+			return err
+		}
+		authorName = recipientName(author)
+	}
+
+	now := uc.now()
+	var emailID string
+	err = uc.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		emailID = ""
+		sub, err := uc.subscriberRepo.FindByEmail(txCtx, email, authorID)
+		switch {
+		case errors.Is(err, domain.ErrSubscriberNotFound):
+			sub, err = uc.subscriberRepo.Create(txCtx, domain.NewsletterSubscriber{
+				Email:                email,
+				Author_id:            authorID,
+				Language:             language,
+				Status:               domain.SubscriberPending,
+				Confirmation_sent_at: now,
+				Created_at:           now,
+			})
+		case err != nil:
+			return err
+		case sub.Status == domain.SubscriberActive:
+			return nil
+		case sub.Status == domain.SubscriberPending && now.Sub(sub.Confirmation_sent_at) < newsletterConfirmCooldown:
+			return nil
+		default:
+			// pending for a while, or subscribing again after leaving
+			sub.Status = domain.SubscriberPending
+			sub.Confirmation_sent_at = now
+			if language != "" {
+				sub.Language = language
+			}
+			err = uc.subscriberRepo.Update(txCtx, sub)
+		}
+		if err != nil {
+			return err
+		}
+
+		token, err := uc.jwtService.GenerateScopedToken(sub.Subscriber_id, NewsletterConfirmPurpose, newsletterConfirmTTL)
+		if err != nil {
+			return err
+		}
+		rendered, err := uc.renderer.Render(txCtx, domain.EmailTemplateNewsletterConfirm, sub.Language, map[string]interface{}{
+			"Name":       "",
+			"Link":       fmt.Sprintf("%s/newsletter/confirm?token=%s", uc.baseURL, url.QueryEscape(token)),
+			"AuthorName": authorName,
+		})
+		if err != nil {
+			return err
+		}
+		emailID, err = uc.emailOutbox.Queue(txCtx, domain.EmailMessage{
+			To:      sub.Email,
+			Subject: rendered.Subject,
+			HTML:    rendered.HTML,
+			Text:    rendered.Text,
+		}, domain.EmailTemplateNewsletterConfirm)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	uc.emailOutbox.Dispatch(emailID)
+	return nil
+}
+
+func normalizeSubscriberEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", domain.ErrInvalidSubscriberEmail
+	}
+	return email, nil
+}
+
+// subscriberLanguage returns the email locale for a preference such as
+// "fr-CA", or ErrUnsupportedLocale if no email is written in its language.
+func (uc *NewsletterUseCase) subscriberLanguage(language string) (string, error) {
+	language = normalizeLanguage(language)
+	if language == "" {
+		return "", nil
+	}
+	base, _, _ := strings.Cut(language, "-")
+	for _, locale := range uc.renderer.Locales() {
+		if locale == language || locale == base {
+			return locale, nil
+		}
+	}
+	return "", domain.ErrUnsupportedLocale
+}
+
+// Confirm activates a pending subscription. Confirming twice is not an error,
+// but the link cannot bring back a subscriber who left since.
+func (uc *NewsletterUseCase) Confirm(ctx context.Context, token string) error {
+	subscriberID, err := uc.jwtService.ValidateScopedToken(token, NewsletterConfirmPurpose)
+	if err != nil {
+		return domain.ErrInvalidConfirmationToken
+	}
+	sub, err := uc.subscriberRepo.Get(ctx, subscriberID)
+	if errors.Is(err, domain.ErrSubscriberNotFound) {
+		return domain.ErrInvalidConfirmationToken
+	}
+	if err != nil {
+		return err
+	}
+
+	switch sub.Status {
+	case domain.SubscriberActive:
+		return nil
+	case domain.SubscriberPending:
+		sub.Status = domain.SubscriberActive
+		sub.Confirmed_at = uc.now()
+		sub.Left_at = time.Time{}
+		return uc.subscriberRepo.Update(ctx, sub)
+	default:
+		return domain.ErrInvalidConfirmationToken
+	}
+}
+
+// Unsubscribe takes the subscriber off the list the link's issue was sent
+// through, and counts the unsubscribe against that issue.
+func (uc *NewsletterUseCase) Unsubscribe(ctx context.Context, token string) error {
+	subject, err := uc.jwtService.ValidateScopedToken(token, NewsletterUnsubscribePurpose)
+	if err != nil {
+		return domain.ErrInvalidUnsubscribeToken
+	}
+	subscriberID, issueID, _ := strings.Cut(subject, ":")
+
+	sub, err := uc.subscriberRepo.Get(ctx, subscriberID)
+	if errors.Is(err, domain.ErrSubscriberNotFound) {
+		// removed by the author since
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sub.Status != domain.SubscriberActive && sub.Status != domain.SubscriberPending {
+		return nil
+	}
+
+	sub.Status = domain.SubscriberUnsubscribed
+	sub.Left_at = uc.now()
+	if err := uc.subscriberRepo.Update(ctx, sub); err != nil {
+		return err
+	}
+	uc.countAgainstIssue(ctx, issueID, 0, 1)
+	return nil
+}
+
+// HandleBounce stops mail to an email the provider reported as undeliverable
+// or as spam. Soft bounces are left to the outbox's retries.
+func (uc *NewsletterUseCase) HandleBounce(ctx context.Context, email string, kind domain.BounceKind, issueID string) error {
+	email, err := normalizeSubscriberEmail(email)
+	if err != nil {
+		return domain.ErrInvalidBounce
+	}
+
+	var status domain.SubscriberStatus
+	switch kind {
+	case domain.BounceSoft:
+		return nil
+	case domain.BounceHard:
+		status = domain.SubscriberBounced
+	case domain.BounceComplaint:
+		status = domain.SubscriberUnsubscribed
+	default:
+		return domain.ErrInvalidBounce
+	}
+
+	changed, err := uc.subscriberRepo.SetStatusByEmail(ctx, email, status, uc.now())
+	if err != nil {
+		return err
+	}
+	if changed > 0 {
+		if status == domain.SubscriberBounced {
+			uc.countAgainstIssue(ctx, issueID, 1, 0)
+		} else {
+			uc.countAgainstIssue(ctx, issueID, 0, 1)
+		}
+	}
+	return nil
+}
+
+// countAgainstIssue is best effort: the link or report may name an issue
+// that no longer exists.
+func (uc *NewsletterUseCase) countAgainstIssue(ctx context.Context, issueID string, bounced, unsubscribed int) {
+	if issueID == "" {
+		return
+	}
+	err := uc.issueRepo.Increment(ctx, issueID, bounced, unsubscribed)
+	if err != nil && !errors.Is(err, domain.ErrIssueNotFound) {
+		slog.ErrorContext(ctx, "newsletter: counting against issue failed", "issue_id", issueID, "error", err)
+	}
+}
+
+func (uc *NewsletterUseCase) ListSubscribers(ctx context.Context, authorID string, status domain.SubscriberStatus, page, limit int) ([]domain.NewsletterSubscriber, domain.Pagination, error) {
+	switch status {
+	case "", domain.SubscriberPending, domain.SubscriberActive, domain.SubscriberUnsubscribed, domain.SubscriberBounced:
+	default:
+		return nil, domain.Pagination{}, domain.ErrInvalidSubscriberStatus
+	}
+	page, limit = newsletterPage(page, limit)
+
+	subs, total, err := uc.subscriberRepo.List(ctx, authorID, status, page, limit)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+	return subs, domain.Pagination{Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *NewsletterUseCase) ExportSubscribers(ctx context.Context, authorID string, fn func(domain.NewsletterSubscriber) error) error {
+	return uc.subscriberRepo.Each(ctx, authorID, fn)
+}
+
+func (uc *NewsletterUseCase) RemoveSubscriber(ctx context.Context, authorID, subscriberID string) error {
+	return uc.subscriberRepo.Delete(ctx, subscriberID, authorID)
+}
+
+func (uc *NewsletterUseCase) SendIssue(ctx context.Context, authorID, blogID string) (domain.NewsletterIssue, error) {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if err != nil {
+		return domain.NewsletterIssue{}, err
+	}
+	if blog.User_id != authorID {
+		return domain.NewsletterIssue{}, domain.ErrForbidden
+	}
+	return uc.sendIssue(ctx, authorID, blog)
+}
+
+func (uc *NewsletterUseCase) SendSiteIssue(ctx context.Context, blogID string) (domain.NewsletterIssue, error) {
+	blog, err := uc.blogRepo.GetByID(ctx, blogID)
+	if err != nil {
+		return domain.NewsletterIssue{}, err
+	}
+	return uc.sendIssue(ctx, "", blog)
+}
+
+// sendIssue creates an issue of the blog for the list of authorID, the
+// site-wide list when empty, and queues it in the background.
+func (uc *NewsletterUseCase) sendIssue(ctx context.Context, authorID string, blog domain.Blog) (domain.NewsletterIssue, error) {
+	issue, err := uc.issueRepo.Create(ctx, domain.NewsletterIssue{
+		Author_id:  authorID,
+		Blog_id:    blog.Blog_id,
+		Title:      blog.Title,
+		Status:     domain.IssueSending,
+		Created_at: uc.now(),
+	})
+	if err != nil {
+		return domain.NewsletterIssue{}, err
+	}
+	if !uc.queue.Enqueue(issue.Issue_id) {
+		slog.WarnContext(ctx, "newsletter: could not queue issue, leaving it to the sweep", "issue_id", issue.Issue_id)
+	}
+	return issue, nil
+}
+
+// DeliverIssue queues the issue's emails in batches. Each batch and the
+// issue's cursor are saved in one transaction, so an interrupted send resumes
+// without mailing anyone twice.
+func (uc *NewsletterUseCase) DeliverIssue(ctx context.Context, issueID string) error {
+	now := uc.now()
+	issue, err := uc.issueRepo.Claim(ctx, issueID, now, now.Add(newsletterIssueLease))
+	if errors.Is(err, domain.ErrIssueNotClaimable) || errors.Is(err, domain.ErrIssueNotFound) {
+		// sent, or being sent by another worker
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	blog, err := uc.blogRepo.GetByID(ctx, issue.Blog_id)
+	if errors.Is(err, domain.ErrBlogNotFound) {
+		slog.WarnContext(ctx, "newsletter: blog of issue was deleted, stopping", "blog_id", issue.Blog_id, "issue_id", issueID)
+		return uc.issueRepo.MarkSent(ctx, issueID, uc.now())
+	}
+	if err != nil {
+		return err
+	}
+	authorName := ""
+	if author, err := uc.userRepo.FindByID(ctx, blog.User_id); err == nil {
+		authorName = recipientName(author)
+	}
+
+	cursor := issue.Cursor
+	for {
+		if err := ctx.Err(); err != nil {
+			// the sweep resumes the issue once the lease runs out
+			return err
+		}
+		subs, err := uc.subscriberRepo.Recipients(ctx, issue.Author_id, cursor, newsletterBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			return uc.issueRepo.MarkSent(ctx, issueID, uc.now())
+		}
+
+		msgs := make([]domain.EmailMessage, 0, len(subs))
+		for _, sub := range subs {
+			msg, err := uc.issueEmail(ctx, issue, blog, authorName, sub)
+			if err != nil {
+				return err
+			}
+			msgs = append(msgs, msg)
+		}
+
+		var emailIDs []string
+		err = uc.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+			var err error
+			emailIDs, err = uc.emailOutbox.QueueBatch(txCtx, issueID, msgs, domain.EmailTemplateNewsletterIssue)
+			if err != nil {
+				return err
+			}
+			return uc.issueRepo.Advance(txCtx, issueID, subs[len(subs)-1].Email, len(msgs), uc.now().Add(newsletterIssueLease))
+		})
+		if err != nil {
+			return err
+		}
+		uc.emailOutbox.Dispatch(emailIDs...)
+		cursor = subs[len(subs)-1].Email
+	}
+}
+
+func (uc *NewsletterUseCase) issueEmail(ctx context.Context, issue domain.NewsletterIssue, blog domain.Blog, authorName string, sub domain.NewsletterSubscriber) (domain.EmailMessage, error) {
+	token, err := uc.jwtService.GenerateScopedToken(sub.Subscriber_id+":"+issue.Issue_id, NewsletterUnsubscribePurpose, newsletterUnsubscribeTTL)
+	if err != nil {
+		return domain.EmailMessage{}, err
+	}
+	rendered, err := uc.renderer.Render(ctx, domain.EmailTemplateNewsletterIssue, sub.Language, map[string]interface{}{
+		"Name":            "",
+		"Link":            fmt.Sprintf("%s/blogs/%s", uc.baseURL, blog.Blog_id),
+		"Title":           blog.Title,
+		"Excerpt":         blogExcerpt(blog, newsletterExcerptRunes),
+		"AuthorName":      authorName,
+		"UnsubscribeLink": fmt.Sprintf("%s/newsletter/unsubscribe?token=%s", uc.baseURL, url.QueryEscape(token)),
+	})
+	if err != nil {
+		return domain.EmailMessage{}, err
+	}
+	return domain.EmailMessage{
+		To:      sub.Email,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	}, nil
+}
+
+func (uc *NewsletterUseCase) ResumeIssues(ctx context.Context) {
+	issueIDs, err := uc.issueRepo.FindUnsent(ctx, uc.now(), newsletterSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "newsletter: finding interrupted issues failed", "error", err)
+	}
+	for _, issueID := range issueIDs {
+		if !uc.queue.Enqueue(issueID) {
+			break
+		}
+	}
+
+	issueIDs, err = uc.issueRepo.FindUnfinalized(ctx, newsletterSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "newsletter: finding unfinalized issues failed", "error", err)
+		return
+	}
+	for _, issueID := range issueIDs {
+		counts, err := uc.emailOutbox.BatchStatus(ctx, issueID)
+		if err != nil {
+			slog.ErrorContext(ctx, "newsletter: counting the emails of issue failed", "issue_id", issueID, "error", err)
+			continue
+		}
+		if counts[domain.OutboxPending]+counts[domain.OutboxSending] > 0 {
+			continue
+		}
+		if err := uc.issueRepo.Finalize(ctx, issueID, counts[domain.OutboxSent], counts[domain.OutboxDead]); err != nil {
+			slog.ErrorContext(ctx, "newsletter: finalizing issue failed", "issue_id", issueID, "error", err)
+		}
+	}
+}
+
+func (uc *NewsletterUseCase) ListIssues(ctx context.Context, authorID string, page, limit int) ([]domain.NewsletterIssue, domain.Pagination, error) {
+	page, limit = newsletterPage(page, limit)
+	issues, total, err := uc.issueRepo.List(ctx, authorID, page, limit)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+	return issues, domain.Pagination{Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *NewsletterUseCase) IssueStats(ctx context.Context, authorID, issueID string) (domain.NewsletterIssue, domain.NewsletterIssueStats, error) {
+	issue, err := uc.issueRepo.Get(ctx, issueID)
+	if err != nil {
+		return domain.NewsletterIssue{}, domain.NewsletterIssueStats{}, err
+	}
+	if issue.Author_id != authorID {
+		return domain.NewsletterIssue{}, domain.NewsletterIssueStats{}, domain.ErrIssueNotFound
+	}
+
+	stats := domain.NewsletterIssueStats{
+		Recipients:   issue.Recipients,
+		Delivered:    issue.Delivered,
+		Failed:       issue.Failed,
+		Bounced:      issue.Bounced,
+		Unsubscribed: issue.Unsubscribed,
+	}
+	if !issue.Finalized {
+		counts, err := uc.emailOutbox.BatchStatus(ctx, issueID)
+		if err != nil {
+			return domain.NewsletterIssue{}, domain.NewsletterIssueStats{}, err
+		}
+		stats.Pending = counts[domain.OutboxPending] + counts[domain.OutboxSending]
+		stats.Delivered = counts[domain.OutboxSent]
+		stats.Failed = counts[domain.OutboxDead]
+	}
+	return issue, stats, nil
+}
+
+func newsletterPage(page, limit int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
@@ -0,0 +1,479 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriberRepo keeps subscribers in memory.
+type fakeSubscriberRepo struct {
+	domain.INewsletterSubscriberRepository
+	subs   map[string]domain.NewsletterSubscriber
+	nextID int
+}
+
+func newFakeSubscriberRepo() *fakeSubscriberRepo {
+	return &fakeSubscriberRepo{subs: make(map[string]domain.NewsletterSubscriber)}
+}
+
+func (r *fakeSubscriberRepo) Create(ctx context.Context, sub domain.NewsletterSubscriber) (domain.NewsletterSubscriber, error) {
+	r.nextID++
+	sub.Subscriber_id = fmt.Sprintf("sub-%d", r.nextID)
+	r.subs[sub.Subscriber_id] = sub
+	return sub, nil
+}
+
+func (r *fakeSubscriberRepo) Get(ctx context.Context, subscriberID string) (domain.NewsletterSubscriber, error) {
+	sub, ok := r.subs[subscriberID]
+	if !ok {
+		return domain.NewsletterSubscriber{}, domain.ErrSubscriberNotFound
+	}
+	return sub, nil
+}
+
+func (r *fakeSubscriberRepo) FindByEmail(ctx context.Context, email, authorID string) (domain.NewsletterSubscriber, error) {
+	for _, sub := range r.subs {
+		if sub.Email == email && sub.Author_id == authorID {
+			return sub, nil
+		}
+	}
+	return domain.NewsletterSubscriber{}, domain.ErrSubscriberNotFound
+}
+
+func (r *fakeSubscriberRepo) Update(ctx context.Context, sub domain.NewsletterSubscriber) error {
+	r.subs[sub.Subscriber_id] = sub
+	return nil
+}
+
+func (r *fakeSubscriberRepo) SetStatusByEmail(ctx context.Context, email string, status domain.SubscriberStatus, at time.Time) (int, error) {
+	changed := 0
+	for id, sub := range r.subs {
+		if sub.Email == email && (sub.Status == domain.SubscriberPending || sub.Status == domain.SubscriberActive) {
+			sub.Status, sub.Left_at = status, at
+			r.subs[id] = sub
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (r *fakeSubscriberRepo) Recipients(ctx context.Context, authorID, afterEmail string, limit int) ([]domain.NewsletterSubscriber, error) {
+	var subs []domain.NewsletterSubscriber
+	for _, sub := range r.subs {
+		if sub.Author_id == authorID && sub.Status == domain.SubscriberActive && sub.Email > afterEmail {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Email < subs[j].Email })
+	if len(subs) > limit {
+		subs = subs[:limit]
+	}
+	return subs, nil
+}
+
+// add stores an active subscriber of authorID.
+func (r *fakeSubscriberRepo) add(email, authorID string) domain.NewsletterSubscriber {
+	sub, _ := r.Create(context.Background(), domain.NewsletterSubscriber{Email: email, Author_id: authorID, Status: domain.SubscriberActive})
+	return sub
+}
+
+// fakeIssueRepo keeps issues in memory.
+type fakeIssueRepo struct {
+	domain.INewsletterIssueRepository
+	issues map[string]domain.NewsletterIssue
+	nextID int
+}
+
+func newFakeIssueRepo() *fakeIssueRepo {
+	return &fakeIssueRepo{issues: make(map[string]domain.NewsletterIssue)}
+}
+
+func (r *fakeIssueRepo) Create(ctx context.Context, issue domain.NewsletterIssue) (domain.NewsletterIssue, error) {
+	r.nextID++
+	issue.Issue_id = fmt.Sprintf("issue-%d", r.nextID)
+	r.issues[issue.Issue_id] = issue
+	return issue, nil
+}
+
+func (r *fakeIssueRepo) Get(ctx context.Context, issueID string) (domain.NewsletterIssue, error) {
+	issue, ok := r.issues[issueID]
+	if !ok {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotFound
+	}
+	return issue, nil
+}
+
+func (r *fakeIssueRepo) Claim(ctx context.Context, issueID string, now, leaseUntil time.Time) (domain.NewsletterIssue, error) {
+	issue, ok := r.issues[issueID]
+	if !ok {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotFound
+	}
+	if issue.Status != domain.IssueSending || issue.Lease_until.After(now) {
+		return domain.NewsletterIssue{}, domain.ErrIssueNotClaimable
+	}
+	issue.Lease_until = leaseUntil
+	r.issues[issueID] = issue
+	return issue, nil
+}
+
+func (r *fakeIssueRepo) Advance(ctx context.Context, issueID, cursor string, queued int, leaseUntil time.Time) error {
+	issue := r.issues[issueID]
+	issue.Cursor, issue.Lease_until = cursor, leaseUntil
+	issue.Recipients += queued
+	r.issues[issueID] = issue
+	return nil
+}
+
+func (r *fakeIssueRepo) MarkSent(ctx context.Context, issueID string, sentAt time.Time) error {
+	issue := r.issues[issueID]
+	issue.Status, issue.Sent_at = domain.IssueSent, sentAt
+	r.issues[issueID] = issue
+	return nil
+}
+
+func (r *fakeIssueRepo) Increment(ctx context.Context, issueID string, bounced, unsubscribed int) error {
+	issue, ok := r.issues[issueID]
+	if !ok {
+		return domain.ErrIssueNotFound
+	}
+	issue.Bounced += bounced
+	issue.Unsubscribed += unsubscribed
+	r.issues[issueID] = issue
+	return nil
+}
+
+// fakeOutbox records queued emails instead of storing them.
+type fakeOutbox struct {
+	domain.IEmailOutboxUseCase
+	queued     []domain.EmailMessage
+	dispatched []string
+	// failQueue makes Queue fail
+	failQueue bool
+	// failBatch makes that call of QueueBatch fail, counting from 1
+	failBatch int
+	batches   int
+	counts    map[domain.OutboxStatus]int
+}
+
+func (o *fakeOutbox) Queue(ctx context.Context, msg domain.EmailMessage, template string) (string, error) {
+	if o.failQueue {
+		return "", errors.New("outbox unavailable")
+	}
+	o.queued = append(o.queued, msg)
+	return fmt.Sprintf("email-%d", len(o.queued)), nil
+}
+
+func (o *fakeOutbox) QueueBatch(ctx context.Context, batchID string, msgs []domain.EmailMessage, template string) ([]string, error) {
+	o.batches++
+	if o.batches == o.failBatch {
+		return nil, errors.New("outbox unavailable")
+	}
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i], _ = o.Queue(ctx, msg, template)
+	}
+	return ids, nil
+}
+
+func (o *fakeOutbox) Dispatch(emailIDs ...string) {
+	o.dispatched = append(o.dispatched, emailIDs...)
+}
+
+func (o *fakeOutbox) BatchStatus(ctx context.Context, batchID string) (map[domain.OutboxStatus]int, error) {
+	return o.counts, nil
+}
+
+// recipients returns who the queued emails are to.
+func (o *fakeOutbox) recipients() []string {
+	to := make([]string, len(o.queued))
+	for i, msg := range o.queued {
+		to[i] = msg.To
+	}
+	return to
+}
+
+// fakeTokens signs a scoped token as "purpose|subject".
+type fakeTokens struct {
+	domain.IJWTService
+}
+
+func (fakeTokens) GenerateScopedToken(subject, purpose string, ttl time.Duration) (string, error) {
+	return purpose + "|" + subject, nil
+}
+
+func (fakeTokens) ValidateScopedToken(token, purpose string) (string, error) {
+	tokenPurpose, subject, ok := strings.Cut(token, "|")
+	if !ok || tokenPurpose != purpose {
+		return "", domain.ErrInvalidToken
+	}
+	return subject, nil
+}
+
+func (fakeTokens) GenerateAccessToken(userID string, role string) (string, time.Duration, error) {
+	return "access|" + userID, time.Hour, nil
+}
+
+func (fakeTokens) GenerateRefreshToken(userID string, role string) (string, error) {
+	return "refresh|" + userID, nil
+}
+
+// inlineTx runs the function without a transaction.
+type inlineTx struct{}
+
+func (inlineTx) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeEmailRenderer renders every email as its name, in "en" and "fr".
+type fakeEmailRenderer struct {
+	domain.IEmailRenderer
+}
+
+func (fakeEmailRenderer) Render(ctx context.Context, name, locale string, data map[string]interface{}) (domain.RenderedEmail, error) {
+	return domain.RenderedEmail{Subject: name, Text: fmt.Sprint(data["Link"])}, nil
+}
+
+func (fakeEmailRenderer) Locales() []string { return []string{"en", "fr"} }
+
+type fakeUserRepo struct {
+	domain.IUserRepository
+	users map[string]*domain.User
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type fakeQueue struct{ keys []string }
+
+func (q *fakeQueue) Enqueue(key string) bool {
+	q.keys = append(q.keys, key)
+	return true
+}
+
+type newsletterTest struct {
+	uc     *NewsletterUseCase
+	subs   *fakeSubscriberRepo
+	issues *fakeIssueRepo
+	outbox *fakeOutbox
+	now    time.Time
+}
+
+func newNewsletterTest(blogs ...domain.Blog) *newsletterTest {
+	nt := &newsletterTest{
+		subs:   newFakeSubscriberRepo(),
+		issues: newFakeIssueRepo(),
+		outbox: &fakeOutbox{},
+		now:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	blogRepo := &fakeBlogRepo{}
+	if len(blogs) > 0 {
+		blogRepo.blog = blogs[0]
+	}
+	uc := NewNewsletterUseCase(nt.subs, nt.issues, blogRepo, &fakeUserRepo{}, fakeEmailRenderer{}, nt.outbox, fakeTokens{}, inlineTx{}, &fakeQueue{}, "https://inkforge.example").(*NewsletterUseCase)
+	uc.now = func() time.Time { return nt.now }
+	nt.uc = uc
+	return nt
+}
+
+func TestSubscribe_StoresASupportedLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+		wantErr  error
+	}{
+		{language: "", want: ""},
+		{language: "fr", want: "fr"},
+		{language: "fr_CA", want: "fr"},
+		{language: " EN-gb ", want: "en"},
+		{language: "de", wantErr: domain.ErrUnsupportedLocale},
+		{language: "=HYPERLINK(\"https://evil.example\")", wantErr: domain.ErrUnsupportedLocale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			nt := newNewsletterTest()
+			err := nt.uc.Subscribe(context.Background(), "reader@example.com", "", tt.language)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, nt.subs.subs)
+				return
+			}
+			require.NoError(t, err)
+			sub, err := nt.subs.FindByEmail(context.Background(), "reader@example.com", "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sub.Language)
+		})
+	}
+}
+
+func TestSubscribe_ResendsConfirmationOnlyAfterCooldown(t *testing.T) {
+	nt := newNewsletterTest()
+	ctx := context.Background()
+
+	require.NoError(t, nt.uc.Subscribe(ctx, "reader@example.com", "", ""))
+	nt.now = nt.now.Add(newsletterConfirmCooldown - time.Minute)
+	require.NoError(t, nt.uc.Subscribe(ctx, "reader@example.com", "", ""))
+	assert.Len(t, nt.outbox.queued, 1, "asking again within the cooldown sends nothing")
+
+	nt.now = nt.now.Add(2 * time.Minute)
+	require.NoError(t, nt.uc.Subscribe(ctx, "Reader@Example.com", "", ""))
+	assert.Len(t, nt.outbox.queued, 2)
+	assert.Len(t, nt.subs.subs, 1)
+}
+
+func TestConfirm_AcceptsOnlyConfirmationTokens(t *testing.T) {
+	nt := newNewsletterTest()
+	ctx := context.Background()
+	sub, _ := nt.subs.Create(ctx, domain.NewsletterSubscriber{Email: "reader@example.com", Status: domain.SubscriberPending})
+
+	unsubscribeToken, _ := fakeTokens{}.GenerateScopedToken(sub.Subscriber_id, NewsletterUnsubscribePurpose, time.Hour)
+	assert.ErrorIs(t, nt.uc.Confirm(ctx, unsubscribeToken), domain.ErrInvalidConfirmationToken)
+	assert.Equal(t, domain.SubscriberPending, nt.subs.subs[sub.Subscriber_id].Status)
+
+	confirmToken, _ := fakeTokens{}.GenerateScopedToken(sub.Subscriber_id, NewsletterConfirmPurpose, time.Hour)
+	require.NoError(t, nt.uc.Confirm(ctx, confirmToken))
+	assert.Equal(t, domain.SubscriberActive, nt.subs.subs[sub.Subscriber_id].Status)
+	assert.Equal(t, nt.now, nt.subs.subs[sub.Subscriber_id].Confirmed_at)
+}
+
+func TestUnsubscribe_AcceptsOnlyUnsubscribeTokens(t *testing.T) {
+	nt := newNewsletterTest()
+	ctx := context.Background()
+	sub := nt.subs.add("reader@example.com", "author-1")
+	issue, _ := nt.issues.Create(ctx, domain.NewsletterIssue{Author_id: "author-1", Status: domain.IssueSent})
+
+	confirmToken, _ := fakeTokens{}.GenerateScopedToken(sub.Subscriber_id+":"+issue.Issue_id, NewsletterConfirmPurpose, time.Hour)
+	assert.ErrorIs(t, nt.uc.Unsubscribe(ctx, confirmToken), domain.ErrInvalidUnsubscribeToken)
+	assert.Equal(t, domain.SubscriberActive, nt.subs.subs[sub.Subscriber_id].Status)
+
+	unsubscribeToken, _ := fakeTokens{}.GenerateScopedToken(sub.Subscriber_id+":"+issue.Issue_id, NewsletterUnsubscribePurpose, time.Hour)
+	require.NoError(t, nt.uc.Unsubscribe(ctx, unsubscribeToken))
+	assert.Equal(t, domain.SubscriberUnsubscribed, nt.subs.subs[sub.Subscriber_id].Status)
+	assert.Equal(t, 1, nt.issues.issues[issue.Issue_id].Unsubscribed)
+
+	require.NoError(t, nt.uc.Unsubscribe(ctx, unsubscribeToken))
+	assert.Equal(t, 1, nt.issues.issues[issue.Issue_id].Unsubscribed, "unsubscribing twice counts once")
+}
+
+func TestHandleBounce(t *testing.T) {
+	tests := []struct {
+		kind             domain.BounceKind
+		wantStatus       domain.SubscriberStatus
+		wantBounced      int
+		wantUnsubscribed int
+		wantErr          error
+	}{
+		{kind: domain.BounceSoft, wantStatus: domain.SubscriberActive},
+		{kind: domain.BounceHard, wantStatus: domain.SubscriberBounced, wantBounced: 1},
+		{kind: domain.BounceComplaint, wantStatus: domain.SubscriberUnsubscribed, wantUnsubscribed: 1},
+		{kind: "blocked", wantStatus: domain.SubscriberActive, wantErr: domain.ErrInvalidBounce},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			nt := newNewsletterTest()
+			ctx := context.Background()
+			ownList := nt.subs.add("reader@example.com", "author-1")
+			siteList := nt.subs.add("reader@example.com", "")
+			issue, _ := nt.issues.Create(ctx, domain.NewsletterIssue{Author_id: "author-1", Status: domain.IssueSent})
+
+			err := nt.uc.HandleBounce(ctx, "Reader@example.com", tt.kind, issue.Issue_id)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantStatus, nt.subs.subs[ownList.Subscriber_id].Status)
+			assert.Equal(t, tt.wantStatus, nt.subs.subs[siteList.Subscriber_id].Status, "every list of the email")
+			assert.Equal(t, tt.wantBounced, nt.issues.issues[issue.Issue_id].Bounced)
+			assert.Equal(t, tt.wantUnsubscribed, nt.issues.issues[issue.Issue_id].Unsubscribed)
+		})
+	}
+}
+
+func TestDeliverIssue_QueuesAuthorsSubscribersInBatches(t *testing.T) {
+	blog := domain.Blog{Blog_id: "blog-1", User_id: "author-1", Title: "Hello"}
+	nt := newNewsletterTest(blog)
+	ctx := context.Background()
+	for i := 0; i < 2*newsletterBatchSize+50; i++ {
+		nt.subs.add(fmt.Sprintf("reader-%03d@example.com", i), "author-1")
+	}
+	nt.subs.add("other@example.com", "author-2")
+	nt.subs.add("site@example.com", "")
+	issue, err := nt.uc.SendIssue(ctx, "author-1", "blog-1")
+	require.NoError(t, err)
+
+	require.NoError(t, nt.uc.DeliverIssue(ctx, issue.Issue_id))
+
+	assert.Equal(t, 3, nt.outbox.batches)
+	to := nt.outbox.recipients()
+	assert.Len(t, to, 2*newsletterBatchSize+50)
+	assert.NotContains(t, to, "other@example.com")
+	assert.NotContains(t, to, "site@example.com")
+	assert.Len(t, nt.outbox.dispatched, len(to))
+	sent := nt.issues.issues[issue.Issue_id]
+	assert.Equal(t, domain.IssueSent, sent.Status)
+	assert.Equal(t, 2*newsletterBatchSize+50, sent.Recipients)
+}
+
+func TestDeliverIssue_ResumesAfterPartialSend(t *testing.T) {
+	blog := domain.Blog{Blog_id: "blog-1", User_id: "author-1", Title: "Hello"}
+	nt := newNewsletterTest(blog)
+	ctx := context.Background()
+	for i := 0; i < newsletterBatchSize+50; i++ {
+		nt.subs.add(fmt.Sprintf("reader-%03d@example.com", i), "author-1")
+	}
+	issue, err := nt.uc.SendIssue(ctx, "author-1", "blog-1")
+	require.NoError(t, err)
+
+	nt.outbox.failBatch = 2
+	assert.Error(t, nt.uc.DeliverIssue(ctx, issue.Issue_id))
+	assert.Len(t, nt.outbox.queued, newsletterBatchSize)
+	assert.Equal(t, "reader-199@example.com", nt.issues.issues[issue.Issue_id].Cursor)
+
+	// the worker still holds the lease
+	require.NoError(t, nt.uc.DeliverIssue(ctx, issue.Issue_id))
+	assert.Len(t, nt.outbox.queued, newsletterBatchSize)
+
+	nt.now = nt.now.Add(newsletterIssueLease + time.Second)
+	require.NoError(t, nt.uc.DeliverIssue(ctx, issue.Issue_id))
+	to := nt.outbox.recipients()
+	assert.Len(t, to, newsletterBatchSize+50)
+	seen := make(map[string]bool)
+	for _, email := range to {
+		assert.False(t, seen[email], "%s mailed twice", email)
+		seen[email] = true
+	}
+	assert.Equal(t, domain.IssueSent, nt.issues.issues[issue.Issue_id].Status)
+}
+
+func TestIssueStats(t *testing.T) {
+	nt := newNewsletterTest()
+	ctx := context.Background()
+	sending, _ := nt.issues.Create(ctx, domain.NewsletterIssue{Author_id: "author-1", Recipients: 10, Bounced: 1})
+	finalized, _ := nt.issues.Create(ctx, domain.NewsletterIssue{Author_id: "author-1", Recipients: 10, Delivered: 9, Failed: 1, Finalized: true})
+	nt.outbox.counts = map[domain.OutboxStatus]int{domain.OutboxPending: 3, domain.OutboxSending: 1, domain.OutboxSent: 5, domain.OutboxDead: 1}
+
+	_, stats, err := nt.uc.IssueStats(ctx, "author-1", sending.Issue_id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewsletterIssueStats{Recipients: 10, Pending: 4, Delivered: 5, Failed: 1, Bounced: 1}, stats)
+
+	_, stats, err = nt.uc.IssueStats(ctx, "author-1", finalized.Issue_id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewsletterIssueStats{Recipients: 10, Delivered: 9, Failed: 1}, stats)
+
+	_, _, err = nt.uc.IssueStats(ctx, "author-2", sending.Issue_id)
+	assert.ErrorIs(t, err, domain.ErrIssueNotFound)
+}
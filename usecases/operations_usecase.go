@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+// OperationsUseCase implements domain.IOperationsUseCase
+type OperationsUseCase struct {
+	userRepo        domain.IUserRepository
+	contentRepo     domain.IContentRepository
+	passwordService domain.IPasswordService
+	now             func() time.Time
+}
+
+func NewOperationsUseCase(userRepo domain.IUserRepository, contentRepo domain.IContentRepository, passwordService domain.IPasswordService) domain.IOperationsUseCase {
+	return &OperationsUseCase{
+		userRepo:        userRepo,
+		contentRepo:     contentRepo,
+		passwordService: passwordService,
+		now:             time.Now,
+	}
+}
+
+func (uc *OperationsUseCase) CreateAdmin(ctx context.Context, email, username, password string) (*domain.User, bool, error) {
+	email = strings.TrimSpace(email)
+	if !validateEmail(email) {
+		return nil, false, domain.ErrInvalidEmailFormat
+	}
+
+	existing, err := uc.userRepo.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if err := uc.userRepo.UpdateRole(ctx, existing.UserID, domain.RoleAdmin); err != nil {
+			return nil, false, err
+		}
+		existing.Role = domain.RoleAdmin
+		return existing, false, nil
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return nil, false, err
+	}
+
+	if !validatePasswordStrength(password) {
+		return nil, false, domain.ErrWeakPassword
+	}
+	hashed, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", domain.ErrPasswordHashingFailed, err)
+	}
+
+	now := uc.now()
+	user := &domain.User{
+		Email:      email,
+		Password:   &hashed,
+		IsVerified: true,
+		Role:       domain.RoleAdmin,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if username = strings.TrimSpace(username); username != "" {
+		user.Username = &username
+	}
+	if err := uc.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+func (uc *OperationsUseCase) ResetPassword(ctx context.Context, email, password string) error {
+	if !validatePasswordStrength(password) {
+		return domain.ErrWeakPassword
+	}
+	user, err := uc.userRepo.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	hashed, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPasswordHashingFailed, err)
+	}
+
+	user.Password = &hashed
+	// a reset usually follows a compromised account, so its sessions end too
+	user.RefreshToken = nil
+	user.AccessToken = nil
+	user.UpdatedAt = uc.now()
+	return uc.userRepo.UpdateUser(ctx, user)
+}
+
+func (uc *OperationsUseCase) VerifyEmail(ctx context.Context, email string) error {
+	user, err := uc.userRepo.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	if user.IsVerified {
+		return nil
+	}
+	return uc.userRepo.SetEmailVerified(ctx, user.UserID)
+}
+
+func (uc *OperationsUseCase) ExportContent(ctx context.Context, sink domain.IContentSink) (domain.ContentCounts, error) {
+	var counts domain.ContentCounts
+	err := uc.contentRepo.EachTag(ctx, func(tag domain.Tag) error {
+		counts.Tags++
+		return sink.Tag(tag)
+	})
+	if err != nil {
+		return counts, err
+	}
+	err = uc.contentRepo.EachBlog(ctx, func(blog domain.Blog) error {
+		counts.Blogs++
+		return sink.Blog(blog)
+	})
+	if err != nil {
+		return counts, err
+	}
+	err = uc.contentRepo.EachComment(ctx, func(comment domain.Comment) error {
+		counts.Comments++
+		return sink.Comment(comment)
+	})
+	return counts, err
+}
+
+func (uc *OperationsUseCase) ImportContent(ctx context.Context, read func(domain.IContentSink) error) (domain.ContentCounts, error) {
+	importer := &contentImporter{ctx: ctx, repo: uc.contentRepo}
+	err := read(importer)
+	return importer.counts, err
+}
+
+// contentImporter upserts the content it receives.
+type contentImporter struct {
+	ctx    context.Context
+	repo   domain.IContentRepository
+	counts domain.ContentCounts
+}
+
+func (i *contentImporter) Tag(tag domain.Tag) error {
+	if err := i.repo.UpsertTag(i.ctx, tag); err != nil {
+		return fmt.Errorf("tag %s: %w", tag.Tag_id, err)
+	}
+	i.counts.Tags++
+	return nil
+}
+
+func (i *contentImporter) Blog(blog domain.Blog) error {
+	if err := i.repo.UpsertBlog(i.ctx, blog); err != nil {
+		return fmt.Errorf("blog %s: %w", blog.Blog_id, err)
+	}
+	i.counts.Blogs++
+	return nil
+}
+
+func (i *contentImporter) Comment(comment domain.Comment) error {
+	if err := i.repo.UpsertComment(i.ctx, comment); err != nil {
+		return fmt.Errorf("comment %s: %w", comment.Comment_id, err)
+	}
+	i.counts.Comments++
+	return nil
+}
@@ -0,0 +1,240 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/InkForge/Blog_Website/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUserRepo keeps users in memory, by ID.
+type memoryUserRepo struct {
+	domain.IUserRepository
+	users  map[string]*domain.User
+	nextID int
+}
+
+func newMemoryUserRepo(users ...*domain.User) *memoryUserRepo {
+	r := &memoryUserRepo{users: make(map[string]*domain.User)}
+	for _, user := range users {
+		r.CreateUser(context.Background(), user)
+	}
+	return r
+}
+
+func (r *memoryUserRepo) CreateUser(ctx context.Context, user *domain.User) error {
+	if user.UserID == "" {
+		r.nextID++
+		user.UserID = fmt.Sprintf("user-%d", r.nextID)
+	}
+	stored := *user
+	r.users[user.UserID] = &stored
+	return nil
+}
+
+func (r *memoryUserRepo) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *memoryUserRepo) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepo) UpdateUser(ctx context.Context, user *domain.User) error {
+	if _, ok := r.users[user.UserID]; !ok {
+		return domain.ErrUserNotFound
+	}
+	stored := *user
+	r.users[user.UserID] = &stored
+	return nil
+}
+
+func (r *memoryUserRepo) UpdateRole(ctx context.Context, userID string, role domain.Role) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Role = role
+	return nil
+}
+
+func (r *memoryUserRepo) UpdateTokens(ctx context.Context, userID string, accessToken string, refreshToken string) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.AccessToken, user.RefreshToken = &accessToken, &refreshToken
+	return nil
+}
+
+func (r *memoryUserRepo) FindByIdentity(ctx context.Context, provider, subjectID string) (*domain.User, error) {
+	for _, user := range r.users {
+		if identity, ok := user.Identity(provider); ok && identity.Subject_id == subjectID {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// AddIdentity also refuses a provider account linked to another user, as the
+// unique index on identities does.
+func (r *memoryUserRepo) AddIdentity(ctx context.Context, userID string, identity domain.LinkedIdentity) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if _, linked := user.Identity(identity.Provider); linked {
+		return domain.ErrIdentityAlreadyLinked
+	}
+	if _, err := r.FindByIdentity(ctx, identity.Provider, identity.Subject_id); err == nil {
+		return domain.ErrIdentityAlreadyLinked
+	}
+	user.Identities = append(user.Identities, identity)
+	return nil
+}
+
+func (r *memoryUserRepo) RemoveIdentity(ctx context.Context, userID, provider string) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	for i, identity := range user.Identities {
+		if identity.Provider == provider {
+			user.Identities = append(user.Identities[:i:i], user.Identities[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrIdentityNotLinked
+}
+
+// fakePasswords "hashes" a password by prefixing it.
+type fakePasswords struct{}
+
+func (fakePasswords) HashPassword(password string) (string, error) { return "hashed:" + password, nil }
+
+func (fakePasswords) ComparePassword(hashedPassword, inputPassword string) bool {
+	return hashedPassword == "hashed:"+inputPassword
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestCreateAdmin_PromotesExistingUser(t *testing.T) {
+	users := newMemoryUserRepo(&domain.User{Email: "someone@example.com", Role: domain.RoleUser, Password: strPtr("hashed:old")})
+	uc := NewOperationsUseCase(users, nil, fakePasswords{})
+
+	user, created, err := uc.CreateAdmin(context.Background(), " someone@example.com ", "", "")
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, domain.RoleAdmin, user.Role)
+	assert.Equal(t, domain.RoleAdmin, users.users[user.UserID].Role)
+	assert.Equal(t, "hashed:old", *users.users[user.UserID].Password, "promoting keeps the password")
+	assert.Len(t, users.users, 1)
+}
+
+func TestCreateAdmin_CreatesVerifiedAdmin(t *testing.T) {
+	users := newMemoryUserRepo()
+	uc := NewOperationsUseCase(users, nil, fakePasswords{})
+
+	_, _, err := uc.CreateAdmin(context.Background(), "admin@example.com", "admin", "short")
+	assert.ErrorIs(t, err, domain.ErrWeakPassword)
+	assert.Empty(t, users.users)
+
+	user, created, err := uc.CreateAdmin(context.Background(), "admin@example.com", "admin", "s3cretpassword")
+	require.NoError(t, err)
+	assert.True(t, created)
+	stored := users.users[user.UserID]
+	assert.Equal(t, domain.RoleAdmin, stored.Role)
+	assert.True(t, stored.IsVerified)
+	assert.Equal(t, "admin", *stored.Username)
+	assert.Equal(t, "hashed:s3cretpassword", *stored.Password)
+}
+
+func TestResetPassword_SignsUserOut(t *testing.T) {
+	users := newMemoryUserRepo(&domain.User{
+		Email:        "someone@example.com",
+		Password:     strPtr("hashed:old"),
+		AccessToken:  strPtr("access"),
+		RefreshToken: strPtr("refresh"),
+	})
+	uc := NewOperationsUseCase(users, nil, fakePasswords{})
+
+	assert.ErrorIs(t, uc.ResetPassword(context.Background(), "someone@example.com", "weak"), domain.ErrWeakPassword)
+
+	require.NoError(t, uc.ResetPassword(context.Background(), "someone@example.com", "n3wpassword"))
+	stored, _ := users.FindByEmail(context.Background(), "someone@example.com")
+	assert.Equal(t, "hashed:n3wpassword", *stored.Password)
+	assert.Nil(t, stored.AccessToken)
+	assert.Nil(t, stored.RefreshToken)
+
+	assert.ErrorIs(t, uc.ResetPassword(context.Background(), "nobody@example.com", "n3wpassword"), domain.ErrUserNotFound)
+}
+
+// fakeContentRepo records upserts and fails the blog with failBlog as ID.
+type fakeContentRepo struct {
+	domain.IContentRepository
+	failBlog string
+	upserted []string
+}
+
+func (r *fakeContentRepo) UpsertTag(ctx context.Context, tag domain.Tag) error {
+	r.upserted = append(r.upserted, "tag "+tag.Tag_id)
+	return nil
+}
+
+func (r *fakeContentRepo) UpsertBlog(ctx context.Context, blog domain.Blog) error {
+	if blog.Blog_id == r.failBlog {
+		return domain.ErrUpdatingDocument
+	}
+	r.upserted = append(r.upserted, "blog "+blog.Blog_id)
+	return nil
+}
+
+func (r *fakeContentRepo) UpsertComment(ctx context.Context, comment domain.Comment) error {
+	r.upserted = append(r.upserted, "comment "+comment.Comment_id)
+	return nil
+}
+
+func TestImportContent_CountsWhatWasUpsertedBeforeFailing(t *testing.T) {
+	content := &fakeContentRepo{failBlog: "b2"}
+	uc := NewOperationsUseCase(nil, content, fakePasswords{})
+
+	read := func(sink domain.IContentSink) error {
+		for _, id := range []string{"t1", "t2"} {
+			if err := sink.Tag(domain.Tag{Tag_id: id}); err != nil {
+				return err
+			}
+		}
+		for _, id := range []string{"b1", "b2", "b3"} {
+			if err := sink.Blog(domain.Blog{Blog_id: id}); err != nil {
+				return err
+			}
+		}
+		return sink.Comment(domain.Comment{Comment_id: "c1"})
+	}
+	counts, err := uc.ImportContent(context.Background(), read)
+
+	assert.ErrorIs(t, err, domain.ErrUpdatingDocument)
+	assert.Contains(t, err.Error(), "blog b2")
+	assert.Equal(t, domain.ContentCounts{Tags: 2, Blogs: 1}, counts)
+	assert.Equal(t, []string{"tag t1", "tag t2", "blog b1"}, content.upserted)
+
+	content.failBlog = ""
+	counts, err = uc.ImportContent(context.Background(), read)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ContentCounts{Tags: 2, Blogs: 3, Comments: 1}, counts)
+}
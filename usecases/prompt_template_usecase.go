@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const maxPromptRolloutWeight = 100
+
+// PromptTemplateUseCase implements domain.IPromptTemplateUseCase
+type PromptTemplateUseCase struct {
+	templateRepo domain.IPromptTemplateRepository
+	usageRepo    domain.IAIUsageRepository
+	renderer     domain.IPromptRenderer
+}
+
+func NewPromptTemplateUseCase(
+	templateRepo domain.IPromptTemplateRepository,
+	usageRepo domain.IAIUsageRepository,
+	renderer domain.IPromptRenderer,
+) domain.IPromptTemplateUseCase {
+	return &PromptTemplateUseCase{
+		templateRepo: templateRepo,
+		usageRepo:    usageRepo,
+		renderer:     renderer,
+	}
+}
+
+// ListTemplates returns every prompt the service uses with its latest stored
+// version and current rollout.
+func (uc *PromptTemplateUseCase) ListTemplates(ctx context.Context) ([]domain.PromptTemplateInfo, error) {
+	names := uc.renderer.Names()
+	infos := make([]domain.PromptTemplateInfo, 0, len(names))
+	for _, name := range names {
+		versions, err := uc.templateRepo.ListVersions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		rollout, err := uc.templateRepo.GetRollout(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		info := domain.PromptTemplateInfo{Name: name, Rollout: rollout}
+		if len(versions) > 0 {
+			info.Latest_version = versions[0].Version
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (uc *PromptTemplateUseCase) ListVersions(ctx context.Context, name string) ([]domain.PromptTemplate, []domain.PromptRollout, error) {
+	body, ok := uc.renderer.DefaultBody(name)
+	if !ok {
+		return nil, nil, domain.ErrUnknownPromptTemplate
+	}
+	versions, err := uc.templateRepo.ListVersions(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rollout, err := uc.templateRepo.GetRollout(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions = append(versions, domain.PromptTemplate{Name: name, Version: 0, Body: body})
+	return versions, rollout, nil
+}
+
+// CreateVersion stores body as a new version of name. The version gets no
+// traffic until it is added to the rollout.
+// It returns domain.ErrInvalidPromptTemplate if body does not parse or does
+// not render with the data the service provides.
+func (uc *PromptTemplateUseCase) CreateVersion(ctx context.Context, name, body, userID string) (domain.PromptTemplate, error) {
+	if err := uc.renderer.Validate(name, body); err != nil {
+		return domain.PromptTemplate{}, err
+	}
+	return uc.templateRepo.CreateVersion(ctx, domain.PromptTemplate{
+		Name:       name,
+		Body:       body,
+		Created_by: userID,
+	})
+}
+
+// SetRollout replaces the traffic split of name. Version 0 is the built-in
+// default; an empty rollout sends all traffic to it.
+func (uc *PromptTemplateUseCase) SetRollout(ctx context.Context, name string, rollout []domain.PromptRollout) error {
+	if _, ok := uc.renderer.DefaultBody(name); !ok {
+		return domain.ErrUnknownPromptTemplate
+	}
+
+	seen := make(map[int]bool, len(rollout))
+	for _, r := range rollout {
+		if r.Weight <= 0 || r.Weight > maxPromptRolloutWeight {
+			return fmt.Errorf("%w: weight of version %d must be between 1 and %d", domain.ErrInvalidPromptRollout, r.Version, maxPromptRolloutWeight)
+		}
+		if r.Version < 0 || seen[r.Version] {
+			return fmt.Errorf("%w: version %d is invalid or listed twice", domain.ErrInvalidPromptRollout, r.Version)
+		}
+		seen[r.Version] = true
+		if r.Version == 0 {
+			continue
+		}
+		if _, err := uc.templateRepo.GetVersion(ctx, name, r.Version); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.templateRepo.SetRollout(ctx, name, rollout); err != nil {
+		return err
+	}
+	uc.renderer.Invalidate(name)
+	return nil
+}
+
+// VersionStats compares the AI calls made with each version of name between from and to.
+func (uc *PromptTemplateUseCase) VersionStats(ctx context.Context, name string, from, to time.Time) ([]domain.PromptVersionStats, error) {
+	if _, ok := uc.renderer.DefaultBody(name); !ok {
+		return nil, domain.ErrUnknownPromptTemplate
+	}
+	return uc.usageRepo.PromptVersionStats(ctx, name, from, to)
+}
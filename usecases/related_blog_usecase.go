@@ -0,0 +1,394 @@
+package usecases
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/InkForge/Blog_Website/domain"
+)
+
+const (
+	relatedPerBlog      = 20
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 20
+	// users with very long histories add little signal and quadratic cost
+	maxSignalItemsPerUser = 200
+	// likewise tags on very many blogs, such as a catch-all "general"
+	maxSignalBlogsPerTag = 500
+	// nearest neighbours kept per blog by embedding similarity
+	maxEmbeddingCandidates = 50
+)
+
+// weights of each signal in the combined score; every signal is in [0, 1]
+const (
+	weightTags      = 0.4
+	weightCoLike    = 0.25
+	weightCoView    = 0.2
+	weightEmbedding = 0.15
+)
+
+// RelatedBlogUseCase implements domain.IRelatedBlogUseCase
+type RelatedBlogUseCase struct {
+	blogRepo       domain.IBlogRepository
+	blogViewRepo   domain.IBlogViewRepository
+	reactionRepo   domain.IBlogReactionRepository
+	relatedRepo    domain.IRelatedBlogRepository
+	chunkRepo      domain.IBlogChunkRepository
+	embeddingModel string
+}
+
+// NewRelatedBlogUseCase returns a recommendations usecase. chunkRepo may be nil,
+// in which case embedding similarity is left out of the score.
+func NewRelatedBlogUseCase(
+	blogRepo domain.IBlogRepository,
+	blogViewRepo domain.IBlogViewRepository,
+	reactionRepo domain.IBlogReactionRepository,
+	relatedRepo domain.IRelatedBlogRepository,
+	chunkRepo domain.IBlogChunkRepository,
+	embeddingModel string,
+) domain.IRelatedBlogUseCase {
+	return &RelatedBlogUseCase{
+		blogRepo:       blogRepo,
+		blogViewRepo:   blogViewRepo,
+		reactionRepo:   reactionRepo,
+		relatedRepo:    relatedRepo,
+		chunkRepo:      chunkRepo,
+		embeddingModel: embeddingModel,
+	}
+}
+
+// blogPair is an unordered pair of blog IDs, stored with a < b.
+type blogPair struct{ a, b string }
+
+func pairOf(x, y string) blogPair {
+	if x > y {
+		x, y = y, x
+	}
+	return blogPair{a: x, b: y}
+}
+
+// Recompute scores every pair of blogs on shared tags, users who liked or viewed
+// both and, if available, embedding similarity, and stores each blog's best matches.
+func (uc *RelatedBlogUseCase) Recompute(ctx context.Context) error {
+	started := time.Now()
+
+	tagsByBlog, err := uc.blogRepo.TagIDsByBlog(ctx)
+	if err != nil {
+		return err
+	}
+	likes, err := uc.reactionRepo.LikedBlogsByUser(ctx)
+	if err != nil {
+		return err
+	}
+	views, err := uc.blogViewRepo.ViewedBlogsByUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	scores := make(map[blogPair]float64)
+	addTagSignal(scores, tagsByBlog)
+	addCoOccurrenceSignal(scores, likes, tagsByBlog, weightCoLike)
+	addCoOccurrenceSignal(scores, views, tagsByBlog, weightCoView)
+	if uc.chunkRepo != nil {
+		vectors, err := uc.blogVectors(ctx)
+		if err != nil {
+			// the other signals are still worth publishing
+			slog.WarnContext(ctx, "related blogs: skipping embedding similarity", "error", err)
+		} else {
+			addEmbeddingSignal(scores, vectors)
+		}
+	}
+
+	relations := topRelations(scores, tagsByBlog, started)
+	if err := uc.relatedRepo.SaveAll(ctx, relations); err != nil {
+		return err
+	}
+	return uc.relatedRepo.DeleteComputedBefore(ctx, started)
+}
+
+// GetRelated serves the precomputed recommendations. Posts the reader already
+// viewed are skipped; a blog that has not been scored yet has none.
+func (uc *RelatedBlogUseCase) GetRelated(ctx context.Context, blogID, userID string, limit int) ([]domain.RelatedBlogPost, error) {
+	if blogID == "" {
+		return nil, domain.ErrBlogIDRequired
+	}
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	if limit > maxRelatedLimit {
+		limit = maxRelatedLimit
+	}
+
+	if _, err := uc.blogRepo.GetByID(ctx, blogID); err != nil {
+		return nil, err
+	}
+	relations, err := uc.relatedRepo.Get(ctx, blogID)
+	if errors.Is(err, domain.ErrNoRelatedBlogs) {
+		return []domain.RelatedBlogPost{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	candidateIDs := make([]string, 0, len(relations.Related))
+	for _, related := range relations.Related {
+		if related.Blog_id != blogID {
+			candidateIDs = append(candidateIDs, related.Blog_id)
+		}
+	}
+	viewed, err := uc.blogViewRepo.FilterViewed(ctx, userID, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[string]bool, len(viewed))
+	for _, id := range viewed {
+		skip[id] = true
+	}
+
+	var picked []domain.RelatedBlog
+	var pickedIDs []string
+	for _, related := range relations.Related {
+		if related.Blog_id == blogID || skip[related.Blog_id] {
+			continue
+		}
+		picked = append(picked, related)
+		pickedIDs = append(pickedIDs, related.Blog_id)
+		if len(picked) == limit {
+			break
+		}
+	}
+
+	blogs, err := uc.blogRepo.FindByIDs(ctx, pickedIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.Blog_id] = blog
+	}
+
+	posts := make([]domain.RelatedBlogPost, 0, len(picked))
+	for _, related := range picked {
+		// a post deleted since the last run is simply left out
+		if blog, ok := byID[related.Blog_id]; ok {
+			posts = append(posts, domain.RelatedBlogPost{Blog: blog, Score: related.Score})
+		}
+	}
+	return posts, nil
+}
+
+// addTagSignal adds the Jaccard similarity of the tag sets of every pair of
+// blogs sharing at least one tag. Tags on more than maxSignalBlogsPerTag blogs
+// still count towards the union but pair no blogs.
+func addTagSignal(scores map[blogPair]float64, tagsByBlog map[string][]string) {
+	blogsByTag := make(map[string][]string)
+	tagCount := make(map[string]int, len(tagsByBlog))
+	for blogID, tagIDs := range tagsByBlog {
+		unique := uniqueStrings(tagIDs)
+		tagCount[blogID] = len(unique)
+		for _, tagID := range unique {
+			blogsByTag[tagID] = append(blogsByTag[tagID], blogID)
+		}
+	}
+
+	shared := make(map[blogPair]int)
+	for _, blogIDs := range blogsByTag {
+		if len(blogIDs) > maxSignalBlogsPerTag {
+			continue
+		}
+		for i := range blogIDs {
+			for j := i + 1; j < len(blogIDs); j++ {
+				shared[pairOf(blogIDs[i], blogIDs[j])]++
+			}
+		}
+	}
+	for pair, n := range shared {
+		union := tagCount[pair.a] + tagCount[pair.b] - n
+		scores[pair] += weightTags * float64(n) / float64(union)
+	}
+}
+
+// addCoOccurrenceSignal adds, for every pair of blogs the same users engaged
+// with, the number of shared users over the geometric mean of each blog's users.
+// Blogs missing from existing are ignored.
+func addCoOccurrenceSignal(scores map[blogPair]float64, blogsByUser map[string][]string, existing map[string][]string, weight float64) {
+	users := make(map[string]int)
+	together := make(map[blogPair]int)
+	for _, blogIDs := range blogsByUser {
+		var known []string
+		for _, blogID := range uniqueStrings(blogIDs) {
+			if _, ok := existing[blogID]; ok {
+				known = append(known, blogID)
+			}
+		}
+		for _, blogID := range known {
+			users[blogID]++
+		}
+		if len(known) > maxSignalItemsPerUser {
+			continue
+		}
+		for i := range known {
+			for j := i + 1; j < len(known); j++ {
+				together[pairOf(known[i], known[j])]++
+			}
+		}
+	}
+
+	for pair, n := range together {
+		scores[pair] += weight * float64(n) / math.Sqrt(float64(users[pair.a]*users[pair.b]))
+	}
+}
+
+// addEmbeddingSignal adds the positive cosine similarity of blogs already
+// paired by tags or readers, keeping each blog's maxEmbeddingCandidates
+// nearest. Only those pairs are compared, so the cost follows the other
+// signals instead of growing with the square of the number of blogs.
+func addEmbeddingSignal(scores map[blogPair]float64, vectors map[string][]float32) {
+	nearest := make(map[string]*candidateHeap)
+	offer := func(blogID string, c candidate) {
+		h, ok := nearest[blogID]
+		if !ok {
+			h = &candidateHeap{}
+			nearest[blogID] = h
+		}
+		h.offer(c)
+	}
+	for pair := range scores {
+		a, okA := vectors[pair.a]
+		b, okB := vectors[pair.b]
+		if !okA || !okB {
+			continue
+		}
+		sim := domain.CosineSimilarity(a, b)
+		if sim <= 0 {
+			continue
+		}
+		offer(pair.a, candidate{blogID: pair.b, score: sim})
+		offer(pair.b, candidate{blogID: pair.a, score: sim})
+	}
+
+	for blogID, candidates := range nearest {
+		for _, c := range *candidates {
+			// a pair near each other from both sides is scored once
+			if blogID < c.blogID || !nearest[c.blogID].contains(blogID) {
+				scores[pairOf(blogID, c.blogID)] += weightEmbedding * c.score
+			}
+		}
+	}
+}
+
+type candidate struct {
+	blogID string
+	score  float64
+}
+
+// candidateHeap is a min-heap on score holding at most maxEmbeddingCandidates,
+// so the weakest of the kept candidates is on top.
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h candidateHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (h *candidateHeap) offer(c candidate) {
+	if h.Len() < maxEmbeddingCandidates {
+		heap.Push(h, c)
+	} else if c.score > (*h)[0].score {
+		(*h)[0] = c
+		heap.Fix(h, 0)
+	}
+}
+
+func (h candidateHeap) contains(blogID string) bool {
+	for _, c := range h {
+		if c.blogID == blogID {
+			return true
+		}
+	}
+	return false
+}
+
+// blogVectors averages each blog's chunk embeddings into one vector.
+func (uc *RelatedBlogUseCase) blogVectors(ctx context.Context) (map[string][]float32, error) {
+	sums := make(map[string][]float32)
+	counts := make(map[string]int)
+	err := uc.chunkRepo.ForEachByModel(ctx, uc.embeddingModel, func(chunk domain.BlogChunk) error {
+		sum, ok := sums[chunk.Blog_id]
+		if !ok {
+			sum = make([]float32, len(chunk.Embedding))
+			sums[chunk.Blog_id] = sum
+		}
+		if len(sum) != len(chunk.Embedding) {
+			return nil
+		}
+		for i, v := range chunk.Embedding {
+			sum[i] += v
+		}
+		counts[chunk.Blog_id]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for blogID, sum := range sums {
+		for i := range sum {
+			sum[i] /= float32(counts[blogID])
+		}
+	}
+	return sums, nil
+}
+
+// topRelations keeps the best scored matches of every existing blog.
+func topRelations(scores map[blogPair]float64, existing map[string][]string, computedAt time.Time) []domain.BlogRelations {
+	related := make(map[string][]domain.RelatedBlog, len(existing))
+	for pair, score := range scores {
+		_, okA := existing[pair.a]
+		_, okB := existing[pair.b]
+		if !okA || !okB || score <= 0 {
+			continue
+		}
+		related[pair.a] = append(related[pair.a], domain.RelatedBlog{Blog_id: pair.b, Score: score})
+		related[pair.b] = append(related[pair.b], domain.RelatedBlog{Blog_id: pair.a, Score: score})
+	}
+
+	relations := make([]domain.BlogRelations, 0, len(existing))
+	for blogID := range existing {
+		matches := related[blogID]
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].Score != matches[j].Score {
+				return matches[i].Score > matches[j].Score
+			}
+			return matches[i].Blog_id < matches[j].Blog_id
+		})
+		if len(matches) > relatedPerBlog {
+			matches = matches[:relatedPerBlog]
+		}
+		relations = append(relations, domain.BlogRelations{Blog_id: blogID, Related: matches, Computed_at: computedAt})
+	}
+	return relations
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
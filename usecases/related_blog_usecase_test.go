@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTagSignal_SkipsCrowdedTags(t *testing.T) {
+	tagsByBlog := map[string][]string{"a": {"go", "general"}, "b": {"go", "general"}}
+	for i := 0; i < maxSignalBlogsPerTag; i++ {
+		tagsByBlog[fmt.Sprintf("filler-%d", i)] = []string{"general"}
+	}
+
+	scores := make(map[blogPair]float64)
+	addTagSignal(scores, tagsByBlog)
+
+	// only "go" is counted as shared, though "general" stays in the union
+	assert.InDelta(t, weightTags/3, scores[pairOf("a", "b")], 1e-9)
+	assert.Len(t, scores, 1)
+}
+
+func TestAddEmbeddingSignal_KeepsNearestCandidates(t *testing.T) {
+	vectors := map[string][]float32{"query": {1, 0}}
+	for i := 0; i <= maxEmbeddingCandidates; i++ {
+		// each further blog points a little further away from the query
+		vectors[fmt.Sprintf("blog-%02d", i)] = []float32{1, float32(i+1) / 100}
+	}
+	// every blog shares a tag with every other
+	scores := make(map[blogPair]float64)
+	for a := range vectors {
+		for b := range vectors {
+			if a < b {
+				scores[pairOf(a, b)] = 0
+			}
+		}
+	}
+
+	addEmbeddingSignal(scores, vectors)
+
+	assert.Zero(t, scores[pairOf("query", fmt.Sprintf("blog-%02d", maxEmbeddingCandidates))])
+	assert.Greater(t, scores[pairOf("query", "blog-00")], 0.0)
+	for pair, score := range scores {
+		assert.LessOrEqual(t, score, weightEmbedding+1e-9, "pair %v scored more than once", pair)
+	}
+}
+
+func TestAddEmbeddingSignal_ComparesOnlyPairedBlogs(t *testing.T) {
+	vectors := map[string][]float32{"a": {1, 0}, "b": {1, 0}, "c": {1, 0}}
+	scores := map[blogPair]float64{pairOf("a", "b"): weightTags}
+
+	addEmbeddingSignal(scores, vectors)
+
+	assert.InDelta(t, weightTags+weightEmbedding, scores[pairOf("a", "b")], 1e-9)
+	assert.Len(t, scores, 1, "blogs sharing no tag or reader are not compared")
+}
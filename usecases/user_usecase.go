@@ -90,7 +90,7 @@ func (uc *UserUseCase) PromoteToAdmin(ctx context.Context, userID string) error
 	}
 
 	// promote to admin
-	return uc.UserRepo.UpdateRole(ctx, userID, "admin")
+	return uc.UserRepo.UpdateRole(ctx, userID, domain.RoleAdmin)
 }
 
 func (uc *UserUseCase) DemoteFromAdmin(ctx context.Context, userID string) error {
@@ -105,7 +105,7 @@ func (uc *UserUseCase) DemoteFromAdmin(ctx context.Context, userID string) error
 	}
 
 	// demote to user
-	return uc.UserRepo.UpdateRole(ctx, userID, "user")
+	return uc.UserRepo.UpdateRole(ctx, userID, domain.RoleUser)
 }
 
 